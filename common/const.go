@@ -10,7 +10,19 @@ const (
 const (
 	MsgData           MsgType = 0
 	MsgGpioData       MsgType = 1
-	MsgRpiStatus      MsgType = 2
+	MsgRpiStatus      MsgType = 2 // body is StationHealthTimeStruct
 	MsgItemStatus     MsgType = 3
 	MsgCameraSnapShot MsgType = 5
 )
+
+// Quality flags of data points, the SeaDataNet codes the IOC sea level manual also uses.
+// The larger of two flags among QCGood to QCBad is the worse one.
+const (
+	QCNone         QCFlag = 0 // no quality control
+	QCGood         QCFlag = 1
+	QCProbablyGood QCFlag = 2
+	QCProbablyBad  QCFlag = 3
+	QCBad          QCFlag = 4
+	QCChanged      QCFlag = 5
+	QCMissing      QCFlag = 9
+)
@@ -9,6 +9,7 @@ import (
 
 type Status = string
 type MsgType = uint8
+type QCFlag = uint8
 type StringMsecMap = map[string]custype.UnixMs
 type StringMapMap = map[string]map[string]string // map[deviceName]map[itemType]itemName
 type UUIDStringsMap = map[uuid.UUID][]string
@@ -17,6 +18,9 @@ type StationInfoStruct struct {
 	Identifier string       `json:"identifier"`
 	Devices    StringMapMap `json:"devices"`
 	Cameras    []string     `json:"cameras"`
+	// Only reported by Sync V2 stations, see the DeviceConfigPush frame.
+	DeviceConfigVersion int64  `json:"device_config_version,omitempty"`
+	DeviceConfigError   string `json:"device_config_error,omitempty"`
 }
 
 type SendMsgStruct struct {
@@ -29,18 +33,39 @@ type ReceiveMsgStruct struct {
 	Body json.RawMessage `json:"body"`
 }
 
-type RpiStatusStruct struct {
-	CpuTemp float64 `json:"cpu_temp"`
+type DiskUsageStruct struct {
+	Path       string `json:"path"`
+	FreeBytes  uint64 `json:"free_bytes"`
+	TotalBytes uint64 `json:"total_bytes"`
 }
 
-type RpiStatusTimeStruct struct {
-	RpiStatusStruct
+// StationHealthStruct is the state of the station host, CpuTemp is nil without a temperature sensor.
+// Its json is compatible with the cpu_temp only status of older stations.
+type StationHealthStruct struct {
+	CpuTemp           *float64          `json:"cpu_temp"`
+	Disks             []DiskUsageStruct `json:"disks,omitempty"`
+	MemTotalBytes     uint64            `json:"mem_total_bytes,omitempty"`
+	MemAvailableBytes uint64            `json:"mem_available_bytes,omitempty"`
+	Load1             float64           `json:"load1,omitempty"`
+	Load5             float64           `json:"load5,omitempty"`
+	Load15            float64           `json:"load15,omitempty"`
+	UptimeSeconds     uint64            `json:"uptime_seconds,omitempty"`
+	DbSizeBytes       uint64            `json:"db_size_bytes,omitempty"`
+	ReplayBacklog     uint64            `json:"replay_backlog,omitempty"`
+	SerialReopens     map[string]uint32 `json:"serial_reopens,omitempty"`
+	ClockOffsetMs     int64             `json:"clock_offset_ms,omitempty"`
+	ClockSynced       bool              `json:"clock_synced,omitempty"`
+}
+
+type StationHealthTimeStruct struct {
+	StationHealthStruct
 	Millisecond custype.UnixMs `json:"msec"`
 }
 
 type DataTimeStruct struct {
 	Value       float64        `json:"val"`
 	Millisecond custype.UnixMs `json:"msec"`
+	Flag        QCFlag         `json:"flag,omitempty"`
 }
 
 type ItemNameDataTimeStruct struct {
@@ -48,6 +73,12 @@ type ItemNameDataTimeStruct struct {
 	DataTimeStruct
 }
 
+// SeqItemNameDataTimeStruct is a data point with its station outbox seq.
+type SeqItemNameDataTimeStruct struct {
+	Seq int64 `json:"seq"`
+	ItemNameDataTimeStruct
+}
+
 type StatusChangeStruct struct {
 	Status    Status         `json:"status"`
 	ChangedAt custype.UnixMs `json:"changed_at"`
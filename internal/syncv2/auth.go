@@ -0,0 +1,50 @@
+package syncv2
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	// ErrCodeUnauthenticated is the ErrorFrame code sent when a station fails the handshake auth.
+	ErrCodeUnauthenticated = "unauthenticated"
+
+	authChallengeBytes = 32
+	authKeyBytes       = 32
+)
+
+// NewAuthChallenge returns a random challenge for ServerHello.auth_challenge.
+func NewAuthChallenge() ([]byte, error) {
+	challenge := make([]byte, authChallengeBytes)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// NewAuthKey returns a random hex encoded station auth key.
+func NewAuthKey() (string, error) {
+	key := make([]byte, authKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// AuthMAC computes HMAC-SHA256(authKey, challenge || stationIdentifier).
+func AuthMAC(authKey string, challenge []byte, stationIdentifier string) []byte {
+	mac := hmac.New(sha256.New, []byte(authKey))
+	mac.Write(challenge)
+	mac.Write([]byte(stationIdentifier))
+	return mac.Sum(nil)
+}
+
+// VerifyAuthMAC reports whether got is the expected MAC, in constant time.
+func VerifyAuthMAC(authKey string, challenge []byte, stationIdentifier string, got []byte) bool {
+	if authKey == "" || len(challenge) == 0 {
+		return false
+	}
+	return hmac.Equal(AuthMAC(authKey, challenge, stationIdentifier), got)
+}
@@ -0,0 +1,138 @@
+package syncv2
+
+import (
+	"errors"
+	"math"
+	"slices"
+
+	syncpb "tide/pkg/pb/syncproto"
+)
+
+// maxValueScale is the most decimal places tried when encoding values as scaled integers.
+const maxValueScale = 9
+
+// DataColumns encodes points as one column per item and kind, keeping the point order within each column.
+func DataColumns(points []*syncpb.DataPoint) []*syncpb.DataColumn {
+	type key struct {
+		item string
+		kind syncpb.DataKind
+	}
+	var (
+		columns []*syncpb.DataColumn
+		groups  = make(map[key][]*syncpb.DataPoint)
+	)
+	for _, p := range points {
+		k := key{item: p.ItemName, kind: p.Kind}
+		if _, ok := groups[k]; !ok {
+			columns = append(columns, &syncpb.DataColumn{ItemName: p.ItemName, Kind: p.Kind})
+		}
+		groups[k] = append(groups[k], p)
+	}
+	for _, c := range columns {
+		encodeColumn(c, groups[key{item: c.ItemName, kind: c.Kind}])
+	}
+	return columns
+}
+
+func encodeColumn(c *syncpb.DataColumn, points []*syncpb.DataPoint) {
+	c.UnixMsDelta = make([]int64, len(points))
+	hasSeq := false
+	var prevMs int64
+	for i, p := range points {
+		c.UnixMsDelta[i] = p.UnixMs - prevMs
+		prevMs = p.UnixMs
+		hasSeq = hasSeq || p.Seq != 0
+	}
+	if hasSeq {
+		c.SeqDelta = make([]int64, len(points))
+		var prevSeq int64
+		for i, p := range points {
+			c.SeqDelta[i] = p.Seq - prevSeq
+			prevSeq = p.Seq
+		}
+	}
+	// Flags are left out when no point of the column was quality controlled.
+	if slices.ContainsFunc(points, func(p *syncpb.DataPoint) bool { return p.Flag != 0 }) {
+		c.Flags = make([]uint32, len(points))
+		for i, p := range points {
+			c.Flags[i] = p.Flag
+		}
+	}
+
+	c.ValueScale = -1
+	for scale := int32(0); scale <= maxValueScale; scale++ {
+		if deltas, ok := scaledValueDeltas(points, scale); ok {
+			c.ValueScale, c.ValueDelta = scale, deltas
+			return
+		}
+	}
+	c.Values = make([]float64, len(points))
+	for i, p := range points {
+		c.Values[i] = p.Value
+	}
+}
+
+// scaledValueDeltas returns the deltas of the values times 10^scale, if every value survives the round trip exactly.
+func scaledValueDeltas(points []*syncpb.DataPoint, scale int32) ([]int64, bool) {
+	pow := math.Pow10(int(scale))
+	deltas := make([]int64, len(points))
+	var prev int64
+	for i, p := range points {
+		f := math.Round(p.Value * pow)
+		if math.Abs(f) > 1<<53 {
+			return nil, false
+		}
+		n := int64(f)
+		if math.Float64bits(float64(n)/pow) != math.Float64bits(p.Value) {
+			return nil, false
+		}
+		deltas[i] = n - prev
+		prev = n
+	}
+	return deltas, true
+}
+
+// DataBatchPoints returns the points of batch followed by the decoded points of its columns.
+func DataBatchPoints(batch *syncpb.DataBatch) ([]*syncpb.DataPoint, error) {
+	if len(batch.Columns) == 0 {
+		return batch.Points, nil
+	}
+	points := append([]*syncpb.DataPoint(nil), batch.Points...)
+	for _, c := range batch.Columns {
+		n := len(c.UnixMsDelta)
+		if len(c.SeqDelta) != 0 && len(c.SeqDelta) != n {
+			return nil, errors.New("invalid data column seq length")
+		}
+		if len(c.Flags) != 0 && len(c.Flags) != n {
+			return nil, errors.New("invalid data column flag length")
+		}
+		if c.ValueScale > maxValueScale {
+			return nil, errors.New("invalid data column value scale")
+		}
+		if c.ValueScale >= 0 && len(c.ValueDelta) != n || c.ValueScale < 0 && len(c.Values) != n {
+			return nil, errors.New("invalid data column value length")
+		}
+
+		pow := math.Pow10(int(max(c.ValueScale, 0)))
+		var ms, seq, scaled int64
+		for i := range n {
+			ms += c.UnixMsDelta[i]
+			p := &syncpb.DataPoint{ItemName: c.ItemName, Kind: c.Kind, UnixMs: ms}
+			if len(c.SeqDelta) != 0 {
+				seq += c.SeqDelta[i]
+				p.Seq = seq
+			}
+			if len(c.Flags) != 0 {
+				p.Flag = c.Flags[i]
+			}
+			if c.ValueScale >= 0 {
+				scaled += c.ValueDelta[i]
+				p.Value = float64(scaled) / pow
+			} else {
+				p.Value = c.Values[i]
+			}
+			points = append(points, p)
+		}
+	}
+	return points, nil
+}
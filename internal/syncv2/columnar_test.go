@@ -0,0 +1,68 @@
+package syncv2
+
+import (
+	"math"
+	"testing"
+
+	syncpb "tide/pkg/pb/syncproto"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDataColumns_RoundTrip(t *testing.T) {
+	points := []*syncpb.DataPoint{
+		{ItemName: "item1", Value: 1.25, UnixMs: 60000, Kind: syncpb.DataKind_DATA_KIND_NORMAL, Seq: 10},
+		{ItemName: "item2", Value: math.Pi, UnixMs: 60000, Kind: syncpb.DataKind_DATA_KIND_NORMAL, Seq: 11},
+		{ItemName: "item1", Value: -0.5, UnixMs: 120000, Kind: syncpb.DataKind_DATA_KIND_NORMAL, Seq: 12, Flag: 4},
+		{ItemName: "item2", Value: math.Copysign(0, -1), UnixMs: 30000, Kind: syncpb.DataKind_DATA_KIND_NORMAL, Seq: 13},
+		{ItemName: "item1", Value: 3, UnixMs: 180000, Kind: syncpb.DataKind_DATA_KIND_GPIO},
+	}
+	columns := DataColumns(points)
+	require.Len(t, columns, 3)
+	require.EqualValues(t, 2, columns[0].ValueScale)
+	require.EqualValues(t, -1, columns[1].ValueScale)
+	require.Empty(t, columns[2].SeqDelta)
+	require.Equal(t, []uint32{0, 4}, columns[0].Flags)
+	require.Empty(t, columns[1].Flags)
+
+	got, err := DataBatchPoints(&syncpb.DataBatch{Columns: columns})
+	require.NoError(t, err)
+	want := []*syncpb.DataPoint{points[0], points[2], points[1], points[3], points[4]}
+	require.Len(t, got, len(want))
+	for i := range want {
+		require.True(t, proto.Equal(want[i], got[i]), "point %d: want %v, got %v", i, want[i], got[i])
+		require.Equal(t, math.Float64bits(want[i].Value), math.Float64bits(got[i].Value))
+	}
+}
+
+func TestDataColumns_Smaller(t *testing.T) {
+	var points []*syncpb.DataPoint
+	for i := range 1000 {
+		points = append(points, &syncpb.DataPoint{
+			ItemName: "water_level",
+			Value:    float64(1500+i%7) / 1000,
+			UnixMs:   1700000000000 + int64(i)*60000,
+			Kind:     syncpb.DataKind_DATA_KIND_NORMAL,
+			Seq:      int64(100000 + i),
+		})
+	}
+	rowSize := proto.Size(&syncpb.DataBatch{Points: points})
+	columnSize := proto.Size(&syncpb.DataBatch{Columns: DataColumns(points)})
+	require.Less(t, columnSize*5, rowSize)
+}
+
+func TestDataBatchPoints_Invalid(t *testing.T) {
+	for name, c := range map[string]*syncpb.DataColumn{
+		"seq length":   {UnixMsDelta: []int64{1, 2}, SeqDelta: []int64{1}, ValueDelta: []int64{1, 2}},
+		"value length": {UnixMsDelta: []int64{1, 2}, ValueDelta: []int64{1}},
+		"raw length":   {UnixMsDelta: []int64{1, 2}, ValueScale: -1, Values: []float64{1}},
+		"value scale":  {UnixMsDelta: []int64{1}, ValueScale: 30, ValueDelta: []int64{1}},
+		"flag length":  {UnixMsDelta: []int64{1, 2}, ValueDelta: []int64{1, 2}, Flags: []uint32{1}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := DataBatchPoints(&syncpb.DataBatch{Columns: []*syncpb.DataColumn{c}})
+			require.Error(t, err)
+		})
+	}
+}
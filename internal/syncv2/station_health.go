@@ -0,0 +1,56 @@
+package syncv2
+
+import (
+	"maps"
+
+	"tide/common"
+	"tide/pkg/custype"
+
+	syncpb "tide/pkg/pb/syncproto"
+)
+
+func StationHealthToPB(h common.StationHealthTimeStruct) *syncpb.StationHealth {
+	ret := &syncpb.StationHealth{
+		UnixMs:            h.Millisecond.ToInt64(),
+		CpuTemp:           h.CpuTemp,
+		MemTotalBytes:     h.MemTotalBytes,
+		MemAvailableBytes: h.MemAvailableBytes,
+		Load1:             h.Load1,
+		Load5:             h.Load5,
+		Load15:            h.Load15,
+		UptimeSeconds:     h.UptimeSeconds,
+		DbSizeBytes:       h.DbSizeBytes,
+		ReplayBacklog:     h.ReplayBacklog,
+		SerialReopens:     maps.Clone(h.SerialReopens),
+		ClockOffsetMs:     h.ClockOffsetMs,
+		ClockSynced:       h.ClockSynced,
+	}
+	for _, d := range h.Disks {
+		ret.Disks = append(ret.Disks, &syncpb.DiskUsage{Path: d.Path, FreeBytes: d.FreeBytes, TotalBytes: d.TotalBytes})
+	}
+	return ret
+}
+
+func PBToStationHealth(h *syncpb.StationHealth) common.StationHealthTimeStruct {
+	ret := common.StationHealthTimeStruct{
+		StationHealthStruct: common.StationHealthStruct{
+			CpuTemp:           h.CpuTemp,
+			MemTotalBytes:     h.MemTotalBytes,
+			MemAvailableBytes: h.MemAvailableBytes,
+			Load1:             h.Load1,
+			Load5:             h.Load5,
+			Load15:            h.Load15,
+			UptimeSeconds:     h.UptimeSeconds,
+			DbSizeBytes:       h.DbSizeBytes,
+			ReplayBacklog:     h.ReplayBacklog,
+			SerialReopens:     maps.Clone(h.SerialReopens),
+			ClockOffsetMs:     h.ClockOffsetMs,
+			ClockSynced:       h.ClockSynced,
+		},
+		Millisecond: custype.UnixMs(h.UnixMs),
+	}
+	for _, d := range h.Disks {
+		ret.Disks = append(ret.Disks, common.DiskUsageStruct{Path: d.Path, FreeBytes: d.FreeBytes, TotalBytes: d.TotalBytes})
+	}
+	return ret
+}
@@ -8,9 +8,11 @@ import (
 
 func StationInfoToPB(info common.StationInfoStruct) *syncpb.StationInfo {
 	ret := &syncpb.StationInfo{
-		Identifier: info.Identifier,
-		Devices:    make(map[string]*syncpb.DeviceItems, len(info.Devices)),
-		Cameras:    append([]string(nil), info.Cameras...),
+		Identifier:          info.Identifier,
+		Devices:             make(map[string]*syncpb.DeviceItems, len(info.Devices)),
+		Cameras:             append([]string(nil), info.Cameras...),
+		DeviceConfigVersion: info.DeviceConfigVersion,
+		DeviceConfigError:   info.DeviceConfigError,
 	}
 	for deviceName, items := range info.Devices {
 		di := &syncpb.DeviceItems{Items: make(map[string]string, len(items))}
@@ -24,9 +26,11 @@ func StationInfoToPB(info common.StationInfoStruct) *syncpb.StationInfo {
 
 func PBToStationInfo(info *syncpb.StationInfo) common.StationInfoStruct {
 	ret := common.StationInfoStruct{
-		Identifier: info.Identifier,
-		Devices:    make(common.StringMapMap),
-		Cameras:    append([]string(nil), info.Cameras...),
+		Identifier:          info.Identifier,
+		Devices:             make(common.StringMapMap),
+		Cameras:             append([]string(nil), info.Cameras...),
+		DeviceConfigVersion: info.DeviceConfigVersion,
+		DeviceConfigError:   info.DeviceConfigError,
 	}
 	for deviceName, items := range info.Devices {
 		ret.Devices[deviceName] = make(map[string]string)
@@ -0,0 +1,179 @@
+// Package logring keeps the latest slog records in memory so they can be read remotely.
+package logring
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Attr struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type Entry struct {
+	Time    time.Time  `json:"time"`
+	Level   slog.Level `json:"level"`
+	Message string     `json:"message"`
+	Attrs   []Attr     `json:"attrs,omitempty"`
+}
+
+// Filter selects entries by minimum level and device.
+type Filter struct {
+	MinLevel slog.Level
+	// Device matches the value of a device, device_name or model attribute, or a word of the message, ignoring case.
+	Device string
+}
+
+func (f Filter) Match(e Entry) bool {
+	if e.Level < f.MinLevel {
+		return false
+	}
+	if f.Device == "" {
+		return true
+	}
+	for _, a := range e.Attrs {
+		switch a.Key {
+		case "device", "device_name", "model":
+			if strings.EqualFold(a.Value, f.Device) {
+				return true
+			}
+		}
+	}
+	return slices.ContainsFunc(strings.Fields(e.Message), func(w string) bool { return strings.EqualFold(w, f.Device) })
+}
+
+// Buffer is a fixed size ring of entries with live subscribers.
+type Buffer struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+	subs    map[chan Entry]struct{}
+}
+
+func NewBuffer(size int) *Buffer {
+	return &Buffer{
+		entries: make([]Entry, size),
+		subs:    make(map[chan Entry]struct{}),
+	}
+}
+
+func (b *Buffer) add(e Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) > 0 {
+		b.entries[b.next] = e
+		b.next = (b.next + 1) % len(b.entries)
+		b.full = b.full || b.next == 0
+	}
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default: // A slow subscriber misses entries rather than blocking the logger.
+		}
+	}
+}
+
+// Entries returns the buffered entries matching f, oldest first, at most limit of the newest if limit > 0.
+func (b *Buffer) Entries(f Filter, limit int) []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var ordered []Entry
+	if b.full {
+		ordered = append(ordered, b.entries[b.next:]...)
+	}
+	ordered = append(ordered, b.entries[:b.next]...)
+
+	var ret []Entry
+	for _, e := range ordered {
+		if f.Match(e) {
+			ret = append(ret, e)
+		}
+	}
+	if limit > 0 && len(ret) > limit {
+		ret = ret[len(ret)-limit:]
+	}
+	return ret
+}
+
+// Subscribe returns a channel receiving new entries until cancel is called.
+func (b *Buffer) Subscribe(capacity int) (ch <-chan Entry, cancel func()) {
+	c := make(chan Entry, capacity)
+	b.mu.Lock()
+	b.subs[c] = struct{}{}
+	b.mu.Unlock()
+	return c, func() {
+		b.mu.Lock()
+		delete(b.subs, c)
+		b.mu.Unlock()
+	}
+}
+
+// Handler passes records to another handler and copies them into a Buffer.
+type Handler struct {
+	next   slog.Handler
+	buf    *Buffer
+	attrs  []Attr
+	prefix string
+}
+
+func NewHandler(next slog.Handler, buf *Buffer) *Handler {
+	return &Handler{next: next, buf: buf}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	e := Entry{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: slices.Clone(h.attrs)}
+	r.Attrs(func(a slog.Attr) bool {
+		e.Attrs = appendAttr(e.Attrs, h.prefix, a)
+		return true
+	})
+	h.buf.add(e)
+	return h.next.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	h2.attrs = slices.Clone(h.attrs)
+	for _, a := range attrs {
+		h2.attrs = appendAttr(h2.attrs, h.prefix, a)
+	}
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+func appendAttr(attrs []Attr, prefix string, a slog.Attr) []Attr {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			attrs = appendAttr(attrs, groupPrefix, ga)
+		}
+		return attrs
+	}
+	return append(attrs, Attr{Key: prefix + a.Key, Value: a.Value.String()})
+}
@@ -0,0 +1,66 @@
+package logring
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(size int) (*slog.Logger, *Buffer) {
+	buf := NewBuffer(size)
+	next := slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(NewHandler(next, buf)), buf
+}
+
+func TestBuffer_KeepsNewestEntries(t *testing.T) {
+	logger, buf := newTestLogger(3)
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		logger.Info(msg)
+	}
+	var msgs []string
+	for _, e := range buf.Entries(Filter{MinLevel: slog.LevelDebug}, 0) {
+		msgs = append(msgs, e.Message)
+	}
+	assert.Equal(t, []string{"b", "c", "d"}, msgs)
+
+	entries := buf.Entries(Filter{MinLevel: slog.LevelDebug}, 1)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "d", entries[0].Message)
+}
+
+func TestBuffer_Filter(t *testing.T) {
+	logger, buf := newTestLogger(10)
+	logger.Debug("Polling", "device", "PLS-C")
+	logger.Error("Failed to read line from PWD50 device", "error", "timeout")
+	logger.With("model", "VEGAPULS61").WithGroup("req").Warn("Unexpected status byte", "status", 3)
+
+	entries := buf.Entries(Filter{MinLevel: slog.LevelWarn}, 0)
+	require.Len(t, entries, 2)
+
+	entries = buf.Entries(Filter{Device: "pwd50"}, 0)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []Attr{{Key: "error", Value: "timeout"}}, entries[0].Attrs)
+
+	entries = buf.Entries(Filter{MinLevel: slog.LevelDebug, Device: "PLS-C"}, 0)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Polling", entries[0].Message)
+
+	entries = buf.Entries(Filter{Device: "VEGAPULS61"}, 0)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []Attr{{Key: "model", Value: "VEGAPULS61"}, {Key: "req.status", Value: "3"}}, entries[0].Attrs)
+}
+
+func TestBuffer_Subscribe(t *testing.T) {
+	logger, buf := newTestLogger(10)
+	ch, cancel := buf.Subscribe(1)
+	logger.Info("first")
+	logger.Info("dropped")
+	assert.Equal(t, "first", (<-ch).Message)
+
+	cancel()
+	logger.Info("after cancel")
+	assert.Empty(t, ch)
+}
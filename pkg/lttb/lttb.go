@@ -0,0 +1,52 @@
+// Package lttb downsamples series for charts with the Largest-Triangle-Three-Buckets algorithm.
+package lttb
+
+// Downsample returns threshold points of points, which are ordered by x, keeping the shape of the series:
+// the first and the last point, and from each bucket in between the point making the largest triangle with
+// the point kept from the previous bucket and the average of the next bucket.
+// points is returned as it is if it has no more than threshold points or threshold is less than 3.
+func Downsample[T any](points []T, threshold int, xy func(T) (x, y float64)) []T {
+	if threshold < 3 || len(points) <= threshold {
+		return points
+	}
+
+	sampled := make([]T, 0, threshold)
+	sampled = append(sampled, points[0])
+	// The points between the first and the last are split into threshold-2 buckets.
+	every := float64(len(points)-2) / float64(threshold-2)
+	a := 0
+	for i := range threshold - 2 {
+		start := int(float64(i)*every) + 1
+		end := int(float64(i+1)*every) + 1
+
+		// Average of the next bucket, the last point for the last bucket.
+		nextStart, nextEnd := end, min(int(float64(i+2)*every)+1, len(points))
+		if i == threshold-3 {
+			nextStart, nextEnd = len(points)-1, len(points)
+		}
+		var avgX, avgY float64
+		for _, p := range points[nextStart:nextEnd] {
+			x, y := xy(p)
+			avgX += x
+			avgY += y
+		}
+		avgX /= float64(nextEnd - nextStart)
+		avgY /= float64(nextEnd - nextStart)
+
+		ax, ay := xy(points[a])
+		maxArea, maxIndex := -1.0, start
+		for j := start; j < end; j++ {
+			x, y := xy(points[j])
+			area := (ax-avgX)*(y-ay) - (ax-x)*(avgY-ay)
+			if area < 0 {
+				area = -area
+			}
+			if area > maxArea {
+				maxArea, maxIndex = area, j
+			}
+		}
+		sampled = append(sampled, points[maxIndex])
+		a = maxIndex
+	}
+	return append(sampled, points[len(points)-1])
+}
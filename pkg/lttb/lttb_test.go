@@ -0,0 +1,53 @@
+package lttb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type point struct{ x, y float64 }
+
+func xy(p point) (float64, float64) { return p.x, p.y }
+
+func TestDownsample_KeepsShortSeries(t *testing.T) {
+	points := []point{{0, 1}, {1, 2}, {2, 3}}
+	assert.Equal(t, points, Downsample(points, 3, xy))
+	assert.Equal(t, points, Downsample(points, 10, xy))
+	assert.Equal(t, points, Downsample(points, 2, xy))
+}
+
+func TestDownsample_KeepsPeaks(t *testing.T) {
+	points := make([]point, 1000)
+	for i := range points {
+		points[i] = point{float64(i), 0}
+	}
+	points[250].y = 10
+	points[700].y = -10
+
+	got := Downsample(points, 20, xy)
+	require.Len(t, got, 20)
+	assert.Equal(t, points[0], got[0])
+	assert.Equal(t, points[999], got[19])
+	assert.Contains(t, got, points[250])
+	assert.Contains(t, got, points[700])
+	for i := 1; i < len(got); i++ {
+		assert.Less(t, got[i-1].x, got[i].x)
+	}
+}
+
+func TestDownsample_Sine(t *testing.T) {
+	points := make([]point, 10000)
+	for i := range points {
+		points[i] = point{float64(i), math.Sin(float64(i) / 500)}
+	}
+	got := Downsample(points, 500, xy)
+	require.Len(t, got, 500)
+	var top float64
+	for _, p := range got {
+		top = max(top, p.y)
+	}
+	assert.InDelta(t, 1, top, 1e-3)
+}
@@ -0,0 +1,277 @@
+// Package netcdf streams files in the netCDF classic 64-bit offset format (CDF-2) holding scalar variables
+// and variables along one record dimension, such as the time series of a station.
+package netcdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+type Type int32
+
+const (
+	Byte   Type = 1
+	Char   Type = 2
+	Int    Type = 4
+	Double Type = 6
+)
+
+// Default fill values of the types, for the records without a value.
+const (
+	FillByte   int8    = -127
+	FillInt    int32   = -2147483647
+	FillDouble float64 = 9.9692099683868690e+36
+)
+
+const (
+	tagDimension = 0x0A
+	tagVariable  = 0x0B
+	tagAttribute = 0x0C
+)
+
+// Attr is an attribute, its value is a string, an int8, int32 or float64, or a slice of them.
+type Attr struct {
+	Name  string
+	Value any
+}
+
+// Var is a variable along the record dimension, or a scalar of Value if Scalar is set.
+type Var struct {
+	Name   string
+	Type   Type
+	Attrs  []Attr
+	Scalar bool
+	Value  any
+}
+
+// File describes the content of a file with NumRecs records along RecordDim.
+type File struct {
+	RecordDim string
+	NumRecs   int
+	Attrs     []Attr
+	Vars      []Var
+}
+
+// Encoder writes the records of a File.
+type Encoder struct {
+	w       io.Writer
+	file    File
+	records []Var
+	pad     bool
+	written int
+	record  bytes.Buffer
+}
+
+// NewEncoder writes the header and the scalar variables of f to w, the records are written with WriteRecord.
+func NewEncoder(w io.Writer, f File) (*Encoder, error) {
+	if f.NumRecs < 0 || f.NumRecs > math.MaxInt32 {
+		return nil, fmt.Errorf("netcdf: invalid number of records %d", f.NumRecs)
+	}
+	e := &Encoder{w: w, file: f}
+	for _, v := range f.Vars {
+		if !v.Scalar {
+			e.records = append(e.records, v)
+		}
+	}
+	// A single record variable is not padded in the records.
+	e.pad = len(e.records) > 1
+
+	header, err := f.header(0)
+	if err != nil {
+		return nil, err
+	}
+	if header, err = f.header(int64(len(header))); err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, v := range f.Vars {
+		if v.Scalar {
+			if err = writeValue(&buf, v.Type, v.Value, true); err != nil {
+				return nil, fmt.Errorf("netcdf: variable %s: %w", v.Name, err)
+			}
+		}
+	}
+	_, err = w.Write(buf.Bytes())
+	return e, err
+}
+
+// WriteRecord writes the next record, with a value per record variable in the order of the variables.
+func (e *Encoder) WriteRecord(values ...any) error {
+	if len(values) != len(e.records) {
+		return fmt.Errorf("netcdf: %d values for %d record variables", len(values), len(e.records))
+	}
+	if e.written == e.file.NumRecs {
+		return errors.New("netcdf: more records than NumRecs")
+	}
+	e.record.Reset()
+	for i, v := range e.records {
+		if err := writeValue(&e.record, v.Type, values[i], e.pad); err != nil {
+			return fmt.Errorf("netcdf: variable %s: %w", v.Name, err)
+		}
+	}
+	e.written++
+	_, err := e.w.Write(e.record.Bytes())
+	return err
+}
+
+// Close reports whether all the records were written.
+func (e *Encoder) Close() error {
+	if e.written != e.file.NumRecs {
+		return fmt.Errorf("netcdf: %d of %d records written", e.written, e.file.NumRecs)
+	}
+	return nil
+}
+
+// header encodes the header with the data starting at begin.
+func (f *File) header(begin int64) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("CDF\x02")
+	writeInt(&buf, int32(f.NumRecs))
+
+	writeInt(&buf, tagDimension)
+	writeInt(&buf, 1)
+	writeName(&buf, f.RecordDim)
+	writeInt(&buf, 0)
+
+	if err := writeAttrs(&buf, f.Attrs); err != nil {
+		return nil, err
+	}
+
+	if len(f.Vars) == 0 {
+		writeInt(&buf, 0)
+		writeInt(&buf, 0)
+		return buf.Bytes(), nil
+	}
+	writeInt(&buf, tagVariable)
+	writeInt(&buf, int32(len(f.Vars)))
+	// The scalars come first in the data, then the records.
+	scalarsSize := int64(0)
+	for _, v := range f.Vars {
+		if v.Scalar {
+			scalarsSize += padded(v.Type.size())
+		}
+	}
+	scalarOffset, recordOffset := begin, begin+scalarsSize
+	for _, v := range f.Vars {
+		writeName(&buf, v.Name)
+		if v.Scalar {
+			writeInt(&buf, 0)
+		} else {
+			writeInt(&buf, 1)
+			writeInt(&buf, 0)
+		}
+		if err := writeAttrs(&buf, v.Attrs); err != nil {
+			return nil, fmt.Errorf("netcdf: variable %s: %w", v.Name, err)
+		}
+		writeInt(&buf, int32(v.Type))
+		vsize := padded(v.Type.size())
+		writeInt(&buf, int32(vsize))
+		if v.Scalar {
+			_ = binary.Write(&buf, binary.BigEndian, scalarOffset)
+			scalarOffset += vsize
+		} else {
+			_ = binary.Write(&buf, binary.BigEndian, recordOffset)
+			recordOffset += vsize
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (t Type) size() int64 {
+	switch t {
+	case Byte, Char:
+		return 1
+	case Int:
+		return 4
+	case Double:
+		return 8
+	}
+	return 0
+}
+
+func padded(n int64) int64 {
+	return (n + 3) / 4 * 4
+}
+
+func writeInt(buf *bytes.Buffer, n int32) {
+	_ = binary.Write(buf, binary.BigEndian, n)
+}
+
+func writePadding(buf *bytes.Buffer, n int64) {
+	buf.Write(make([]byte, padded(n)-n))
+}
+
+func writeName(buf *bytes.Buffer, name string) {
+	writeInt(buf, int32(len(name)))
+	buf.WriteString(name)
+	writePadding(buf, int64(len(name)))
+}
+
+func writeAttrs(buf *bytes.Buffer, attrs []Attr) error {
+	if len(attrs) == 0 {
+		writeInt(buf, 0)
+		writeInt(buf, 0)
+		return nil
+	}
+	writeInt(buf, tagAttribute)
+	writeInt(buf, int32(len(attrs)))
+	for _, a := range attrs {
+		writeName(buf, a.Name)
+		var (
+			t    Type
+			n    int
+			data any
+		)
+		switch v := a.Value.(type) {
+		case string:
+			t, n, data = Char, len(v), []byte(v)
+		case int8:
+			t, n, data = Byte, 1, v
+		case []int8:
+			t, n, data = Byte, len(v), v
+		case int32:
+			t, n, data = Int, 1, v
+		case []int32:
+			t, n, data = Int, len(v), v
+		case float64:
+			t, n, data = Double, 1, v
+		case []float64:
+			t, n, data = Double, len(v), v
+		default:
+			return fmt.Errorf("netcdf: attribute %s of type %T", a.Name, a.Value)
+		}
+		writeInt(buf, int32(t))
+		writeInt(buf, int32(n))
+		_ = binary.Write(buf, binary.BigEndian, data)
+		writePadding(buf, int64(n)*t.size())
+	}
+	return nil
+}
+
+func writeValue(buf *bytes.Buffer, t Type, value any, pad bool) error {
+	var ok bool
+	switch t {
+	case Byte:
+		_, ok = value.(int8)
+	case Int:
+		_, ok = value.(int32)
+	case Double:
+		_, ok = value.(float64)
+	}
+	if !ok {
+		return fmt.Errorf("value of type %T for type %d", value, t)
+	}
+	_ = binary.Write(buf, binary.BigEndian, value)
+	if pad {
+		writePadding(buf, t.size())
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package netcdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decoded is the part of a file read back by decode.
+type decoded struct {
+	numRecs int32
+	dim     string
+	attrs   map[string]any
+	vars    []decodedVar
+}
+
+type decodedVar struct {
+	name  string
+	ndims int32
+	attrs map[string]any
+	typ   Type
+	vsize int32
+	begin int64
+}
+
+type reader struct {
+	b   []byte
+	off int
+}
+
+func (r *reader) int() int32 {
+	n := int32(binary.BigEndian.Uint32(r.b[r.off:]))
+	r.off += 4
+	return n
+}
+
+func (r *reader) bytes(n int) []byte {
+	b := r.b[r.off : r.off+n]
+	r.off += int(padded(int64(n)))
+	return b
+}
+
+func (r *reader) name() string {
+	return string(r.bytes(int(r.int())))
+}
+
+func (r *reader) attrs(t *testing.T) map[string]any {
+	tag, n := r.int(), r.int()
+	if tag == 0 {
+		return nil
+	}
+	require.EqualValues(t, tagAttribute, tag)
+	attrs := make(map[string]any)
+	for range n {
+		name := r.name()
+		typ, nelems := Type(r.int()), int(r.int())
+		b := r.bytes(nelems * int(typ.size()))
+		switch typ {
+		case Char:
+			attrs[name] = string(b)
+		case Byte:
+			attrs[name] = int8(b[0])
+		case Double:
+			attrs[name] = math.Float64frombits(binary.BigEndian.Uint64(b))
+		}
+	}
+	return attrs
+}
+
+func decode(t *testing.T, b []byte) decoded {
+	r := &reader{b: b}
+	require.Equal(t, "CDF\x02", string(r.bytes(4)))
+	var d decoded
+	d.numRecs = r.int()
+	require.EqualValues(t, tagDimension, r.int())
+	require.EqualValues(t, 1, r.int())
+	d.dim = r.name()
+	require.EqualValues(t, 0, r.int())
+	d.attrs = r.attrs(t)
+	require.EqualValues(t, tagVariable, r.int())
+	for range r.int() {
+		v := decodedVar{name: r.name(), ndims: r.int()}
+		for range v.ndims {
+			require.EqualValues(t, 0, r.int())
+		}
+		v.attrs = r.attrs(t)
+		v.typ, v.vsize = Type(r.int()), r.int()
+		v.begin = int64(binary.BigEndian.Uint64(b[r.off:]))
+		r.off += 8
+		d.vars = append(d.vars, v)
+	}
+	return d
+}
+
+func TestEncoder(t *testing.T) {
+	f := File{
+		RecordDim: "time",
+		NumRecs:   2,
+		Attrs:     []Attr{{"Conventions", "CF-1.8"}, {"title", "station1"}},
+		Vars: []Var{
+			{Name: "lat", Type: Double, Scalar: true, Value: 51.5, Attrs: []Attr{{"units", "degrees_north"}}},
+			{Name: "time", Type: Double, Attrs: []Attr{{"units", "milliseconds since 1970-01-01 00:00:00 UTC"}}},
+			{Name: "item1", Type: Double, Attrs: []Attr{{"_FillValue", FillDouble}}},
+			{Name: "item1_qc", Type: Byte, Attrs: []Attr{{"_FillValue", FillByte}}},
+		},
+	}
+	var buf bytes.Buffer
+	e, err := NewEncoder(&buf, f)
+	require.NoError(t, err)
+	require.NoError(t, e.WriteRecord(1000.0, 0.5, int8(1)))
+	assert.Error(t, e.Close())
+	require.NoError(t, e.WriteRecord(2000.0, FillDouble, FillByte))
+	require.NoError(t, e.Close())
+	assert.Error(t, e.WriteRecord(3000.0, 0.1, int8(1)))
+
+	b := buf.Bytes()
+	d := decode(t, b)
+	assert.EqualValues(t, 2, d.numRecs)
+	assert.Equal(t, "time", d.dim)
+	assert.Equal(t, map[string]any{"Conventions": "CF-1.8", "title": "station1"}, d.attrs)
+	require.Len(t, d.vars, 4)
+	assert.Equal(t, "item1_qc", d.vars[3].name)
+	assert.Equal(t, map[string]any{"_FillValue": FillByte}, d.vars[3].attrs)
+
+	lat := d.vars[0]
+	assert.EqualValues(t, 0, lat.ndims)
+	assert.Equal(t, 51.5, math.Float64frombits(binary.BigEndian.Uint64(b[lat.begin:])))
+
+	// Records of 8+8+4 bytes follow the scalar.
+	tm, item1, qc := d.vars[1], d.vars[2], d.vars[3]
+	assert.Equal(t, lat.begin+8, tm.begin)
+	assert.Equal(t, tm.begin+8, item1.begin)
+	assert.Equal(t, item1.begin+8, qc.begin)
+	assert.EqualValues(t, 4, qc.vsize)
+	recSize := int64(20)
+	assert.Equal(t, tm.begin+2*recSize, int64(len(b)))
+	assert.Equal(t, 2000.0, math.Float64frombits(binary.BigEndian.Uint64(b[tm.begin+recSize:])))
+	assert.Equal(t, 0.5, math.Float64frombits(binary.BigEndian.Uint64(b[item1.begin:])))
+	assert.Equal(t, FillDouble, math.Float64frombits(binary.BigEndian.Uint64(b[item1.begin+recSize:])))
+	assert.Equal(t, int8(1), int8(b[qc.begin]))
+	assert.Equal(t, FillByte, int8(b[qc.begin+recSize]))
+}
+
+func TestEncoder_Invalid(t *testing.T) {
+	_, err := NewEncoder(&bytes.Buffer{}, File{RecordDim: "time", Attrs: []Attr{{"bad", uint64(1)}}})
+	assert.Error(t, err)
+
+	e, err := NewEncoder(&bytes.Buffer{}, File{RecordDim: "time", NumRecs: 1, Vars: []Var{{Name: "time", Type: Double}}})
+	require.NoError(t, err)
+	assert.Error(t, e.WriteRecord(int8(1)))
+	assert.Error(t, e.WriteRecord(1.0, 2.0))
+}
@@ -74,6 +74,8 @@ type ClientHello struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	StationIdentifier string                 `protobuf:"bytes,1,opt,name=station_identifier,json=stationIdentifier,proto3" json:"station_identifier,omitempty"`
 	ProtocolVersion   string                 `protobuf:"bytes,2,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	OutboxId          string                 `protobuf:"bytes,3,opt,name=outbox_id,json=outboxId,proto3" json:"outbox_id,omitempty"` // 客户端 SQLite 数据发件箱的唯一 ID，重建数据库后会变化；为空表示旧客户端
+	Compressions      []string               `protobuf:"bytes,4,rep,name=compressions,proto3" json:"compressions,omitempty"`         // 客户端支持的帧压缩算法，按优先级排序（"zstd"、"gzip"）
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
@@ -122,9 +124,27 @@ func (x *ClientHello) GetProtocolVersion() string {
 	return ""
 }
 
+func (x *ClientHello) GetOutboxId() string {
+	if x != nil {
+		return x.OutboxId
+	}
+	return ""
+}
+
+func (x *ClientHello) GetCompressions() []string {
+	if x != nil {
+		return x.Compressions
+	}
+	return nil
+}
+
 type ServerHello struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ServerVersion string                 `protobuf:"bytes,1,opt,name=server_version,json=serverVersion,proto3" json:"server_version,omitempty"`
+	AuthChallenge []byte                 `protobuf:"bytes,2,opt,name=auth_challenge,json=authChallenge,proto3" json:"auth_challenge,omitempty"` // 随机挑战值，客户端需用站点密钥计算 HMAC 应答
+	Compression   string                 `protobuf:"bytes,3,opt,name=compression,proto3" json:"compression,omitempty"`                          // 从 ClientHello.compressions 中选定的压缩算法，空表示不压缩；ServerHello 之后的帧生效
+	ColumnarData  bool                   `protobuf:"varint,4,opt,name=columnar_data,json=columnarData,proto3" json:"columnar_data,omitempty"`   // 服务端支持 DataBatch.columns
+	ServerUnixMs  int64                  `protobuf:"varint,5,opt,name=server_unix_ms,json=serverUnixMs,proto3" json:"server_unix_ms,omitempty"` // 服务端发送 ServerHello 时的时间，客户端据此计算时钟偏差
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -166,6 +186,88 @@ func (x *ServerHello) GetServerVersion() string {
 	return ""
 }
 
+func (x *ServerHello) GetAuthChallenge() []byte {
+	if x != nil {
+		return x.AuthChallenge
+	}
+	return nil
+}
+
+func (x *ServerHello) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *ServerHello) GetColumnarData() bool {
+	if x != nil {
+		return x.ColumnarData
+	}
+	return false
+}
+
+func (x *ServerHello) GetServerUnixMs() int64 {
+	if x != nil {
+		return x.ServerUnixMs
+	}
+	return 0
+}
+
+// ClientAuth 站点对 ServerHello.auth_challenge 的应答。
+// mac = HMAC-SHA256(auth_key, auth_challenge || station_identifier)
+type ClientAuth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mac           []byte                 `protobuf:"bytes,1,opt,name=mac,proto3" json:"mac,omitempty"`
+	ClockOffsetMs int64                  `protobuf:"varint,2,opt,name=clock_offset_ms,json=clockOffsetMs,proto3" json:"clock_offset_ms,omitempty"` // 服务端时间减站点时间，按 ServerHello 往返的中点估计，服务端未提供时间时为 0
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientAuth) Reset() {
+	*x = ClientAuth{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientAuth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientAuth) ProtoMessage() {}
+
+func (x *ClientAuth) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientAuth.ProtoReflect.Descriptor instead.
+func (*ClientAuth) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ClientAuth) GetMac() []byte {
+	if x != nil {
+		return x.Mac
+	}
+	return nil
+}
+
+func (x *ClientAuth) GetClockOffsetMs() int64 {
+	if x != nil {
+		return x.ClockOffsetMs
+	}
+	return 0
+}
+
 type DeviceItems struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Items         map[string]string      `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // map[item_type]item_name
@@ -175,7 +277,7 @@ type DeviceItems struct {
 
 func (x *DeviceItems) Reset() {
 	*x = DeviceItems{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[2]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -187,7 +289,7 @@ func (x *DeviceItems) String() string {
 func (*DeviceItems) ProtoMessage() {}
 
 func (x *DeviceItems) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[2]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -200,7 +302,7 @@ func (x *DeviceItems) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeviceItems.ProtoReflect.Descriptor instead.
 func (*DeviceItems) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{2}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *DeviceItems) GetItems() map[string]string {
@@ -211,17 +313,19 @@ func (x *DeviceItems) GetItems() map[string]string {
 }
 
 type StationInfo struct {
-	state         protoimpl.MessageState  `protogen:"open.v1"`
-	Identifier    string                  `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
-	Devices       map[string]*DeviceItems `protobuf:"bytes,2,rep,name=devices,proto3" json:"devices,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // map[device_name]items
-	Cameras       []string                `protobuf:"bytes,3,rep,name=cameras,proto3" json:"cameras,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state               protoimpl.MessageState  `protogen:"open.v1"`
+	Identifier          string                  `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	Devices             map[string]*DeviceItems `protobuf:"bytes,2,rep,name=devices,proto3" json:"devices,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // map[device_name]items
+	Cameras             []string                `protobuf:"bytes,3,rep,name=cameras,proto3" json:"cameras,omitempty"`
+	DeviceConfigVersion int64                   `protobuf:"varint,4,opt,name=device_config_version,json=deviceConfigVersion,proto3" json:"device_config_version,omitempty"` // 当前生效的服务端设备配置版本，0 表示使用本地配置文件
+	DeviceConfigError   string                  `protobuf:"bytes,5,opt,name=device_config_error,json=deviceConfigError,proto3" json:"device_config_error,omitempty"`        // 最近一次设备配置应用失败并回滚的原因
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *StationInfo) Reset() {
 	*x = StationInfo{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[3]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -233,7 +337,7 @@ func (x *StationInfo) String() string {
 func (*StationInfo) ProtoMessage() {}
 
 func (x *StationInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[3]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -246,7 +350,7 @@ func (x *StationInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StationInfo.ProtoReflect.Descriptor instead.
 func (*StationInfo) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{3}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *StationInfo) GetIdentifier() string {
@@ -270,6 +374,20 @@ func (x *StationInfo) GetCameras() []string {
 	return nil
 }
 
+func (x *StationInfo) GetDeviceConfigVersion() int64 {
+	if x != nil {
+		return x.DeviceConfigVersion
+	}
+	return 0
+}
+
+func (x *StationInfo) GetDeviceConfigError() string {
+	if x != nil {
+		return x.DeviceConfigError
+	}
+	return ""
+}
+
 type ItemsLatest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	LatestUnixMs  map[string]int64       `protobuf:"bytes,1,rep,name=latest_unix_ms,json=latestUnixMs,proto3" json:"latest_unix_ms,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // map[item_name]latest_msec
@@ -279,7 +397,7 @@ type ItemsLatest struct {
 
 func (x *ItemsLatest) Reset() {
 	*x = ItemsLatest{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[4]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -291,7 +409,7 @@ func (x *ItemsLatest) String() string {
 func (*ItemsLatest) ProtoMessage() {}
 
 func (x *ItemsLatest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[4]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -304,7 +422,7 @@ func (x *ItemsLatest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ItemsLatest.ProtoReflect.Descriptor instead.
 func (*ItemsLatest) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{4}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ItemsLatest) GetLatestUnixMs() map[string]int64 {
@@ -323,7 +441,7 @@ type StatusLatest struct {
 
 func (x *StatusLatest) Reset() {
 	*x = StatusLatest{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[5]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -335,7 +453,7 @@ func (x *StatusLatest) String() string {
 func (*StatusLatest) ProtoMessage() {}
 
 func (x *StatusLatest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[5]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -348,7 +466,7 @@ func (x *StatusLatest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusLatest.ProtoReflect.Descriptor instead.
 func (*StatusLatest) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{5}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *StatusLatest) GetLatestRowId() int64 {
@@ -358,19 +476,67 @@ func (x *StatusLatest) GetLatestRowId() int64 {
 	return 0
 }
 
+// DataCursor 服务端已连续持久化的最大数据序号，客户端补发 seq > last_seq 的数据。
+// 仅在 ClientHello.outbox_id 非空时代替 ItemsLatest 发送。
+type DataCursor struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LastSeq       int64                  `protobuf:"varint,1,opt,name=last_seq,json=lastSeq,proto3" json:"last_seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataCursor) Reset() {
+	*x = DataCursor{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataCursor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataCursor) ProtoMessage() {}
+
+func (x *DataCursor) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataCursor.ProtoReflect.Descriptor instead.
+func (*DataCursor) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DataCursor) GetLastSeq() int64 {
+	if x != nil {
+		return x.LastSeq
+	}
+	return 0
+}
+
 type DataPoint struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ItemName      string                 `protobuf:"bytes,1,opt,name=item_name,json=itemName,proto3" json:"item_name,omitempty"`
 	Value         float64                `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
 	UnixMs        int64                  `protobuf:"varint,3,opt,name=unix_ms,json=unixMs,proto3" json:"unix_ms,omitempty"`
 	Kind          DataKind               `protobuf:"varint,4,opt,name=kind,proto3,enum=tide.sync.v2.DataKind" json:"kind,omitempty"`
+	Seq           int64                  `protobuf:"varint,5,opt,name=seq,proto3" json:"seq,omitempty"`   // 客户端发件箱序号，0 表示无序号
+	Flag          uint32                 `protobuf:"varint,6,opt,name=flag,proto3" json:"flag,omitempty"` // 质量标志，SeaDataNet 代码，0 表示未做质量控制
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DataPoint) Reset() {
 	*x = DataPoint{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[6]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -382,7 +548,7 @@ func (x *DataPoint) String() string {
 func (*DataPoint) ProtoMessage() {}
 
 func (x *DataPoint) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[6]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -395,7 +561,7 @@ func (x *DataPoint) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DataPoint.ProtoReflect.Descriptor instead.
 func (*DataPoint) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{6}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *DataPoint) GetItemName() string {
@@ -426,29 +592,52 @@ func (x *DataPoint) GetKind() DataKind {
 	return DataKind_DATA_KIND_UNSPECIFIED
 }
 
-type DataBatch struct {
+func (x *DataPoint) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *DataPoint) GetFlag() uint32 {
+	if x != nil {
+		return x.Flag
+	}
+	return 0
+}
+
+// DataColumn 同一 item、同一 kind 的一组数据点的列式编码。
+// 时间戳和序号按前一个点差分，第一个为绝对值；seq_delta 为空表示无序号。
+// value_scale >= 0 时数值为 value_delta 差分累加后除以 10^value_scale，否则数值在 values 中。
+type DataColumn struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Replay        bool                   `protobuf:"varint,1,opt,name=replay,proto3" json:"replay,omitempty"`
-	Points        []*DataPoint           `protobuf:"bytes,2,rep,name=points,proto3" json:"points,omitempty"`
+	ItemName      string                 `protobuf:"bytes,1,opt,name=item_name,json=itemName,proto3" json:"item_name,omitempty"`
+	Kind          DataKind               `protobuf:"varint,2,opt,name=kind,proto3,enum=tide.sync.v2.DataKind" json:"kind,omitempty"`
+	UnixMsDelta   []int64                `protobuf:"zigzag64,3,rep,packed,name=unix_ms_delta,json=unixMsDelta,proto3" json:"unix_ms_delta,omitempty"`
+	SeqDelta      []int64                `protobuf:"zigzag64,4,rep,packed,name=seq_delta,json=seqDelta,proto3" json:"seq_delta,omitempty"`
+	ValueScale    int32                  `protobuf:"zigzag32,5,opt,name=value_scale,json=valueScale,proto3" json:"value_scale,omitempty"`
+	ValueDelta    []int64                `protobuf:"zigzag64,6,rep,packed,name=value_delta,json=valueDelta,proto3" json:"value_delta,omitempty"`
+	Values        []float64              `protobuf:"fixed64,7,rep,packed,name=values,proto3" json:"values,omitempty"`
+	Flags         []uint32               `protobuf:"varint,8,rep,packed,name=flags,proto3" json:"flags,omitempty"` // 每个点的质量标志，为空表示全部为 0
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DataBatch) Reset() {
-	*x = DataBatch{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[7]
+func (x *DataColumn) Reset() {
+	*x = DataColumn{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DataBatch) String() string {
+func (x *DataColumn) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DataBatch) ProtoMessage() {}
+func (*DataColumn) ProtoMessage() {}
 
-func (x *DataBatch) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[7]
+func (x *DataColumn) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -459,116 +648,93 @@ func (x *DataBatch) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DataBatch.ProtoReflect.Descriptor instead.
-func (*DataBatch) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use DataColumn.ProtoReflect.Descriptor instead.
+func (*DataColumn) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *DataBatch) GetReplay() bool {
+func (x *DataColumn) GetItemName() string {
 	if x != nil {
-		return x.Replay
+		return x.ItemName
 	}
-	return false
+	return ""
 }
 
-func (x *DataBatch) GetPoints() []*DataPoint {
+func (x *DataColumn) GetKind() DataKind {
 	if x != nil {
-		return x.Points
+		return x.Kind
 	}
-	return nil
-}
-
-type ItemStatusLog struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	RowId           int64                  `protobuf:"varint,1,opt,name=row_id,json=rowId,proto3" json:"row_id,omitempty"`
-	ItemName        string                 `protobuf:"bytes,2,opt,name=item_name,json=itemName,proto3" json:"item_name,omitempty"`
-	Status          string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
-	ChangedAtUnixMs int64                  `protobuf:"varint,4,opt,name=changed_at_unix_ms,json=changedAtUnixMs,proto3" json:"changed_at_unix_ms,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
-}
-
-func (x *ItemStatusLog) Reset() {
-	*x = ItemStatusLog{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[8]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *ItemStatusLog) String() string {
-	return protoimpl.X.MessageStringOf(x)
+	return DataKind_DATA_KIND_UNSPECIFIED
 }
 
-func (*ItemStatusLog) ProtoMessage() {}
-
-func (x *ItemStatusLog) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[8]
+func (x *DataColumn) GetUnixMsDelta() []int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.UnixMsDelta
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use ItemStatusLog.ProtoReflect.Descriptor instead.
-func (*ItemStatusLog) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{8}
+func (x *DataColumn) GetSeqDelta() []int64 {
+	if x != nil {
+		return x.SeqDelta
+	}
+	return nil
 }
 
-func (x *ItemStatusLog) GetRowId() int64 {
+func (x *DataColumn) GetValueScale() int32 {
 	if x != nil {
-		return x.RowId
+		return x.ValueScale
 	}
 	return 0
 }
 
-func (x *ItemStatusLog) GetItemName() string {
+func (x *DataColumn) GetValueDelta() []int64 {
 	if x != nil {
-		return x.ItemName
+		return x.ValueDelta
 	}
-	return ""
+	return nil
 }
 
-func (x *ItemStatusLog) GetStatus() string {
+func (x *DataColumn) GetValues() []float64 {
 	if x != nil {
-		return x.Status
+		return x.Values
 	}
-	return ""
+	return nil
 }
 
-func (x *ItemStatusLog) GetChangedAtUnixMs() int64 {
+func (x *DataColumn) GetFlags() []uint32 {
 	if x != nil {
-		return x.ChangedAtUnixMs
+		return x.Flags
 	}
-	return 0
+	return nil
 }
 
-type ItemStatusBatch struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Replay        bool                   `protobuf:"varint,1,opt,name=replay,proto3" json:"replay,omitempty"`
-	Logs          []*ItemStatusLog       `protobuf:"bytes,2,rep,name=logs,proto3" json:"logs,omitempty"`
+type DataBatch struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Replay  bool                   `protobuf:"varint,1,opt,name=replay,proto3" json:"replay,omitempty"`
+	Points  []*DataPoint           `protobuf:"bytes,2,rep,name=points,proto3" json:"points,omitempty"`
+	Columns []*DataColumn          `protobuf:"bytes,4,rep,name=columns,proto3" json:"columns,omitempty"` // 仅在 ServerHello.columnar_data 为 true 时使用
+	// batch_id 非 0 时，服务端在数据落库后回复 DataAck。
+	BatchId       uint64 `protobuf:"varint,3,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ItemStatusBatch) Reset() {
-	*x = ItemStatusBatch{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[9]
+func (x *DataBatch) Reset() {
+	*x = DataBatch{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ItemStatusBatch) String() string {
+func (x *DataBatch) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ItemStatusBatch) ProtoMessage() {}
+func (*DataBatch) ProtoMessage() {}
 
-func (x *ItemStatusBatch) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[9]
+func (x *DataBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -579,9 +745,188 @@ func (x *ItemStatusBatch) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ItemStatusBatch.ProtoReflect.Descriptor instead.
-func (*ItemStatusBatch) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use DataBatch.ProtoReflect.Descriptor instead.
+func (*DataBatch) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DataBatch) GetReplay() bool {
+	if x != nil {
+		return x.Replay
+	}
+	return false
+}
+
+func (x *DataBatch) GetPoints() []*DataPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+func (x *DataBatch) GetColumns() []*DataColumn {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *DataBatch) GetBatchId() uint64 {
+	if x != nil {
+		return x.BatchId
+	}
+	return 0
+}
+
+// DataAck 确认对应 batch_id 的 DataBatch 已经写入数据库。
+type DataAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BatchId       uint64                 `protobuf:"varint,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataAck) Reset() {
+	*x = DataAck{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataAck) ProtoMessage() {}
+
+func (x *DataAck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataAck.ProtoReflect.Descriptor instead.
+func (*DataAck) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DataAck) GetBatchId() uint64 {
+	if x != nil {
+		return x.BatchId
+	}
+	return 0
+}
+
+type ItemStatusLog struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RowId           int64                  `protobuf:"varint,1,opt,name=row_id,json=rowId,proto3" json:"row_id,omitempty"`
+	ItemName        string                 `protobuf:"bytes,2,opt,name=item_name,json=itemName,proto3" json:"item_name,omitempty"`
+	Status          string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	ChangedAtUnixMs int64                  `protobuf:"varint,4,opt,name=changed_at_unix_ms,json=changedAtUnixMs,proto3" json:"changed_at_unix_ms,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ItemStatusLog) Reset() {
+	*x = ItemStatusLog{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemStatusLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemStatusLog) ProtoMessage() {}
+
+func (x *ItemStatusLog) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemStatusLog.ProtoReflect.Descriptor instead.
+func (*ItemStatusLog) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ItemStatusLog) GetRowId() int64 {
+	if x != nil {
+		return x.RowId
+	}
+	return 0
+}
+
+func (x *ItemStatusLog) GetItemName() string {
+	if x != nil {
+		return x.ItemName
+	}
+	return ""
+}
+
+func (x *ItemStatusLog) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ItemStatusLog) GetChangedAtUnixMs() int64 {
+	if x != nil {
+		return x.ChangedAtUnixMs
+	}
+	return 0
+}
+
+type ItemStatusBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Replay        bool                   `protobuf:"varint,1,opt,name=replay,proto3" json:"replay,omitempty"`
+	Logs          []*ItemStatusLog       `protobuf:"bytes,2,rep,name=logs,proto3" json:"logs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ItemStatusBatch) Reset() {
+	*x = ItemStatusBatch{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemStatusBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemStatusBatch) ProtoMessage() {}
+
+func (x *ItemStatusBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemStatusBatch.ProtoReflect.Descriptor instead.
+func (*ItemStatusBatch) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *ItemStatusBatch) GetReplay() bool {
@@ -595,32 +940,715 @@ func (x *ItemStatusBatch) GetLogs() []*ItemStatusLog {
 	if x != nil {
 		return x.Logs
 	}
-	return nil
+	return nil
+}
+
+// RpiStatus 旧版客户端的树莓派状态，新版客户端发送 StationHealth。
+type RpiStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CpuTemp       float64                `protobuf:"fixed64,1,opt,name=cpu_temp,json=cpuTemp,proto3" json:"cpu_temp,omitempty"`
+	UnixMs        int64                  `protobuf:"varint,2,opt,name=unix_ms,json=unixMs,proto3" json:"unix_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpiStatus) Reset() {
+	*x = RpiStatus{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpiStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpiStatus) ProtoMessage() {}
+
+func (x *RpiStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpiStatus.ProtoReflect.Descriptor instead.
+func (*RpiStatus) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RpiStatus) GetCpuTemp() float64 {
+	if x != nil {
+		return x.CpuTemp
+	}
+	return 0
+}
+
+func (x *RpiStatus) GetUnixMs() int64 {
+	if x != nil {
+		return x.UnixMs
+	}
+	return 0
+}
+
+type DiskUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	FreeBytes     uint64                 `protobuf:"varint,2,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	TotalBytes    uint64                 `protobuf:"varint,3,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiskUsage) Reset() {
+	*x = DiskUsage{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskUsage) ProtoMessage() {}
+
+func (x *DiskUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskUsage.ProtoReflect.Descriptor instead.
+func (*DiskUsage) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DiskUsage) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DiskUsage) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *DiskUsage) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+// StationHealth 站点主机状态，客户端每 60s 发送一次，读取自 /proc 和 /sys。
+type StationHealth struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	UnixMs            int64                  `protobuf:"varint,1,opt,name=unix_ms,json=unixMs,proto3" json:"unix_ms,omitempty"`
+	CpuTemp           *float64               `protobuf:"fixed64,2,opt,name=cpu_temp,json=cpuTemp,proto3,oneof" json:"cpu_temp,omitempty"` // 没有温度传感器时为空
+	Disks             []*DiskUsage           `protobuf:"bytes,3,rep,name=disks,proto3" json:"disks,omitempty"`                            // 数据库和 FTP 目录所在磁盘
+	MemTotalBytes     uint64                 `protobuf:"varint,4,opt,name=mem_total_bytes,json=memTotalBytes,proto3" json:"mem_total_bytes,omitempty"`
+	MemAvailableBytes uint64                 `protobuf:"varint,5,opt,name=mem_available_bytes,json=memAvailableBytes,proto3" json:"mem_available_bytes,omitempty"`
+	Load1             float64                `protobuf:"fixed64,6,opt,name=load1,proto3" json:"load1,omitempty"`
+	Load5             float64                `protobuf:"fixed64,7,opt,name=load5,proto3" json:"load5,omitempty"`
+	Load15            float64                `protobuf:"fixed64,8,opt,name=load15,proto3" json:"load15,omitempty"`
+	UptimeSeconds     uint64                 `protobuf:"varint,9,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	DbSizeBytes       uint64                 `protobuf:"varint,10,opt,name=db_size_bytes,json=dbSizeBytes,proto3" json:"db_size_bytes,omitempty"`                                                                               // SQLite 文件大小，含 -wal 文件
+	ReplayBacklog     uint64                 `protobuf:"varint,11,opt,name=replay_backlog,json=replayBacklog,proto3" json:"replay_backlog,omitempty"`                                                                           // data_outbox 中服务端尚未确认存储的行数
+	SerialReopens     map[string]uint32      `protobuf:"bytes,12,rep,name=serial_reopens,json=serialReopens,proto3" json:"serial_reopens,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // map[port]串口出错后重新打开的次数
+	ClockOffsetMs     int64                  `protobuf:"varint,13,opt,name=clock_offset_ms,json=clockOffsetMs,proto3" json:"clock_offset_ms,omitempty"`                                                                         // 内核 NTP 估计的时钟偏差
+	ClockSynced       bool                   `protobuf:"varint,14,opt,name=clock_synced,json=clockSynced,proto3" json:"clock_synced,omitempty"`                                                                                 // 内核时钟已与 NTP 同步
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *StationHealth) Reset() {
+	*x = StationHealth{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StationHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StationHealth) ProtoMessage() {}
+
+func (x *StationHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StationHealth.ProtoReflect.Descriptor instead.
+func (*StationHealth) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StationHealth) GetUnixMs() int64 {
+	if x != nil {
+		return x.UnixMs
+	}
+	return 0
+}
+
+func (x *StationHealth) GetCpuTemp() float64 {
+	if x != nil && x.CpuTemp != nil {
+		return *x.CpuTemp
+	}
+	return 0
+}
+
+func (x *StationHealth) GetDisks() []*DiskUsage {
+	if x != nil {
+		return x.Disks
+	}
+	return nil
+}
+
+func (x *StationHealth) GetMemTotalBytes() uint64 {
+	if x != nil {
+		return x.MemTotalBytes
+	}
+	return 0
+}
+
+func (x *StationHealth) GetMemAvailableBytes() uint64 {
+	if x != nil {
+		return x.MemAvailableBytes
+	}
+	return 0
+}
+
+func (x *StationHealth) GetLoad1() float64 {
+	if x != nil {
+		return x.Load1
+	}
+	return 0
+}
+
+func (x *StationHealth) GetLoad5() float64 {
+	if x != nil {
+		return x.Load5
+	}
+	return 0
+}
+
+func (x *StationHealth) GetLoad15() float64 {
+	if x != nil {
+		return x.Load15
+	}
+	return 0
+}
+
+func (x *StationHealth) GetUptimeSeconds() uint64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *StationHealth) GetDbSizeBytes() uint64 {
+	if x != nil {
+		return x.DbSizeBytes
+	}
+	return 0
+}
+
+func (x *StationHealth) GetReplayBacklog() uint64 {
+	if x != nil {
+		return x.ReplayBacklog
+	}
+	return 0
+}
+
+func (x *StationHealth) GetSerialReopens() map[string]uint32 {
+	if x != nil {
+		return x.SerialReopens
+	}
+	return nil
+}
+
+func (x *StationHealth) GetClockOffsetMs() int64 {
+	if x != nil {
+		return x.ClockOffsetMs
+	}
+	return 0
+}
+
+func (x *StationHealth) GetClockSynced() bool {
+	if x != nil {
+		return x.ClockSynced
+	}
+	return false
+}
+
+type CameraSnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CameraName    string                 `protobuf:"bytes,1,opt,name=camera_name,json=cameraName,proto3" json:"camera_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CameraSnapshotRequest) Reset() {
+	*x = CameraSnapshotRequest{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CameraSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CameraSnapshotRequest) ProtoMessage() {}
+
+func (x *CameraSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CameraSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*CameraSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CameraSnapshotRequest) GetCameraName() string {
+	if x != nil {
+		return x.CameraName
+	}
+	return ""
+}
+
+type CameraSnapshotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CameraSnapshotResponse) Reset() {
+	*x = CameraSnapshotResponse{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CameraSnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CameraSnapshotResponse) ProtoMessage() {}
+
+func (x *CameraSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CameraSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*CameraSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CameraSnapshotResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CameraSnapshotResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// DeviceConfigPush 服务端下发站点设备配置（命令子流）。
+// config 为 JSON：map[conn_type][]device_config，每个元素与客户端本地设备配置文件的内容相同。
+type DeviceConfigPush struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Config        []byte                 `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceConfigPush) Reset() {
+	*x = DeviceConfigPush{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceConfigPush) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceConfigPush) ProtoMessage() {}
+
+func (x *DeviceConfigPush) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceConfigPush.ProtoReflect.Descriptor instead.
+func (*DeviceConfigPush) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeviceConfigPush) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *DeviceConfigPush) GetConfig() []byte {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+// DeviceConfigResult 客户端校验结果。error 为空表示已接受，客户端随后重启并应用，
+// 实际生效的版本通过下次连接的 StationInfo.device_config_version 上报。
+type DeviceConfigResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceConfigResult) Reset() {
+	*x = DeviceConfigResult{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceConfigResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceConfigResult) ProtoMessage() {}
+
+func (x *DeviceConfigResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceConfigResult.ProtoReflect.Descriptor instead.
+func (*DeviceConfigResult) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeviceConfigResult) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *DeviceConfigResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// DeviceCommandRequest 服务端向站点某条总线上的传感器发送一条原始命令（命令子流）。
+// bus 为连接的串口或 TCP 地址；protocol 为 "text"、"sdi12" 或 "modbus"。
+// text、sdi12 使用 command；modbus 使用 slave_id、function（3 保持寄存器，4 输入寄存器）、address、quantity。
+type DeviceCommandRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bus           string                 `protobuf:"bytes,1,opt,name=bus,proto3" json:"bus,omitempty"`
+	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Command       []byte                 `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	SlaveId       uint32                 `protobuf:"varint,4,opt,name=slave_id,json=slaveId,proto3" json:"slave_id,omitempty"`
+	Function      uint32                 `protobuf:"varint,5,opt,name=function,proto3" json:"function,omitempty"`
+	Address       uint32                 `protobuf:"varint,6,opt,name=address,proto3" json:"address,omitempty"`
+	Quantity      uint32                 `protobuf:"varint,7,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceCommandRequest) Reset() {
+	*x = DeviceCommandRequest{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceCommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceCommandRequest) ProtoMessage() {}
+
+func (x *DeviceCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceCommandRequest.ProtoReflect.Descriptor instead.
+func (*DeviceCommandRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeviceCommandRequest) GetBus() string {
+	if x != nil {
+		return x.Bus
+	}
+	return ""
+}
+
+func (x *DeviceCommandRequest) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *DeviceCommandRequest) GetCommand() []byte {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *DeviceCommandRequest) GetSlaveId() uint32 {
+	if x != nil {
+		return x.SlaveId
+	}
+	return 0
+}
+
+func (x *DeviceCommandRequest) GetFunction() uint32 {
+	if x != nil {
+		return x.Function
+	}
+	return 0
+}
+
+func (x *DeviceCommandRequest) GetAddress() uint32 {
+	if x != nil {
+		return x.Address
+	}
+	return 0
+}
+
+func (x *DeviceCommandRequest) GetQuantity() uint32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type DeviceCommandResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Output        []byte                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceCommandResponse) Reset() {
+	*x = DeviceCommandResponse{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceCommandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceCommandResponse) ProtoMessage() {}
+
+func (x *DeviceCommandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceCommandResponse.ProtoReflect.Descriptor instead.
+func (*DeviceCommandResponse) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DeviceCommandResponse) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *DeviceCommandResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// LogRequest 服务端读取站点内存中的最新日志（命令子流）。
+// 客户端先回复缓冲区中符合条件的日志；follow 为 true 时继续推送新日志，直到服务端关闭子流。
+type LogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinLevel      int32                  `protobuf:"zigzag32,1,opt,name=min_level,json=minLevel,proto3" json:"min_level,omitempty"` // slog 级别：-4 debug，0 info，4 warn，8 error
+	Device        string                 `protobuf:"bytes,2,opt,name=device,proto3" json:"device,omitempty"`                        // 按设备过滤：device/device_name/model 属性或日志消息中的单词，不区分大小写；空表示不过滤
+	Limit         uint32                 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                         // 最多返回缓冲区中最新的条数，0 表示全部
+	Follow        bool                   `protobuf:"varint,4,opt,name=follow,proto3" json:"follow,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogRequest) Reset() {
+	*x = LogRequest{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRequest) ProtoMessage() {}
+
+func (x *LogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRequest.ProtoReflect.Descriptor instead.
+func (*LogRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *LogRequest) GetMinLevel() int32 {
+	if x != nil {
+		return x.MinLevel
+	}
+	return 0
+}
+
+func (x *LogRequest) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *LogRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *LogRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
 }
 
-type RpiStatus struct {
+type LogAttr struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CpuTemp       float64                `protobuf:"fixed64,1,opt,name=cpu_temp,json=cpuTemp,proto3" json:"cpu_temp,omitempty"`
-	UnixMs        int64                  `protobuf:"varint,2,opt,name=unix_ms,json=unixMs,proto3" json:"unix_ms,omitempty"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RpiStatus) Reset() {
-	*x = RpiStatus{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[10]
+func (x *LogAttr) Reset() {
+	*x = LogAttr{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RpiStatus) String() string {
+func (x *LogAttr) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RpiStatus) ProtoMessage() {}
+func (*LogAttr) ProtoMessage() {}
 
-func (x *RpiStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[10]
+func (x *LogAttr) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -631,47 +1659,50 @@ func (x *RpiStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RpiStatus.ProtoReflect.Descriptor instead.
-func (*RpiStatus) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use LogAttr.ProtoReflect.Descriptor instead.
+func (*LogAttr) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *RpiStatus) GetCpuTemp() float64 {
+func (x *LogAttr) GetKey() string {
 	if x != nil {
-		return x.CpuTemp
+		return x.Key
 	}
-	return 0
+	return ""
 }
 
-func (x *RpiStatus) GetUnixMs() int64 {
+func (x *LogAttr) GetValue() string {
 	if x != nil {
-		return x.UnixMs
+		return x.Value
 	}
-	return 0
+	return ""
 }
 
-type CameraSnapshotRequest struct {
+type LogEntry struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CameraName    string                 `protobuf:"bytes,1,opt,name=camera_name,json=cameraName,proto3" json:"camera_name,omitempty"`
+	UnixMs        int64                  `protobuf:"varint,1,opt,name=unix_ms,json=unixMs,proto3" json:"unix_ms,omitempty"`
+	Level         int32                  `protobuf:"zigzag32,2,opt,name=level,proto3" json:"level,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Attrs         []*LogAttr             `protobuf:"bytes,4,rep,name=attrs,proto3" json:"attrs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CameraSnapshotRequest) Reset() {
-	*x = CameraSnapshotRequest{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[11]
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CameraSnapshotRequest) String() string {
+func (x *LogEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CameraSnapshotRequest) ProtoMessage() {}
+func (*LogEntry) ProtoMessage() {}
 
-func (x *CameraSnapshotRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[11]
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -682,41 +1713,61 @@ func (x *CameraSnapshotRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CameraSnapshotRequest.ProtoReflect.Descriptor instead.
-func (*CameraSnapshotRequest) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *CameraSnapshotRequest) GetCameraName() string {
+func (x *LogEntry) GetUnixMs() int64 {
 	if x != nil {
-		return x.CameraName
+		return x.UnixMs
+	}
+	return 0
+}
+
+func (x *LogEntry) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *LogEntry) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-type CameraSnapshotResponse struct {
+func (x *LogEntry) GetAttrs() []*LogAttr {
+	if x != nil {
+		return x.Attrs
+	}
+	return nil
+}
+
+type LogBatch struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Entries       []*LogEntry            `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CameraSnapshotResponse) Reset() {
-	*x = CameraSnapshotResponse{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[12]
+func (x *LogBatch) Reset() {
+	*x = LogBatch{}
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CameraSnapshotResponse) String() string {
+func (x *LogBatch) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CameraSnapshotResponse) ProtoMessage() {}
+func (*LogBatch) ProtoMessage() {}
 
-func (x *CameraSnapshotResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[12]
+func (x *LogBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -727,25 +1778,18 @@ func (x *CameraSnapshotResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CameraSnapshotResponse.ProtoReflect.Descriptor instead.
-func (*CameraSnapshotResponse) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use LogBatch.ProtoReflect.Descriptor instead.
+func (*LogBatch) Descriptor() ([]byte, []int) {
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *CameraSnapshotResponse) GetData() []byte {
+func (x *LogBatch) GetEntries() []*LogEntry {
 	if x != nil {
-		return x.Data
+		return x.Entries
 	}
 	return nil
 }
 
-func (x *CameraSnapshotResponse) GetError() string {
-	if x != nil {
-		return x.Error
-	}
-	return ""
-}
-
 type ErrorFrame struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
@@ -757,7 +1801,7 @@ type ErrorFrame struct {
 
 func (x *ErrorFrame) Reset() {
 	*x = ErrorFrame{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[13]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -769,7 +1813,7 @@ func (x *ErrorFrame) String() string {
 func (*ErrorFrame) ProtoMessage() {}
 
 func (x *ErrorFrame) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[13]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -782,7 +1826,7 @@ func (x *ErrorFrame) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ErrorFrame.ProtoReflect.Descriptor instead.
 func (*ErrorFrame) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{13}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *ErrorFrame) GetCode() string {
@@ -821,6 +1865,16 @@ type StationMessage struct {
 	//	*StationMessage_CameraSnapshotRequest
 	//	*StationMessage_CameraSnapshotResponse
 	//	*StationMessage_Error
+	//	*StationMessage_ClientAuth
+	//	*StationMessage_DataCursor
+	//	*StationMessage_DataAck
+	//	*StationMessage_DeviceConfigPush
+	//	*StationMessage_DeviceConfigResult
+	//	*StationMessage_DeviceCommandRequest
+	//	*StationMessage_DeviceCommandResponse
+	//	*StationMessage_LogRequest
+	//	*StationMessage_LogBatch
+	//	*StationMessage_StationHealth
 	Body          isStationMessage_Body `protobuf_oneof:"body"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -828,7 +1882,7 @@ type StationMessage struct {
 
 func (x *StationMessage) Reset() {
 	*x = StationMessage{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[14]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -840,7 +1894,7 @@ func (x *StationMessage) String() string {
 func (*StationMessage) ProtoMessage() {}
 
 func (x *StationMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[14]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -853,7 +1907,7 @@ func (x *StationMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StationMessage.ProtoReflect.Descriptor instead.
 func (*StationMessage) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{14}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *StationMessage) GetBody() isStationMessage_Body {
@@ -962,6 +2016,96 @@ func (x *StationMessage) GetError() *ErrorFrame {
 	return nil
 }
 
+func (x *StationMessage) GetClientAuth() *ClientAuth {
+	if x != nil {
+		if x, ok := x.Body.(*StationMessage_ClientAuth); ok {
+			return x.ClientAuth
+		}
+	}
+	return nil
+}
+
+func (x *StationMessage) GetDataCursor() *DataCursor {
+	if x != nil {
+		if x, ok := x.Body.(*StationMessage_DataCursor); ok {
+			return x.DataCursor
+		}
+	}
+	return nil
+}
+
+func (x *StationMessage) GetDataAck() *DataAck {
+	if x != nil {
+		if x, ok := x.Body.(*StationMessage_DataAck); ok {
+			return x.DataAck
+		}
+	}
+	return nil
+}
+
+func (x *StationMessage) GetDeviceConfigPush() *DeviceConfigPush {
+	if x != nil {
+		if x, ok := x.Body.(*StationMessage_DeviceConfigPush); ok {
+			return x.DeviceConfigPush
+		}
+	}
+	return nil
+}
+
+func (x *StationMessage) GetDeviceConfigResult() *DeviceConfigResult {
+	if x != nil {
+		if x, ok := x.Body.(*StationMessage_DeviceConfigResult); ok {
+			return x.DeviceConfigResult
+		}
+	}
+	return nil
+}
+
+func (x *StationMessage) GetDeviceCommandRequest() *DeviceCommandRequest {
+	if x != nil {
+		if x, ok := x.Body.(*StationMessage_DeviceCommandRequest); ok {
+			return x.DeviceCommandRequest
+		}
+	}
+	return nil
+}
+
+func (x *StationMessage) GetDeviceCommandResponse() *DeviceCommandResponse {
+	if x != nil {
+		if x, ok := x.Body.(*StationMessage_DeviceCommandResponse); ok {
+			return x.DeviceCommandResponse
+		}
+	}
+	return nil
+}
+
+func (x *StationMessage) GetLogRequest() *LogRequest {
+	if x != nil {
+		if x, ok := x.Body.(*StationMessage_LogRequest); ok {
+			return x.LogRequest
+		}
+	}
+	return nil
+}
+
+func (x *StationMessage) GetLogBatch() *LogBatch {
+	if x != nil {
+		if x, ok := x.Body.(*StationMessage_LogBatch); ok {
+			return x.LogBatch
+		}
+	}
+	return nil
+}
+
+func (x *StationMessage) GetStationHealth() *StationHealth {
+	if x != nil {
+		if x, ok := x.Body.(*StationMessage_StationHealth); ok {
+			return x.StationHealth
+		}
+	}
+	return nil
+}
+
 type isStationMessage_Body interface {
 	isStationMessage_Body()
 }
@@ -1010,6 +2154,46 @@ type StationMessage_Error struct {
 	Error *ErrorFrame `protobuf:"bytes,11,opt,name=error,proto3,oneof"`
 }
 
+type StationMessage_ClientAuth struct {
+	ClientAuth *ClientAuth `protobuf:"bytes,12,opt,name=client_auth,json=clientAuth,proto3,oneof"`
+}
+
+type StationMessage_DataCursor struct {
+	DataCursor *DataCursor `protobuf:"bytes,13,opt,name=data_cursor,json=dataCursor,proto3,oneof"`
+}
+
+type StationMessage_DataAck struct {
+	DataAck *DataAck `protobuf:"bytes,14,opt,name=data_ack,json=dataAck,proto3,oneof"`
+}
+
+type StationMessage_DeviceConfigPush struct {
+	DeviceConfigPush *DeviceConfigPush `protobuf:"bytes,15,opt,name=device_config_push,json=deviceConfigPush,proto3,oneof"`
+}
+
+type StationMessage_DeviceConfigResult struct {
+	DeviceConfigResult *DeviceConfigResult `protobuf:"bytes,16,opt,name=device_config_result,json=deviceConfigResult,proto3,oneof"`
+}
+
+type StationMessage_DeviceCommandRequest struct {
+	DeviceCommandRequest *DeviceCommandRequest `protobuf:"bytes,17,opt,name=device_command_request,json=deviceCommandRequest,proto3,oneof"`
+}
+
+type StationMessage_DeviceCommandResponse struct {
+	DeviceCommandResponse *DeviceCommandResponse `protobuf:"bytes,18,opt,name=device_command_response,json=deviceCommandResponse,proto3,oneof"`
+}
+
+type StationMessage_LogRequest struct {
+	LogRequest *LogRequest `protobuf:"bytes,19,opt,name=log_request,json=logRequest,proto3,oneof"`
+}
+
+type StationMessage_LogBatch struct {
+	LogBatch *LogBatch `protobuf:"bytes,20,opt,name=log_batch,json=logBatch,proto3,oneof"`
+}
+
+type StationMessage_StationHealth struct {
+	StationHealth *StationHealth `protobuf:"bytes,21,opt,name=station_health,json=stationHealth,proto3,oneof"`
+}
+
 func (*StationMessage_ClientHello) isStationMessage_Body() {}
 
 func (*StationMessage_ServerHello) isStationMessage_Body() {}
@@ -1032,6 +2216,26 @@ func (*StationMessage_CameraSnapshotResponse) isStationMessage_Body() {}
 
 func (*StationMessage_Error) isStationMessage_Body() {}
 
+func (*StationMessage_ClientAuth) isStationMessage_Body() {}
+
+func (*StationMessage_DataCursor) isStationMessage_Body() {}
+
+func (*StationMessage_DataAck) isStationMessage_Body() {}
+
+func (*StationMessage_DeviceConfigPush) isStationMessage_Body() {}
+
+func (*StationMessage_DeviceConfigResult) isStationMessage_Body() {}
+
+func (*StationMessage_DeviceCommandRequest) isStationMessage_Body() {}
+
+func (*StationMessage_DeviceCommandResponse) isStationMessage_Body() {}
+
+func (*StationMessage_LogRequest) isStationMessage_Body() {}
+
+func (*StationMessage_LogBatch) isStationMessage_Body() {}
+
+func (*StationMessage_StationHealth) isStationMessage_Body() {}
+
 // RelayDownstreamHello 下游 server 握手，告知自己的身份和认证信息。
 type RelayDownstreamHello struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -1043,7 +2247,7 @@ type RelayDownstreamHello struct {
 
 func (x *RelayDownstreamHello) Reset() {
 	*x = RelayDownstreamHello{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[15]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1055,7 +2259,7 @@ func (x *RelayDownstreamHello) String() string {
 func (*RelayDownstreamHello) ProtoMessage() {}
 
 func (x *RelayDownstreamHello) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[15]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1068,7 +2272,7 @@ func (x *RelayDownstreamHello) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayDownstreamHello.ProtoReflect.Descriptor instead.
 func (*RelayDownstreamHello) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{15}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *RelayDownstreamHello) GetUsername() string {
@@ -1095,7 +2299,7 @@ type RelayUpstreamHello struct {
 
 func (x *RelayUpstreamHello) Reset() {
 	*x = RelayUpstreamHello{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[16]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1107,7 +2311,7 @@ func (x *RelayUpstreamHello) String() string {
 func (*RelayUpstreamHello) ProtoMessage() {}
 
 func (x *RelayUpstreamHello) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[16]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1120,7 +2324,7 @@ func (x *RelayUpstreamHello) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayUpstreamHello.ProtoReflect.Descriptor instead.
 func (*RelayUpstreamHello) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{16}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *RelayUpstreamHello) GetServerVersion() string {
@@ -1152,7 +2356,7 @@ type RelayStationFull struct {
 
 func (x *RelayStationFull) Reset() {
 	*x = RelayStationFull{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[17]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1164,7 +2368,7 @@ func (x *RelayStationFull) String() string {
 func (*RelayStationFull) ProtoMessage() {}
 
 func (x *RelayStationFull) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[17]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1177,7 +2381,7 @@ func (x *RelayStationFull) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayStationFull.ProtoReflect.Descriptor instead.
 func (*RelayStationFull) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{17}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *RelayStationFull) GetId() string {
@@ -1274,7 +2478,7 @@ type RelayDevice struct {
 
 func (x *RelayDevice) Reset() {
 	*x = RelayDevice{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[18]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1286,7 +2490,7 @@ func (x *RelayDevice) String() string {
 func (*RelayDevice) ProtoMessage() {}
 
 func (x *RelayDevice) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[18]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1299,7 +2503,7 @@ func (x *RelayDevice) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayDevice.ProtoReflect.Descriptor instead.
 func (*RelayDevice) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{18}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *RelayDevice) GetStationId() string {
@@ -1331,7 +2535,7 @@ type RelayItem struct {
 
 func (x *RelayItem) Reset() {
 	*x = RelayItem{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[19]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1343,7 +2547,7 @@ func (x *RelayItem) String() string {
 func (*RelayItem) ProtoMessage() {}
 
 func (x *RelayItem) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[19]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1356,7 +2560,7 @@ func (x *RelayItem) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayItem.ProtoReflect.Descriptor instead.
 func (*RelayItem) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{19}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *RelayItem) GetStationId() string {
@@ -1420,7 +2624,7 @@ type RelayDeviceRecord struct {
 
 func (x *RelayDeviceRecord) Reset() {
 	*x = RelayDeviceRecord{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[20]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1432,7 +2636,7 @@ func (x *RelayDeviceRecord) String() string {
 func (*RelayDeviceRecord) ProtoMessage() {}
 
 func (x *RelayDeviceRecord) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[20]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1445,7 +2649,7 @@ func (x *RelayDeviceRecord) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayDeviceRecord.ProtoReflect.Descriptor instead.
 func (*RelayDeviceRecord) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{20}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *RelayDeviceRecord) GetId() string {
@@ -1491,7 +2695,7 @@ type RelayConfigBatch struct {
 
 func (x *RelayConfigBatch) Reset() {
 	*x = RelayConfigBatch{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[21]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1503,7 +2707,7 @@ func (x *RelayConfigBatch) String() string {
 func (*RelayConfigBatch) ProtoMessage() {}
 
 func (x *RelayConfigBatch) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[21]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1516,7 +2720,7 @@ func (x *RelayConfigBatch) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayConfigBatch.ProtoReflect.Descriptor instead.
 func (*RelayConfigBatch) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{21}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *RelayConfigBatch) GetFullSync() bool {
@@ -1558,7 +2762,7 @@ type RelayConfigEvent struct {
 
 func (x *RelayConfigEvent) Reset() {
 	*x = RelayConfigEvent{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[22]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1570,7 +2774,7 @@ func (x *RelayConfigEvent) String() string {
 func (*RelayConfigEvent) ProtoMessage() {}
 
 func (x *RelayConfigEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[22]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1583,7 +2787,7 @@ func (x *RelayConfigEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayConfigEvent.ProtoReflect.Descriptor instead.
 func (*RelayConfigEvent) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{22}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *RelayConfigEvent) GetType() string {
@@ -1610,7 +2814,7 @@ type RelayAvailableItems struct {
 
 func (x *RelayAvailableItems) Reset() {
 	*x = RelayAvailableItems{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[23]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1622,7 +2826,7 @@ func (x *RelayAvailableItems) String() string {
 func (*RelayAvailableItems) ProtoMessage() {}
 
 func (x *RelayAvailableItems) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[23]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1635,7 +2839,7 @@ func (x *RelayAvailableItems) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayAvailableItems.ProtoReflect.Descriptor instead.
 func (*RelayAvailableItems) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{23}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *RelayAvailableItems) GetStations() map[string]*RelayAvailableItemList {
@@ -1654,7 +2858,7 @@ type RelayAvailableItemList struct {
 
 func (x *RelayAvailableItemList) Reset() {
 	*x = RelayAvailableItemList{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[24]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1666,7 +2870,7 @@ func (x *RelayAvailableItemList) String() string {
 func (*RelayAvailableItemList) ProtoMessage() {}
 
 func (x *RelayAvailableItemList) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[24]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1679,7 +2883,7 @@ func (x *RelayAvailableItemList) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayAvailableItemList.ProtoReflect.Descriptor instead.
 func (*RelayAvailableItemList) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{24}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *RelayAvailableItemList) GetItemNames() []string {
@@ -1691,15 +2895,18 @@ func (x *RelayAvailableItemList) GetItemNames() []string {
 
 // RelayItemsLatest 多站点的 item 最新时间戳，用于缺失数据查询。
 type RelayItemsLatest struct {
-	state         protoimpl.MessageState  `protogen:"open.v1"`
-	Stations      map[string]*ItemsLatest `protobuf:"bytes,1,rep,name=stations,proto3" json:"stations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // map[stationUUID]ItemsLatest
+	state    protoimpl.MessageState  `protogen:"open.v1"`
+	Stations map[string]*ItemsLatest `protobuf:"bytes,1,rep,name=stations,proto3" json:"stations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // map[stationUUID]ItemsLatest
+	// edit_after_id 下游已应用的上游数据编辑（data_edits.id）的最大值，上游补发 id 更大的编辑。
+	// 为 0 时不补发，结束批次携带上游当前最大编辑 id 作为游标。
+	EditAfterId   int64 `protobuf:"varint,2,opt,name=edit_after_id,json=editAfterId,proto3" json:"edit_after_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RelayItemsLatest) Reset() {
 	*x = RelayItemsLatest{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[25]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1711,7 +2918,7 @@ func (x *RelayItemsLatest) String() string {
 func (*RelayItemsLatest) ProtoMessage() {}
 
 func (x *RelayItemsLatest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[25]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1724,7 +2931,7 @@ func (x *RelayItemsLatest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayItemsLatest.ProtoReflect.Descriptor instead.
 func (*RelayItemsLatest) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{25}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *RelayItemsLatest) GetStations() map[string]*ItemsLatest {
@@ -1734,6 +2941,13 @@ func (x *RelayItemsLatest) GetStations() map[string]*ItemsLatest {
 	return nil
 }
 
+func (x *RelayItemsLatest) GetEditAfterId() int64 {
+	if x != nil {
+		return x.EditAfterId
+	}
+	return 0
+}
+
 // RelayStatusLatest 多站点的最新状态日志行号。
 type RelayStatusLatest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1744,7 +2958,7 @@ type RelayStatusLatest struct {
 
 func (x *RelayStatusLatest) Reset() {
 	*x = RelayStatusLatest{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[26]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1756,7 +2970,7 @@ func (x *RelayStatusLatest) String() string {
 func (*RelayStatusLatest) ProtoMessage() {}
 
 func (x *RelayStatusLatest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[26]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1769,7 +2983,7 @@ func (x *RelayStatusLatest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayStatusLatest.ProtoReflect.Descriptor instead.
 func (*RelayStatusLatest) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{26}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *RelayStatusLatest) GetStations() map[string]int64 {
@@ -1781,17 +2995,20 @@ func (x *RelayStatusLatest) GetStations() map[string]int64 {
 
 // RelayDataBatch 带站点 ID 的数据批次，用于 server-to-server 数据转发。
 type RelayDataBatch struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	StationId     string                 `protobuf:"bytes,1,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"` // UUID
-	DataType      string                 `protobuf:"bytes,2,opt,name=data_type,json=dataType,proto3" json:"data_type,omitempty"`    // "data", "data_gpio", "MissData"
-	Points        []*DataPoint           `protobuf:"bytes,3,rep,name=points,proto3" json:"points,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	StationId string                 `protobuf:"bytes,1,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"` // UUID
+	DataType  string                 `protobuf:"bytes,2,opt,name=data_type,json=dataType,proto3" json:"data_type,omitempty"`    // "data", "data_gpio", "MissData", "EditData"
+	Points    []*DataPoint           `protobuf:"bytes,3,rep,name=points,proto3" json:"points,omitempty"`
+	// edit_id 数据编辑的 id（data_type 为 "EditData"），一次编辑拆成多批时只在最后一批设置，下游应用后记为游标；
+	// 补数据结束的空批次携带补发后的游标。
+	EditId        int64 `protobuf:"varint,4,opt,name=edit_id,json=editId,proto3" json:"edit_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RelayDataBatch) Reset() {
 	*x = RelayDataBatch{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[27]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1803,7 +3020,7 @@ func (x *RelayDataBatch) String() string {
 func (*RelayDataBatch) ProtoMessage() {}
 
 func (x *RelayDataBatch) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[27]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1816,7 +3033,7 @@ func (x *RelayDataBatch) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayDataBatch.ProtoReflect.Descriptor instead.
 func (*RelayDataBatch) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{27}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *RelayDataBatch) GetStationId() string {
@@ -1840,6 +3057,13 @@ func (x *RelayDataBatch) GetPoints() []*DataPoint {
 	return nil
 }
 
+func (x *RelayDataBatch) GetEditId() int64 {
+	if x != nil {
+		return x.EditId
+	}
+	return 0
+}
+
 // RelayStatusEvent 带站点 ID 的状态变更事件，用于 server-to-server 状态转发。
 type RelayStatusEvent struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -1855,7 +3079,7 @@ type RelayStatusEvent struct {
 
 func (x *RelayStatusEvent) Reset() {
 	*x = RelayStatusEvent{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[28]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1867,7 +3091,7 @@ func (x *RelayStatusEvent) String() string {
 func (*RelayStatusEvent) ProtoMessage() {}
 
 func (x *RelayStatusEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[28]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1880,7 +3104,7 @@ func (x *RelayStatusEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayStatusEvent.ProtoReflect.Descriptor instead.
 func (*RelayStatusEvent) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{28}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *RelayStatusEvent) GetStationId() string {
@@ -1946,7 +3170,7 @@ type RelayMessage struct {
 
 func (x *RelayMessage) Reset() {
 	*x = RelayMessage{}
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[29]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1958,7 +3182,7 @@ func (x *RelayMessage) String() string {
 func (*RelayMessage) ProtoMessage() {}
 
 func (x *RelayMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[29]
+	mi := &file_proto_sync_v2_sync_v2_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1971,7 +3195,7 @@ func (x *RelayMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayMessage.ProtoReflect.Descriptor instead.
 func (*RelayMessage) Descriptor() ([]byte, []int) {
-	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{29}
+	return file_proto_sync_v2_sync_v2_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *RelayMessage) GetBody() isRelayMessage_Body {
@@ -2124,24 +3348,36 @@ var File_proto_sync_v2_sync_v2_proto protoreflect.FileDescriptor
 
 const file_proto_sync_v2_sync_v2_proto_rawDesc = "" +
 	"\n" +
-	"\x1bproto/sync/v2/sync_v2.proto\x12\ftide.sync.v2\"g\n" +
+	"\x1bproto/sync/v2/sync_v2.proto\x12\ftide.sync.v2\"\xa8\x01\n" +
 	"\vClientHello\x12-\n" +
 	"\x12station_identifier\x18\x01 \x01(\tR\x11stationIdentifier\x12)\n" +
-	"\x10protocol_version\x18\x02 \x01(\tR\x0fprotocolVersion\"4\n" +
+	"\x10protocol_version\x18\x02 \x01(\tR\x0fprotocolVersion\x12\x1b\n" +
+	"\toutbox_id\x18\x03 \x01(\tR\boutboxId\x12\"\n" +
+	"\fcompressions\x18\x04 \x03(\tR\fcompressions\"\xc8\x01\n" +
 	"\vServerHello\x12%\n" +
-	"\x0eserver_version\x18\x01 \x01(\tR\rserverVersion\"\x83\x01\n" +
+	"\x0eserver_version\x18\x01 \x01(\tR\rserverVersion\x12%\n" +
+	"\x0eauth_challenge\x18\x02 \x01(\fR\rauthChallenge\x12 \n" +
+	"\vcompression\x18\x03 \x01(\tR\vcompression\x12#\n" +
+	"\rcolumnar_data\x18\x04 \x01(\bR\fcolumnarData\x12$\n" +
+	"\x0eserver_unix_ms\x18\x05 \x01(\x03R\fserverUnixMs\"F\n" +
+	"\n" +
+	"ClientAuth\x12\x10\n" +
+	"\x03mac\x18\x01 \x01(\fR\x03mac\x12&\n" +
+	"\x0fclock_offset_ms\x18\x02 \x01(\x03R\rclockOffsetMs\"\x83\x01\n" +
 	"\vDeviceItems\x12:\n" +
 	"\x05items\x18\x01 \x03(\v2$.tide.sync.v2.DeviceItems.ItemsEntryR\x05items\x1a8\n" +
 	"\n" +
 	"ItemsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe0\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc4\x02\n" +
 	"\vStationInfo\x12\x1e\n" +
 	"\n" +
 	"identifier\x18\x01 \x01(\tR\n" +
 	"identifier\x12@\n" +
 	"\adevices\x18\x02 \x03(\v2&.tide.sync.v2.StationInfo.DevicesEntryR\adevices\x12\x18\n" +
-	"\acameras\x18\x03 \x03(\tR\acameras\x1aU\n" +
+	"\acameras\x18\x03 \x03(\tR\acameras\x122\n" +
+	"\x15device_config_version\x18\x04 \x01(\x03R\x13deviceConfigVersion\x12.\n" +
+	"\x13device_config_error\x18\x05 \x01(\tR\x11deviceConfigError\x1aU\n" +
 	"\fDevicesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
 	"\x05value\x18\x02 \x01(\v2\x19.tide.sync.v2.DeviceItemsR\x05value:\x028\x01\"\xa1\x01\n" +
@@ -2151,15 +3387,36 @@ const file_proto_sync_v2_sync_v2_proto_rawDesc = "" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"2\n" +
 	"\fStatusLatest\x12\"\n" +
-	"\rlatest_row_id\x18\x01 \x01(\x03R\vlatestRowId\"\x83\x01\n" +
+	"\rlatest_row_id\x18\x01 \x01(\x03R\vlatestRowId\"'\n" +
+	"\n" +
+	"DataCursor\x12\x19\n" +
+	"\blast_seq\x18\x01 \x01(\x03R\alastSeq\"\xa9\x01\n" +
 	"\tDataPoint\x12\x1b\n" +
 	"\titem_name\x18\x01 \x01(\tR\bitemName\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\x01R\x05value\x12\x17\n" +
 	"\aunix_ms\x18\x03 \x01(\x03R\x06unixMs\x12*\n" +
-	"\x04kind\x18\x04 \x01(\x0e2\x16.tide.sync.v2.DataKindR\x04kind\"T\n" +
+	"\x04kind\x18\x04 \x01(\x0e2\x16.tide.sync.v2.DataKindR\x04kind\x12\x10\n" +
+	"\x03seq\x18\x05 \x01(\x03R\x03seq\x12\x12\n" +
+	"\x04flag\x18\x06 \x01(\rR\x04flag\"\x86\x02\n" +
+	"\n" +
+	"DataColumn\x12\x1b\n" +
+	"\titem_name\x18\x01 \x01(\tR\bitemName\x12*\n" +
+	"\x04kind\x18\x02 \x01(\x0e2\x16.tide.sync.v2.DataKindR\x04kind\x12\"\n" +
+	"\runix_ms_delta\x18\x03 \x03(\x12R\vunixMsDelta\x12\x1b\n" +
+	"\tseq_delta\x18\x04 \x03(\x12R\bseqDelta\x12\x1f\n" +
+	"\vvalue_scale\x18\x05 \x01(\x11R\n" +
+	"valueScale\x12\x1f\n" +
+	"\vvalue_delta\x18\x06 \x03(\x12R\n" +
+	"valueDelta\x12\x16\n" +
+	"\x06values\x18\a \x03(\x01R\x06values\x12\x14\n" +
+	"\x05flags\x18\b \x03(\rR\x05flags\"\xa3\x01\n" +
 	"\tDataBatch\x12\x16\n" +
 	"\x06replay\x18\x01 \x01(\bR\x06replay\x12/\n" +
-	"\x06points\x18\x02 \x03(\v2\x17.tide.sync.v2.DataPointR\x06points\"\x88\x01\n" +
+	"\x06points\x18\x02 \x03(\v2\x17.tide.sync.v2.DataPointR\x06points\x122\n" +
+	"\acolumns\x18\x04 \x03(\v2\x18.tide.sync.v2.DataColumnR\acolumns\x12\x19\n" +
+	"\bbatch_id\x18\x03 \x01(\x04R\abatchId\"$\n" +
+	"\aDataAck\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\x04R\abatchId\"\x88\x01\n" +
 	"\rItemStatusLog\x12\x15\n" +
 	"\x06row_id\x18\x01 \x01(\x03R\x05rowId\x12\x1b\n" +
 	"\titem_name\x18\x02 \x01(\tR\bitemName\x12\x16\n" +
@@ -2170,18 +3427,77 @@ const file_proto_sync_v2_sync_v2_proto_rawDesc = "" +
 	"\x04logs\x18\x02 \x03(\v2\x1b.tide.sync.v2.ItemStatusLogR\x04logs\"?\n" +
 	"\tRpiStatus\x12\x19\n" +
 	"\bcpu_temp\x18\x01 \x01(\x01R\acpuTemp\x12\x17\n" +
-	"\aunix_ms\x18\x02 \x01(\x03R\x06unixMs\"8\n" +
+	"\aunix_ms\x18\x02 \x01(\x03R\x06unixMs\"_\n" +
+	"\tDiskUsage\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x02 \x01(\x04R\tfreeBytes\x12\x1f\n" +
+	"\vtotal_bytes\x18\x03 \x01(\x04R\n" +
+	"totalBytes\"\xf6\x04\n" +
+	"\rStationHealth\x12\x17\n" +
+	"\aunix_ms\x18\x01 \x01(\x03R\x06unixMs\x12\x1e\n" +
+	"\bcpu_temp\x18\x02 \x01(\x01H\x00R\acpuTemp\x88\x01\x01\x12-\n" +
+	"\x05disks\x18\x03 \x03(\v2\x17.tide.sync.v2.DiskUsageR\x05disks\x12&\n" +
+	"\x0fmem_total_bytes\x18\x04 \x01(\x04R\rmemTotalBytes\x12.\n" +
+	"\x13mem_available_bytes\x18\x05 \x01(\x04R\x11memAvailableBytes\x12\x14\n" +
+	"\x05load1\x18\x06 \x01(\x01R\x05load1\x12\x14\n" +
+	"\x05load5\x18\a \x01(\x01R\x05load5\x12\x16\n" +
+	"\x06load15\x18\b \x01(\x01R\x06load15\x12%\n" +
+	"\x0euptime_seconds\x18\t \x01(\x04R\ruptimeSeconds\x12\"\n" +
+	"\rdb_size_bytes\x18\n" +
+	" \x01(\x04R\vdbSizeBytes\x12%\n" +
+	"\x0ereplay_backlog\x18\v \x01(\x04R\rreplayBacklog\x12U\n" +
+	"\x0eserial_reopens\x18\f \x03(\v2..tide.sync.v2.StationHealth.SerialReopensEntryR\rserialReopens\x12&\n" +
+	"\x0fclock_offset_ms\x18\r \x01(\x03R\rclockOffsetMs\x12!\n" +
+	"\fclock_synced\x18\x0e \x01(\bR\vclockSynced\x1a@\n" +
+	"\x12SerialReopensEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\rR\x05value:\x028\x01B\v\n" +
+	"\t_cpu_temp\"8\n" +
 	"\x15CameraSnapshotRequest\x12\x1f\n" +
 	"\vcamera_name\x18\x01 \x01(\tR\n" +
 	"cameraName\"B\n" +
 	"\x16CameraSnapshotResponse\x12\x12\n" +
 	"\x04data\x18\x01 \x01(\fR\x04data\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"X\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"D\n" +
+	"\x10DeviceConfigPush\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x16\n" +
+	"\x06config\x18\x02 \x01(\fR\x06config\"D\n" +
+	"\x12DeviceConfigResult\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xcb\x01\n" +
+	"\x14DeviceCommandRequest\x12\x10\n" +
+	"\x03bus\x18\x01 \x01(\tR\x03bus\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x18\n" +
+	"\acommand\x18\x03 \x01(\fR\acommand\x12\x19\n" +
+	"\bslave_id\x18\x04 \x01(\rR\aslaveId\x12\x1a\n" +
+	"\bfunction\x18\x05 \x01(\rR\bfunction\x12\x18\n" +
+	"\aaddress\x18\x06 \x01(\rR\aaddress\x12\x1a\n" +
+	"\bquantity\x18\a \x01(\rR\bquantity\"E\n" +
+	"\x15DeviceCommandResponse\x12\x16\n" +
+	"\x06output\x18\x01 \x01(\fR\x06output\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"o\n" +
+	"\n" +
+	"LogRequest\x12\x1b\n" +
+	"\tmin_level\x18\x01 \x01(\x11R\bminLevel\x12\x16\n" +
+	"\x06device\x18\x02 \x01(\tR\x06device\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\rR\x05limit\x12\x16\n" +
+	"\x06follow\x18\x04 \x01(\bR\x06follow\"1\n" +
+	"\aLogAttr\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\x80\x01\n" +
+	"\bLogEntry\x12\x17\n" +
+	"\aunix_ms\x18\x01 \x01(\x03R\x06unixMs\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\x11R\x05level\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12+\n" +
+	"\x05attrs\x18\x04 \x03(\v2\x15.tide.sync.v2.LogAttrR\x05attrs\"<\n" +
+	"\bLogBatch\x120\n" +
+	"\aentries\x18\x01 \x03(\v2\x16.tide.sync.v2.LogEntryR\aentries\"X\n" +
 	"\n" +
 	"ErrorFrame\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
-	"\tretryable\x18\x03 \x01(\bR\tretryable\"\x8f\x06\n" +
+	"\tretryable\x18\x03 \x01(\bR\tretryable\"\xd8\v\n" +
 	"\x0eStationMessage\x12>\n" +
 	"\fclient_hello\x18\x01 \x01(\v2\x19.tide.sync.v2.ClientHelloH\x00R\vclientHello\x12>\n" +
 	"\fserver_hello\x18\x02 \x01(\v2\x19.tide.sync.v2.ServerHelloH\x00R\vserverHello\x12>\n" +
@@ -2196,7 +3512,20 @@ const file_proto_sync_v2_sync_v2_proto_rawDesc = "" +
 	"\x17camera_snapshot_request\x18\t \x01(\v2#.tide.sync.v2.CameraSnapshotRequestH\x00R\x15cameraSnapshotRequest\x12`\n" +
 	"\x18camera_snapshot_response\x18\n" +
 	" \x01(\v2$.tide.sync.v2.CameraSnapshotResponseH\x00R\x16cameraSnapshotResponse\x120\n" +
-	"\x05error\x18\v \x01(\v2\x18.tide.sync.v2.ErrorFrameH\x00R\x05errorB\x06\n" +
+	"\x05error\x18\v \x01(\v2\x18.tide.sync.v2.ErrorFrameH\x00R\x05error\x12;\n" +
+	"\vclient_auth\x18\f \x01(\v2\x18.tide.sync.v2.ClientAuthH\x00R\n" +
+	"clientAuth\x12;\n" +
+	"\vdata_cursor\x18\r \x01(\v2\x18.tide.sync.v2.DataCursorH\x00R\n" +
+	"dataCursor\x122\n" +
+	"\bdata_ack\x18\x0e \x01(\v2\x15.tide.sync.v2.DataAckH\x00R\adataAck\x12N\n" +
+	"\x12device_config_push\x18\x0f \x01(\v2\x1e.tide.sync.v2.DeviceConfigPushH\x00R\x10deviceConfigPush\x12T\n" +
+	"\x14device_config_result\x18\x10 \x01(\v2 .tide.sync.v2.DeviceConfigResultH\x00R\x12deviceConfigResult\x12Z\n" +
+	"\x16device_command_request\x18\x11 \x01(\v2\".tide.sync.v2.DeviceCommandRequestH\x00R\x14deviceCommandRequest\x12]\n" +
+	"\x17device_command_response\x18\x12 \x01(\v2#.tide.sync.v2.DeviceCommandResponseH\x00R\x15deviceCommandResponse\x12;\n" +
+	"\vlog_request\x18\x13 \x01(\v2\x18.tide.sync.v2.LogRequestH\x00R\n" +
+	"logRequest\x125\n" +
+	"\tlog_batch\x18\x14 \x01(\v2\x16.tide.sync.v2.LogBatchH\x00R\blogBatch\x12D\n" +
+	"\x0estation_health\x18\x15 \x01(\v2\x1b.tide.sync.v2.StationHealthH\x00R\rstationHealthB\x06\n" +
 	"\x04body\"]\n" +
 	"\x14RelayDownstreamHello\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12)\n" +
@@ -2255,9 +3584,10 @@ const file_proto_sync_v2_sync_v2_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\v2$.tide.sync.v2.RelayAvailableItemListR\x05value:\x028\x01\"7\n" +
 	"\x16RelayAvailableItemList\x12\x1d\n" +
 	"\n" +
-	"item_names\x18\x01 \x03(\tR\titemNames\"\xb4\x01\n" +
+	"item_names\x18\x01 \x03(\tR\titemNames\"\xd8\x01\n" +
 	"\x10RelayItemsLatest\x12H\n" +
-	"\bstations\x18\x01 \x03(\v2,.tide.sync.v2.RelayItemsLatest.StationsEntryR\bstations\x1aV\n" +
+	"\bstations\x18\x01 \x03(\v2,.tide.sync.v2.RelayItemsLatest.StationsEntryR\bstations\x12\"\n" +
+	"\redit_after_id\x18\x02 \x01(\x03R\veditAfterId\x1aV\n" +
 	"\rStationsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
 	"\x05value\x18\x02 \x01(\v2\x19.tide.sync.v2.ItemsLatestR\x05value:\x028\x01\"\x9b\x01\n" +
@@ -2265,12 +3595,13 @@ const file_proto_sync_v2_sync_v2_proto_rawDesc = "" +
 	"\bstations\x18\x01 \x03(\v2-.tide.sync.v2.RelayStatusLatest.StationsEntryR\bstations\x1a;\n" +
 	"\rStationsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"}\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x96\x01\n" +
 	"\x0eRelayDataBatch\x12\x1d\n" +
 	"\n" +
 	"station_id\x18\x01 \x01(\tR\tstationId\x12\x1b\n" +
 	"\tdata_type\x18\x02 \x01(\tR\bdataType\x12/\n" +
-	"\x06points\x18\x03 \x03(\v2\x17.tide.sync.v2.DataPointR\x06points\"\xca\x01\n" +
+	"\x06points\x18\x03 \x03(\v2\x17.tide.sync.v2.DataPointR\x06points\x12\x17\n" +
+	"\aedit_id\x18\x04 \x01(\x03R\x06editId\"\xca\x01\n" +
 	"\x10RelayStatusEvent\x12\x1d\n" +
 	"\n" +
 	"station_id\x18\x01 \x01(\tR\tstationId\x12\x1e\n" +
@@ -2315,94 +3646,125 @@ func file_proto_sync_v2_sync_v2_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_sync_v2_sync_v2_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_sync_v2_sync_v2_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
+var file_proto_sync_v2_sync_v2_proto_msgTypes = make([]protoimpl.MessageInfo, 51)
 var file_proto_sync_v2_sync_v2_proto_goTypes = []any{
 	(DataKind)(0),                  // 0: tide.sync.v2.DataKind
 	(*ClientHello)(nil),            // 1: tide.sync.v2.ClientHello
 	(*ServerHello)(nil),            // 2: tide.sync.v2.ServerHello
-	(*DeviceItems)(nil),            // 3: tide.sync.v2.DeviceItems
-	(*StationInfo)(nil),            // 4: tide.sync.v2.StationInfo
-	(*ItemsLatest)(nil),            // 5: tide.sync.v2.ItemsLatest
-	(*StatusLatest)(nil),           // 6: tide.sync.v2.StatusLatest
-	(*DataPoint)(nil),              // 7: tide.sync.v2.DataPoint
-	(*DataBatch)(nil),              // 8: tide.sync.v2.DataBatch
-	(*ItemStatusLog)(nil),          // 9: tide.sync.v2.ItemStatusLog
-	(*ItemStatusBatch)(nil),        // 10: tide.sync.v2.ItemStatusBatch
-	(*RpiStatus)(nil),              // 11: tide.sync.v2.RpiStatus
-	(*CameraSnapshotRequest)(nil),  // 12: tide.sync.v2.CameraSnapshotRequest
-	(*CameraSnapshotResponse)(nil), // 13: tide.sync.v2.CameraSnapshotResponse
-	(*ErrorFrame)(nil),             // 14: tide.sync.v2.ErrorFrame
-	(*StationMessage)(nil),         // 15: tide.sync.v2.StationMessage
-	(*RelayDownstreamHello)(nil),   // 16: tide.sync.v2.RelayDownstreamHello
-	(*RelayUpstreamHello)(nil),     // 17: tide.sync.v2.RelayUpstreamHello
-	(*RelayStationFull)(nil),       // 18: tide.sync.v2.RelayStationFull
-	(*RelayDevice)(nil),            // 19: tide.sync.v2.RelayDevice
-	(*RelayItem)(nil),              // 20: tide.sync.v2.RelayItem
-	(*RelayDeviceRecord)(nil),      // 21: tide.sync.v2.RelayDeviceRecord
-	(*RelayConfigBatch)(nil),       // 22: tide.sync.v2.RelayConfigBatch
-	(*RelayConfigEvent)(nil),       // 23: tide.sync.v2.RelayConfigEvent
-	(*RelayAvailableItems)(nil),    // 24: tide.sync.v2.RelayAvailableItems
-	(*RelayAvailableItemList)(nil), // 25: tide.sync.v2.RelayAvailableItemList
-	(*RelayItemsLatest)(nil),       // 26: tide.sync.v2.RelayItemsLatest
-	(*RelayStatusLatest)(nil),      // 27: tide.sync.v2.RelayStatusLatest
-	(*RelayDataBatch)(nil),         // 28: tide.sync.v2.RelayDataBatch
-	(*RelayStatusEvent)(nil),       // 29: tide.sync.v2.RelayStatusEvent
-	(*RelayMessage)(nil),           // 30: tide.sync.v2.RelayMessage
-	nil,                            // 31: tide.sync.v2.DeviceItems.ItemsEntry
-	nil,                            // 32: tide.sync.v2.StationInfo.DevicesEntry
-	nil,                            // 33: tide.sync.v2.ItemsLatest.LatestUnixMsEntry
-	nil,                            // 34: tide.sync.v2.RelayAvailableItems.StationsEntry
-	nil,                            // 35: tide.sync.v2.RelayItemsLatest.StationsEntry
-	nil,                            // 36: tide.sync.v2.RelayStatusLatest.StationsEntry
+	(*ClientAuth)(nil),             // 3: tide.sync.v2.ClientAuth
+	(*DeviceItems)(nil),            // 4: tide.sync.v2.DeviceItems
+	(*StationInfo)(nil),            // 5: tide.sync.v2.StationInfo
+	(*ItemsLatest)(nil),            // 6: tide.sync.v2.ItemsLatest
+	(*StatusLatest)(nil),           // 7: tide.sync.v2.StatusLatest
+	(*DataCursor)(nil),             // 8: tide.sync.v2.DataCursor
+	(*DataPoint)(nil),              // 9: tide.sync.v2.DataPoint
+	(*DataColumn)(nil),             // 10: tide.sync.v2.DataColumn
+	(*DataBatch)(nil),              // 11: tide.sync.v2.DataBatch
+	(*DataAck)(nil),                // 12: tide.sync.v2.DataAck
+	(*ItemStatusLog)(nil),          // 13: tide.sync.v2.ItemStatusLog
+	(*ItemStatusBatch)(nil),        // 14: tide.sync.v2.ItemStatusBatch
+	(*RpiStatus)(nil),              // 15: tide.sync.v2.RpiStatus
+	(*DiskUsage)(nil),              // 16: tide.sync.v2.DiskUsage
+	(*StationHealth)(nil),          // 17: tide.sync.v2.StationHealth
+	(*CameraSnapshotRequest)(nil),  // 18: tide.sync.v2.CameraSnapshotRequest
+	(*CameraSnapshotResponse)(nil), // 19: tide.sync.v2.CameraSnapshotResponse
+	(*DeviceConfigPush)(nil),       // 20: tide.sync.v2.DeviceConfigPush
+	(*DeviceConfigResult)(nil),     // 21: tide.sync.v2.DeviceConfigResult
+	(*DeviceCommandRequest)(nil),   // 22: tide.sync.v2.DeviceCommandRequest
+	(*DeviceCommandResponse)(nil),  // 23: tide.sync.v2.DeviceCommandResponse
+	(*LogRequest)(nil),             // 24: tide.sync.v2.LogRequest
+	(*LogAttr)(nil),                // 25: tide.sync.v2.LogAttr
+	(*LogEntry)(nil),               // 26: tide.sync.v2.LogEntry
+	(*LogBatch)(nil),               // 27: tide.sync.v2.LogBatch
+	(*ErrorFrame)(nil),             // 28: tide.sync.v2.ErrorFrame
+	(*StationMessage)(nil),         // 29: tide.sync.v2.StationMessage
+	(*RelayDownstreamHello)(nil),   // 30: tide.sync.v2.RelayDownstreamHello
+	(*RelayUpstreamHello)(nil),     // 31: tide.sync.v2.RelayUpstreamHello
+	(*RelayStationFull)(nil),       // 32: tide.sync.v2.RelayStationFull
+	(*RelayDevice)(nil),            // 33: tide.sync.v2.RelayDevice
+	(*RelayItem)(nil),              // 34: tide.sync.v2.RelayItem
+	(*RelayDeviceRecord)(nil),      // 35: tide.sync.v2.RelayDeviceRecord
+	(*RelayConfigBatch)(nil),       // 36: tide.sync.v2.RelayConfigBatch
+	(*RelayConfigEvent)(nil),       // 37: tide.sync.v2.RelayConfigEvent
+	(*RelayAvailableItems)(nil),    // 38: tide.sync.v2.RelayAvailableItems
+	(*RelayAvailableItemList)(nil), // 39: tide.sync.v2.RelayAvailableItemList
+	(*RelayItemsLatest)(nil),       // 40: tide.sync.v2.RelayItemsLatest
+	(*RelayStatusLatest)(nil),      // 41: tide.sync.v2.RelayStatusLatest
+	(*RelayDataBatch)(nil),         // 42: tide.sync.v2.RelayDataBatch
+	(*RelayStatusEvent)(nil),       // 43: tide.sync.v2.RelayStatusEvent
+	(*RelayMessage)(nil),           // 44: tide.sync.v2.RelayMessage
+	nil,                            // 45: tide.sync.v2.DeviceItems.ItemsEntry
+	nil,                            // 46: tide.sync.v2.StationInfo.DevicesEntry
+	nil,                            // 47: tide.sync.v2.ItemsLatest.LatestUnixMsEntry
+	nil,                            // 48: tide.sync.v2.StationHealth.SerialReopensEntry
+	nil,                            // 49: tide.sync.v2.RelayAvailableItems.StationsEntry
+	nil,                            // 50: tide.sync.v2.RelayItemsLatest.StationsEntry
+	nil,                            // 51: tide.sync.v2.RelayStatusLatest.StationsEntry
 }
 var file_proto_sync_v2_sync_v2_proto_depIdxs = []int32{
-	31, // 0: tide.sync.v2.DeviceItems.items:type_name -> tide.sync.v2.DeviceItems.ItemsEntry
-	32, // 1: tide.sync.v2.StationInfo.devices:type_name -> tide.sync.v2.StationInfo.DevicesEntry
-	33, // 2: tide.sync.v2.ItemsLatest.latest_unix_ms:type_name -> tide.sync.v2.ItemsLatest.LatestUnixMsEntry
+	45, // 0: tide.sync.v2.DeviceItems.items:type_name -> tide.sync.v2.DeviceItems.ItemsEntry
+	46, // 1: tide.sync.v2.StationInfo.devices:type_name -> tide.sync.v2.StationInfo.DevicesEntry
+	47, // 2: tide.sync.v2.ItemsLatest.latest_unix_ms:type_name -> tide.sync.v2.ItemsLatest.LatestUnixMsEntry
 	0,  // 3: tide.sync.v2.DataPoint.kind:type_name -> tide.sync.v2.DataKind
-	7,  // 4: tide.sync.v2.DataBatch.points:type_name -> tide.sync.v2.DataPoint
-	9,  // 5: tide.sync.v2.ItemStatusBatch.logs:type_name -> tide.sync.v2.ItemStatusLog
-	1,  // 6: tide.sync.v2.StationMessage.client_hello:type_name -> tide.sync.v2.ClientHello
-	2,  // 7: tide.sync.v2.StationMessage.server_hello:type_name -> tide.sync.v2.ServerHello
-	4,  // 8: tide.sync.v2.StationMessage.station_info:type_name -> tide.sync.v2.StationInfo
-	5,  // 9: tide.sync.v2.StationMessage.items_latest:type_name -> tide.sync.v2.ItemsLatest
-	6,  // 10: tide.sync.v2.StationMessage.status_latest:type_name -> tide.sync.v2.StatusLatest
-	8,  // 11: tide.sync.v2.StationMessage.data_batch:type_name -> tide.sync.v2.DataBatch
-	10, // 12: tide.sync.v2.StationMessage.item_status_batch:type_name -> tide.sync.v2.ItemStatusBatch
-	11, // 13: tide.sync.v2.StationMessage.rpi_status:type_name -> tide.sync.v2.RpiStatus
-	12, // 14: tide.sync.v2.StationMessage.camera_snapshot_request:type_name -> tide.sync.v2.CameraSnapshotRequest
-	13, // 15: tide.sync.v2.StationMessage.camera_snapshot_response:type_name -> tide.sync.v2.CameraSnapshotResponse
-	14, // 16: tide.sync.v2.StationMessage.error:type_name -> tide.sync.v2.ErrorFrame
-	19, // 17: tide.sync.v2.RelayStationFull.devices:type_name -> tide.sync.v2.RelayDevice
-	20, // 18: tide.sync.v2.RelayStationFull.items:type_name -> tide.sync.v2.RelayItem
-	18, // 19: tide.sync.v2.RelayConfigBatch.stations:type_name -> tide.sync.v2.RelayStationFull
-	21, // 20: tide.sync.v2.RelayConfigBatch.device_records:type_name -> tide.sync.v2.RelayDeviceRecord
-	23, // 21: tide.sync.v2.RelayConfigBatch.events:type_name -> tide.sync.v2.RelayConfigEvent
-	34, // 22: tide.sync.v2.RelayAvailableItems.stations:type_name -> tide.sync.v2.RelayAvailableItems.StationsEntry
-	35, // 23: tide.sync.v2.RelayItemsLatest.stations:type_name -> tide.sync.v2.RelayItemsLatest.StationsEntry
-	36, // 24: tide.sync.v2.RelayStatusLatest.stations:type_name -> tide.sync.v2.RelayStatusLatest.StationsEntry
-	7,  // 25: tide.sync.v2.RelayDataBatch.points:type_name -> tide.sync.v2.DataPoint
-	16, // 26: tide.sync.v2.RelayMessage.downstream_hello:type_name -> tide.sync.v2.RelayDownstreamHello
-	17, // 27: tide.sync.v2.RelayMessage.upstream_hello:type_name -> tide.sync.v2.RelayUpstreamHello
-	22, // 28: tide.sync.v2.RelayMessage.config_batch:type_name -> tide.sync.v2.RelayConfigBatch
-	28, // 29: tide.sync.v2.RelayMessage.data_batch:type_name -> tide.sync.v2.RelayDataBatch
-	29, // 30: tide.sync.v2.RelayMessage.status_event:type_name -> tide.sync.v2.RelayStatusEvent
-	24, // 31: tide.sync.v2.RelayMessage.available_items:type_name -> tide.sync.v2.RelayAvailableItems
-	26, // 32: tide.sync.v2.RelayMessage.stations_items_latest:type_name -> tide.sync.v2.RelayItemsLatest
-	27, // 33: tide.sync.v2.RelayMessage.stations_status_latest:type_name -> tide.sync.v2.RelayStatusLatest
-	14, // 34: tide.sync.v2.RelayMessage.error:type_name -> tide.sync.v2.ErrorFrame
-	3,  // 35: tide.sync.v2.StationInfo.DevicesEntry.value:type_name -> tide.sync.v2.DeviceItems
-	25, // 36: tide.sync.v2.RelayAvailableItems.StationsEntry.value:type_name -> tide.sync.v2.RelayAvailableItemList
-	5,  // 37: tide.sync.v2.RelayItemsLatest.StationsEntry.value:type_name -> tide.sync.v2.ItemsLatest
-	15, // 38: tide.sync.v2.StationSyncService.StreamStation:input_type -> tide.sync.v2.StationMessage
-	30, // 39: tide.sync.v2.RelaySyncService.StreamRelay:input_type -> tide.sync.v2.RelayMessage
-	15, // 40: tide.sync.v2.StationSyncService.StreamStation:output_type -> tide.sync.v2.StationMessage
-	30, // 41: tide.sync.v2.RelaySyncService.StreamRelay:output_type -> tide.sync.v2.RelayMessage
-	40, // [40:42] is the sub-list for method output_type
-	38, // [38:40] is the sub-list for method input_type
-	38, // [38:38] is the sub-list for extension type_name
-	38, // [38:38] is the sub-list for extension extendee
-	0,  // [0:38] is the sub-list for field type_name
+	0,  // 4: tide.sync.v2.DataColumn.kind:type_name -> tide.sync.v2.DataKind
+	9,  // 5: tide.sync.v2.DataBatch.points:type_name -> tide.sync.v2.DataPoint
+	10, // 6: tide.sync.v2.DataBatch.columns:type_name -> tide.sync.v2.DataColumn
+	13, // 7: tide.sync.v2.ItemStatusBatch.logs:type_name -> tide.sync.v2.ItemStatusLog
+	16, // 8: tide.sync.v2.StationHealth.disks:type_name -> tide.sync.v2.DiskUsage
+	48, // 9: tide.sync.v2.StationHealth.serial_reopens:type_name -> tide.sync.v2.StationHealth.SerialReopensEntry
+	25, // 10: tide.sync.v2.LogEntry.attrs:type_name -> tide.sync.v2.LogAttr
+	26, // 11: tide.sync.v2.LogBatch.entries:type_name -> tide.sync.v2.LogEntry
+	1,  // 12: tide.sync.v2.StationMessage.client_hello:type_name -> tide.sync.v2.ClientHello
+	2,  // 13: tide.sync.v2.StationMessage.server_hello:type_name -> tide.sync.v2.ServerHello
+	5,  // 14: tide.sync.v2.StationMessage.station_info:type_name -> tide.sync.v2.StationInfo
+	6,  // 15: tide.sync.v2.StationMessage.items_latest:type_name -> tide.sync.v2.ItemsLatest
+	7,  // 16: tide.sync.v2.StationMessage.status_latest:type_name -> tide.sync.v2.StatusLatest
+	11, // 17: tide.sync.v2.StationMessage.data_batch:type_name -> tide.sync.v2.DataBatch
+	14, // 18: tide.sync.v2.StationMessage.item_status_batch:type_name -> tide.sync.v2.ItemStatusBatch
+	15, // 19: tide.sync.v2.StationMessage.rpi_status:type_name -> tide.sync.v2.RpiStatus
+	18, // 20: tide.sync.v2.StationMessage.camera_snapshot_request:type_name -> tide.sync.v2.CameraSnapshotRequest
+	19, // 21: tide.sync.v2.StationMessage.camera_snapshot_response:type_name -> tide.sync.v2.CameraSnapshotResponse
+	28, // 22: tide.sync.v2.StationMessage.error:type_name -> tide.sync.v2.ErrorFrame
+	3,  // 23: tide.sync.v2.StationMessage.client_auth:type_name -> tide.sync.v2.ClientAuth
+	8,  // 24: tide.sync.v2.StationMessage.data_cursor:type_name -> tide.sync.v2.DataCursor
+	12, // 25: tide.sync.v2.StationMessage.data_ack:type_name -> tide.sync.v2.DataAck
+	20, // 26: tide.sync.v2.StationMessage.device_config_push:type_name -> tide.sync.v2.DeviceConfigPush
+	21, // 27: tide.sync.v2.StationMessage.device_config_result:type_name -> tide.sync.v2.DeviceConfigResult
+	22, // 28: tide.sync.v2.StationMessage.device_command_request:type_name -> tide.sync.v2.DeviceCommandRequest
+	23, // 29: tide.sync.v2.StationMessage.device_command_response:type_name -> tide.sync.v2.DeviceCommandResponse
+	24, // 30: tide.sync.v2.StationMessage.log_request:type_name -> tide.sync.v2.LogRequest
+	27, // 31: tide.sync.v2.StationMessage.log_batch:type_name -> tide.sync.v2.LogBatch
+	17, // 32: tide.sync.v2.StationMessage.station_health:type_name -> tide.sync.v2.StationHealth
+	33, // 33: tide.sync.v2.RelayStationFull.devices:type_name -> tide.sync.v2.RelayDevice
+	34, // 34: tide.sync.v2.RelayStationFull.items:type_name -> tide.sync.v2.RelayItem
+	32, // 35: tide.sync.v2.RelayConfigBatch.stations:type_name -> tide.sync.v2.RelayStationFull
+	35, // 36: tide.sync.v2.RelayConfigBatch.device_records:type_name -> tide.sync.v2.RelayDeviceRecord
+	37, // 37: tide.sync.v2.RelayConfigBatch.events:type_name -> tide.sync.v2.RelayConfigEvent
+	49, // 38: tide.sync.v2.RelayAvailableItems.stations:type_name -> tide.sync.v2.RelayAvailableItems.StationsEntry
+	50, // 39: tide.sync.v2.RelayItemsLatest.stations:type_name -> tide.sync.v2.RelayItemsLatest.StationsEntry
+	51, // 40: tide.sync.v2.RelayStatusLatest.stations:type_name -> tide.sync.v2.RelayStatusLatest.StationsEntry
+	9,  // 41: tide.sync.v2.RelayDataBatch.points:type_name -> tide.sync.v2.DataPoint
+	30, // 42: tide.sync.v2.RelayMessage.downstream_hello:type_name -> tide.sync.v2.RelayDownstreamHello
+	31, // 43: tide.sync.v2.RelayMessage.upstream_hello:type_name -> tide.sync.v2.RelayUpstreamHello
+	36, // 44: tide.sync.v2.RelayMessage.config_batch:type_name -> tide.sync.v2.RelayConfigBatch
+	42, // 45: tide.sync.v2.RelayMessage.data_batch:type_name -> tide.sync.v2.RelayDataBatch
+	43, // 46: tide.sync.v2.RelayMessage.status_event:type_name -> tide.sync.v2.RelayStatusEvent
+	38, // 47: tide.sync.v2.RelayMessage.available_items:type_name -> tide.sync.v2.RelayAvailableItems
+	40, // 48: tide.sync.v2.RelayMessage.stations_items_latest:type_name -> tide.sync.v2.RelayItemsLatest
+	41, // 49: tide.sync.v2.RelayMessage.stations_status_latest:type_name -> tide.sync.v2.RelayStatusLatest
+	28, // 50: tide.sync.v2.RelayMessage.error:type_name -> tide.sync.v2.ErrorFrame
+	4,  // 51: tide.sync.v2.StationInfo.DevicesEntry.value:type_name -> tide.sync.v2.DeviceItems
+	39, // 52: tide.sync.v2.RelayAvailableItems.StationsEntry.value:type_name -> tide.sync.v2.RelayAvailableItemList
+	6,  // 53: tide.sync.v2.RelayItemsLatest.StationsEntry.value:type_name -> tide.sync.v2.ItemsLatest
+	29, // 54: tide.sync.v2.StationSyncService.StreamStation:input_type -> tide.sync.v2.StationMessage
+	44, // 55: tide.sync.v2.RelaySyncService.StreamRelay:input_type -> tide.sync.v2.RelayMessage
+	29, // 56: tide.sync.v2.StationSyncService.StreamStation:output_type -> tide.sync.v2.StationMessage
+	44, // 57: tide.sync.v2.RelaySyncService.StreamRelay:output_type -> tide.sync.v2.RelayMessage
+	56, // [56:58] is the sub-list for method output_type
+	54, // [54:56] is the sub-list for method input_type
+	54, // [54:54] is the sub-list for extension type_name
+	54, // [54:54] is the sub-list for extension extendee
+	0,  // [0:54] is the sub-list for field type_name
 }
 
 func init() { file_proto_sync_v2_sync_v2_proto_init() }
@@ -2410,7 +3772,8 @@ func file_proto_sync_v2_sync_v2_proto_init() {
 	if File_proto_sync_v2_sync_v2_proto != nil {
 		return
 	}
-	file_proto_sync_v2_sync_v2_proto_msgTypes[14].OneofWrappers = []any{
+	file_proto_sync_v2_sync_v2_proto_msgTypes[16].OneofWrappers = []any{}
+	file_proto_sync_v2_sync_v2_proto_msgTypes[28].OneofWrappers = []any{
 		(*StationMessage_ClientHello)(nil),
 		(*StationMessage_ServerHello)(nil),
 		(*StationMessage_StationInfo)(nil),
@@ -2422,8 +3785,18 @@ func file_proto_sync_v2_sync_v2_proto_init() {
 		(*StationMessage_CameraSnapshotRequest)(nil),
 		(*StationMessage_CameraSnapshotResponse)(nil),
 		(*StationMessage_Error)(nil),
-	}
-	file_proto_sync_v2_sync_v2_proto_msgTypes[29].OneofWrappers = []any{
+		(*StationMessage_ClientAuth)(nil),
+		(*StationMessage_DataCursor)(nil),
+		(*StationMessage_DataAck)(nil),
+		(*StationMessage_DeviceConfigPush)(nil),
+		(*StationMessage_DeviceConfigResult)(nil),
+		(*StationMessage_DeviceCommandRequest)(nil),
+		(*StationMessage_DeviceCommandResponse)(nil),
+		(*StationMessage_LogRequest)(nil),
+		(*StationMessage_LogBatch)(nil),
+		(*StationMessage_StationHealth)(nil),
+	}
+	file_proto_sync_v2_sync_v2_proto_msgTypes[43].OneofWrappers = []any{
 		(*RelayMessage_DownstreamHello)(nil),
 		(*RelayMessage_UpstreamHello)(nil),
 		(*RelayMessage_ConfigBatch)(nil),
@@ -2440,7 +3813,7 @@ func file_proto_sync_v2_sync_v2_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_sync_v2_sync_v2_proto_rawDesc), len(file_proto_sync_v2_sync_v2_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   36,
+			NumMessages:   51,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
@@ -0,0 +1,139 @@
+package pbstream
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// Compressions lists the supported compressions, most preferred first.
+func Compressions() []string {
+	return []string{CompressionZstd, CompressionGzip}
+}
+
+// SelectCompression returns the first offered compression that is supported, or "" if there is none.
+func SelectCompression(offered []string) string {
+	for _, name := range offered {
+		if slices.Contains(Compressions(), name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// compressMinBytes is the smallest payload worth compressing, realtime frames are usually far below it.
+const compressMinBytes = 256
+
+// frame flags, written after the size once compression is enabled.
+const (
+	frameRaw        byte = 0
+	frameCompressed byte = 1
+)
+
+var errFrameTooLarge = errors.New("pbstream: frame too large")
+
+type frameCodec interface {
+	compress(src []byte) ([]byte, error)
+	// decompress fails if the output is larger than limit.
+	decompress(src []byte, limit int64) ([]byte, error)
+	close()
+}
+
+func newFrameCodec(name string, maxFrameBytes int64) (frameCodec, error) {
+	switch name {
+	case CompressionGzip:
+		return &gzipCodec{}, nil
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		opts := []zstd.DOption{zstd.WithDecoderConcurrency(1)}
+		if maxFrameBytes > 0 {
+			opts = append(opts, zstd.WithDecoderMaxMemory(uint64(maxFrameBytes)))
+		}
+		dec, err := zstd.NewReader(nil, opts...)
+		if err != nil {
+			enc.Close()
+			return nil, err
+		}
+		return &zstdCodec{enc: enc, dec: dec}, nil
+	default:
+		return nil, errors.New("pbstream: unsupported compression " + name)
+	}
+}
+
+type gzipCodec struct {
+	w *gzip.Writer
+	r *gzip.Reader
+}
+
+func (c *gzipCodec) compress(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if c.w == nil {
+		c.w = gzip.NewWriter(&buf)
+	} else {
+		c.w.Reset(&buf)
+	}
+	if _, err := c.w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := c.w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *gzipCodec) decompress(src []byte, limit int64) ([]byte, error) {
+	var err error
+	if c.r == nil {
+		c.r, err = gzip.NewReader(bytes.NewReader(src))
+	} else {
+		err = c.r.Reset(bytes.NewReader(src))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = c.r
+	if limit > 0 {
+		r = io.LimitReader(c.r, limit+1)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && int64(len(b)) > limit {
+		return nil, errFrameTooLarge
+	}
+	return b, nil
+}
+
+func (c *gzipCodec) close() {}
+
+type zstdCodec struct {
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+func (c *zstdCodec) compress(src []byte) ([]byte, error) {
+	return c.enc.EncodeAll(src, nil), nil
+}
+
+// decompress relies on WithDecoderMaxMemory for the limit.
+func (c *zstdCodec) decompress(src []byte, _ int64) ([]byte, error) {
+	return c.dec.DecodeAll(src, nil)
+}
+
+func (c *zstdCodec) close() {
+	_ = c.enc.Close()
+	c.dec.Close()
+}
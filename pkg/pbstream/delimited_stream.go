@@ -2,6 +2,8 @@ package pbstream
 
 import (
 	"bufio"
+	"encoding/binary"
+	"errors"
 	"io"
 	"sync"
 
@@ -11,12 +13,17 @@ import (
 
 // DelimitedStream reads and writes protobuf messages in varint size-delimited format.
 // It is safe for concurrent Send calls.
+//
+// After SetCompression every frame carries a flag byte after the size, and payloads
+// of compressMinBytes or more are compressed when that makes them smaller.
 type DelimitedStream struct {
 	conn io.ReadWriteCloser
 	r    *bufio.Reader
 
-	sendMu  sync.Mutex
-	recvOpt protodelim.UnmarshalOptions
+	sendMu    sync.Mutex
+	recvOpt   protodelim.UnmarshalOptions
+	sendCodec frameCodec
+	recvCodec frameCodec
 }
 
 func NewDelimitedStream(conn io.ReadWriteCloser, maxFrameBytes int64) *DelimitedStream {
@@ -29,18 +36,97 @@ func NewDelimitedStream(conn io.ReadWriteCloser, maxFrameBytes int64) *Delimited
 	}
 }
 
+// SetCompression switches both directions to compressed framing. Both peers must switch
+// at the same point in the stream, and it must not be called concurrently with Recv.
+func (s *DelimitedStream) SetCompression(name string) error {
+	sendCodec, err := newFrameCodec(name, s.recvOpt.MaxSize)
+	if err != nil {
+		return err
+	}
+	recvCodec, err := newFrameCodec(name, s.recvOpt.MaxSize)
+	if err != nil {
+		sendCodec.close()
+		return err
+	}
+	s.sendMu.Lock()
+	s.sendCodec = sendCodec
+	s.sendMu.Unlock()
+	s.recvCodec = recvCodec
+	return nil
+}
+
 func (s *DelimitedStream) Send(msg proto.Message) error {
 	s.sendMu.Lock()
 	defer s.sendMu.Unlock()
-	_, err := protodelim.MarshalTo(s.conn, msg)
+	if s.sendCodec == nil {
+		_, err := protodelim.MarshalTo(s.conn, msg)
+		return err
+	}
+
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	flag := frameRaw
+	if len(b) >= compressMinBytes {
+		c, err := s.sendCodec.compress(b)
+		if err != nil {
+			return err
+		}
+		if len(c) < len(b) {
+			b, flag = c, frameCompressed
+		}
+	}
+	frame := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+1+len(b)), uint64(len(b)+1))
+	frame = append(frame, flag)
+	frame = append(frame, b...)
+	_, err = s.conn.Write(frame)
 	return err
 }
 
 func (s *DelimitedStream) Recv(msg proto.Message) error {
-	return s.recvOpt.UnmarshalFrom(s.r, msg)
+	if s.recvCodec == nil {
+		return s.recvOpt.UnmarshalFrom(s.r, msg)
+	}
+
+	size, err := binary.ReadUvarint(s.r)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		return errors.New("pbstream: empty frame")
+	}
+	if s.recvOpt.MaxSize > 0 && size > uint64(s.recvOpt.MaxSize) {
+		return errFrameTooLarge
+	}
+	b := make([]byte, size)
+	if _, err = io.ReadFull(s.r, b); err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	flag, b := b[0], b[1:]
+	switch flag {
+	case frameRaw:
+	case frameCompressed:
+		if b, err = s.recvCodec.decompress(b, s.recvOpt.MaxSize); err != nil {
+			return err
+		}
+	default:
+		return errors.New("pbstream: unknown frame flag")
+	}
+	return proto.Unmarshal(b, msg)
 }
 
 func (s *DelimitedStream) Close() error {
+	s.sendMu.Lock()
+	if s.sendCodec != nil {
+		s.sendCodec.close()
+		s.sendCodec = nil
+	}
+	s.sendMu.Unlock()
+	// recvCodec is left to the garbage collector, a concurrent Recv may still be using it.
 	return s.conn.Close()
 }
 
@@ -57,6 +143,10 @@ func NewTypedDelimitedStream[T proto.Message](conn io.ReadWriteCloser, maxFrameB
 	}
 }
 
+func (s *TypedDelimitedStream[T]) SetCompression(name string) error {
+	return s.raw.SetCompression(name)
+}
+
 func (s *TypedDelimitedStream[T]) Send(msg T) error {
 	return s.raw.Send(msg)
 }
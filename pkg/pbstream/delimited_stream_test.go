@@ -4,6 +4,7 @@ import (
 	"io"
 	"net"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 
@@ -132,3 +133,66 @@ func TestTypedDelimitedStreamConcurrentSend(t *testing.T) {
 		require.Len(t, got, count)
 	}
 }
+
+func TestTypedDelimitedStreamCompression(t *testing.T) {
+	for _, name := range Compressions() {
+		t.Run(name, func(t *testing.T) {
+			clientConn, serverConn := net.Pipe()
+			defer func() { _ = clientConn.Close() }()
+			defer func() { _ = serverConn.Close() }()
+
+			newMsg := func() *syncpb.StationMessage { return &syncpb.StationMessage{} }
+			clientStream := NewTypedDelimitedStream[*syncpb.StationMessage](clientConn, 32<<20, newMsg)
+			serverStream := NewTypedDelimitedStream[*syncpb.StationMessage](serverConn, 32<<20, newMsg)
+			require.NoError(t, clientStream.SetCompression(name))
+			require.NoError(t, serverStream.SetCompression(name))
+
+			msgs := []string{"short", strings.Repeat("compressible ", 1000)}
+			go func() {
+				for _, m := range msgs {
+					_ = clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_Error{
+						Error: &syncpb.ErrorFrame{Message: m},
+					}})
+				}
+			}()
+			for _, want := range msgs {
+				got, err := serverStream.Recv()
+				require.NoError(t, err)
+				errBody, ok := got.Body.(*syncpb.StationMessage_Error)
+				require.True(t, ok)
+				require.Equal(t, want, errBody.Error.Message)
+			}
+		})
+	}
+}
+
+func TestTypedDelimitedStreamCompression_MaxFrameBytes(t *testing.T) {
+	for _, name := range Compressions() {
+		t.Run(name, func(t *testing.T) {
+			clientConn, serverConn := net.Pipe()
+			defer func() { _ = clientConn.Close() }()
+			defer func() { _ = serverConn.Close() }()
+
+			newMsg := func() *syncpb.StationMessage { return &syncpb.StationMessage{} }
+			clientStream := NewTypedDelimitedStream[*syncpb.StationMessage](clientConn, 32<<20, newMsg)
+			serverStream := NewTypedDelimitedStream[*syncpb.StationMessage](serverConn, 4<<10, newMsg)
+			require.NoError(t, clientStream.SetCompression(name))
+			require.NoError(t, serverStream.SetCompression(name))
+
+			// Compresses far below the limit, but must not expand beyond it.
+			go func() {
+				_ = clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_Error{
+					Error: &syncpb.ErrorFrame{Message: strings.Repeat("a", 64<<10)},
+				}})
+			}()
+			_, err := serverStream.Recv()
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSelectCompression(t *testing.T) {
+	require.Equal(t, CompressionGzip, SelectCompression([]string{"br", CompressionGzip, CompressionZstd}))
+	require.Empty(t, SelectCompression([]string{"br"}))
+	require.Empty(t, SelectCompression(nil))
+}
@@ -1,6 +1,7 @@
 package connWrap
 
 import (
+	"fmt"
 	"io"
 	"sync"
 	"time"
@@ -22,6 +23,11 @@ type Bus struct {
 	ConnCommon
 	mu        sync.Mutex
 	quietTime time.Duration
+
+	// claimMu guards owner and users, it is separate from mu which an owner holds for good.
+	claimMu sync.Mutex
+	owner   string
+	users   []string
 }
 
 func NewBus(conn ConnCommon) *Bus {
@@ -62,3 +68,36 @@ func (b *Bus) Lock() {
 func (b *Bus) Unlock() {
 	b.mu.Unlock()
 }
+
+// Claim makes owner the only user of the bus, for devices that stream without being polled.
+// It fails if the bus is already claimed or polled by another device.
+func (b *Bus) Claim(owner string) error {
+	b.claimMu.Lock()
+	defer b.claimMu.Unlock()
+	if b.owner != "" {
+		return fmt.Errorf("bus is owned by %s", b.owner)
+	}
+	if len(b.users) > 0 {
+		return fmt.Errorf("bus is polled by %v", b.users)
+	}
+	b.owner = owner
+	return nil
+}
+
+// Share registers user as one of the devices polling the bus, it fails if the bus is claimed.
+func (b *Bus) Share(user string) error {
+	b.claimMu.Lock()
+	defer b.claimMu.Unlock()
+	if b.owner != "" {
+		return fmt.Errorf("bus is owned by %s", b.owner)
+	}
+	b.users = append(b.users, user)
+	return nil
+}
+
+// Owner returns the device that claimed the bus, or "" if it is shared.
+func (b *Bus) Owner() string {
+	b.claimMu.Lock()
+	defer b.claimMu.Unlock()
+	return b.owner
+}
@@ -52,3 +52,29 @@ func TestWriteWaitsBeforeResettingInput(t *testing.T) {
 		t.Fatalf("Write happened before ResetInputBuffer: writeAt=%v resetAt=%v", rawConn.writeAt, rawConn.resetAt)
 	}
 }
+
+func TestClaimConflicts(t *testing.T) {
+	t.Parallel()
+
+	bus := NewBusWithQuietTime(&writeOrderConn{}, 0)
+	if err := bus.Share("VEGAPULS61"); err != nil {
+		t.Fatalf("Share returned error: %v", err)
+	}
+	if err := bus.Claim("NMEA-GNSS"); err == nil {
+		t.Fatal("Claim of a polled bus returned nil error")
+	}
+
+	bus = NewBusWithQuietTime(&writeOrderConn{}, 0)
+	if err := bus.Claim("NMEA-GNSS"); err != nil {
+		t.Fatalf("Claim returned error: %v", err)
+	}
+	if err := bus.Claim("GenericStream"); err == nil {
+		t.Fatal("second Claim returned nil error")
+	}
+	if err := bus.Share("VEGAPULS61"); err == nil {
+		t.Fatal("Share of a claimed bus returned nil error")
+	}
+	if bus.Owner() != "NMEA-GNSS" {
+		t.Fatalf("Owner = %q, want NMEA-GNSS", bus.Owner())
+	}
+}
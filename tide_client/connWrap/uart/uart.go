@@ -3,6 +3,7 @@ package uart
 import (
 	"errors"
 	"log/slog"
+	"maps"
 	"os"
 	"strings"
 	"sync"
@@ -30,6 +31,24 @@ var parityMap = map[string]serial.Parity{
 	"space": serial.SpaceParity,
 }
 
+var (
+	reopenMu     sync.Mutex
+	reopenCounts = make(map[string]uint32)
+)
+
+// ReopenCounts returns how many times each port was reopened after an I/O error.
+func ReopenCounts() map[string]uint32 {
+	reopenMu.Lock()
+	defer reopenMu.Unlock()
+	return maps.Clone(reopenCounts)
+}
+
+func countReopen(portName string) {
+	reopenMu.Lock()
+	defer reopenMu.Unlock()
+	reopenCounts[portName]++
+}
+
 type Mode struct {
 	BaudRate int    `json:"baud_rate"` // The serial port bitrate (aka Baud rate)
 	DataBits int    `json:"data_bits"` // Size of the character (must be 5, 6, 7 or 8)
@@ -71,6 +90,7 @@ func (c *Uart) reopenUntilSuccess() {
 		// Keep the closed port published until a replacement port is opened so
 		// in-flight callers fail with the transport error, not a nil deref.
 		_ = conn.Close()
+		countReopen(c.portName)
 	}
 	for {
 		if err := c.open(); err != nil {
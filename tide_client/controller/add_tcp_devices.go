@@ -32,9 +32,13 @@ func newTcpConn(rawConf json.RawMessage) common.StringMapMap {
 		os.Exit(1)
 	}
 	bus := connWrap.NewBus(connCommon)
+	if err = registerCommandBus(conf.Addr, bus, conf.Model, conf.Config); err != nil {
+		slog.Error("Bus ownership conflict", "tcp", conf.Addr, "error", err)
+		os.Exit(1)
+	}
 	slog.Info("Connection manager started", "tcp", conf.Addr)
 
-	subInfo := device.MustBusDevice(conf.Model).NewBusDevice(bus, conf.Config)
+	subInfo := device.StartBusDevice(conf.Model, bus, conf.Config)
 	var info = make(common.StringMapMap)
 	device.MergeInfo(info, subInfo)
 	return info
@@ -34,9 +34,13 @@ func newUartConn(rawConf json.RawMessage) common.StringMapMap {
 	}
 
 	bus := connWrap.NewBus(connCommon)
+	if err = registerCommandBus(conf.Port, bus, conf.Model, conf.Config); err != nil {
+		slog.Error("Bus ownership conflict", "port", conf.Port, "error", err)
+		os.Exit(1)
+	}
 	slog.Info("Connection manager started", "port", conf.Port)
 
-	subInfo := device.MustBusDevice(conf.Model).NewBusDevice(bus, conf.Config)
+	subInfo := device.StartBusDevice(conf.Model, bus, conf.Config)
 	var info = make(common.StringMapMap)
 	device.MergeInfo(info, subInfo)
 	return info
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"tide/common"
+	"tide/pkg/custype"
+	"tide/tide_client/global"
+)
+
+// aggregateStats are the statistics an aggregation can store, each one is the derived item <item_name>_<stat>.
+var aggregateStats = []string{"mean", "median", "std", "min", "max", "count"}
+
+// aggregators are keyed by the name of the sampled item, they are set up before receiveData starts
+// and only used under ingestMu.
+var aggregators map[string]*aggregator
+
+// aggregator collects the samples of an item over windows aligned to multiples of the window length,
+// a window is stamped with its end.
+type aggregator struct {
+	itemName string
+	window   custype.UnixMs
+	stats    []string
+	keepRaw  bool
+
+	hasWindow   bool
+	windowStart custype.UnixMs
+	values      []float64
+}
+
+type derivedData struct {
+	ItemName string
+	At       custype.UnixMs
+	Value    *float64
+}
+
+func newAggregator(itemName string, conf global.Aggregation) (*aggregator, error) {
+	if conf.WindowSec <= 0 || 86400%conf.WindowSec != 0 {
+		return nil, fmt.Errorf("window_sec of %s must divide a day, got %d", itemName, conf.WindowSec)
+	}
+	stats := conf.Stats
+	if len(stats) == 0 {
+		stats = aggregateStats
+	}
+	for _, stat := range stats {
+		if !slices.Contains(aggregateStats, stat) {
+			return nil, fmt.Errorf("unknown stat %q of %s", stat, itemName)
+		}
+	}
+	return &aggregator{
+		itemName: itemName,
+		window:   custype.UnixMs(conf.WindowSec) * 1000,
+		stats:    stats,
+		keepRaw:  conf.KeepRaw,
+	}, nil
+}
+
+// derivedItems returns the derived item names keyed by item type, the sampled item has itemType.
+func (a *aggregator) derivedItems(itemType string) map[string]string {
+	items := make(map[string]string, len(a.stats))
+	for _, stat := range a.stats {
+		items[itemType+"_"+stat] = a.itemName + "_" + stat
+	}
+	return items
+}
+
+// add collects a sample, it returns the statistics of the collected window if the sample is outside it.
+// Samples flagged probably bad or bad are left out of the statistics.
+func (a *aggregator) add(at custype.UnixMs, value *float64, flag common.QCFlag) []derivedData {
+	start := at - at%a.window
+	var data []derivedData
+	if a.hasWindow && start != a.windowStart {
+		data = a.flush()
+	}
+	a.hasWindow, a.windowStart = true, start
+	if value != nil && !math.IsNaN(*value) && flag != common.QCProbablyBad && flag != common.QCBad {
+		a.values = append(a.values, *value)
+	}
+	return data
+}
+
+// flushBefore returns the statistics of the collected window if it ended a window length before now,
+// so the last window of a sensor that stopped sending is not held back. The extra window leaves room for
+// devices whose timestamps lag the local clock.
+func (a *aggregator) flushBefore(now custype.UnixMs) []derivedData {
+	if !a.hasWindow || a.windowStart+2*a.window > now {
+		return nil
+	}
+	return a.flush()
+}
+
+func (a *aggregator) flush() []derivedData {
+	at := a.windowStart + a.window
+	values := a.values
+	data := make([]derivedData, 0, len(a.stats))
+	for _, stat := range a.stats {
+		data = append(data, derivedData{ItemName: a.itemName + "_" + stat, At: at, Value: computeStat(stat, values)})
+	}
+	a.hasWindow, a.values = false, nil
+	return data
+}
+
+// computeStat returns nil if the window has too few values for stat, count is 0 then.
+func computeStat(stat string, values []float64) *float64 {
+	if stat == "count" {
+		return new(float64(len(values)))
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	switch stat {
+	case "mean":
+		return new(mean(values))
+	case "median":
+		sorted := slices.Sorted(slices.Values(values))
+		mid := len(sorted) / 2
+		if len(sorted)%2 == 1 {
+			return new(sorted[mid])
+		}
+		return new((sorted[mid-1] + sorted[mid]) / 2)
+	case "std":
+		// The sample standard deviation, it needs at least two values.
+		if len(values) < 2 {
+			return nil
+		}
+		m := mean(values)
+		var sum float64
+		for _, v := range values {
+			sum += (v - m) * (v - m)
+		}
+		return new(math.Sqrt(sum / float64(len(values)-1)))
+	case "min":
+		return new(slices.Min(values))
+	case "max":
+		return new(slices.Max(values))
+	}
+	return nil
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// newAggregators registers the derived items of the aggregations with the device of the sampled item in info.
+func newAggregators(info common.StringMapMap, confs map[string]global.Aggregation) (map[string]*aggregator, error) {
+	aggs := make(map[string]*aggregator, len(confs))
+	for itemName, conf := range confs {
+		a, err := newAggregator(itemName, conf)
+		if err != nil {
+			return nil, err
+		}
+		var found bool
+		for _, items := range info {
+			for itemType, name := range items {
+				if name == itemName {
+					found = true
+					for derivedType, derivedName := range a.derivedItems(itemType) {
+						items[derivedType] = derivedName
+					}
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("aggregated item %s is not provided by any device", itemName)
+		}
+		aggs[itemName] = a
+	}
+	return aggs, nil
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"testing"
+	"tide/common"
+	"tide/pkg/custype"
+	"tide/tide_client/global"
+
+	"github.com/stretchr/testify/require"
+)
+
+func derivedValues(data []derivedData) map[string]*float64 {
+	values := make(map[string]*float64, len(data))
+	for _, d := range data {
+		values[d.ItemName] = d.Value
+	}
+	return values
+}
+
+func Test_aggregator(t *testing.T) {
+	a, err := newAggregator("level", global.Aggregation{WindowSec: 60})
+	require.NoError(t, err)
+
+	const minute = custype.UnixMs(60_000)
+	for i, v := range []*float64{new(1.0), new(2.0), nil, new(4.0), new(8.0)} {
+		require.Nil(t, a.add(10*minute+custype.UnixMs(i)*1000, v, common.QCGood))
+	}
+	require.Nil(t, a.flushBefore(11*minute+30_000), "flushed before the grace window")
+
+	require.Nil(t, a.add(10*minute+59_000, new(100.0), common.QCBad), "bad samples are left out")
+	data := a.add(11*minute+500, new(5.0), common.QCNone)
+	require.Len(t, data, len(aggregateStats))
+	require.Equal(t, 11*minute, data[0].At, "a window is stamped with its end")
+	values := derivedValues(data)
+	require.InDelta(t, 3.75, *values["level_mean"], 1e-9)
+	require.InDelta(t, 3.0, *values["level_median"], 1e-9)
+	require.InDelta(t, 3.0956959, *values["level_std"], 1e-6)
+	require.Equal(t, 1.0, *values["level_min"])
+	require.Equal(t, 8.0, *values["level_max"])
+	require.Equal(t, 4.0, *values["level_count"], "nil samples are not counted")
+
+	require.Nil(t, a.flushBefore(12*minute+59_999))
+	data = a.flushBefore(13 * minute)
+	values = derivedValues(data)
+	require.Equal(t, 5.0, *values["level_median"])
+	require.Nil(t, values["level_std"], "one value has no standard deviation")
+	require.Nil(t, a.flushBefore(20*minute), "the window was already flushed")
+
+	require.Nil(t, a.add(30*minute, nil, common.QCNone))
+	values = derivedValues(a.add(31*minute, nil, common.QCNone))
+	require.Nil(t, values["level_mean"])
+	require.Equal(t, 0.0, *values["level_count"])
+}
+
+func Test_newAggregators(t *testing.T) {
+	info := common.StringMapMap{"PLS-C": {"water_level": "level", "water_temperature": "temp"}}
+	aggs, err := newAggregators(info, map[string]global.Aggregation{"level": {WindowSec: 120, Stats: []string{"mean", "count"}}})
+	require.NoError(t, err)
+	require.Contains(t, aggs, "level")
+	require.Equal(t, map[string]string{
+		"water_level":       "level",
+		"water_level_mean":  "level_mean",
+		"water_level_count": "level_count",
+		"water_temperature": "temp",
+	}, info["PLS-C"])
+
+	_, err = newAggregators(info, map[string]global.Aggregation{"missing": {WindowSec: 60}})
+	require.ErrorContains(t, err, "not provided")
+	_, err = newAggregators(info, map[string]global.Aggregation{"temp": {WindowSec: 7}})
+	require.ErrorContains(t, err, "window_sec")
+	_, err = newAggregators(info, map[string]global.Aggregation{"temp": {WindowSec: 60, Stats: []string{"mode"}}})
+	require.ErrorContains(t, err, "unknown stat")
+}
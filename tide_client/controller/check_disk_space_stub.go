@@ -8,3 +8,8 @@ import "errors"
 func CheckDiskSpace(string) (uint64, error) {
 	return 0, errors.New("disk space check is only supported on linux")
 }
+
+// DiskUsage returns the available and total space of the file system containing path.
+func DiskUsage(string) (free, total uint64, err error) {
+	return 0, 0, errors.New("disk space check is only supported on linux")
+}
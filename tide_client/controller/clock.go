@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"log/slog"
+	"tide/tide_client/device"
+	"time"
+)
+
+// clockCorrectionThreshold is the server clock offset from which data timestamps are corrected.
+const clockCorrectionThreshold = 10 * time.Second
+
+// onServerClockOffset corrects data timestamps by the offset to the server clock measured in the
+// Sync V2 handshake, unless the kernel clock is synchronized by NTP.
+func onServerClockOffset(offset time.Duration) {
+	_, synced, err := kernelClockOffset()
+	synced = synced && err == nil
+	if synced || offset.Abs() < clockCorrectionThreshold {
+		if offset.Abs() >= clockCorrectionThreshold {
+			slog.Warn("Station clock differs from the server although synchronized by NTP", "offset", offset)
+		}
+		if device.ClockCorrection() != 0 {
+			slog.Info("Stopped correcting data timestamps", "offset", offset, "ntp_synced", synced)
+			device.SetClockCorrection(0)
+		}
+		return
+	}
+	slog.Warn("Station clock is not synchronized, correcting data timestamps by the server clock", "offset", offset)
+	device.SetClockCorrection(offset)
+}
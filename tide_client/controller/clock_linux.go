@@ -0,0 +1,28 @@
+//go:build linux
+
+package controller
+
+import (
+	"syscall"
+	"time"
+)
+
+const (
+	timeError = 5      // adjtimex state TIME_ERROR, the clock is not synchronized
+	staUnsync = 0x0040 // STA_UNSYNC
+	staNano   = 0x2000 // STA_NANO, the offset is in nanoseconds instead of microseconds
+)
+
+// kernelClockOffset returns the clock offset estimated by the kernel NTP discipline and whether the clock is synchronized.
+func kernelClockOffset() (offset time.Duration, synced bool, err error) {
+	var tx syscall.Timex
+	state, err := syscall.Adjtimex(&tx)
+	if err != nil {
+		return 0, false, err
+	}
+	offset = time.Duration(tx.Offset) * time.Microsecond
+	if tx.Status&staNano != 0 {
+		offset = time.Duration(tx.Offset)
+	}
+	return offset, state != timeError && tx.Status&staUnsync == 0, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package controller
+
+import (
+	"errors"
+	"time"
+)
+
+// kernelClockOffset returns the clock offset estimated by the kernel NTP discipline and whether the clock is synchronized.
+func kernelClockOffset() (time.Duration, bool, error) {
+	return 0, false, errors.New("clock offset is only supported on linux")
+}
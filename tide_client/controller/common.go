@@ -3,7 +3,9 @@ package controller
 import (
 	"encoding/json"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
 	"sync"
 	"tide/common"
 	"tide/pkg/custype"
@@ -50,7 +52,7 @@ func Init() {
 	addDevices()
 	go receiveData(dataBroker)
 
-	addRpiStatus(dataBroker)
+	addStationHealth(dataBroker)
 
 	for name := range global.Config.Cameras.List {
 		stationInfo.Cameras = append(stationInfo.Cameras, name)
@@ -68,20 +70,37 @@ func Init() {
 
 var itemsStatus = make(map[string]common.StatusChangeStruct)
 
+// outboxId identifies the local data outbox, see db.InitOutbox.
+var outboxId string
+
 func addDevices() {
+	devices, version, applyErr, err := loadDeviceConfig(deviceConfigPath())
+	if err != nil {
+		slog.Error("Failed to read device config", "error", err)
+		os.Exit(1)
+	}
+	stationInfo.DeviceConfigVersion, stationInfo.DeviceConfigError = version, applyErr
+
 	var info = stationInfo.Devices
-	for connType, files := range global.Config.Devices {
-		newConnFunc := GetRegConn(connType).(func(json.RawMessage) common.StringMapMap)
-		for _, filename := range files {
-			rawConf, err := os.ReadFile(filename)
-			if err != nil {
-				slog.Error("Failed to read config file", "filename", filename, "error", err)
-				os.Exit(1)
-			}
+	for connType, rawConfs := range devices {
+		newConnFunc, ok := GetRegConn(connType).(func(json.RawMessage) common.StringMapMap)
+		if !ok {
+			slog.Error("Unknown conn type", "conn_type", connType)
+			os.Exit(1)
+		}
+		for _, rawConf := range rawConfs {
 			subInfo := newConnFunc(rawConf)
 			device.MergeInfo(info, subInfo)
 		}
 	}
+	if aggregators, err = newAggregators(info, global.Config.Aggregation); err != nil {
+		slog.Error("Invalid aggregation config", "error", err)
+		os.Exit(1)
+	}
+	if qcCheckers, err = newQCCheckers(info, global.Config.QC); err != nil {
+		slog.Error("Invalid qc config", "error", err)
+		os.Exit(1)
+	}
 	// check duplicate
 	var tmp = make(map[string]struct{})
 	for deviceName, items := range info {
@@ -102,6 +121,10 @@ func addDevices() {
 			}
 		}
 	}
+	if outboxId, err = db.InitOutbox(slices.Collect(maps.Keys(tmp))); err != nil {
+		slog.Error("Failed to init data outbox", "error", err)
+		os.Exit(1)
+	}
 	ds, err := db.GetItemsLatestStatus()
 	if err != nil {
 		slog.Error("Failed to get latest item status", "error", err)
@@ -110,78 +133,113 @@ func addDevices() {
 	for _, itemStatus := range ds {
 		itemsStatus[itemStatus.ItemName] = itemStatus.StatusChangeStruct
 	}
+	if err = commitDeviceConfig(); err != nil {
+		slog.Error("Failed to commit device config", "error", err)
+		os.Exit(1)
+	}
 }
 
 func receiveData(dataBroker *pubsub.Broker) {
-	for itemsData := range device.DataReceive {
-		func() {
-			// Lock when saving and sending data, avoid saving new data when querying data, and ensure that the connection is subscribed before running
-			ingestMu.Lock()
-			defer ingestMu.Unlock()
-			for _, data := range itemsData {
-				at := data.At
-				if at == 0 {
-					at = custype.ToUnixMs(time.Now())
-				}
-
-				if data.Typ == common.MsgData {
-					if data.Value == nil {
-						if itemsStatus[data.ItemName].Status != common.Abnormal {
-							itemsStatus[data.ItemName] = common.StatusChangeStruct{Status: common.Abnormal, ChangedAt: at}
-							if rowId, err := db.SaveItemStatusLog(data.ItemName, common.Abnormal, at.ToInt64()); err != nil {
-								slog.Error("Failed to save item status log",
-									"item_name", data.ItemName,
-									"status", common.Abnormal,
-									"error", err)
-							} else {
-								dataBroker.Publish(common.SendMsgStruct{Type: common.MsgItemStatus,
-									Body: common.RowIdItemStatusStruct{
-										RowId: rowId,
-										ItemStatusStruct: common.ItemStatusStruct{
-											ItemName:           data.ItemName,
-											StatusChangeStruct: common.StatusChangeStruct{Status: common.Abnormal, ChangedAt: at},
-										},
-									}}, nil)
-							}
+	// The ticker flushes the windows of aggregated items that stopped sending.
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case itemsData := <-device.DataReceive:
+			func() {
+				// Lock when saving and sending data, avoid saving new data when querying data, and ensure that the connection is subscribed before running
+				ingestMu.Lock()
+				defer ingestMu.Unlock()
+				for _, data := range itemsData {
+					at := data.At
+					if at == 0 {
+						at = custype.ToUnixMs(time.Now())
+					}
+					var flag common.QCFlag
+					if data.Typ == common.MsgData {
+						flag = qualityFlag(data.ItemName, at, data.Value)
+					}
+					if a := aggregators[data.ItemName]; a != nil && data.Typ == common.MsgData {
+						for _, d := range a.add(at, data.Value, flag) {
+							saveItemData(dataBroker, common.MsgData, d.ItemName, d.Value, qualityFlag(d.ItemName, d.At, d.Value), d.At)
 						}
-					} else {
-						if itemsStatus[data.ItemName].Status != common.Normal {
-							itemsStatus[data.ItemName] = common.StatusChangeStruct{Status: common.Normal, ChangedAt: at}
-							if rowId, err := db.SaveItemStatusLog(data.ItemName, common.Normal, at.ToInt64()); err != nil {
-								slog.Error("Failed to save item status log",
-									"item_name", data.ItemName,
-									"status", common.Normal,
-									"error", err)
-							} else {
-								dataBroker.Publish(common.SendMsgStruct{Type: common.MsgItemStatus,
-									Body: common.RowIdItemStatusStruct{
-										RowId: rowId,
-										ItemStatusStruct: common.ItemStatusStruct{
-											ItemName:           data.ItemName,
-											StatusChangeStruct: common.StatusChangeStruct{Status: common.Normal, ChangedAt: at},
-										},
-									},
-								}, nil)
-							}
+						if !a.keepRaw {
+							updateItemStatus(dataBroker, data.ItemName, data.Value, flag, at)
+							continue
 						}
 					}
+					saveItemData(dataBroker, data.Typ, data.ItemName, data.Value, flag, at)
 				}
-				if data.Value != nil {
-					if err := db.SaveData(data.ItemName, *data.Value, at.ToInt64()); err != nil {
-						slog.Error("Failed to save data",
-							"item_name", data.ItemName,
-							"value", *data.Value,
-							"error", err)
+			}()
+		case <-ticker.C:
+			if len(aggregators) == 0 {
+				continue
+			}
+			func() {
+				ingestMu.Lock()
+				defer ingestMu.Unlock()
+				now := custype.ToUnixMs(time.Now().Add(device.ClockCorrection()))
+				for _, a := range aggregators {
+					for _, d := range a.flushBefore(now) {
+						saveItemData(dataBroker, common.MsgData, d.ItemName, d.Value, qualityFlag(d.ItemName, d.At, d.Value), d.At)
 					}
-					dataBroker.Publish(common.SendMsgStruct{
-						Type: data.Typ,
-						Body: common.ItemNameDataTimeStruct{
-							ItemName:       data.ItemName,
-							DataTimeStruct: common.DataTimeStruct{Value: *data.Value, Millisecond: at},
-						},
-					}, nil)
 				}
-			}
-		}()
+			}()
+		}
+	}
+}
+
+// saveItemData saves and publishes one value with its quality flag, a nil value only changes the item status.
+// The caller must hold ingestMu.
+func saveItemData(dataBroker *pubsub.Broker, typ common.MsgType, itemName string, value *float64, flag common.QCFlag, at custype.UnixMs) {
+	if typ == common.MsgData {
+		updateItemStatus(dataBroker, itemName, value, flag, at)
+	}
+	if value != nil {
+		seq, err := db.SaveData(itemName, *value, flag, at.ToInt64())
+		if err != nil {
+			slog.Error("Failed to save data",
+				"item_name", itemName,
+				"value", *value,
+				"error", err)
+		}
+		dataBroker.Publish(common.SendMsgStruct{
+			Type: typ,
+			Body: common.SeqItemNameDataTimeStruct{
+				Seq: seq,
+				ItemNameDataTimeStruct: common.ItemNameDataTimeStruct{
+					ItemName:       itemName,
+					DataTimeStruct: common.DataTimeStruct{Value: *value, Millisecond: at, Flag: flag},
+				},
+			},
+		}, nil)
+	}
+}
+
+// updateItemStatus marks the item abnormal while it has no value or a bad one, and normal again once it has a value.
+func updateItemStatus(dataBroker *pubsub.Broker, itemName string, value *float64, flag common.QCFlag, at custype.UnixMs) {
+	status := common.Normal
+	if value == nil || flag == common.QCBad {
+		status = common.Abnormal
+	}
+	if itemsStatus[itemName].Status == status {
+		return
+	}
+	itemsStatus[itemName] = common.StatusChangeStruct{Status: status, ChangedAt: at}
+	rowId, err := db.SaveItemStatusLog(itemName, status, at.ToInt64())
+	if err != nil {
+		slog.Error("Failed to save item status log",
+			"item_name", itemName,
+			"status", status,
+			"error", err)
+		return
 	}
+	dataBroker.Publish(common.SendMsgStruct{Type: common.MsgItemStatus,
+		Body: common.RowIdItemStatusStruct{
+			RowId: rowId,
+			ItemStatusStruct: common.ItemStatusStruct{
+				ItemName:           itemName,
+				StatusChangeStruct: common.StatusChangeStruct{Status: status, ChangedAt: at},
+			},
+		}}, nil)
 }
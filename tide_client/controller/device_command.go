@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"tide/tide_client/connWrap"
+	"tide/tide_client/protocol/modbusrtu"
+	"tide/tide_client/protocol/sdi12"
+	"tide/tide_client/protocol/textline"
+	"tide/tide_client/syncv2"
+)
+
+// commandBuses are the buses remote device commands can be sent to, keyed by uart port or tcp addr.
+var commandBuses sync.Map
+
+type commandBus struct {
+	bus       *connWrap.Bus
+	sdi12Mode sdi12.Mode
+	// modbusTCP is set for the buses whose Modbus devices use Modbus TCP framing.
+	modbusTCP bool
+}
+
+// registerCommandBus fails if another connection was already opened to the same port or addr,
+// two buses would interleave their exchanges on one physical line.
+func registerCommandBus(name string, bus *connWrap.Bus, model string, config json.RawMessage) error {
+	mode := sdi12.ModeNative
+	if model == "arduino" {
+		mode = sdi12.ModeArduino
+	}
+	b := commandBus{bus: bus, sdi12Mode: mode, modbusTCP: modbusTCPFraming(model, config)}
+	if _, loaded := commandBuses.LoadOrStore(name, b); loaded {
+		return fmt.Errorf("%s is opened more than once", name)
+	}
+	return nil
+}
+
+// modbusTCPFraming reports whether the GenericModbus devices of a bus, directly or on an uart-rs485 bus,
+// use Modbus TCP framing. Invalid configs are reported when the devices start.
+func modbusTCPFraming(model string, config json.RawMessage) bool {
+	var framing struct {
+		Framing string `json:"framing"`
+	}
+	switch model {
+	case "GenericModbus":
+		return json.Unmarshal(config, &framing) == nil && framing.Framing == "tcp"
+	case "uart-rs485":
+		var subDevices []subDeviceConfig
+		if json.Unmarshal(config, &subDevices) != nil {
+			return false
+		}
+		for _, sub := range subDevices {
+			if modbusTCPFraming(sub.Model, sub.Config) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runDeviceCommand sends a command from the server to a sensor, it waits for the bus like the devices do.
+func runDeviceCommand(cmd syncv2.DeviceCommand) ([]byte, error) {
+	value, ok := commandBuses.Load(cmd.Bus)
+	if !ok {
+		return nil, fmt.Errorf("unknown bus %q", cmd.Bus)
+	}
+	b := value.(commandBus)
+	if owner := b.bus.Owner(); owner != "" {
+		// The streaming device holds the bus for good, the command would wait forever.
+		return nil, fmt.Errorf("bus %q is owned by streaming device %s", cmd.Bus, owner)
+	}
+	switch cmd.Protocol {
+	case "text":
+		if len(cmd.Command) == 0 {
+			return nil, errors.New("empty command")
+		}
+		return textline.NewSession(b.bus).CustomCommand(cmd.Command)
+	case "sdi12":
+		if len(cmd.Command) == 0 {
+			return nil, errors.New("empty command")
+		}
+		response, err := sdi12.NewSession(b.bus, b.sdi12Mode).Command(string(cmd.Command), 0)
+		return []byte(response), err
+	case "modbus":
+		if cmd.Quantity == 0 || cmd.Quantity > 125 {
+			return nil, errors.New("modbus quantity must be between 1 and 125")
+		}
+		session := modbusrtu.NewSession(b.bus, cmd.SlaveID)
+		if b.modbusTCP {
+			session = modbusrtu.NewTCPSession(b.bus, cmd.SlaveID)
+		}
+		switch cmd.Function {
+		case 3:
+			return session.ReadHoldingRegisters(cmd.Address, cmd.Quantity)
+		case 4:
+			return session.ReadInputRegisters(cmd.Address, cmd.Quantity)
+		default:
+			return nil, fmt.Errorf("unsupported modbus function %d", cmd.Function)
+		}
+	default:
+		return nil, fmt.Errorf("unknown protocol %q", cmd.Protocol)
+	}
+}
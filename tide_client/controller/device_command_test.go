@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"tide/tide_client/connWrap"
+	"tide/tide_client/protocol/sdi12"
+	"tide/tide_client/syncv2"
+
+	"github.com/stretchr/testify/require"
+)
+
+type replyConn struct {
+	reply   *bytes.Reader
+	written []byte
+}
+
+func (c *replyConn) Read(p []byte) (int, error) {
+	if c.reply.Len() == 0 {
+		return 0, io.EOF
+	}
+	return c.reply.Read(p)
+}
+
+func (c *replyConn) Write(p []byte) (int, error) {
+	c.written = append(c.written, p...)
+	return len(p), nil
+}
+
+func (c *replyConn) ResetInputBuffer() error { return nil }
+
+func Test_runDeviceCommand(t *testing.T) {
+	conn := &replyConn{reply: bytes.NewReader([]byte("0131OTT HydrometPLS\r\n"))}
+	commandBuses.Store("test-bus", commandBus{bus: connWrap.NewBusWithQuietTime(conn, 0), sdi12Mode: sdi12.ModeNative})
+	t.Cleanup(func() { commandBuses.Delete("test-bus") })
+
+	output, err := runDeviceCommand(syncv2.DeviceCommand{Bus: "test-bus", Protocol: "sdi12", Command: []byte("0I!")})
+	require.NoError(t, err)
+	require.Equal(t, "0131OTT HydrometPLS", string(output))
+	require.Equal(t, "0I!", string(conn.written))
+
+	_, err = runDeviceCommand(syncv2.DeviceCommand{Bus: "missing", Protocol: "sdi12", Command: []byte("0I!")})
+	require.ErrorContains(t, err, "unknown bus")
+	_, err = runDeviceCommand(syncv2.DeviceCommand{Bus: "test-bus", Protocol: "ascii", Command: []byte("x")})
+	require.ErrorContains(t, err, "unknown protocol")
+	_, err = runDeviceCommand(syncv2.DeviceCommand{Bus: "test-bus", Protocol: "modbus", Function: 6, Quantity: 1})
+	require.ErrorContains(t, err, "unsupported modbus function")
+}
+
+func Test_runDeviceCommandOnStreamBus(t *testing.T) {
+	bus := connWrap.NewBusWithQuietTime(&replyConn{reply: bytes.NewReader(nil)}, 0)
+	require.NoError(t, bus.Claim("NMEA-GNSS"))
+	require.NoError(t, registerCommandBus("stream-bus", bus, "NMEA-GNSS", nil))
+	t.Cleanup(func() { commandBuses.Delete("stream-bus") })
+
+	_, err := runDeviceCommand(syncv2.DeviceCommand{Bus: "stream-bus", Protocol: "text", Command: []byte("x")})
+	require.ErrorContains(t, err, "owned by streaming device NMEA-GNSS")
+
+	require.ErrorContains(t, registerCommandBus("stream-bus", bus, "NMEA-GNSS", nil), "opened more than once")
+}
+
+// mbapConn answers every Modbus TCP request with two registers, under the transaction id of the request.
+type mbapConn struct {
+	reply   bytes.Buffer
+	written []byte
+}
+
+func (c *mbapConn) Read(p []byte) (int, error) { return c.reply.Read(p) }
+
+func (c *mbapConn) Write(p []byte) (int, error) {
+	c.written = append(c.written, p...)
+	c.reply.Write(p[:2])
+	c.reply.Write([]byte{0x00, 0x00, 0x00, 0x07, p[6], p[7], 0x04, 0x12, 0x34, 0x56, 0x78})
+	return len(p), nil
+}
+
+func (c *mbapConn) ResetInputBuffer() error { return nil }
+
+func Test_runDeviceCommandModbusTCP(t *testing.T) {
+	conn := &mbapConn{}
+	config := json.RawMessage(`[{"model": "GenericModbus", "config": {"device_name": "gauge", "addr": 5, "framing": "tcp"}}]`)
+	require.NoError(t, registerCommandBus("tcp-bus", connWrap.NewBusWithQuietTime(conn, 0), "uart-rs485", config))
+	t.Cleanup(func() { commandBuses.Delete("tcp-bus") })
+
+	output, err := runDeviceCommand(syncv2.DeviceCommand{Bus: "tcp-bus", Protocol: "modbus", SlaveID: 5, Function: 3, Address: 100, Quantity: 2})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x12, 0x34, 0x56, 0x78}, output)
+	require.Equal(t, []byte{0x00, 0x00, 0x00, 0x06, 0x05, 0x03, 0x00, 0x64, 0x00, 0x02}, conn.written[2:])
+}
+
+func Test_modbusTCPFraming(t *testing.T) {
+	require.True(t, modbusTCPFraming("GenericModbus", json.RawMessage(`{"framing": "tcp"}`)))
+	require.False(t, modbusTCPFraming("GenericModbus", json.RawMessage(`{"framing": "rtu"}`)))
+	require.False(t, modbusTCPFraming("uart-rs485", json.RawMessage(`[{"model": "VEGAPULS61", "config": {"framing": "tcp"}}]`)))
+	require.False(t, modbusTCPFraming("arduino", nil))
+}
@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"tide/pkg/project"
+	"tide/tide_client/global"
+	"time"
+)
+
+// The device config pushed by the server is kept next to the local config files:
+//
+//	<file>          the config the station runs with
+//	<file>.pending  an accepted config, used from the next start
+//	<file>.trying   marker that a start with the pending config is in progress
+//	<file>.failed   the last pending config that failed to start, it is not accepted again
+//
+// A start that finds both the pending config and the marker crashed with the pending
+// config, so it is moved aside and the station starts with the previous one.
+const defaultDeviceConfigFile = "device_config.json"
+
+// deviceConfigRestartCode is the exit code used to restart with a new device config,
+// systemd restarts the service because it is not 0.
+const deviceConfigRestartCode = 3
+
+const deviceConfigRestartDelay = time.Second
+
+// restartForDeviceConfig is replaced in tests.
+var restartForDeviceConfig = func() {
+	project.CallReleaseFunc()
+	os.Exit(deviceConfigRestartCode)
+}
+
+type deviceConfigFile struct {
+	Version int64                        `json:"version"`
+	Devices map[string][]json.RawMessage `json:"devices"`
+}
+
+var deviceConfig struct {
+	mu sync.Mutex
+	// path of the config file, the other files append a suffix to it.
+	path string
+	// version is the version the station runs with, 0 for the local config files.
+	version int64
+	// failedVersion is the last version that failed to start.
+	failedVersion int64
+	// pendingVersion is the version waiting for the restart.
+	pendingVersion int64
+	// trying is set when the station starts with the pending config.
+	trying bool
+}
+
+func deviceConfigPath() string {
+	if global.Config.SyncV2.DeviceConfigFile != "" {
+		return global.Config.SyncV2.DeviceConfigFile
+	}
+	return defaultDeviceConfigFile
+}
+
+// loadDeviceConfig returns the device configs to start with, keyed by conn type.
+// It rolls back a pending config whose previous start failed and reports that in applyErr.
+func loadDeviceConfig(path string) (devices map[string][]json.RawMessage, version int64, applyErr string, err error) {
+	deviceConfig.mu.Lock()
+	defer deviceConfig.mu.Unlock()
+	deviceConfig.path = path
+
+	pending, trying, failed := path+".pending", path+".trying", path+".failed"
+	if _, err = os.Stat(pending); err == nil {
+		if _, err = os.Stat(trying); err == nil {
+			if err = os.Rename(pending, failed); err != nil {
+				return nil, 0, "", err
+			}
+			slog.Warn("Pending device config failed to start, rolled back", "file", pending)
+		} else if errors.Is(err, os.ErrNotExist) {
+			if err = os.WriteFile(trying, nil, 0o644); err != nil {
+				return nil, 0, "", err
+			}
+			var c deviceConfigFile
+			if c, err = readDeviceConfigFile(pending); err != nil {
+				return nil, 0, "", err
+			}
+			deviceConfig.version, deviceConfig.trying = c.Version, true
+			return c.Devices, c.Version, "", nil
+		} else {
+			return nil, 0, "", err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, 0, "", err
+	}
+	if err = os.Remove(trying); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, 0, "", err
+	}
+
+	if _, err = os.Stat(path); err == nil {
+		var c deviceConfigFile
+		if c, err = readDeviceConfigFile(path); err != nil {
+			return nil, 0, "", err
+		}
+		devices, version = c.Devices, c.Version
+	} else if errors.Is(err, os.ErrNotExist) {
+		if devices, err = readLocalDeviceConfigs(); err != nil {
+			return nil, 0, "", err
+		}
+	} else {
+		return nil, 0, "", err
+	}
+	deviceConfig.version = version
+
+	if c, err := readDeviceConfigFile(failed); err == nil && c.Version > version {
+		deviceConfig.failedVersion = c.Version
+		applyErr = fmt.Sprintf("device config version %d failed to start, rolled back", c.Version)
+	}
+	return devices, version, applyErr, nil
+}
+
+// commitDeviceConfig makes a pending config that started successfully the current one.
+func commitDeviceConfig() error {
+	deviceConfig.mu.Lock()
+	defer deviceConfig.mu.Unlock()
+	if !deviceConfig.trying {
+		return nil
+	}
+	path := deviceConfig.path
+	if err := os.Rename(path+".pending", path); err != nil {
+		return err
+	}
+	deviceConfig.trying = false
+	_ = os.Remove(path + ".failed")
+	return os.Remove(path + ".trying")
+}
+
+func readDeviceConfigFile(name string) (deviceConfigFile, error) {
+	var c deviceConfigFile
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return c, err
+	}
+	if err = json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("%s: %w", name, err)
+	}
+	return c, nil
+}
+
+// readLocalDeviceConfigs reads the files listed in the devices option.
+func readLocalDeviceConfigs() (map[string][]json.RawMessage, error) {
+	devices := make(map[string][]json.RawMessage)
+	for connType, files := range global.Config.Devices {
+		for _, filename := range files {
+			rawConf, err := os.ReadFile(filename)
+			if err != nil {
+				return nil, err
+			}
+			devices[connType] = append(devices[connType], rawConf)
+		}
+	}
+	return devices, nil
+}
+
+// applyDeviceConfig validates a config pushed by the server, saves it as pending and restarts the station to use it.
+func applyDeviceConfig(version int64, config []byte) error {
+	devices, err := validateDeviceConfig(config)
+	if err != nil {
+		return err
+	}
+
+	deviceConfig.mu.Lock()
+	defer deviceConfig.mu.Unlock()
+	switch {
+	case version <= 0:
+		return fmt.Errorf("invalid device config version %d", version)
+	case version == deviceConfig.version || version == deviceConfig.pendingVersion:
+		return nil
+	case version < deviceConfig.version:
+		return fmt.Errorf("device config version %d is older than the applied version %d", version, deviceConfig.version)
+	case version == deviceConfig.failedVersion:
+		return fmt.Errorf("device config version %d failed to start, rolled back", version)
+	}
+
+	b, err := json.Marshal(deviceConfigFile{Version: version, Devices: devices})
+	if err != nil {
+		return err
+	}
+	if err = writeFileAtomic(deviceConfig.path+".pending", b); err != nil {
+		return err
+	}
+	deviceConfig.pendingVersion = version
+	slog.Info("Device config accepted, restarting", "version", version)
+	time.AfterFunc(deviceConfigRestartDelay, restartForDeviceConfig)
+	return nil
+}
+
+// validateDeviceConfig checks that config is map[conn_type][]device_config with registered conn types.
+// The device configs themselves are checked by the devices when the station starts.
+func validateDeviceConfig(config []byte) (map[string][]json.RawMessage, error) {
+	var devices map[string][]json.RawMessage
+	if err := json.Unmarshal(config, &devices); err != nil || devices == nil {
+		return nil, errors.New("device config must be an object of device config arrays")
+	}
+	for connType, list := range devices {
+		if GetRegConn(connType) == nil {
+			return nil, fmt.Errorf("unknown conn type %q", connType)
+		}
+		for _, device := range list {
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(device, &obj); err != nil || obj == nil {
+				return nil, fmt.Errorf("%s: device config must be an object", connType)
+			}
+		}
+	}
+	return devices, nil
+}
+
+func writeFileAtomic(name string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_deviceConfig(t *testing.T) {
+	restarted := make(chan struct{}, 1)
+	restartForDeviceConfig = func() { restarted <- struct{}{} }
+	// start simulates a restart of the station, started reports whether the devices came up.
+	start := func(path string, started bool) (int64, string) {
+		deviceConfig.version, deviceConfig.failedVersion, deviceConfig.pendingVersion, deviceConfig.trying = 0, 0, 0, false
+		_, version, applyErr, err := loadDeviceConfig(path)
+		require.NoError(t, err)
+		if started {
+			require.NoError(t, commitDeviceConfig())
+		}
+		return version, applyErr
+	}
+	waitRestart := func() {
+		select {
+		case <-restarted:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for restart")
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "device_config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":1,"devices":{"tcp":[]}}`), 0o644))
+	version, applyErr := start(path, true)
+	require.Equal(t, int64(1), version)
+	require.Empty(t, applyErr)
+
+	require.ErrorContains(t, applyDeviceConfig(2, []byte(`[]`)), "object of device config arrays")
+	require.ErrorContains(t, applyDeviceConfig(2, []byte(`{"unknown":[]}`)), "unknown conn type")
+	require.ErrorContains(t, applyDeviceConfig(2, []byte(`{"tcp":[1]}`)), "must be an object")
+	require.NoError(t, applyDeviceConfig(1, []byte(`{"tcp":[]}`)), "the applied version is accepted again")
+	require.ErrorContains(t, applyDeviceConfig(0, []byte(`{"tcp":[]}`)), "invalid device config version")
+
+	// A new version is started with and committed.
+	require.NoError(t, applyDeviceConfig(2, []byte(`{"tcp":[{"port":"a"}]}`)))
+	waitRestart()
+	version, applyErr = start(path, true)
+	require.Equal(t, int64(2), version)
+	require.Empty(t, applyErr)
+	require.ErrorContains(t, applyDeviceConfig(1, []byte(`{"tcp":[]}`)), "older than the applied version")
+
+	// A version that fails to start is rolled back and rejected from then on.
+	require.NoError(t, applyDeviceConfig(3, []byte(`{"tcp":[{"port":"b"}]}`)))
+	waitRestart()
+	version, _ = start(path, false)
+	require.Equal(t, int64(3), version)
+	version, applyErr = start(path, true)
+	require.Equal(t, int64(2), version)
+	require.Equal(t, "device config version 3 failed to start, rolled back", applyErr)
+	require.ErrorContains(t, applyDeviceConfig(3, []byte(`{"tcp":[{"port":"b"}]}`)), "failed to start")
+
+	c, err := readDeviceConfigFile(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), c.Version)
+	require.JSONEq(t, `{"port":"a"}`, string(c.Devices["tcp"][0]))
+}
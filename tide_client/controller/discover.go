@@ -0,0 +1,367 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"tide/tide_client/connWrap"
+	"tide/tide_client/connWrap/tcp"
+	"tide/tide_client/connWrap/uart"
+	"tide/tide_client/device"
+	"tide/tide_client/protocol/modbusrtu"
+	"tide/tide_client/protocol/sdi12"
+	"time"
+
+	"github.com/wwnt/modbus"
+)
+
+// sdi12Addresses are the addresses discovery probes, in the order of the SDI-12 specification.
+const sdi12Addresses = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const (
+	modbusMinSlaveID = 1
+	modbusMaxSlaveID = 247
+)
+
+const discoverConnectTimeout = 10 * time.Second
+
+const draftCron = "0 * * * * *"
+
+// modbusModels are the models on an uart-rs485 bus that are addressed by Modbus slave id.
+var modbusModels = map[string]bool{"GenericModbus": true, "VEGAPULS61": true, "ANALOG-VOLTAGE-MODBUS": true}
+
+type subDeviceConfig struct {
+	Model  string          `json:"model"`
+	Config json.RawMessage `json:"config"`
+}
+
+// Discover scans the SDI-12 buses behind an arduino and the uart-rs485 Modbus buses of the device config
+// for sensors, and writes the device config with the sensors that are not configured yet added to out.
+// Native SDI-12 buses are scanned too, but hold one sensor in the config, the others found are only logged.
+// The station must be stopped, discovery needs the buses for itself.
+func Discover(out string) error {
+	devices, err := readDeviceConfigForDiscover()
+	if err != nil {
+		return err
+	}
+	draft := make(map[string][]json.RawMessage, len(devices))
+	for connType, rawConfs := range devices {
+		for _, rawConf := range rawConfs {
+			newConf, err := discoverConn(connType, rawConf)
+			if err != nil {
+				return err
+			}
+			draft[connType] = append(draft[connType], newConf)
+		}
+	}
+	b, err := json.MarshalIndent(deviceConfigFile{Devices: draft}, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err = writeFileAtomic(out, b); err != nil {
+		return err
+	}
+	slog.Info("Draft device config written, edit the item names before using it", "file", out)
+	return nil
+}
+
+// readDeviceConfigForDiscover reads the device config the station runs with, without touching a pending config.
+func readDeviceConfigForDiscover() (map[string][]json.RawMessage, error) {
+	c, err := readDeviceConfigFile(deviceConfigPath())
+	if err == nil {
+		return c.Devices, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return readLocalDeviceConfigs()
+}
+
+func discoverConn(connType string, rawConf json.RawMessage) (json.RawMessage, error) {
+	var (
+		conf struct {
+			Model  string          `json:"model"`
+			Config json.RawMessage `json:"config"`
+		}
+		name       string
+		connCommon connWrap.ConnCommon
+		err        error
+	)
+	if err = json.Unmarshal(rawConf, &conf); err != nil {
+		return nil, err
+	}
+	switch connType {
+	case "uart":
+		var uartConf uartDeviceConfig
+		if err = json.Unmarshal(rawConf, &uartConf); err != nil {
+			return nil, err
+		}
+		name = uartConf.Port
+		if discoverModel(conf.Model) {
+			connCommon, err = uart.StartUart(uartConf.Port, uartConf.ReadTimeout, uartConf.Mode)
+		}
+	case "tcp":
+		var tcpConf tcpDeviceConfig
+		if err = json.Unmarshal(rawConf, &tcpConf); err != nil {
+			return nil, err
+		}
+		name = tcpConf.Addr
+		if discoverModel(conf.Model) {
+			connCommon, err = tcp.StartTcp(tcpConf.Addr, tcpConf.ReadTimeout)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if connCommon == nil {
+		slog.Warn("Bus not scanned, only arduino and native SDI-12 buses and uart-rs485 Modbus buses are", "conn_type", connType, "bus", name, "model", conf.Model)
+		return rawConf, nil
+	}
+
+	bus := connWrap.NewBus(connCommon)
+	if err = waitBusConnected(bus, discoverConnectTimeout); err != nil {
+		slog.Error("Bus skipped, not connected", "bus", name, "error", err)
+		return rawConf, nil
+	}
+	slog.Info("Scanning bus", "bus", name, "model", conf.Model)
+	if device.IsSDI12Model(conf.Model) {
+		others, err := discoverNativeSDI12(sdi12.NewSession(bus, sdi12.ModeNative), conf.Config)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, sub := range others {
+			slog.Warn("SDI-12 sensor not added, a native SDI-12 bus holds one sensor, connect the sensors through an arduino to use several",
+				"bus", name, "model", sub.Model, "config", string(sub.Config))
+		}
+		return rawConf, nil
+	}
+	var newConfig json.RawMessage
+	if conf.Model == "arduino" {
+		newConfig, err = discoverArduino(sdi12.NewSession(bus, sdi12.ModeArduino), conf.Config)
+	} else {
+		newConfig, err = discoverRs485(bus, conf.Config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(rawConf, &fields); err != nil {
+		return nil, err
+	}
+	fields["config"] = newConfig
+	return json.Marshal(fields)
+}
+
+// discoverModel reports whether the buses of model are scanned.
+func discoverModel(model string) bool {
+	return model == "arduino" || model == "uart-rs485" || device.IsSDI12Model(model)
+}
+
+// waitBusConnected waits for the transport, which opens in the background, to be usable.
+func waitBusConnected(bus *connWrap.Bus, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := bus.ResetInputBuffer()
+		if !errors.Is(err, os.ErrInvalid) {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return errors.New("connect timeout")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// discoverArduino adds the SDI-12 sensors that answer but are not in the sdi12 list of the arduino config.
+func discoverArduino(session *sdi12.Session, rawConfig json.RawMessage) (json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(rawConfig) > 0 {
+		if err := json.Unmarshal(rawConfig, &fields); err != nil {
+			return nil, err
+		}
+	}
+	// The arduino config is read case-insensitively, keep whichever key the file uses.
+	key := "sdi12"
+	for k := range fields {
+		if strings.EqualFold(k, "sdi12") {
+			key = k
+		}
+	}
+	var subDevices []subDeviceConfig
+	if raw, ok := fields[key]; ok {
+		if err := json.Unmarshal(raw, &subDevices); err != nil {
+			return nil, err
+		}
+	}
+	configured := make(map[string]string, len(subDevices))
+	for _, sub := range subDevices {
+		var c struct {
+			Addr string `json:"addr"`
+		}
+		_ = json.Unmarshal(sub.Config, &c)
+		configured[c.Addr] = sub.Model
+	}
+
+	for _, addr := range strings.Split(sdi12Addresses, "") {
+		ok, err := session.Acknowledge(addr, 0)
+		if err != nil {
+			slog.Warn("Unexpected SDI-12 reply", "addr", addr, "error", err)
+			continue
+		}
+		if !ok {
+			if model, isConfigured := configured[addr]; isConfigured {
+				slog.Warn("Configured SDI-12 sensor did not answer", "addr", addr, "model", model)
+			}
+			continue
+		}
+		if model, isConfigured := configured[addr]; isConfigured {
+			slog.Info("Found configured SDI-12 sensor", "addr", addr, "model", model)
+			continue
+		}
+		sub, err := draftSDI12Device(session, addr)
+		if err != nil {
+			slog.Error("Failed to identify SDI-12 sensor", "addr", addr, "error", err)
+			continue
+		}
+		slog.Info("Found SDI-12 sensor", "addr", addr, "model", sub.Model)
+		subDevices = append(subDevices, sub)
+	}
+
+	b, err := json.Marshal(subDevices)
+	if err != nil {
+		return nil, err
+	}
+	fields[key] = b
+	return json.Marshal(fields)
+}
+
+// discoverNativeSDI12 checks that the sensor configured on a native SDI-12 bus answers and drafts the configs of
+// the other sensors that do, which the bus cannot hold.
+func discoverNativeSDI12(session *sdi12.Session, rawConfig json.RawMessage) ([]subDeviceConfig, error) {
+	var c struct {
+		Addr string `json:"addr"`
+	}
+	if len(rawConfig) > 0 {
+		if err := json.Unmarshal(rawConfig, &c); err != nil {
+			return nil, err
+		}
+	}
+	var others []subDeviceConfig
+	for _, addr := range strings.Split(sdi12Addresses, "") {
+		ok, err := session.Acknowledge(addr, 0)
+		if err != nil {
+			slog.Warn("Unexpected SDI-12 reply", "addr", addr, "error", err)
+			continue
+		}
+		if addr == c.Addr {
+			if ok {
+				slog.Info("Found configured SDI-12 sensor", "addr", addr)
+			} else {
+				slog.Warn("Configured SDI-12 sensor did not answer", "addr", addr)
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+		sub, err := draftSDI12Device(session, addr)
+		if err != nil {
+			slog.Error("Failed to identify SDI-12 sensor", "addr", addr, "error", err)
+			continue
+		}
+		others = append(others, sub)
+	}
+	return others, nil
+}
+
+// draftSDI12Device identifies the sensor and drafts its config with the matching model,
+// or with GenericSDI12 and the value count of a standard measurement for unknown sensors.
+func draftSDI12Device(session *sdi12.Session, addr string) (subDeviceConfig, error) {
+	id, err := session.Identify(addr, 0)
+	if err != nil {
+		return subDeviceConfig{}, err
+	}
+	model, draft, ok := device.MatchSDI12Model(id)
+	if !ok {
+		count, err := session.Measure(addr, 0, "M")
+		if err != nil {
+			slog.Warn("Failed to get the value count of SDI-12 sensor", "addr", addr, "error", err)
+		}
+		values := make([]map[string]any, count)
+		for i := range values {
+			values[i] = map[string]any{"index": i, "item_type": "", "item_name": ""}
+		}
+		model = "GenericSDI12"
+		draft = map[string]any{
+			"device_name":     strings.TrimSpace(id.Vendor + " " + id.Model + " " + addr),
+			"addr":            addr,
+			"extra_wake_time": 0,
+			"cron":            draftCron,
+			"command":         "M",
+			"value_count":     count,
+			"values":          values,
+		}
+	}
+	draft["identification"] = id
+	b, err := json.Marshal(draft)
+	if err != nil {
+		return subDeviceConfig{}, err
+	}
+	return subDeviceConfig{Model: model, Config: b}, nil
+}
+
+// discoverRs485 adds GenericModbus drafts for the slave ids that answer but are not configured.
+// A Modbus exception reply also shows that a device is there.
+func discoverRs485(bus *connWrap.Bus, rawConfig json.RawMessage) (json.RawMessage, error) {
+	var subDevices []subDeviceConfig
+	if len(rawConfig) > 0 {
+		if err := json.Unmarshal(rawConfig, &subDevices); err != nil {
+			return nil, err
+		}
+	}
+	configured := make(map[byte]string)
+	for _, sub := range subDevices {
+		if !modbusModels[sub.Model] {
+			continue
+		}
+		var c struct {
+			Addr byte `json:"addr"`
+		}
+		if err := json.Unmarshal(sub.Config, &c); err == nil {
+			configured[c.Addr] = sub.Model
+		}
+	}
+
+	for id := modbusMinSlaveID; id <= modbusMaxSlaveID; id++ {
+		slaveID := byte(id)
+		_, err := modbusrtu.NewSession(bus, slaveID).ReadHoldingRegisters(0, 1)
+		var modbusErr *modbus.ModbusError
+		if err != nil && !errors.As(err, &modbusErr) {
+			if model, isConfigured := configured[slaveID]; isConfigured {
+				slog.Warn("Configured Modbus device did not answer", "addr", slaveID, "model", model, "error", err)
+			}
+			continue
+		}
+		if model, isConfigured := configured[slaveID]; isConfigured {
+			slog.Info("Found configured Modbus device", "addr", slaveID, "model", model)
+			continue
+		}
+		slog.Info("Found Modbus device", "addr", slaveID)
+		b, err := json.Marshal(map[string]any{
+			"device_name": fmt.Sprintf("modbus_%d", slaveID),
+			"addr":        slaveID,
+			"framing":     "rtu",
+			"cron":        draftCron,
+			"registers":   []any{},
+		})
+		if err != nil {
+			return nil, err
+		}
+		subDevices = append(subDevices, subDeviceConfig{Model: "GenericModbus", Config: b})
+	}
+	return json.Marshal(subDevices)
+}
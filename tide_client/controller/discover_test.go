@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"tide/tide_client/connWrap"
+	"tide/tide_client/protocol/sdi12"
+
+	"github.com/wwnt/modbus"
+)
+
+// fakeSDI12Bus answers arduino framed SDI-12 commands, or native ones, for the sensors it holds.
+type fakeSDI12Bus struct {
+	idents   map[string]string
+	native   bool
+	response []byte
+}
+
+func (b *fakeSDI12Bus) Read(p []byte) (int, error) {
+	if len(b.response) == 0 {
+		return 0, connWrap.ErrTimeout
+	}
+	n := copy(p, b.response)
+	b.response = b.response[n:]
+	return n, nil
+}
+
+func (b *fakeSDI12Bus) Write(p []byte) (int, error) {
+	cmd := string(p)
+	if !b.native {
+		// Strip the extra wake time and terminator of the arduino framing.
+		cmd = cmd[:len(cmd)-2]
+	}
+	addr, rest := cmd[:1], cmd[1:]
+	ident, ok := b.idents[addr]
+	switch {
+	case !ok:
+	case rest == "!":
+		b.response = []byte(addr + "\r\n")
+	case rest == "I!":
+		b.response = []byte(addr + ident + "\r\n")
+	case rest == "M!":
+		b.response = []byte(addr + "0003\r\n")
+	}
+	return len(p), nil
+}
+
+func (b *fakeSDI12Bus) ResetInputBuffer() error { return nil }
+
+func Test_discoverArduino(t *testing.T) {
+	rawConn := &fakeSDI12Bus{idents: map[string]string{
+		"1": "13OTT HYD PLS_C 100",
+		"3": "13OTT HYD PLS_C 100",
+		"b": "14ACME    LEVEL 001SN42",
+	}}
+	session := sdi12.NewSession(connWrap.NewBusWithQuietTime(rawConn, 0), sdi12.ModeArduino)
+	rawConfig := json.RawMessage(`{"sdi12":[{"model":"PLS-C","config":{"addr":"1"}}],"analog":[{"pin":0}]}`)
+
+	b, err := discoverArduino(session, rawConfig)
+	if err != nil {
+		t.Fatalf("discoverArduino returned error: %v", err)
+	}
+	var got struct {
+		Sdi12  []subDeviceConfig `json:"sdi12"`
+		Analog json.RawMessage   `json:"analog"`
+	}
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Analog) != `[{"pin":0}]` {
+		t.Fatalf("analog = %s, want it kept", got.Analog)
+	}
+	if len(got.Sdi12) != 3 {
+		t.Fatalf("sdi12 = %+v, want the configured and two discovered sensors", got.Sdi12)
+	}
+	if got.Sdi12[1].Model != "PLS-C" {
+		t.Fatalf("model of addr 3 = %s, want PLS-C", got.Sdi12[1].Model)
+	}
+	var generic struct {
+		Addr           string               `json:"addr"`
+		ValueCount     int                  `json:"value_count"`
+		Values         []json.RawMessage    `json:"values"`
+		Identification sdi12.Identification `json:"identification"`
+	}
+	if err = json.Unmarshal(got.Sdi12[2].Config, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if got.Sdi12[2].Model != "GenericSDI12" || generic.Addr != "b" || generic.ValueCount != 3 || len(generic.Values) != 3 {
+		t.Fatalf("unknown sensor draft = %s %s", got.Sdi12[2].Model, got.Sdi12[2].Config)
+	}
+	if generic.Identification.Vendor != "ACME" || generic.Identification.Serial != "SN42" {
+		t.Fatalf("identification = %+v", generic.Identification)
+	}
+}
+
+func Test_discoverNativeSDI12(t *testing.T) {
+	rawConn := &fakeSDI12Bus{native: true, idents: map[string]string{
+		"0": "13OTT HYD PLS_C 100",
+		"5": "13OTT HYD PLS_C 100",
+	}}
+	session := sdi12.NewSession(connWrap.NewBusWithQuietTime(rawConn, 0), sdi12.ModeNative)
+
+	others, err := discoverNativeSDI12(session, json.RawMessage(`{"addr":"0"}`))
+	if err != nil {
+		t.Fatalf("discoverNativeSDI12 returned error: %v", err)
+	}
+	if len(others) != 1 || others[0].Model != "PLS-C" {
+		t.Fatalf("others = %+v, want the PLS-C at addr 5", others)
+	}
+	var c struct {
+		Addr string `json:"addr"`
+	}
+	if err = json.Unmarshal(others[0].Config, &c); err != nil || c.Addr != "5" {
+		t.Fatalf("draft = %s", others[0].Config)
+	}
+}
+
+// fakeModbusBus answers Modbus RTU requests of the slave ids it holds, with an exception for the ones in exceptions.
+type fakeModbusBus struct {
+	slaves     map[byte]bool
+	exceptions map[byte]bool
+	response   []byte
+}
+
+func (b *fakeModbusBus) Read(p []byte) (int, error) {
+	if len(b.response) == 0 {
+		return 0, connWrap.ErrTimeout
+	}
+	n := copy(p, b.response)
+	b.response = b.response[n:]
+	return n, nil
+}
+
+func (b *fakeModbusBus) Write(p []byte) (int, error) {
+	slaveID := p[0]
+	var pdu *modbus.ProtocolDataUnit
+	switch {
+	case b.slaves[slaveID]:
+		pdu = &modbus.ProtocolDataUnit{FunctionCode: p[1], Data: []byte{0x02, 0x00, 0x01}}
+	case b.exceptions[slaveID]:
+		pdu = &modbus.ProtocolDataUnit{FunctionCode: p[1] | 0x80, Data: []byte{modbus.ExceptionCodeIllegalDataAddress}}
+	default:
+		return len(p), nil
+	}
+	handler := modbus.NewRTUClientHandler(bytes.NewBuffer(nil))
+	handler.SlaveId = slaveID
+	resp, err := handler.Encode(pdu)
+	if err != nil {
+		return 0, err
+	}
+	b.response = resp
+	return len(p), nil
+}
+
+func (b *fakeModbusBus) ResetInputBuffer() error { return nil }
+
+func Test_discoverRs485(t *testing.T) {
+	rawConn := &fakeModbusBus{slaves: map[byte]bool{1: true, 7: true}, exceptions: map[byte]bool{9: true}}
+	rawConfig := json.RawMessage(`[{"model":"VEGAPULS61","config":{"addr":1}},{"model":"HMP155","config":{"addr":"5"}}]`)
+
+	b, err := discoverRs485(connWrap.NewBusWithQuietTime(rawConn, 0), rawConfig)
+	if err != nil {
+		t.Fatalf("discoverRs485 returned error: %v", err)
+	}
+	var got []subDeviceConfig
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("config = %s, want the two configured and two discovered devices", b)
+	}
+	for i, wantAddr := range []byte{7, 9} {
+		sub := got[2+i]
+		var c struct {
+			Addr byte `json:"addr"`
+		}
+		if err = json.Unmarshal(sub.Config, &c); err != nil {
+			t.Fatal(err)
+		}
+		if sub.Model != "GenericModbus" || c.Addr != wantAddr {
+			t.Fatalf("draft %d = %s %s, want GenericModbus at %d", i, sub.Model, sub.Config, wantAddr)
+		}
+	}
+}
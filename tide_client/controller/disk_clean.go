@@ -52,7 +52,16 @@ func scheduleRemoveOutdatedData() {
 				os.Exit(1)
 			}
 		}
-		db.CleanDBData(time.Now().Add(-global.Config.Db.HoldDays * 24 * time.Hour).UnixMilli())
+		db.CleanDBData(time.Now().Add(-global.Config.Db.HoldDays*24*time.Hour).UnixMilli(), global.Config.SyncV2.Enabled)
+		// Samples of aggregated items may be kept shorter than the rest.
+		for itemName, conf := range global.Config.Aggregation {
+			if !conf.KeepRaw || conf.RawHoldDays <= 0 || conf.RawHoldDays >= global.Config.Db.HoldDays {
+				continue
+			}
+			if err := db.CleanItemData(itemName, time.Now().Add(-conf.RawHoldDays*24*time.Hour).UnixMilli()); err != nil {
+				slog.Error("Error cleaning raw samples", "item_name", itemName, "error", err)
+			}
+		}
 	}
 	removeOutdatedDataJob()
 	if _, err := global.CronJob.AddFunc("@daily", removeOutdatedDataJob); err != nil {
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"tide/common"
+	"tide/pkg/custype"
+	"tide/pkg/pubsub"
+	"tide/tide_client/connWrap/uart"
+	"tide/tide_client/global"
+	"time"
+)
+
+func addStationHealth(dataBroker *pubsub.Broker) {
+	_, err := global.CronJob.AddFunc("@every 60s",
+		func() {
+			dataBroker.Publish(common.SendMsgStruct{
+				Type: common.MsgRpiStatus,
+				Body: common.StationHealthTimeStruct{
+					StationHealthStruct: stationHealth(),
+					Millisecond:         custype.ToUnixMs(time.Now()),
+				}}, nil)
+		},
+	)
+	if err != nil {
+		slog.Error("Failed to add station health cron job", "error", err)
+		os.Exit(1)
+	}
+}
+
+// stationHealth reads the state of the host from /proc and /sys, the values it fails to read are left empty.
+func stationHealth() (h common.StationHealthStruct) {
+	var err error
+	if h.CpuTemp, err = cpuTemp(); err != nil {
+		slog.Debug("Failed to read CPU temperature", "error", err)
+	}
+	for _, path := range healthDiskPaths() {
+		free, total, err := DiskUsage(path)
+		if err != nil {
+			slog.Debug("Failed to check disk space", "path", path, "error", err)
+			continue
+		}
+		h.Disks = append(h.Disks, common.DiskUsageStruct{Path: path, FreeBytes: free, TotalBytes: total})
+	}
+	if b, err := os.ReadFile("/proc/meminfo"); err == nil {
+		h.MemTotalBytes, h.MemAvailableBytes = parseMeminfo(string(b))
+	}
+	if b, err := os.ReadFile("/proc/loadavg"); err == nil {
+		_, _ = fmt.Sscan(string(b), &h.Load1, &h.Load5, &h.Load15)
+	}
+	if b, err := os.ReadFile("/proc/uptime"); err == nil {
+		var uptime float64
+		if _, err = fmt.Sscan(string(b), &uptime); err == nil {
+			h.UptimeSeconds = uint64(uptime)
+		}
+	}
+	if path := sqlitePath(global.Config.Db.Dsn); path != "" {
+		for _, name := range []string{path, path + "-wal"} {
+			if fi, err := os.Stat(name); err == nil {
+				h.DbSizeBytes += uint64(fi.Size())
+			}
+		}
+	}
+	if counts := uart.ReopenCounts(); len(counts) > 0 {
+		h.SerialReopens = counts
+	}
+	if offset, synced, err := kernelClockOffset(); err == nil {
+		h.ClockOffsetMs, h.ClockSynced = offset.Milliseconds(), synced
+	}
+	return h
+}
+
+func cpuTemp() (*float64, error) {
+	b, err := os.ReadFile("/sys/class/thermal/thermal_zone0/temp")
+	if err != nil {
+		return nil, err
+	}
+	// millidegree Celsius, e.g. 45678\n
+	milli, err := strconv.ParseFloat(strings.TrimSpace(string(b)), 64)
+	if err != nil {
+		return nil, err
+	}
+	temp := milli / 1000
+	return &temp, nil
+}
+
+// healthDiskPaths returns the directories of the database and the FTP uploads.
+func healthDiskPaths() []string {
+	var paths []string
+	if path := sqlitePath(global.Config.Db.Dsn); path != "" {
+		if abs, err := filepath.Abs(filepath.Dir(path)); err == nil {
+			paths = append(paths, abs)
+		}
+	}
+	for _, ftp := range []global.Ftp{global.Config.Gnss.Ftp, global.Config.Cameras.Ftp} {
+		if ftp.Path != "" && !slices.Contains(paths, ftp.Path) {
+			paths = append(paths, ftp.Path)
+		}
+	}
+	return paths
+}
+
+// sqlitePath returns the database file of a go-sqlite3 dsn, or "" for an in-memory database.
+func sqlitePath(dsn string) string {
+	path, query, _ := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
+	if path == "" || path == ":memory:" || strings.Contains(query, "mode=memory") {
+		return ""
+	}
+	return path
+}
+
+// parseMeminfo returns MemTotal and MemAvailable of /proc/meminfo in bytes.
+func parseMeminfo(s string) (total, available uint64) {
+	sc := bufio.NewScanner(strings.NewReader(s))
+	for sc.Scan() {
+		// MemTotal:        3884504 kB
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = kb * 1024
+		case "MemAvailable:":
+			available = kb * 1024
+		}
+	}
+	return total, available
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseMeminfo(t *testing.T) {
+	total, available := parseMeminfo(`MemTotal:        3884504 kB
+MemFree:          212340 kB
+MemAvailable:    2861040 kB
+Buffers:          120344 kB
+`)
+	require.Equal(t, uint64(3884504*1024), total)
+	require.Equal(t, uint64(2861040*1024), available)
+}
+
+func Test_sqlitePath(t *testing.T) {
+	require.Equal(t, "data.db", sqlitePath("data.db"))
+	require.Equal(t, "/var/lib/tide/data.db", sqlitePath("file:/var/lib/tide/data.db?_journal_mode=WAL"))
+	require.Empty(t, sqlitePath("file:data.db?cache=shared&mode=memory"))
+	require.Empty(t, sqlitePath(":memory:"))
+}
+
+func Test_stationHealth(t *testing.T) {
+	h := stationHealth()
+	// /proc is there on any linux host, other systems leave the values empty.
+	if h.MemTotalBytes > 0 {
+		require.LessOrEqual(t, h.MemAvailableBytes, h.MemTotalBytes)
+		require.Positive(t, h.UptimeSeconds)
+	}
+}
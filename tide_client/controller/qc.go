@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"tide/common"
+	"tide/pkg/custype"
+	"tide/tide_client/device"
+	"tide/tide_client/global"
+)
+
+// qcCheckers are keyed by item name, they are set up before receiveData starts and only used under ingestMu.
+var qcCheckers map[string]*qcChecker
+
+// qcChecker runs the checks of one item on its values in arrival order. A value that passes every
+// check is QCGood, otherwise it gets the worst flag of the checks it failed:
+//
+//	range           QCBad
+//	rate of change  QCProbablyBad
+//	flat line       QCProbablyBad
+//	gap             QCProbablyGood, for the first value after it
+type qcChecker struct {
+	conf global.QC
+
+	hasPrev bool
+	prevAt  custype.UnixMs
+
+	// The last value not flagged bad, the rate of change is measured from it so a spike
+	// does not also flag the value after it.
+	hasLast   bool
+	lastAt    custype.UnixMs
+	lastValue float64
+
+	flatValue float64
+	flatCount int
+}
+
+func newQCChecker(itemName string, conf global.QC) (*qcChecker, error) {
+	if conf.Min == nil && conf.Max == nil {
+		if r, ok := device.ItemRange(itemName); ok {
+			conf.Min, conf.Max = r.Min, r.Max
+		}
+	}
+	if conf.Min != nil && conf.Max != nil && *conf.Min > *conf.Max {
+		return nil, fmt.Errorf("min of %s is greater than max", itemName)
+	}
+	if conf.MaxRate < 0 || conf.FlatLineCount < 0 || conf.FlatLineTolerance < 0 || conf.MaxGapSec < 0 {
+		return nil, fmt.Errorf("qc settings of %s cannot be negative", itemName)
+	}
+	return &qcChecker{conf: conf}, nil
+}
+
+func (c *qcChecker) check(at custype.UnixMs, value float64) common.QCFlag {
+	flag := common.QCGood
+	if math.IsNaN(value) || c.conf.Min != nil && value < *c.conf.Min || c.conf.Max != nil && value > *c.conf.Max {
+		flag = max(flag, common.QCBad)
+	}
+
+	gap := c.hasPrev && c.conf.MaxGapSec > 0 && float64(at-c.prevAt) > c.conf.MaxGapSec*1000
+	if gap {
+		flag = max(flag, common.QCProbablyGood)
+	}
+	c.hasPrev, c.prevAt = true, at
+
+	// The change over a gap is not a rate the sensor can be judged by.
+	if c.conf.MaxRate > 0 && c.hasLast && !gap && at > c.lastAt {
+		if math.Abs(value-c.lastValue)/(float64(at-c.lastAt)/1000) > c.conf.MaxRate {
+			flag = max(flag, common.QCProbablyBad)
+		}
+	}
+
+	if c.conf.FlatLineCount > 1 {
+		if c.flatCount > 0 && math.Abs(value-c.flatValue) <= c.conf.FlatLineTolerance {
+			c.flatCount++
+		} else {
+			c.flatValue, c.flatCount = value, 1
+		}
+		if c.flatCount >= c.conf.FlatLineCount {
+			flag = max(flag, common.QCProbablyBad)
+		}
+	}
+
+	if flag < common.QCProbablyBad {
+		c.hasLast, c.lastAt, c.lastValue = true, at, value
+	}
+	return flag
+}
+
+// newQCCheckers returns the checkers of the items in info with a QC config or a range known by their device.
+func newQCCheckers(info common.StringMapMap, confs map[string]global.QC) (map[string]*qcChecker, error) {
+	itemNames := make(map[string]bool)
+	for _, items := range info {
+		for _, itemName := range items {
+			itemNames[itemName] = true
+		}
+	}
+	for itemName := range confs {
+		if !itemNames[itemName] {
+			return nil, fmt.Errorf("qc item %s is not provided by any device", itemName)
+		}
+	}
+
+	checkers := make(map[string]*qcChecker)
+	for itemName := range itemNames {
+		conf, ok := confs[itemName]
+		if _, hasRange := device.ItemRange(itemName); !ok && !hasRange {
+			continue
+		}
+		c, err := newQCChecker(itemName, conf)
+		if err != nil {
+			return nil, err
+		}
+		checkers[itemName] = c
+	}
+	return checkers, nil
+}
+
+// qualityFlag runs the QC chain of the item, values of items without one get QCNone.
+func qualityFlag(itemName string, at custype.UnixMs, value *float64) common.QCFlag {
+	c := qcCheckers[itemName]
+	if c == nil || value == nil {
+		return common.QCNone
+	}
+	return c.check(at, *value)
+}
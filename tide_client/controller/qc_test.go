@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"testing"
+	"tide/common"
+	"tide/pkg/custype"
+	"tide/tide_client/global"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_qcChecker(t *testing.T) {
+	c, err := newQCChecker("level", global.QC{Min: new(-1.0), Max: new(4.0), MaxRate: 0.01, MaxGapSec: 300})
+	require.NoError(t, err)
+
+	const minute = custype.UnixMs(60_000)
+	require.Equal(t, common.QCGood, c.check(0, 1.0))
+	require.Equal(t, common.QCGood, c.check(minute, 1.3))
+	require.Equal(t, common.QCProbablyBad, c.check(2*minute, 2.5), "a 1.2 m jump in a minute is a spike")
+	require.Equal(t, common.QCGood, c.check(3*minute, 1.4), "the rate is measured from the last value that was not flagged")
+	require.Equal(t, common.QCBad, c.check(4*minute, 9.9))
+	require.Equal(t, common.QCProbablyGood, c.check(20*minute, 3.0), "the first value after a gap skips the rate check")
+}
+
+func Test_qcCheckerFlatLine(t *testing.T) {
+	c, err := newQCChecker("level", global.QC{FlatLineCount: 3, FlatLineTolerance: 0.001})
+	require.NoError(t, err)
+
+	for i, want := range []common.QCFlag{common.QCGood, common.QCGood, common.QCProbablyBad, common.QCProbablyBad, common.QCGood} {
+		value := 1.5
+		if i == 1 {
+			value = 1.5005
+		}
+		if i == 4 {
+			value = 1.6
+		}
+		require.Equal(t, want, c.check(custype.UnixMs(i)*1000, value), "value %d", i)
+	}
+}
+
+func Test_newQCCheckers(t *testing.T) {
+	info := common.StringMapMap{"PLS-C": {"water_level": "level", "water_temperature": "temp"}}
+	checkers, err := newQCCheckers(info, map[string]global.QC{"level": {MaxRate: 0.1}})
+	require.NoError(t, err)
+	require.Contains(t, checkers, "level")
+	require.NotContains(t, checkers, "temp", "items without qc config or device range are not checked")
+
+	_, err = newQCCheckers(info, map[string]global.QC{"missing": {MaxRate: 0.1}})
+	require.ErrorContains(t, err, "not provided")
+	_, err = newQCCheckers(info, map[string]global.QC{"level": {Min: new(5.0), Max: new(1.0)}})
+	require.ErrorContains(t, err, "greater than max")
+}
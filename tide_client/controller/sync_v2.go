@@ -2,9 +2,11 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
 	"log/slog"
 
 	"tide/common"
+	internalsyncv2 "tide/internal/syncv2"
 	"tide/tide_client/syncv2"
 
 	"tide/pkg/pubsub"
@@ -13,6 +15,9 @@ import (
 	"tide/tide_client/global"
 )
 
+// unackedDataBatches is kept across sessions so realtime data the server never acknowledged is resent.
+var unackedDataBatches syncv2.UnackedBatches
+
 // runSyncV2ClientOnce attempts to run one v2 sync session.
 // Returns true if v2 is enabled and attempted (success or failure), false if v2 is disabled (caller should fall back to v1).
 func runSyncV2ClientOnce(dataBroker *pubsub.Broker) bool {
@@ -22,16 +27,31 @@ func runSyncV2ClientOnce(dataBroker *pubsub.Broker) bool {
 
 	addr := global.Config.SyncV2.Addr
 	ctx := context.Background()
+
+	var tlsConfig *tls.Config
+	if tlsCfg := global.Config.SyncV2.Tls; tlsCfg.CertFile != "" || tlsCfg.CaFile != "" {
+		var err error
+		// Loaded on every attempt so renewed certificates are picked up on reconnect.
+		if tlsConfig, err = internalsyncv2.ClientTLSConfig(tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.CaFile); err != nil {
+			slog.Error("invalid v2 sync tls config", "error", err)
+			return true
+		}
+	}
 	client, err := syncv2.NewClient(
 		syncv2.Config{
 			Addr:              addr,
 			StationIdentifier: global.Config.Identifier,
+			AuthKey:           global.Config.SyncV2.AuthKey,
+			TLS:               tlsConfig,
+			OutboxID:          outboxId,
 		},
 		syncv2.Deps{
 			StationInfoFn: func() common.StationInfoStruct {
 				return stationInfo
 			},
-			GetDataHistory:        db.GetDataHistory,
+			GetOutboxAfter:        db.GetOutboxAfter,
+			CountOutboxAfter:      db.CountOutboxAfter,
+			SaveStoredSeq:         db.SaveOutboxStoredSeq,
 			GetItemStatusLogAfter: db.GetItemStatusLogAfter,
 			Subscribe:             dataBroker.Subscribe,
 			Unsubscribe:           dataBroker.Unsubscribe,
@@ -43,7 +63,12 @@ func runSyncV2ClientOnce(dataBroker *pubsub.Broker) bool {
 				}
 				return cam.Snapshot, cam.Username, cam.Password, true
 			},
-			Snapshot: camera.OnvifSnapshot,
+			Snapshot:          camera.OnvifSnapshot,
+			Unacked:           &unackedDataBatches,
+			ApplyDeviceConfig: applyDeviceConfig,
+			DeviceCommand:     runDeviceCommand,
+			Logs:              global.LogBuffer,
+			ClockOffset:       onServerClockOffset,
 		},
 	)
 	if err != nil {
@@ -7,7 +7,9 @@ import (
 	"slices"
 )
 
-func CleanDBData(cutoffTime int64) {
+// CleanDBData deletes the data stored before cutoffTime. With keepUnsyncedOutbox, the outbox rows the
+// sync v2 server has not stored yet are kept, see DeleteOldOutbox.
+func CleanDBData(cutoffTime int64, keepUnsyncedOutbox bool) {
 	tables, err := GetAllTables(db)
 	if err != nil {
 		slog.Error("Failed to get database tables", "error", err)
@@ -28,6 +30,14 @@ func CleanDBData(cutoffTime int64) {
 			slog.Error("Error cleaning table", "table", table, "error", err)
 		}
 	}
+	if err = DeleteOldOutbox(cutoffTime, keepUnsyncedOutbox); err != nil {
+		slog.Error("Error cleaning data outbox", "error", err)
+	}
+}
+
+// CleanItemData deletes the data of one item before cutoffTime, for items kept shorter than the others.
+func CleanItemData(itemName string, cutoffTime int64) error {
+	return DeleteOldData(db, itemName, cutoffTime)
 }
 
 // GetAllTables retrieves all table names from the SQLite database.
@@ -69,8 +79,8 @@ func IsValidTable(db *sql.DB, tableName string) (bool, error) {
 		columns = append(columns, name)
 	}
 
-	// Validate columns
-	if slices.Equal(columns, []string{"timestamp", "value"}) {
+	// Validate columns, tables created before quality flags have no flag column until MakeSureTableExist adds it.
+	if slices.Equal(columns, []string{"timestamp", "value", "flag"}) || slices.Equal(columns, []string{"timestamp", "value"}) {
 		return true, nil
 	}
 	return false, nil
@@ -3,7 +3,9 @@ package db
 import (
 	"database/sql"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
 	"testing"
 	"tide/common"
 	"tide/tide_client/global"
@@ -40,9 +42,32 @@ func MakeSureTableExist(name string) (err error) {
 	_, err = db.Exec(`create table if not exists ` + name + `
 (
     timestamp int              not null,
-    value     double precision not null
+    value     double precision not null,
+    flag      int              not null default 0
 );
 create index if not exists ` + name + `_timestamp_index on ` + name + ` (timestamp);`)
+	if err != nil {
+		return err
+	}
+	// Tables created before quality flags existed get the column, their rows have no quality control.
+	return addColumnIfMissing(db, name, "flag", "int not null default 0")
+}
+
+// execQuerier is a *sql.DB or a *sql.Tx.
+type execQuerier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func addColumnIfMissing(q execQuerier, table, column, definition string) error {
+	var count int
+	if err := q.QueryRow("select count(*) from pragma_table_info(?) where name=?", table, column).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := q.Exec("alter" + " table " + table + " add column " + column + " " + definition)
 	return err
 }
 
@@ -93,6 +118,8 @@ func InitData(t *testing.T) {
 delete from item_status_log where true;
 drop table if exists item1;
 drop table if exists item2;
+drop table if exists data_outbox;
+drop table if exists outbox_meta;
 `)
 	require.NoError(t, err)
 
@@ -108,7 +135,12 @@ drop table if exists item2;
 			require.NoError(t, err)
 		}
 		tmp[data.ItemName] = struct{}{}
-		err = SaveData(data.ItemName, data.Value, data.Millisecond.ToInt64())
+	}
+	_, err = InitOutbox(slices.Collect(maps.Keys(tmp)))
+	require.NoError(t, err)
+	for i, data := range DataHis {
+		seq, err := SaveData(data.ItemName, data.Value, data.Flag, data.Millisecond.ToInt64())
 		require.NoError(t, err)
+		require.EqualValues(t, i+1, seq)
 	}
 }
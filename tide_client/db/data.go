@@ -5,19 +5,34 @@ import (
 	"tide/common"
 )
 
-func SaveData(itemName string, val float64, msec int64) error {
-	_, err := db.Exec("insert"+" into "+itemName+" (timestamp, value) VALUES (?,?)", msec, val)
-	return err
+// SaveData saves a data point with its quality flag to its item table and appends it to the outbox,
+// returning the outbox seq.
+func SaveData(itemName string, val float64, flag common.QCFlag, msec int64) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec("insert"+" into "+itemName+" (timestamp, value, flag) VALUES (?,?,?)", msec, val, flag); err != nil {
+		return 0, err
+	}
+	seq, err := checkResultLastInsertId(tx.Exec(`insert into data_outbox(item_name, timestamp, value, flag) values (?,?,?,?)`, itemName, msec, val, flag))
+	if err != nil {
+		return 0, err
+	}
+	return seq, tx.Commit()
 }
+
 func GetDataHistory(itemName string, start, end int64) ([]common.DataTimeStruct, error) {
 	var (
 		rows *sql.Rows
 		err  error
 	)
 	if end == 0 {
-		rows, err = db.Query("select"+" timestamp, value from "+itemName+" where timestamp>? order by timestamp", start)
+		rows, err = db.Query("select"+" timestamp, value, flag from "+itemName+" where timestamp>? order by timestamp", start)
 	} else {
-		rows, err = db.Query("select"+" timestamp, value from "+itemName+" where timestamp>? and timestamp<? order by timestamp", start, end)
+		rows, err = db.Query("select"+" timestamp, value, flag from "+itemName+" where timestamp>? and timestamp<? order by timestamp", start, end)
 	}
 	if err != nil {
 		return nil, err
@@ -28,7 +43,7 @@ func GetDataHistory(itemName string, start, end int64) ([]common.DataTimeStruct,
 		ds []common.DataTimeStruct
 	)
 	for rows.Next() {
-		err = rows.Scan(&d.Millisecond, &d.Value)
+		err = rows.Scan(&d.Millisecond, &d.Value, &d.Flag)
 		if err != nil {
 			return nil, err
 		}
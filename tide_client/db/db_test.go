@@ -3,6 +3,7 @@ package db
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 	"tide/common"
 )
@@ -112,6 +113,7 @@ func TestSaveData(t *testing.T) {
 	type args struct {
 		itemName string
 		val      float64
+		flag     common.QCFlag
 		msec     int64
 	}
 	tests := []struct {
@@ -120,10 +122,102 @@ func TestSaveData(t *testing.T) {
 		wantErr assert.ErrorAssertionFunc
 	}{
 		{name: "1", args: args{itemName: "item1", val: 1, msec: 200}, wantErr: assert.NoError},
+		{name: "flagged", args: args{itemName: "item1", val: 99, flag: common.QCBad, msec: 300}, wantErr: assert.NoError},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.wantErr(t, SaveData(tt.args.itemName, tt.args.val, tt.args.msec), fmt.Sprintf("SaveData(%v, %v, %v)", tt.args.itemName, tt.args.val, tt.args.msec))
+			_, err := SaveData(tt.args.itemName, tt.args.val, tt.args.flag, tt.args.msec)
+			tt.wantErr(t, err, fmt.Sprintf("SaveData(%v, %v, %v, %v)", tt.args.itemName, tt.args.val, tt.args.flag, tt.args.msec))
 		})
 	}
 }
+
+func TestOutbox(t *testing.T) {
+	InitData(t)
+
+	outboxId, err := InitOutbox([]string{"item1", "item2"})
+	require.NoError(t, err)
+	require.NotEmpty(t, outboxId)
+	again, err := InitOutbox([]string{"item1", "item2"})
+	require.NoError(t, err)
+	require.Equal(t, outboxId, again, "existing outbox must keep its id")
+
+	// Backfilled timestamps still get a new seq and are replayed after the cursor.
+	seq, err := SaveData("item1", 5, common.QCProbablyBad, 900)
+	require.NoError(t, err)
+	require.EqualValues(t, len(DataHis)+1, seq)
+
+	got, err := GetOutboxAfter(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, []common.SeqItemNameDataTimeStruct{
+		{Seq: 2, ItemNameDataTimeStruct: DataHis[1]},
+		{Seq: 3, ItemNameDataTimeStruct: common.ItemNameDataTimeStruct{ItemName: "item1", DataTimeStruct: common.DataTimeStruct{Value: 5, Millisecond: 900, Flag: common.QCProbablyBad}}},
+	}, got)
+
+	got, err = GetOutboxAfter(0, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	n, err := CountOutboxAfter(1)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+
+	// The backfilled point is old enough but not stored by the server yet.
+	require.NoError(t, SaveOutboxStoredSeq(2))
+	require.NoError(t, DeleteOldOutbox(1000, true))
+	got, err = GetOutboxAfter(0, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	require.NoError(t, SaveOutboxStoredSeq(3))
+	require.NoError(t, SaveOutboxStoredSeq(1), "the stored seq must not go back")
+	again, err = InitOutbox([]string{"item1", "item2"})
+	require.NoError(t, err)
+	require.Equal(t, outboxId, again)
+	require.NoError(t, DeleteOldOutbox(1000, true))
+	got, err = GetOutboxAfter(0, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+}
+
+func TestDeleteOldOutbox_WithoutSync(t *testing.T) {
+	InitData(t)
+	_, err := InitOutbox([]string{"item1", "item2"})
+	require.NoError(t, err)
+	_, err = SaveData("item1", 5, common.QCProbablyBad, 900)
+	require.NoError(t, err)
+
+	require.NoError(t, DeleteOldOutbox(1000, false))
+	got, err := GetOutboxAfter(0, 10)
+	require.NoError(t, err)
+	require.Len(t, got, len(DataHis))
+}
+
+func TestInitOutbox_SeedsExistingData(t *testing.T) {
+	InitData(t)
+	_, err := db.Exec(`drop table data_outbox; drop table outbox_meta;`)
+	require.NoError(t, err)
+
+	_, err = InitOutbox([]string{"item1", "item2"})
+	require.NoError(t, err)
+	got, err := GetOutboxAfter(0, 10)
+	require.NoError(t, err)
+	require.Len(t, got, len(DataHis))
+}
+
+func TestMakeSureTableExist_AddsFlag(t *testing.T) {
+	_, err := db.Exec(`drop table if exists old_item;
+create table old_item (timestamp int not null, value double precision not null);
+insert into old_item (timestamp, value) values (100, 1.5);`)
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = db.Exec(`drop table if exists old_item`) })
+
+	require.NoError(t, MakeSureTableExist("old_item"))
+	require.NoError(t, MakeSureTableExist("old_item"))
+	valid, err := IsValidTable(db, "old_item")
+	require.NoError(t, err)
+	require.True(t, valid)
+	got, err := GetDataHistory("old_item", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []common.DataTimeStruct{{Value: 1.5, Millisecond: 100, Flag: common.QCNone}}, got)
+}
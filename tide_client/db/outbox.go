@@ -0,0 +1,114 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"tide/common"
+
+	"github.com/google/uuid"
+)
+
+// The data outbox keeps a copy of every saved data point under a monotonic seq.
+// Sync V2 replays "everything after seq N" from it, so late or backfilled timestamps are never skipped.
+// The outbox id changes whenever the outbox is created, which tells the server to reset its cursor.
+// The outbox also keeps the newest seq the server is known to have stored, rows after it are not pruned.
+
+// InitOutbox makes sure the outbox exists and returns its id. A new outbox is seeded with the data
+// already in the item tables, so nothing stored before the upgrade is lost from replay.
+func InitOutbox(itemNames []string) (string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec(`create table if not exists data_outbox
+(
+    seq       integer primary key autoincrement,
+    item_name varchar          not null,
+    timestamp int              not null,
+    value     double precision not null,
+    flag      int              not null default 0
+);
+create index if not exists data_outbox_timestamp_index on data_outbox (timestamp);
+create table if not exists outbox_meta
+(
+    id         varchar not null,
+    stored_seq integer not null default 0
+);`); err != nil {
+		return "", err
+	}
+
+	if err = addColumnIfMissing(tx, "data_outbox", "flag", "int not null default 0"); err != nil {
+		return "", err
+	}
+	if err = addColumnIfMissing(tx, "outbox_meta", "stored_seq", "integer not null default 0"); err != nil {
+		return "", err
+	}
+
+	var outboxId string
+	err = tx.QueryRow(`select id from outbox_meta`).Scan(&outboxId)
+	if err == nil {
+		return outboxId, tx.Commit()
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	for _, name := range itemNames {
+		if _, err = tx.Exec("insert into data_outbox(item_name, timestamp, value, flag) select ?, timestamp, value, flag from "+name+" order by timestamp", name); err != nil {
+			return "", err
+		}
+	}
+	outboxId = uuid.NewString()
+	if _, err = tx.Exec(`insert into outbox_meta(id) values (?)`, outboxId); err != nil {
+		return "", err
+	}
+	return outboxId, tx.Commit()
+}
+
+func GetOutboxAfter(afterSeq int64, limit int) ([]common.SeqItemNameDataTimeStruct, error) {
+	rows, err := db.Query(`select seq, item_name, timestamp, value, flag from data_outbox where seq>? order by seq limit ?`, afterSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var (
+		d  common.SeqItemNameDataTimeStruct
+		ds []common.SeqItemNameDataTimeStruct
+	)
+	for rows.Next() {
+		err = rows.Scan(&d.Seq, &d.ItemName, &d.Millisecond, &d.Value, &d.Flag)
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// CountOutboxAfter returns the number of outbox rows after afterSeq.
+func CountOutboxAfter(afterSeq int64) (n int64, err error) {
+	err = db.QueryRow(`select count(*) from data_outbox where seq>?`, afterSeq).Scan(&n)
+	return n, err
+}
+
+// SaveOutboxStoredSeq records that the server stored the outbox rows up to seq. The recorded seq never goes back.
+func SaveOutboxStoredSeq(seq int64) error {
+	_, err := db.Exec(`update outbox_meta set stored_seq=? where stored_seq<?`, seq, seq)
+	return err
+}
+
+// DeleteOldOutbox deletes the outbox rows stamped before before. With keepUnsynced, only the rows the server
+// is known to have stored are deleted, the others are kept whatever their age until they are replayed.
+func DeleteOldOutbox(before int64, keepUnsynced bool) error {
+	if !keepUnsynced {
+		_, err := db.Exec(`delete from data_outbox where timestamp < ?`, before)
+		return err
+	}
+	_, err := db.Exec(`delete from data_outbox where seq <= (select coalesce(max(stored_seq), 0) from outbox_meta) and timestamp < ?`, before)
+	return err
+}
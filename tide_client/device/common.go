@@ -3,7 +3,9 @@ package device
 import (
 	"encoding/json"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"tide/common"
@@ -48,6 +50,34 @@ type SDI12Device interface {
 	NewSDI12Device(session *sdi12.Session, rawConf json.RawMessage) common.StringMapMap
 }
 
+// SDI12Discoverer is implemented by SDI-12 device models that sensor discovery can recognize from the aI! reply.
+type SDI12Discoverer interface {
+	MatchSDI12(id sdi12.Identification) bool
+	// DraftSDI12Config returns a config for the sensor at addr, its item names still need to be edited.
+	DraftSDI12Config(addr string) map[string]any
+}
+
+// MatchSDI12Model returns the registered model the identified sensor belongs to and a draft config for it.
+func MatchSDI12Model(id sdi12.Identification) (model string, draft map[string]any, ok bool) {
+	devicesMu.RLock()
+	defer devicesMu.RUnlock()
+
+	for _, name := range slices.Sorted(maps.Keys(devices)) {
+		if d, isDiscoverer := devices[name].(SDI12Discoverer); isDiscoverer && d.MatchSDI12(id) {
+			return name, d.DraftSDI12Config(id.Address), true
+		}
+	}
+	return "", nil, false
+}
+
+// IsSDI12Model reports whether name is a registered SDI-12 device model, which can be the model of a native SDI-12 bus.
+func IsSDI12Model(name string) bool {
+	devicesMu.RLock()
+	defer devicesMu.RUnlock()
+	_, ok := devices[name].(SDI12Device)
+	return ok
+}
+
 func MustBusDevice(name string) BusDevice {
 	device, ok := getRegisteredDevice(name).(BusDevice)
 	if !ok {
@@ -58,6 +88,34 @@ func MustBusDevice(name string) BusDevice {
 	return device
 }
 
+// busDispatcher is implemented by the transport models that only start the sub-devices in their config.
+type busDispatcher interface {
+	BusDevice
+	dispatchesBus()
+}
+
+// StartBusDevice starts model on bus. Polling models share the bus, a StreamDevice claims it
+// and a conflict between them stops the client at startup.
+func StartBusDevice(model string, bus *connWrap.Bus, rawConf json.RawMessage) common.StringMapMap {
+	switch d := getRegisteredDevice(model).(type) {
+	case StreamDevice:
+		if err := bus.Claim(model); err != nil {
+			slog.Error("Bus ownership conflict", "model", model, "error", err)
+			os.Exit(1)
+		}
+		return d.NewStreamDevice(&Stream{bus: bus, owner: model}, rawConf)
+	case busDispatcher:
+		return d.NewBusDevice(bus, rawConf)
+	default:
+		busDevice := MustBusDevice(model)
+		if err := bus.Share(model); err != nil {
+			slog.Error("Bus ownership conflict", "model", model, "error", err)
+			os.Exit(1)
+		}
+		return busDevice.NewBusDevice(bus, rawConf)
+	}
+}
+
 func MustI2CDevice(name string) I2CDevice {
 	device, ok := getRegisteredDevice(name).(I2CDevice)
 	if !ok {
@@ -98,8 +156,49 @@ type itemData struct {
 	Value    *float64
 }
 
+// ValueRange is the range a sensor can measure, an open bound is nil.
+type ValueRange struct {
+	Min *float64
+	Max *float64
+}
+
+// itemRanges holds the ValueRange of each item name whose device knows one.
+var itemRanges sync.Map
+
+// registerItemRanges records the range of the configured items, keyed by item type in ranges.
+// Values outside it are kept and flagged by the QC chain of the client rather than dropped here.
+func registerItemRanges(items map[string]string, ranges map[string]ValueRange) {
+	for itemType, itemName := range items {
+		if r, ok := ranges[itemType]; ok {
+			itemRanges.Store(itemName, r)
+		}
+	}
+}
+
+// ItemRange returns the range the device of itemName registered for it.
+func ItemRange(itemName string) (ValueRange, bool) {
+	r, ok := itemRanges.Load(itemName)
+	if !ok {
+		return ValueRange{}, false
+	}
+	return r.(ValueRange), true
+}
+
+// clockCorrection is added to the local clock to stamp data, see SetClockCorrection.
+var clockCorrection atomic.Int64
+
+// SetClockCorrection sets the offset added to the local clock to stamp data,
+// for stations whose clock is wrong and not synchronized by NTP.
+func SetClockCorrection(d time.Duration) {
+	clockCorrection.Store(int64(d))
+}
+
+func ClockCorrection() time.Duration {
+	return time.Duration(clockCorrection.Load())
+}
+
 func nowMs() custype.UnixMs {
-	return custype.ToUnixMs(time.Now())
+	return custype.ToUnixMs(time.Now().Add(ClockCorrection()))
 }
 
 func AddCronJob(cron string, items map[string]string, provideItems map[string]int, job func() map[string]*float64) {
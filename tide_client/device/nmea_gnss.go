@@ -0,0 +1,174 @@
+package device
+
+import (
+	"encoding/json"
+	"log/slog"
+	"tide/common"
+	"tide/pkg"
+	"tide/pkg/custype"
+	"tide/tide_client/protocol/nmea"
+	"time"
+)
+
+func init() {
+	RegisterDevice("NMEA-GNSS", &nmeaGNSS{})
+}
+
+// nmeaGNSS reads the NMEA 0183 sentences a GNSS receiver streams, it does not poll.
+type nmeaGNSS struct{}
+
+var _ StreamDevice = (*nmeaGNSS)(nil)
+
+var NMEAGNSSItems = map[string]int{
+	"gnss_latitude":           0,
+	"gnss_longitude":          1,
+	"gnss_antenna_height":     2,
+	"gnss_ellipsoidal_height": 3,
+	"gnss_fix_quality":        4,
+	"gnss_satellites":         5,
+	"gnss_hdop":               6,
+	"gnss_latitude_error":     7,
+	"gnss_longitude_error":    8,
+	"gnss_height_error":       9,
+}
+
+const defaultNMEAGNSSInterval = time.Minute
+
+func (nmeaGNSS) NewStreamDevice(stream *Stream, rawConf json.RawMessage) common.StringMapMap {
+	var conf struct {
+		DeviceName  string            `json:"device_name"`
+		IntervalSec float64           `json:"interval_sec"`
+		Items       map[string]string `json:"items"`
+	}
+	pkg.Must(json.Unmarshal(rawConf, &conf))
+	verifyItems(conf.Items, NMEAGNSSItems)
+	interval := defaultNMEAGNSSInterval
+	if conf.IntervalSec > 0 {
+		interval = time.Duration(conf.IntervalSec * float64(time.Second))
+	}
+	gnss := newNMEAGNSSStream(conf.Items, interval)
+	stream.Start([]byte("\n"), func(frame []byte) []itemData {
+		return gnss.handleLine(string(frame))
+	})
+	return common.StringMapMap{conf.DeviceName: conf.Items}
+}
+
+// nmeaGNSSStream collects the sentences of one epoch, which share the UTC time of day, and publishes
+// the epoch when the first sentence of the next one arrives.
+type nmeaGNSSStream struct {
+	items    map[string]string
+	interval time.Duration
+
+	// lastRMC is the last date and time the receiver sent, GGA and GST only carry the time of day.
+	lastRMC time.Time
+
+	hasEpoch bool
+	epochTOD time.Duration
+	gga      *nmea.GGA
+	gst      *nmea.GST
+
+	lastPublished time.Time
+}
+
+func newNMEAGNSSStream(items map[string]string, interval time.Duration) *nmeaGNSSStream {
+	return &nmeaGNSSStream{items: items, interval: interval}
+}
+
+func (s *nmeaGNSSStream) handleLine(line string) []itemData {
+	sentence, err := nmea.Parse(line)
+	if err != nil {
+		slog.Warn("Invalid NMEA sentence", "line", line, "error", err)
+		return nil
+	}
+	var data []itemData
+	switch sentence.Type {
+	case "GGA":
+		gga, err := nmea.ParseGGA(sentence)
+		if err != nil {
+			slog.Warn("Invalid GGA sentence", "line", line, "error", err)
+			return nil
+		}
+		data = s.startEpoch(gga.Time)
+		s.gga = &gga
+	case "GST":
+		gst, err := nmea.ParseGST(sentence)
+		if err != nil {
+			slog.Warn("Invalid GST sentence", "line", line, "error", err)
+			return nil
+		}
+		data = s.startEpoch(gst.Time)
+		s.gst = &gst
+	case "RMC":
+		rmc, err := nmea.ParseRMC(sentence)
+		if err != nil {
+			slog.Warn("Invalid RMC sentence", "line", line, "error", err)
+			return nil
+		}
+		if !rmc.Time.IsZero() {
+			s.lastRMC = rmc.Time
+		}
+	}
+	return data
+}
+
+// startEpoch publishes the collected epoch if tod starts a new one.
+func (s *nmeaGNSSStream) startEpoch(tod time.Duration) []itemData {
+	if s.hasEpoch && tod == s.epochTOD {
+		return nil
+	}
+	data := s.publish()
+	s.hasEpoch, s.epochTOD, s.gga, s.gst = true, tod, nil, nil
+	return data
+}
+
+func (s *nmeaGNSSStream) publish() []itemData {
+	if s.gga == nil {
+		return nil
+	}
+	at := s.epochTime()
+	if !s.lastPublished.IsZero() && at.Sub(s.lastPublished) < s.interval && at.After(s.lastPublished) {
+		return nil
+	}
+	s.lastPublished = at
+
+	values := make(map[string]*float64, len(NMEAGNSSItems))
+	values["gnss_fix_quality"] = new(float64(s.gga.FixQuality))
+	values["gnss_satellites"] = new(float64(s.gga.Satellites))
+	if s.gga.FixQuality > 0 {
+		values["gnss_latitude"] = s.gga.Latitude
+		values["gnss_longitude"] = s.gga.Longitude
+		values["gnss_antenna_height"] = s.gga.Altitude
+		values["gnss_hdop"] = s.gga.HDOP
+		if s.gga.Altitude != nil && s.gga.GeoidSeparation != nil {
+			values["gnss_ellipsoidal_height"] = new(*s.gga.Altitude + *s.gga.GeoidSeparation)
+		}
+		if s.gst != nil {
+			values["gnss_latitude_error"] = s.gst.LatitudeError
+			values["gnss_longitude_error"] = s.gst.LongitudeError
+			values["gnss_height_error"] = s.gst.AltitudeError
+		}
+	}
+
+	atMs := custype.ToUnixMs(at)
+	data := make([]itemData, 0, len(s.items))
+	for itemType, itemName := range s.items {
+		data = append(data, itemData{At: atMs, Typ: common.MsgData, ItemName: itemName, Value: values[itemType]})
+	}
+	return data
+}
+
+// epochTime stamps the epoch with the receiver time, or the local clock until an RMC gave the date.
+func (s *nmeaGNSSStream) epochTime() time.Time {
+	if s.lastRMC.IsZero() {
+		return time.Now().Add(ClockCorrection())
+	}
+	midnight := s.lastRMC.Truncate(24 * time.Hour)
+	at := midnight.Add(s.epochTOD)
+	// The epoch may be on the other side of midnight than the last RMC.
+	if d := at.Sub(s.lastRMC); d > 12*time.Hour {
+		at = at.Add(-24 * time.Hour)
+	} else if d < -12*time.Hour {
+		at = at.Add(24 * time.Hour)
+	}
+	return at
+}
@@ -0,0 +1,84 @@
+package device
+
+import (
+	"math"
+	"testing"
+	"tide/pkg/custype"
+	"time"
+)
+
+func nmeaValues(t *testing.T, data []itemData) map[string]*float64 {
+	t.Helper()
+
+	values := make(map[string]*float64, len(data))
+	for _, d := range data {
+		values[d.ItemName] = d.Value
+	}
+	return values
+}
+
+func TestNMEAGNSSStream(t *testing.T) {
+	t.Parallel()
+
+	items := map[string]string{
+		"gnss_latitude":           "lat",
+		"gnss_ellipsoidal_height": "h",
+		"gnss_fix_quality":        "fix",
+		"gnss_satellites":         "sats",
+		"gnss_height_error":       "h_err",
+	}
+	s := newNMEAGNSSStream(items, time.Minute)
+
+	for _, line := range []string{
+		"$GNRMC,235958.00,A,4807.038,N,01131.000,E,0.0,0.0,230394,,*20\r\n",
+		"$GNGGA,235958.00,4807.038,N,01131.000,E,4,12,0.7,10.5,M,46.9,M,,*4E\r\n",
+		"$GNGST,235958.00,0.006,0.023,0.020,273.6,0.011,0.012,0.021*4C\r\n",
+		// A corrupted sentence is dropped.
+		"$GNGST,235958.00,0.006,0.023,0.020,273.6,0.011,0.012,0.091*4C\r\n",
+	} {
+		if data := s.handleLine(line); data != nil {
+			t.Fatalf("handleLine(%q) = %v, want the epoch to be collected", line, data)
+		}
+	}
+
+	data := s.handleLine("$GNGGA,235959.00,4807.038,N,01131.000,E,4,12,0.7,10.6,M,46.9,M,,*4C\r\n")
+	if len(data) != len(items) {
+		t.Fatalf("len(data) = %d, want %d", len(data), len(items))
+	}
+	if want := custype.ToUnixMs(time.Date(1994, 3, 23, 23, 59, 58, 0, time.UTC)); data[0].At != want {
+		t.Fatalf("At = %v, want %v", data[0].At, want)
+	}
+	values := nmeaValues(t, data)
+	if values["lat"] == nil || math.Abs(*values["lat"]-(48+7.038/60)) > 1e-9 {
+		t.Fatalf("lat = %v", values["lat"])
+	}
+	if values["h"] == nil || math.Abs(*values["h"]-57.4) > 1e-9 {
+		t.Fatalf("ellipsoidal height = %v, want 57.4", values["h"])
+	}
+	if values["fix"] == nil || *values["fix"] != 4 || values["sats"] == nil || *values["sats"] != 12 {
+		t.Fatalf("fix = %v, sats = %v", values["fix"], values["sats"])
+	}
+	if values["h_err"] == nil || *values["h_err"] != 0.021 {
+		t.Fatalf("height error = %v, want 0.021", values["h_err"])
+	}
+
+	// The 23:59:59 epoch is within the interval of the published one.
+	if data = s.handleLine("$GNGGA,000058.00,,,,,0,03,,,M,,M,,*58\r\n"); data != nil {
+		t.Fatalf("epoch within the interval was published: %v", data)
+	}
+
+	data = s.handleLine("$GNGGA,000100.00,,,,,0,03,,,M,,M,,*54\r\n")
+	if len(data) != len(items) {
+		t.Fatalf("len(data) = %d, want %d", len(data), len(items))
+	}
+	if want := custype.ToUnixMs(time.Date(1994, 3, 24, 0, 0, 58, 0, time.UTC)); data[0].At != want {
+		t.Fatalf("At = %v, want %v after midnight", data[0].At, want)
+	}
+	values = nmeaValues(t, data)
+	if values["lat"] != nil || values["h"] != nil || values["h_err"] != nil {
+		t.Fatalf("values without fix = %v, want no position", values)
+	}
+	if values["fix"] == nil || *values["fix"] != 0 || values["sats"] == nil || *values["sats"] != 3 {
+		t.Fatalf("fix = %v, sats = %v", values["fix"], values["sats"])
+	}
+}
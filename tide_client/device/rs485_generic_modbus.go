@@ -0,0 +1,196 @@
+package device
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"tide/common"
+	"tide/pkg"
+	"tide/tide_client/connWrap"
+	"tide/tide_client/protocol/modbusrtu"
+)
+
+func init() {
+	RegisterDevice("GenericModbus", &genericModbus{})
+}
+
+// genericModbus reads the registers listed in the config, so a gauge only needs its register map.
+type genericModbus struct{}
+
+var _ BusDevice = (*genericModbus)(nil)
+
+const (
+	modbusFuncReadHoldingRegisters = 3
+	modbusFuncReadInputRegisters   = 4
+)
+
+// registerWords is the number of 16-bit registers of each data type.
+var registerWords = map[string]uint16{
+	"int16":   1,
+	"uint16":  1,
+	"int32":   2,
+	"uint32":  2,
+	"float32": 2,
+	"float64": 4,
+}
+
+type genericModbusRegister struct {
+	Function  byte     `json:"function"`
+	Address   uint16   `json:"address"`
+	Type      string   `json:"type"`
+	WordOrder string   `json:"word_order"`
+	ByteOrder string   `json:"byte_order"`
+	Scale     *float64 `json:"scale"`
+	Offset    float64  `json:"offset"`
+	ItemType  string   `json:"item_type"`
+	ItemName  string   `json:"item_name"`
+}
+
+type genericModbusConfig struct {
+	DeviceName string                  `json:"device_name"`
+	Addr       byte                    `json:"addr"`
+	Framing    string                  `json:"framing"`
+	Cron       string                  `json:"cron"`
+	Registers  []genericModbusRegister `json:"registers"`
+}
+
+func (c *genericModbusConfig) validate() error {
+	if c.DeviceName == "" {
+		return errors.New("device_name cannot be empty")
+	}
+	if c.Framing != "" && c.Framing != "rtu" && c.Framing != "tcp" {
+		return fmt.Errorf("unsupported framing %q", c.Framing)
+	}
+	if len(c.Registers) == 0 {
+		return errors.New("registers cannot be empty")
+	}
+	itemTypes := make(map[string]bool, len(c.Registers))
+	for _, r := range c.Registers {
+		if r.Function != modbusFuncReadHoldingRegisters && r.Function != modbusFuncReadInputRegisters {
+			return fmt.Errorf("unsupported function %d of register %d", r.Function, r.Address)
+		}
+		if _, ok := registerWords[r.Type]; !ok {
+			return fmt.Errorf("unsupported type %q of register %d", r.Type, r.Address)
+		}
+		if !validByteOrder(r.WordOrder) || !validByteOrder(r.ByteOrder) {
+			return fmt.Errorf("word_order and byte_order of register %d must be big or little", r.Address)
+		}
+		if r.ItemType == "" || r.ItemName == "" {
+			return fmt.Errorf("item_type and item_name of register %d cannot be empty", r.Address)
+		}
+		if itemTypes[r.ItemType] {
+			return fmt.Errorf("duplicate item_type %s", r.ItemType)
+		}
+		itemTypes[r.ItemType] = true
+	}
+	return nil
+}
+
+func validByteOrder(order string) bool {
+	return order == "" || order == "big" || order == "little"
+}
+
+func (c *genericModbusConfig) items() (items map[string]string, provideItems map[string]int) {
+	items = make(map[string]string, len(c.Registers))
+	provideItems = make(map[string]int, len(c.Registers))
+	for i, r := range c.Registers {
+		items[r.ItemType] = r.ItemName
+		provideItems[r.ItemType] = i
+	}
+	return items, provideItems
+}
+
+// decodeModbusRegister decodes the register payload and applies scale and offset.
+// A little word order puts the least significant register first, a little byte order swaps the
+// two bytes of each register.
+func decodeModbusRegister(r genericModbusRegister, results []byte) (float64, error) {
+	words := registerWords[r.Type]
+	if len(results) != int(words)*2 {
+		return 0, fmt.Errorf("unexpected payload length %d for %s", len(results), r.Type)
+	}
+	buf := make([]byte, len(results))
+	for i := 0; i < int(words); i++ {
+		src := results[i*2 : i*2+2]
+		if r.WordOrder == "little" {
+			src = results[(int(words)-1-i)*2 : (int(words)-i)*2]
+		}
+		if r.ByteOrder == "little" {
+			buf[i*2], buf[i*2+1] = src[1], src[0]
+		} else {
+			buf[i*2], buf[i*2+1] = src[0], src[1]
+		}
+	}
+
+	var raw float64
+	switch r.Type {
+	case "int16":
+		raw = float64(int16(binary.BigEndian.Uint16(buf)))
+	case "uint16":
+		raw = float64(binary.BigEndian.Uint16(buf))
+	case "int32":
+		raw = float64(int32(binary.BigEndian.Uint32(buf)))
+	case "uint32":
+		raw = float64(binary.BigEndian.Uint32(buf))
+	case "float32":
+		raw = Float32To64(math.Float32frombits(binary.BigEndian.Uint32(buf)))
+	case "float64":
+		raw = math.Float64frombits(binary.BigEndian.Uint64(buf))
+	}
+	if math.IsNaN(raw) || math.IsInf(raw, 0) {
+		return 0, fmt.Errorf("invalid %s value %v", r.Type, raw)
+	}
+	if r.Scale != nil {
+		raw *= *r.Scale
+	}
+	return raw + r.Offset, nil
+}
+
+func (d *genericModbus) NewBusDevice(bus *connWrap.Bus, rawConf json.RawMessage) common.StringMapMap {
+	var conf genericModbusConfig
+	pkg.Must(json.Unmarshal(rawConf, &conf))
+	if err := conf.validate(); err != nil {
+		slog.Error("Invalid GenericModbus config", "device", conf.DeviceName, "error", err)
+		os.Exit(1)
+	}
+
+	var session *modbusrtu.Session
+	if conf.Framing == "tcp" {
+		session = modbusrtu.NewTCPSession(bus, conf.Addr)
+	} else {
+		session = modbusrtu.NewSession(bus, conf.Addr)
+	}
+	var job = func() map[string]*float64 {
+		data := make(map[string]*float64, len(conf.Registers))
+		for _, r := range conf.Registers {
+			var (
+				results []byte
+				err     error
+			)
+			if r.Function == modbusFuncReadInputRegisters {
+				results, err = session.ReadInputRegisters(r.Address, registerWords[r.Type])
+			} else {
+				results, err = session.ReadHoldingRegisters(r.Address, registerWords[r.Type])
+			}
+			if err != nil {
+				slog.Error("Error reading registers from Modbus device", "device", conf.DeviceName, "addr", conf.Addr, "register", r.Address, "error", err)
+				data[r.ItemType] = nil
+				continue
+			}
+			value, err := decodeModbusRegister(r, results)
+			if err != nil {
+				slog.Error("Invalid register payload from Modbus device", "device", conf.DeviceName, "addr", conf.Addr, "register", r.Address, "results", results, "error", err)
+				data[r.ItemType] = nil
+				continue
+			}
+			data[r.ItemType] = &value
+		}
+		return data
+	}
+	items, provideItems := conf.items()
+	AddCronJob(conf.Cron, items, provideItems, job)
+	return common.StringMapMap{conf.DeviceName: items}
+}
@@ -0,0 +1,87 @@
+package device
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestDecodeModbusRegister(t *testing.T) {
+	t.Parallel()
+
+	scale := 0.01
+	tests := []struct {
+		name    string
+		reg     genericModbusRegister
+		payload []byte
+		want    float64
+	}{
+		{name: "int16", reg: genericModbusRegister{Type: "int16"}, payload: []byte{0xFF, 0xFE}, want: -2},
+		{name: "uint16Scaled", reg: genericModbusRegister{Type: "uint16", Scale: &scale, Offset: 1}, payload: []byte{0x04, 0xD2}, want: 13.34},
+		{name: "int32", reg: genericModbusRegister{Type: "int32"}, payload: []byte{0xFF, 0xFF, 0xFF, 0x9C}, want: -100},
+		{name: "uint32WordSwap", reg: genericModbusRegister{Type: "uint32", WordOrder: "little"}, payload: []byte{0x00, 0x01, 0x00, 0x02}, want: 0x00020001},
+		{name: "float32", reg: genericModbusRegister{Type: "float32"}, payload: []byte{0x40, 0x49, 0x0F, 0xDB}, want: 3.1415927},
+		{name: "float32ByteSwap", reg: genericModbusRegister{Type: "float32", ByteOrder: "little"}, payload: []byte{0x49, 0x40, 0xDB, 0x0F}, want: 3.1415927},
+		{name: "float32LittleEndian", reg: genericModbusRegister{Type: "float32", WordOrder: "little", ByteOrder: "little"}, payload: []byte{0xDB, 0x0F, 0x49, 0x40}, want: 3.1415927},
+		{name: "float64", reg: genericModbusRegister{Type: "float64"}, payload: []byte{0x40, 0x09, 0x21, 0xFB, 0x54, 0x44, 0x2D, 0x18}, want: math.Pi},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := decodeModbusRegister(tt.reg, tt.payload)
+			if err != nil {
+				t.Fatalf("decodeModbusRegister returned error: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("decodeModbusRegister(%v) = %v, want %v", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeModbusRegisterRejectsBadPayload(t *testing.T) {
+	t.Parallel()
+
+	if _, err := decodeModbusRegister(genericModbusRegister{Type: "float32"}, []byte{0x01, 0x02}); err == nil {
+		t.Fatal("decodeModbusRegister should reject payloads shorter than the type")
+	}
+	if _, err := decodeModbusRegister(genericModbusRegister{Type: "float32"}, []byte{0x7F, 0xC0, 0x00, 0x00}); err == nil {
+		t.Fatal("decodeModbusRegister should reject NaN")
+	}
+}
+
+func TestGenericModbusConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{name: "valid", raw: `{"device_name":"radar","addr":1,"framing":"tcp","registers":[{"function":4,"address":2000,"type":"float32","item_type":"water_distance","item_name":"wd"}]}`},
+		{name: "badFunction", raw: `{"device_name":"radar","addr":1,"registers":[{"function":6,"address":0,"type":"int16","item_type":"water_distance","item_name":"wd"}]}`, wantErr: true},
+		{name: "badType", raw: `{"device_name":"radar","addr":1,"registers":[{"function":3,"address":0,"type":"int8","item_type":"water_distance","item_name":"wd"}]}`, wantErr: true},
+		{name: "badOrder", raw: `{"device_name":"radar","addr":1,"registers":[{"function":3,"address":0,"type":"int32","word_order":"mid","item_type":"water_distance","item_name":"wd"}]}`, wantErr: true},
+		{name: "badFraming", raw: `{"device_name":"radar","addr":1,"framing":"ascii","registers":[{"function":3,"address":0,"type":"int16","item_type":"water_distance","item_name":"wd"}]}`, wantErr: true},
+		{name: "duplicateItemType", raw: `{"device_name":"radar","addr":1,"registers":[{"function":3,"address":0,"type":"int16","item_type":"water_distance","item_name":"a"},{"function":3,"address":1,"type":"int16","item_type":"water_distance","item_name":"b"}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var conf genericModbusConfig
+			if err := json.Unmarshal([]byte(tt.raw), &conf); err != nil {
+				t.Fatalf("json.Unmarshal returned error: %v", err)
+			}
+			err := conf.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
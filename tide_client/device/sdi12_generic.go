@@ -0,0 +1,167 @@
+package device
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"tide/common"
+	"tide/pkg"
+	"tide/tide_client/connWrap"
+	"tide/tide_client/protocol/sdi12"
+)
+
+func init() {
+	RegisterDevice("GenericSDI12", &genericSDI12{})
+}
+
+// genericSDI12 reads any standards-compliant SDI-12 sensor, the measurement command and the meaning
+// of each returned value come from the config.
+type genericSDI12 struct{}
+
+var (
+	_ BusDevice   = (*genericSDI12)(nil)
+	_ SDI12Device = (*genericSDI12)(nil)
+)
+
+var genericSDI12CommandRe = regexp.MustCompile(`^(MC?[1-9]?|CC?[1-9]?|RC?[0-9])$`)
+
+type genericSDI12Value struct {
+	Index      int      `json:"index"`
+	ItemType   string   `json:"item_type"`
+	ItemName   string   `json:"item_name"`
+	Min        *float64 `json:"min"`
+	Max        *float64 `json:"max"`
+	Correction float64  `json:"correction"`
+}
+
+type genericSDI12Config struct {
+	DeviceName    string              `json:"device_name"`
+	Addr          string              `json:"addr"`
+	ExtraWakeTime byte                `json:"extra_wake_time"`
+	Cron          string              `json:"cron"`
+	Command       string              `json:"command"`
+	ValueCount    int                 `json:"value_count"`
+	Values        []genericSDI12Value `json:"values"`
+}
+
+func (c *genericSDI12Config) validate() error {
+	if c.DeviceName == "" {
+		return errors.New("device_name cannot be empty")
+	}
+	if len(c.Addr) != 1 {
+		return fmt.Errorf("invalid addr %q", c.Addr)
+	}
+	if !genericSDI12CommandRe.MatchString(c.Command) {
+		return fmt.Errorf("unsupported command %q", c.Command)
+	}
+	if c.ValueCount <= 0 {
+		return errors.New("value_count must be positive")
+	}
+	if len(c.Values) == 0 {
+		return errors.New("values cannot be empty")
+	}
+	itemTypes := make(map[string]bool, len(c.Values))
+	for _, v := range c.Values {
+		if v.Index < 0 || v.Index >= c.ValueCount {
+			return fmt.Errorf("index %d of %s is out of range", v.Index, v.ItemType)
+		}
+		if v.ItemType == "" || v.ItemName == "" {
+			return fmt.Errorf("item_type and item_name of index %d cannot be empty", v.Index)
+		}
+		if itemTypes[v.ItemType] {
+			return fmt.Errorf("duplicate item_type %s", v.ItemType)
+		}
+		itemTypes[v.ItemType] = true
+		if v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+			return fmt.Errorf("min of %s is greater than max", v.ItemType)
+		}
+	}
+	return nil
+}
+
+func (c *genericSDI12Config) items() (items map[string]string, provideItems map[string]int) {
+	items = make(map[string]string, len(c.Values))
+	provideItems = make(map[string]int, len(c.Values))
+	for _, v := range c.Values {
+		items[v.ItemType] = v.ItemName
+		provideItems[v.ItemType] = v.Index
+	}
+	return items, provideItems
+}
+
+// ranges returns [min, max] of the values keyed by item type, the QC chain flags the values outside it.
+func (c *genericSDI12Config) ranges() map[string]ValueRange {
+	ranges := make(map[string]ValueRange, len(c.Values))
+	for _, v := range c.Values {
+		if v.Min != nil || v.Max != nil {
+			ranges[v.ItemType] = ValueRange{Min: v.Min, Max: v.Max}
+		}
+	}
+	return ranges
+}
+
+// mapValues applies the correction to the values.
+func (c *genericSDI12Config) mapValues(values []*float64) map[string]*float64 {
+	data := make(map[string]*float64, len(c.Values))
+	for _, v := range c.Values {
+		if v.Index >= len(values) || values[v.Index] == nil {
+			data[v.ItemType] = nil
+			continue
+		}
+		data[v.ItemType] = new(*values[v.Index] + v.Correction)
+	}
+	return data
+}
+
+func (c *genericSDI12Config) read(session *sdi12.Session) ([]*float64, error) {
+	if c.Command[0] == 'R' {
+		values, err := session.ContinuousMeasurement(c.Addr, c.ExtraWakeTime, c.Command)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != c.ValueCount {
+			return nil, fmt.Errorf("sensor returned %d values, want %d", len(values), c.ValueCount)
+		}
+		return values, nil
+	}
+	count, err := session.Measure(c.Addr, c.ExtraWakeTime, c.Command)
+	if err != nil {
+		return nil, err
+	}
+	if count != c.ValueCount {
+		return nil, fmt.Errorf("sensor announced %d values, want %d", count, c.ValueCount)
+	}
+	if sdi12.HasCRC(c.Command) {
+		return session.GetDataCRC(c.Addr, c.ExtraWakeTime, count)
+	}
+	return session.GetData(c.Addr, c.ExtraWakeTime, count)
+}
+
+func (d *genericSDI12) NewBusDevice(bus *connWrap.Bus, rawConf json.RawMessage) common.StringMapMap {
+	return d.NewSDI12Device(sdi12.NewSession(bus, sdi12.ModeNative), rawConf)
+}
+
+func (d *genericSDI12) NewSDI12Device(session *sdi12.Session, rawConf json.RawMessage) common.StringMapMap {
+	var conf genericSDI12Config
+	pkg.Must(json.Unmarshal(rawConf, &conf))
+	if err := conf.validate(); err != nil {
+		slog.Error("Invalid GenericSDI12 config", "device", conf.DeviceName, "error", err)
+		os.Exit(1)
+	}
+
+	var job = func() map[string]*float64 {
+		values, err := conf.read(session)
+		if err != nil {
+			slog.Error("Failed to read SDI-12 values", "device", conf.DeviceName, "addr", conf.Addr, "command", conf.Command, "error", err)
+			return nil
+		}
+		return conf.mapValues(values)
+	}
+	items, provideItems := conf.items()
+	AddCronJob(conf.Cron, items, provideItems, job)
+	registerItemRanges(items, conf.ranges())
+	return common.StringMapMap{conf.DeviceName: items}
+}
@@ -0,0 +1,83 @@
+package device
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func parseGenericSDI12Config(t *testing.T, raw string) genericSDI12Config {
+	t.Helper()
+
+	var conf genericSDI12Config
+	if err := json.Unmarshal([]byte(raw), &conf); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	return conf
+}
+
+func TestGenericSDI12ConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{name: "concurrent", raw: `{"device_name":"PLS-C","addr":"1","command":"C","value_count":5,"values":[{"index":0,"item_type":"water_level","item_name":"location1_water_level","min":-1,"max":4}]}`},
+		{name: "continuous", raw: `{"device_name":"probe","addr":"a","command":"R0","value_count":1,"values":[{"index":0,"item_type":"water_level","item_name":"wl"}]}`},
+		{name: "crc", raw: `{"device_name":"probe","addr":"0","command":"MC1","value_count":1,"values":[{"index":0,"item_type":"water_level","item_name":"wl"}]}`},
+		{name: "unsupportedCommand", raw: `{"device_name":"probe","addr":"0","command":"V","value_count":1,"values":[{"index":0,"item_type":"water_level","item_name":"wl"}]}`, wantErr: true},
+		{name: "indexOutOfRange", raw: `{"device_name":"probe","addr":"0","command":"M","value_count":1,"values":[{"index":1,"item_type":"water_level","item_name":"wl"}]}`, wantErr: true},
+		{name: "duplicateItemType", raw: `{"device_name":"probe","addr":"0","command":"M","value_count":2,"values":[{"index":0,"item_type":"water_level","item_name":"a"},{"index":1,"item_type":"water_level","item_name":"b"}]}`, wantErr: true},
+		{name: "minGreaterThanMax", raw: `{"device_name":"probe","addr":"0","command":"M","value_count":1,"values":[{"index":0,"item_type":"water_level","item_name":"wl","min":5,"max":1}]}`, wantErr: true},
+		{name: "badAddr", raw: `{"device_name":"probe","addr":"01","command":"M","value_count":1,"values":[{"index":0,"item_type":"water_level","item_name":"wl"}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := parseGenericSDI12Config(t, tt.raw)
+			err := conf.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenericSDI12ConfigMapValues(t *testing.T) {
+	t.Parallel()
+
+	conf := parseGenericSDI12Config(t, `{"device_name":"probe","addr":"0","command":"C","value_count":3,"values":[
+		{"index":2,"item_type":"water_level","item_name":"wl","min":-1,"max":4,"correction":0.5},
+		{"index":0,"item_type":"water_temperature","item_name":"wt","min":-8,"max":40},
+		{"index":1,"item_type":"water_salinity","item_name":"ws"}
+	]}`)
+	f := func(v float64) *float64 { return &v }
+
+	data := conf.mapValues([]*float64{f(50), f(35.1), f(1.25)})
+	if data["water_level"] == nil || *data["water_level"] != 1.75 {
+		t.Fatalf("water_level = %v, want 1.75", data["water_level"])
+	}
+	if v := data["water_temperature"]; v == nil || *v != 50 {
+		t.Fatalf("water_temperature = %v, want the out of range value kept for the QC chain", v)
+	}
+	if data["water_salinity"] == nil || *data["water_salinity"] != 35.1 {
+		t.Fatalf("water_salinity = %v, want 35.1", data["water_salinity"])
+	}
+
+	ranges := conf.ranges()
+	if r, ok := ranges["water_temperature"]; !ok || *r.Min != -8 || *r.Max != 40 {
+		t.Fatalf("ranges()[water_temperature] = %+v", r)
+	}
+	if _, ok := ranges["water_salinity"]; ok {
+		t.Fatal("ranges() has a range for a value without min and max")
+	}
+
+	items, provideItems := conf.items()
+	if items["water_level"] != "wl" || provideItems["water_level"] != 2 {
+		t.Fatalf("items() = %v, %v", items, provideItems)
+	}
+}
@@ -3,6 +3,7 @@ package device
 import (
 	"encoding/json"
 	"log/slog"
+	"strings"
 	"tide/common"
 	"tide/pkg"
 	"tide/tide_client/connWrap"
@@ -11,40 +12,40 @@ import (
 )
 
 func init() {
-	RegisterDevice("PLS-C", &plsC{
-		minLevel: -1.0,
-		maxLevel: 4.0,
-		minTemp:  -8.0,
-		maxTemp:  40.0,
-		minCond:  0.1,
-		maxCond:  100.0,
-		minSal:   10.0,
-		maxSal:   50.0,
-		minTDS:   10.0,
-		maxTDS:   50.0,
-	})
+	RegisterDevice("PLS-C", &plsC{ranges: map[string]ValueRange{
+		"water_level":                  {Min: new(-1.0), Max: new(4.0)},
+		"water_temperature":            {Min: new(-8.0), Max: new(40.0)},
+		"water_conductivity":           {Min: new(0.1), Max: new(100.0)},
+		"water_salinity":               {Min: new(10.0), Max: new(50.0)},
+		"water_total_dissolved_solids": {Min: new(10.0), Max: new(50.0)},
+	}})
 }
 
 type plsC struct {
-	minLevel float64
-	maxLevel float64
-	minTemp  float64
-	maxTemp  float64
-	minCond  float64
-	maxCond  float64
-	minSal   float64
-	maxSal   float64
-	minTDS   float64
-	maxTDS   float64
+	// ranges are keyed by item type.
+	ranges map[string]ValueRange
 }
 
 var (
-	_ BusDevice   = (*plsC)(nil)
-	_ SDI12Device = (*plsC)(nil)
+	_ BusDevice       = (*plsC)(nil)
+	_ SDI12Device     = (*plsC)(nil)
+	_ SDI12Discoverer = (*plsC)(nil)
 )
 
 var PLSCItems = map[string]int{"water_level": 0, "water_temperature": 1, "water_conductivity": 2, "water_salinity": 3, "water_total_dissolved_solids": 4}
 
+func (d *plsC) MatchSDI12(id sdi12.Identification) bool {
+	return strings.HasPrefix(id.Vendor, "OTT") && strings.Contains(strings.ToUpper(id.Model), "PLS")
+}
+
+func (d *plsC) DraftSDI12Config(addr string) map[string]any {
+	items := make(map[string]string, len(PLSCItems))
+	for itemType := range PLSCItems {
+		items[itemType] = itemType + "_pls_c_" + addr
+	}
+	return map[string]any{"device_name": "PLS-C_" + addr, "addr": addr, "extra_wake_time": 0, "cron": "0 * * * * *", "items": items}
+}
+
 func (d *plsC) NewBusDevice(bus *connWrap.Bus, rawConf json.RawMessage) common.StringMapMap {
 	return d.NewSDI12Device(sdi12.NewSession(bus, sdi12.ModeNative), rawConf)
 }
@@ -76,54 +77,15 @@ func (d *plsC) NewSDI12Device(session *sdi12.Session, rawConf json.RawMessage) m
 			return nil
 		}
 
-		if values[0] != nil {
-			if d.minLevel < *values[0] && *values[0] < d.maxLevel {
-				tmpData["water_level"] = values[0]
-			} else {
-				tmpData["water_level"] = nil
-			}
-		} else {
-			tmpData["water_level"] = nil
-		}
-		if values[1] != nil {
-			if d.minTemp < *values[1] && *values[1] < d.maxTemp {
-				tmpData["water_temperature"] = values[1]
-			} else {
-				tmpData["water_temperature"] = nil
-			}
-		} else {
-			tmpData["water_temperature"] = nil
-		}
-		if values[2] != nil {
-			if d.minCond < *values[2] && *values[2] < d.maxCond {
-				tmpData["water_conductivity"] = values[2]
-			} else {
-				tmpData["water_conductivity"] = nil
-			}
-		} else {
-			tmpData["water_conductivity"] = nil
-		}
-		if values[3] != nil {
-			if d.minSal < *values[3] && *values[3] < d.maxSal {
-				tmpData["water_salinity"] = values[3]
-			} else {
-				tmpData["water_salinity"] = nil
-			}
-		} else {
-			tmpData["water_salinity"] = nil
-		}
-		if values[4] != nil {
-			if d.minTDS < *values[4] && *values[4] < d.maxTDS {
-				tmpData["water_total_dissolved_solids"] = values[4]
-			} else {
-				tmpData["water_total_dissolved_solids"] = nil
-			}
-		} else {
-			tmpData["water_total_dissolved_solids"] = nil
-		}
+		tmpData["water_level"] = values[0]
+		tmpData["water_temperature"] = values[1]
+		tmpData["water_conductivity"] = values[2]
+		tmpData["water_salinity"] = values[3]
+		tmpData["water_total_dissolved_solids"] = values[4]
 		return tmpData
 	}
 	AddCronJob(conf.Cron, conf.Items, PLSCItems, job)
+	registerItemRanges(conf.Items, d.ranges)
 	return common.StringMapMap{conf.DeviceName: conf.Items}
 }
 
@@ -3,6 +3,7 @@ package device
 import (
 	"encoding/json"
 	"log/slog"
+	"strings"
 	"tide/common"
 	"tide/pkg"
 	"tide/tide_client/connWrap"
@@ -11,22 +12,27 @@ import (
 )
 
 func init() {
-	RegisterDevice("SE200", &se200{
-		minLevel: -30.0,
-		maxLevel: 30.0,
-	})
+	RegisterDevice("SE200", &se200{levelRange: ValueRange{Min: new(-30.0), Max: new(30.0)}})
 }
 
 type se200 struct {
-	minLevel float64
-	maxLevel float64
+	levelRange ValueRange
 }
 
 var (
-	_ BusDevice   = (*se200)(nil)
-	_ SDI12Device = (*se200)(nil)
+	_ BusDevice       = (*se200)(nil)
+	_ SDI12Device     = (*se200)(nil)
+	_ SDI12Discoverer = (*se200)(nil)
 )
 
+func (d *se200) MatchSDI12(id sdi12.Identification) bool {
+	return strings.HasPrefix(id.Vendor, "OTT") && strings.Contains(strings.ToUpper(id.Model), "SE200")
+}
+
+func (d *se200) DraftSDI12Config(addr string) map[string]any {
+	return map[string]any{"device_name": "SE200_" + addr, "addr": addr, "extra_wake_time": 0, "cron": "0 * * * * *", "item_name": "water_level_shaft_" + addr, "correction": 0}
+}
+
 func (d *se200) NewBusDevice(bus *connWrap.Bus, rawConf json.RawMessage) common.StringMapMap {
 	return d.NewSDI12Device(sdi12.NewSession(bus, sdi12.ModeNative), rawConf)
 }
@@ -59,15 +65,12 @@ func (d *se200) NewSDI12Device(session *sdi12.Session, rawConf json.RawMessage)
 			return nil
 		}
 		if values[0] != nil {
-			val := *values[0] + conf.Correction
-			if d.minLevel < val && val < d.maxLevel {
-				return &val
-			}
-
-			return nil
+			return new(*values[0] + conf.Correction)
 		}
 		return nil
 	}
 	AddCronJobWithOneItem(conf.Cron, conf.ItemName, job)
-	return map[string]map[string]string{conf.DeviceName: {"water_distance": conf.ItemName}}
+	items := map[string]string{"water_distance": conf.ItemName}
+	registerItemRanges(items, map[string]ValueRange{"water_distance": d.levelRange})
+	return map[string]map[string]string{conf.DeviceName: items}
 }
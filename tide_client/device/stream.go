@@ -0,0 +1,83 @@
+package device
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"tide/common"
+	"tide/tide_client/connWrap"
+	"time"
+)
+
+// StreamDevice is implemented by device models that send data without being polled.
+// Such a device owns its bus, it must be the only device on it.
+type StreamDevice interface {
+	NewStreamDevice(stream *Stream, rawConf json.RawMessage) common.StringMapMap
+}
+
+// maxFrameSize bounds a frame, the bytes read without finding the delimiter are dropped past it.
+const maxFrameSize = 4096
+
+// Stream is a bus claimed by one StreamDevice.
+type Stream struct {
+	bus   *connWrap.Bus
+	owner string
+}
+
+// Start reads the frames ending with delimiter in the background and sends the data handle returns for them.
+func (s *Stream) Start(delimiter []byte, handle func(frame []byte) []itemData) {
+	go func() {
+		// The owner never gives the bus back.
+		s.bus.Lock()
+		frames := newFrameReader(s.bus, delimiter)
+		for {
+			frame, err := frames.next()
+			if err != nil {
+				if !errors.Is(err, connWrap.ErrTimeout) {
+					slog.Error("Failed to read stream", "device", s.owner, "error", err)
+					// A partial frame from before a reconnect would corrupt the next one.
+					frames.buf = frames.buf[:0]
+					time.Sleep(time.Second)
+				}
+				continue
+			}
+			if data := handle(frame); len(data) > 0 {
+				DataReceive <- data
+			}
+		}
+	}()
+}
+
+// frameReader splits a byte stream into frames, the bytes read before an error are kept for the next call.
+type frameReader struct {
+	r         io.Reader
+	delimiter []byte
+	buf       []byte
+	chunk     []byte
+}
+
+func newFrameReader(r io.Reader, delimiter []byte) *frameReader {
+	return &frameReader{r: r, delimiter: delimiter, chunk: make([]byte, 512)}
+}
+
+// next returns the next frame without its delimiter.
+func (f *frameReader) next() ([]byte, error) {
+	for {
+		if i := bytes.Index(f.buf, f.delimiter); i >= 0 {
+			frame := bytes.Clone(f.buf[:i])
+			f.buf = f.buf[i+len(f.delimiter):]
+			return frame, nil
+		}
+		if len(f.buf) > maxFrameSize {
+			slog.Warn("Stream frame too long, dropped", "size", len(f.buf))
+			f.buf = f.buf[:0]
+		}
+		n, err := f.r.Read(f.chunk)
+		f.buf = append(f.buf, f.chunk[:n]...)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
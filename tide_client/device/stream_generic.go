@@ -0,0 +1,154 @@
+package device
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"tide/common"
+	"tide/pkg"
+	"tide/pkg/custype"
+	"time"
+)
+
+func init() {
+	RegisterDevice("GenericStream", &genericStream{})
+}
+
+// genericStream parses the frames of a streaming instrument with the regular expression in its config.
+type genericStream struct{}
+
+var _ StreamDevice = (*genericStream)(nil)
+
+// streamTimeGroup is the pattern group holding the device timestamp of a frame.
+const streamTimeGroup = "time"
+
+type genericStreamConfig struct {
+	DeviceName string `json:"device_name"`
+	// Delimiter ends each frame, "\n" by default.
+	Delimiter string `json:"delimiter"`
+	// Pattern is matched against each frame, its named groups are the item types, except streamTimeGroup.
+	Pattern string `json:"pattern"`
+	// TimeFormat is the Go layout of the time group, or "unix" / "unix_ms".
+	TimeFormat string `json:"time_format"`
+	// TimeZone is the location of a time without offset, UTC by default.
+	TimeZone    string            `json:"time_zone"`
+	IntervalSec float64           `json:"interval_sec"`
+	Items       map[string]string `json:"items"`
+}
+
+func (genericStream) NewStreamDevice(stream *Stream, rawConf json.RawMessage) common.StringMapMap {
+	var conf genericStreamConfig
+	pkg.Must(json.Unmarshal(rawConf, &conf))
+	parser, err := newStreamParser(conf)
+	if err != nil {
+		slog.Error("Invalid GenericStream config", "device", conf.DeviceName, "error", err)
+		os.Exit(1)
+	}
+	verifyItems(conf.Items, parser.provideItems())
+
+	delimiter := conf.Delimiter
+	if delimiter == "" {
+		delimiter = "\n"
+	}
+	stream.Start([]byte(delimiter), parser.handleFrame)
+	return common.StringMapMap{conf.DeviceName: conf.Items}
+}
+
+type streamParser struct {
+	items      map[string]string
+	pattern    *regexp.Regexp
+	timeFormat string
+	location   *time.Location
+	interval   time.Duration
+
+	lastPublished time.Time
+}
+
+func newStreamParser(conf genericStreamConfig) (*streamParser, error) {
+	pattern, err := regexp.Compile(conf.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	p := &streamParser{
+		items:      conf.Items,
+		pattern:    pattern,
+		timeFormat: conf.TimeFormat,
+		location:   time.UTC,
+		interval:   time.Duration(conf.IntervalSec * float64(time.Second)),
+	}
+	if conf.TimeZone != "" {
+		if p.location, err = time.LoadLocation(conf.TimeZone); err != nil {
+			return nil, err
+		}
+	}
+	if hasTime := pattern.SubexpIndex(streamTimeGroup) >= 0; hasTime != (p.timeFormat != "") {
+		return nil, errUnpairedTimeFormat
+	}
+	return p, nil
+}
+
+var errUnpairedTimeFormat = errors.New("time_format must be set if and only if the pattern has a (?P<time>...) group")
+
+func (p *streamParser) provideItems() map[string]int {
+	provide := make(map[string]int)
+	for i, name := range p.pattern.SubexpNames() {
+		if name != "" && name != streamTimeGroup {
+			provide[name] = i
+		}
+	}
+	return provide
+}
+
+// handleFrame returns the items of a frame matching the pattern, a group that is not a number gives a nil value.
+func (p *streamParser) handleFrame(frame []byte) []itemData {
+	line := strings.TrimRight(string(frame), "\r\n")
+	match := p.pattern.FindStringSubmatch(line)
+	if match == nil {
+		slog.Warn("Stream frame does not match the pattern", "frame", line)
+		return nil
+	}
+
+	at := time.Now().Add(ClockCorrection())
+	if p.timeFormat != "" {
+		var err error
+		if at, err = p.parseTime(match[p.pattern.SubexpIndex(streamTimeGroup)]); err != nil {
+			slog.Warn("Invalid stream frame time", "frame", line, "error", err)
+			return nil
+		}
+	}
+	if !p.lastPublished.IsZero() && at.After(p.lastPublished) && at.Sub(p.lastPublished) < p.interval {
+		return nil
+	}
+	p.lastPublished = at
+
+	atMs := custype.ToUnixMs(at)
+	data := make([]itemData, 0, len(p.items))
+	for itemType, itemName := range p.items {
+		var value *float64
+		if f, err := strconv.ParseFloat(strings.TrimSpace(match[p.pattern.SubexpIndex(itemType)]), 64); err == nil {
+			value = &f
+		}
+		data = append(data, itemData{At: atMs, Typ: common.MsgData, ItemName: itemName, Value: value})
+	}
+	return data
+}
+
+func (p *streamParser) parseTime(s string) (time.Time, error) {
+	switch p.timeFormat {
+	case "unix", "unix_ms":
+		v, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if p.timeFormat == "unix" {
+			return time.Unix(v, 0), nil
+		}
+		return time.UnixMilli(v), nil
+	default:
+		return time.ParseInLocation(p.timeFormat, s, p.location)
+	}
+}
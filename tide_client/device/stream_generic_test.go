@@ -0,0 +1,69 @@
+package device
+
+import (
+	"testing"
+	"tide/pkg/custype"
+	"time"
+)
+
+func TestStreamParser(t *testing.T) {
+	t.Parallel()
+
+	p, err := newStreamParser(genericStreamConfig{
+		Pattern:     `^(?P<time>\S+ \S+) \$(?P<wind_speed>[^,]*),(?P<wind_direction>[^,]*)$`,
+		TimeFormat:  "2006-01-02 15:04:05",
+		TimeZone:    "Asia/Shanghai",
+		IntervalSec: 10,
+		Items:       map[string]string{"wind_speed": "ws", "wind_direction": "wd"},
+	})
+	if err != nil {
+		t.Fatalf("newStreamParser returned error: %v", err)
+	}
+	if provide := p.provideItems(); len(provide) != 2 {
+		t.Fatalf("provideItems = %v, want the two value groups", provide)
+	}
+
+	data := p.handleFrame([]byte("2024-05-01 08:00:00 $3.5,999\r"))
+	if len(data) != 2 {
+		t.Fatalf("len(data) = %d, want 2", len(data))
+	}
+	if want := custype.ToUnixMs(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)); data[0].At != want {
+		t.Fatalf("At = %v, want the device time %v", data[0].At, want)
+	}
+	for _, d := range data {
+		if d.ItemName == "ws" && (d.Value == nil || *d.Value != 3.5) {
+			t.Fatalf("ws = %v, want 3.5", d.Value)
+		}
+	}
+
+	if data = p.handleFrame([]byte("2024-05-01 08:00:05 $3.6,999")); data != nil {
+		t.Fatalf("frame within the interval was published: %v", data)
+	}
+	if data = p.handleFrame([]byte("garbage")); data != nil {
+		t.Fatalf("unmatched frame was published: %v", data)
+	}
+	data = p.handleFrame([]byte("2024-05-01 08:00:10 $,120"))
+	if len(data) != 2 {
+		t.Fatalf("len(data) = %d, want 2", len(data))
+	}
+	for _, d := range data {
+		if d.ItemName == "ws" && d.Value != nil {
+			t.Fatalf("empty ws = %v, want nil", *d.Value)
+		}
+	}
+}
+
+func TestStreamParserConfigErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, conf := range []genericStreamConfig{
+		{Pattern: `(?P<level>[0-9.]+`},
+		{Pattern: `(?P<time>\d+) (?P<level>[0-9.]+)`},
+		{Pattern: `(?P<level>[0-9.]+)`, TimeFormat: "unix"},
+		{Pattern: `(?P<level>[0-9.]+)`, TimeZone: "Mars/Olympus"},
+	} {
+		if _, err := newStreamParser(conf); err == nil {
+			t.Fatalf("newStreamParser(%+v) returned nil error", conf)
+		}
+	}
+}
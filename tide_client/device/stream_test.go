@@ -0,0 +1,41 @@
+package device
+
+import (
+	"testing"
+	"tide/tide_client/connWrap"
+)
+
+// chunkReader returns one chunk per Read and a timeout once they are used up.
+type chunkReader struct {
+	chunks []string
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, connWrap.ErrTimeout
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestFrameReader(t *testing.T) {
+	t.Parallel()
+
+	frames := newFrameReader(&chunkReader{chunks: []string{"$1.2,", "30\r\n$1.3,31\r", "\n$1.4"}}, []byte("\r\n"))
+	for _, want := range []string{"$1.2,30", "$1.3,31"} {
+		frame, err := frames.next()
+		if err != nil {
+			t.Fatalf("next returned error: %v", err)
+		}
+		if string(frame) != want {
+			t.Fatalf("next = %q, want %q", frame, want)
+		}
+	}
+	if _, err := frames.next(); err != connWrap.ErrTimeout {
+		t.Fatalf("next error = %v, want timeout", err)
+	}
+	if string(frames.buf) != "$1.4" {
+		t.Fatalf("buffered = %q, want the partial frame kept", frames.buf)
+	}
+}
@@ -13,6 +13,8 @@ func init() {
 
 type uartRs232 struct{}
 
+func (uartRs232) dispatchesBus() {}
+
 func (uartRs232) NewBusDevice(bus *connWrap.Bus, rawConf json.RawMessage) common.StringMapMap {
 	var conf struct {
 		Model  string          `json:"model"`
@@ -21,7 +23,7 @@ func (uartRs232) NewBusDevice(bus *connWrap.Bus, rawConf json.RawMessage) common
 	pkg.Must(json.Unmarshal(rawConf, &conf))
 	var info = make(common.StringMapMap)
 
-	subInfo := MustBusDevice(conf.Model).NewBusDevice(bus, conf.Config)
+	subInfo := StartBusDevice(conf.Model, bus, conf.Config)
 	MergeInfo(info, subInfo)
 
 	return info
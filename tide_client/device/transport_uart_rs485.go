@@ -13,6 +13,8 @@ func init() {
 
 type uartRs485 struct{}
 
+func (uartRs485) dispatchesBus() {}
+
 func (uartRs485) NewBusDevice(bus *connWrap.Bus, rawConf json.RawMessage) common.StringMapMap {
 	var conf []struct {
 		Model  string          `json:"model"`
@@ -21,7 +23,7 @@ func (uartRs485) NewBusDevice(bus *connWrap.Bus, rawConf json.RawMessage) common
 	pkg.Must(json.Unmarshal(rawConf, &conf))
 	var info = make(common.StringMapMap)
 	for _, subDevice := range conf {
-		subInfo := MustBusDevice(subDevice.Model).NewBusDevice(bus, subDevice.Config)
+		subInfo := StartBusDevice(subDevice.Model, bus, subDevice.Config)
 		MergeInfo(info, subInfo)
 	}
 	return info
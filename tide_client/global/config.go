@@ -7,6 +7,8 @@ import (
 	"os"
 	"time"
 
+	"tide/pkg/logring"
+
 	"github.com/lmittmann/tint"
 	"github.com/robfig/cron/v3"
 )
@@ -16,13 +18,45 @@ type Ftp struct {
 	HoldDays time.Duration `json:"hold_days"`
 }
 
+// Aggregation turns the samples of an item into statistics over fixed windows.
+type Aggregation struct {
+	WindowSec int `json:"window_sec"`
+	// Stats are the statistics to store, all of them if empty.
+	Stats []string `json:"stats"`
+	// KeepRaw also stores the samples, for RawHoldDays if it is shorter than the db hold days.
+	KeepRaw     bool          `json:"keep_raw"`
+	RawHoldDays time.Duration `json:"raw_hold_days"`
+}
+
+// QC is the quality control chain of an item, a check without its setting is skipped.
+type QC struct {
+	// Min and Max replace the range the device knows for the item.
+	Min *float64 `json:"min"`
+	Max *float64 `json:"max"`
+	// MaxRate is the largest plausible change per second from the last value that was not flagged bad.
+	MaxRate float64 `json:"max_rate"`
+	// FlatLineCount values in a row within FlatLineTolerance of the first one are a stuck sensor.
+	FlatLineCount     int     `json:"flat_line_count"`
+	FlatLineTolerance float64 `json:"flat_line_tolerance"`
+	// MaxGapSec is the longest expected time between two values.
+	MaxGapSec float64 `json:"max_gap_sec"`
+}
+
 var Config struct {
-	LogLevel string `json:"log_level"`
-	Listen   string `json:"listen"`
-	Server   string `json:"server"`
-	SyncV2   struct {
+	LogLevel      string `json:"log_level"`
+	LogBufferSize int    `json:"log_buffer_size"`
+	Listen        string `json:"listen"`
+	Server        string `json:"server"`
+	SyncV2        struct {
 		Enabled bool   `json:"enabled"`
 		Addr    string `json:"addr"`
+		AuthKey string `json:"auth_key"`
+		Tls     struct {
+			CertFile string `json:"cert_file"`
+			KeyFile  string `json:"key_file"`
+			CaFile   string `json:"ca_file"`
+		} `json:"tls"`
+		DeviceConfigFile string `json:"device_config_file"`
 	} `json:"sync_v2"`
 	Identifier string              `json:"identifier"`
 	Devices    map[string][]string `json:"devices"`
@@ -30,6 +64,10 @@ var Config struct {
 		Dsn      string        `json:"dsn"`
 		HoldDays time.Duration `json:"hold_days"`
 	} `json:"db"`
+	// Aggregation is keyed by the name of the sampled item.
+	Aggregation map[string]Aggregation `json:"aggregation"`
+	// QC is keyed by item name.
+	QC   map[string]QC `json:"qc"`
 	Gnss struct {
 		Ftp Ftp `json:"ftp"`
 	} `json:"gnss"`
@@ -45,6 +83,11 @@ var Config struct {
 
 var CronJob *cron.Cron
 
+// LogBuffer keeps the latest log records so the server can read them over Sync V2.
+var LogBuffer *logring.Buffer
+
+const defaultLogBufferSize = 2000
+
 func Init(name string) {
 	b, err := os.ReadFile(name)
 	if err != nil {
@@ -85,5 +128,10 @@ func initLogger() {
 		TimeFormat: time.DateTime,
 		AddSource:  true,
 	})
-	slog.SetDefault(slog.New(handler))
+	size := Config.LogBufferSize
+	if size <= 0 {
+		size = defaultLogBufferSize
+	}
+	LogBuffer = logring.NewBuffer(size)
+	slog.SetDefault(slog.New(logring.NewHandler(handler, LogBuffer)))
 }
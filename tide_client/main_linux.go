@@ -13,15 +13,33 @@ import (
 	"tide/tide_client/global"
 )
 
+var (
+	// discover is set by "tide_client discover", which scans the buses for sensors instead of running the station.
+	discover    bool
+	discoverOut *string
+)
+
 func init() {
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "discover" {
+		discover, args = true, args[1:]
+	}
 	flag.StringVar(&global.Config.LogLevel, "log", "debug", "log level")
 	cfgName := flag.String("config", "config.json", "Config file")
-	flag.Parse()
+	discoverOut = flag.String("out", "device_config.draft.json", "Draft device config written by discover")
+	_ = flag.CommandLine.Parse(args)
 
 	global.Init(*cfgName)
 }
 
 func main() {
+	if discover {
+		if err := controller.Discover(*discoverOut); err != nil {
+			slog.Error("Discover failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 	controller.Init()
 	go func() {
 		err := http.ListenAndServe(global.Config.Listen, nil)
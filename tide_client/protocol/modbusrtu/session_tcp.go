@@ -0,0 +1,54 @@
+package modbusrtu
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/wwnt/modbus"
+
+	"tide/tide_client/connWrap"
+)
+
+const (
+	mbapHeaderSize = 7
+	mbapMaxLength  = 260
+)
+
+// NewTCPSession returns a Session speaking Modbus TCP (MBAP framing) on the bus,
+// for gauges behind an Ethernet gateway that converts Modbus TCP to RTU.
+func NewTCPSession(bus *connWrap.Bus, unitID byte) *Session {
+	packager := modbus.NewTCPClientHandler("")
+	packager.SlaveId = unitID
+
+	return &Session{
+		bus:    bus,
+		client: modbus.NewClient2(packager, &mbapTransporter{port: bus}),
+	}
+}
+
+// mbapTransporter sends MBAP frames over an already connected bus instead of dialing itself,
+// so the reconnect handling of the tcp transport applies.
+type mbapTransporter struct {
+	port io.ReadWriter
+}
+
+func (t *mbapTransporter) Send(aduRequest []byte) (aduResponse []byte, err error) {
+	if _, err = t.port.Write(aduRequest); err != nil {
+		return nil, err
+	}
+	var data [mbapMaxLength]byte
+	if _, err = io.ReadFull(t.port, data[:mbapHeaderSize]); err != nil {
+		return nil, err
+	}
+	// The length field counts the unit identifier, which is already part of the header.
+	length := int(binary.BigEndian.Uint16(data[4:]))
+	if length <= 0 || length > mbapMaxLength-mbapHeaderSize+1 {
+		return nil, fmt.Errorf("modbus: invalid length in response header '%v'", length)
+	}
+	length += mbapHeaderSize - 1
+	if _, err = io.ReadFull(t.port, data[mbapHeaderSize:length]); err != nil {
+		return nil, err
+	}
+	return data[:length], nil
+}
@@ -92,3 +92,46 @@ func TestSessionWaitsQuietTimeBeforeRequest(t *testing.T) {
 		t.Fatalf("request sent after %v, want at least %v", delay, quietTime)
 	}
 }
+
+type fakeTCPConn struct {
+	lastWrite []byte
+	response  []byte
+}
+
+func (c *fakeTCPConn) Read(p []byte) (int, error) {
+	if len(c.response) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, c.response)
+	c.response = c.response[n:]
+	return n, nil
+}
+
+func (c *fakeTCPConn) Write(p []byte) (int, error) {
+	c.lastWrite = append([]byte(nil), p...)
+	// Echo the transaction id and unit id, return two registers.
+	c.response = []byte{p[0], p[1], 0x00, 0x00, 0x00, 0x07, p[6], p[7], 0x04, 0x12, 0x34, 0x56, 0x78}
+	return len(p), nil
+}
+
+func (c *fakeTCPConn) ResetInputBuffer() error { return nil }
+
+func TestTCPSessionReadHoldingRegisters(t *testing.T) {
+	t.Parallel()
+
+	rawConn := &fakeTCPConn{}
+	session := NewTCPSession(connWrap.NewBusWithQuietTime(rawConn, 0), 0x05)
+
+	got, err := session.ReadHoldingRegisters(100, 2)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters returned error: %v", err)
+	}
+	if !bytes.Equal(got, []byte{0x12, 0x34, 0x56, 0x78}) {
+		t.Fatalf("ReadHoldingRegisters = %v, want %v", got, []byte{0x12, 0x34, 0x56, 0x78})
+	}
+
+	wantReq := []byte{0x00, 0x00, 0x00, 0x06, 0x05, modbus.FuncCodeReadHoldingRegisters, 0x00, 0x64, 0x00, 0x02}
+	if !bytes.Equal(rawConn.lastWrite[2:], wantReq) {
+		t.Fatalf("request = %v, want transaction id followed by %v", rawConn.lastWrite, wantReq)
+	}
+}
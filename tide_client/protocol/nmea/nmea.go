@@ -0,0 +1,216 @@
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrChecksum = errors.New("nmea: checksum mismatch")
+
+// Sentence is one NMEA 0183 sentence such as "$GPGGA,...*hh".
+type Sentence struct {
+	// Talker is the talker id, e.g. "GP" or "GN".
+	Talker string
+	// Type is the sentence formatter, e.g. "GGA".
+	Type   string
+	Fields []string
+}
+
+// Parse checks the framing and the checksum of a sentence and splits its fields.
+// Sentences without checksum are rejected, a receiver streaming over a long cable should always send it.
+func Parse(line string) (Sentence, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) < 9 || line[0] != '$' || line[len(line)-3] != '*' {
+		return Sentence{}, fmt.Errorf("nmea: malformed sentence %q", line)
+	}
+	body := line[1 : len(line)-3]
+	want, err := strconv.ParseUint(line[len(line)-2:], 16, 8)
+	if err != nil {
+		return Sentence{}, fmt.Errorf("nmea: malformed checksum %q", line)
+	}
+	var sum byte
+	for i := 0; i < len(body); i++ {
+		sum ^= body[i]
+	}
+	if sum != byte(want) {
+		return Sentence{}, ErrChecksum
+	}
+	fields := strings.Split(body, ",")
+	if len(fields[0]) != 5 {
+		return Sentence{}, fmt.Errorf("nmea: malformed address %q", fields[0])
+	}
+	return Sentence{Talker: fields[0][:2], Type: fields[0][2:], Fields: fields[1:]}, nil
+}
+
+// GGA is the fix data of an epoch.
+type GGA struct {
+	// Time is the UTC time of day of the fix.
+	Time       time.Duration
+	Latitude   *float64
+	Longitude  *float64
+	FixQuality int
+	Satellites int
+	HDOP       *float64
+	// Altitude is the antenna altitude above mean sea level in meters.
+	Altitude *float64
+	// GeoidSeparation is the height of the geoid above the WGS84 ellipsoid in meters.
+	GeoidSeparation *float64
+}
+
+// RMC is the recommended minimum data, it carries the date the other sentences lack.
+type RMC struct {
+	// Time is the UTC date and time, zero if the receiver does not know it yet.
+	Time  time.Time
+	Valid bool
+}
+
+// GST is the pseudorange error statistics of an epoch, the errors are 1-sigma in meters.
+type GST struct {
+	Time           time.Duration
+	LatitudeError  *float64
+	LongitudeError *float64
+	AltitudeError  *float64
+}
+
+func ParseGGA(s Sentence) (g GGA, err error) {
+	if len(s.Fields) < 11 {
+		return g, fmt.Errorf("nmea: GGA has %d fields", len(s.Fields))
+	}
+	f := s.Fields
+	if g.Time, err = parseTimeOfDay(f[0]); err != nil {
+		return g, err
+	}
+	if g.Latitude, err = parseCoordinate(f[1], f[2], "N", "S"); err != nil {
+		return g, err
+	}
+	if g.Longitude, err = parseCoordinate(f[3], f[4], "E", "W"); err != nil {
+		return g, err
+	}
+	if g.FixQuality, err = parseInt(f[5]); err != nil {
+		return g, err
+	}
+	if g.Satellites, err = parseInt(f[6]); err != nil {
+		return g, err
+	}
+	if g.HDOP, err = parseFloat(f[7]); err != nil {
+		return g, err
+	}
+	if g.Altitude, err = parseFloat(f[8]); err != nil {
+		return g, err
+	}
+	if g.GeoidSeparation, err = parseFloat(f[10]); err != nil {
+		return g, err
+	}
+	return g, nil
+}
+
+func ParseRMC(s Sentence) (r RMC, err error) {
+	if len(s.Fields) < 9 {
+		return r, fmt.Errorf("nmea: RMC has %d fields", len(s.Fields))
+	}
+	f := s.Fields
+	r.Valid = f[1] == "A"
+	if f[0] == "" || f[8] == "" {
+		return r, nil
+	}
+	tod, err := parseTimeOfDay(f[0])
+	if err != nil {
+		return r, err
+	}
+	date, err := time.Parse("020106", f[8])
+	if err != nil {
+		return r, fmt.Errorf("nmea: malformed date %q", f[8])
+	}
+	r.Time = date.Add(tod)
+	return r, nil
+}
+
+func ParseGST(s Sentence) (g GST, err error) {
+	if len(s.Fields) < 8 {
+		return g, fmt.Errorf("nmea: GST has %d fields", len(s.Fields))
+	}
+	f := s.Fields
+	if g.Time, err = parseTimeOfDay(f[0]); err != nil {
+		return g, err
+	}
+	if g.LatitudeError, err = parseFloat(f[5]); err != nil {
+		return g, err
+	}
+	if g.LongitudeError, err = parseFloat(f[6]); err != nil {
+		return g, err
+	}
+	if g.AltitudeError, err = parseFloat(f[7]); err != nil {
+		return g, err
+	}
+	return g, nil
+}
+
+// parseTimeOfDay parses hhmmss.ss.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	if len(s) < 6 {
+		return 0, fmt.Errorf("nmea: malformed time %q", s)
+	}
+	h, errH := strconv.Atoi(s[0:2])
+	m, errM := strconv.Atoi(s[2:4])
+	sec, errS := strconv.ParseFloat(s[4:], 64)
+	if errH != nil || errM != nil || errS != nil || h > 23 || m > 59 || sec >= 61 {
+		return 0, fmt.Errorf("nmea: malformed time %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec*float64(time.Second)).Round(time.Millisecond), nil
+}
+
+// parseCoordinate parses (d)ddmm.mmmm with its hemisphere to signed decimal degrees.
+func parseCoordinate(value, hemisphere, positive, negative string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	dot := strings.IndexByte(value, '.')
+	if dot < 0 {
+		dot = len(value)
+	}
+	if dot < 3 {
+		return nil, fmt.Errorf("nmea: malformed coordinate %q", value)
+	}
+	deg, err := strconv.Atoi(value[:dot-2])
+	if err != nil {
+		return nil, fmt.Errorf("nmea: malformed coordinate %q", value)
+	}
+	minutes, err := strconv.ParseFloat(value[dot-2:], 64)
+	if err != nil {
+		return nil, fmt.Errorf("nmea: malformed coordinate %q", value)
+	}
+	v := float64(deg) + minutes/60
+	switch hemisphere {
+	case positive:
+	case negative:
+		v = -v
+	default:
+		return nil, fmt.Errorf("nmea: malformed hemisphere %q", hemisphere)
+	}
+	return &v, nil
+}
+
+func parseFloat(s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("nmea: malformed number %q", s)
+	}
+	return &v, nil
+}
+
+func parseInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("nmea: malformed number %q", s)
+	}
+	return v, nil
+}
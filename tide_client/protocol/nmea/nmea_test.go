@@ -0,0 +1,102 @@
+package nmea
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func almostEqual(got *float64, want float64) bool {
+	return got != nil && math.Abs(*got-want) < 1e-9
+}
+
+func TestParseChecksum(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse("$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if s.Talker != "GP" || s.Type != "GGA" || len(s.Fields) != 14 {
+		t.Fatalf("Parse = %+v", s)
+	}
+
+	if _, err = Parse("$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.5,M,46.9,M,,*47"); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("Parse of a corrupted sentence error = %v, want ErrChecksum", err)
+	}
+	for _, line := range []string{"GPGGA,123519*47", "$GPGGA,123519", "$GPGGA,123519*G7"} {
+		if _, err = Parse(line); err == nil {
+			t.Fatalf("Parse(%q) returned nil error", line)
+		}
+	}
+}
+
+func TestParseGGA(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse("$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := ParseGGA(s)
+	if err != nil {
+		t.Fatalf("ParseGGA returned error: %v", err)
+	}
+	if g.Time != 12*time.Hour+35*time.Minute+19*time.Second {
+		t.Fatalf("Time = %v", g.Time)
+	}
+	if !almostEqual(g.Latitude, 48+7.038/60) || !almostEqual(g.Longitude, 11+31.0/60) {
+		t.Fatalf("position = %v, %v", g.Latitude, g.Longitude)
+	}
+	if g.FixQuality != 1 || g.Satellites != 8 || !almostEqual(g.HDOP, 0.9) {
+		t.Fatalf("quality = %d, %d, %v", g.FixQuality, g.Satellites, g.HDOP)
+	}
+	if !almostEqual(g.Altitude, 545.4) || !almostEqual(g.GeoidSeparation, 46.9) {
+		t.Fatalf("altitude = %v, %v", g.Altitude, g.GeoidSeparation)
+	}
+}
+
+func TestParseGGAWithoutFix(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse("$GPGGA,000001,,,,,0,00,,,M,,M,,*67")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := ParseGGA(s)
+	if err != nil {
+		t.Fatalf("ParseGGA returned error: %v", err)
+	}
+	if g.FixQuality != 0 || g.Latitude != nil || g.Altitude != nil {
+		t.Fatalf("ParseGGA = %+v, want no position", g)
+	}
+}
+
+func TestParseRMCAndGST(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse("$GNRMC,123519.00,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*5A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ParseRMC(s)
+	if err != nil {
+		t.Fatalf("ParseRMC returned error: %v", err)
+	}
+	if !r.Valid || !r.Time.Equal(time.Date(1994, 3, 23, 12, 35, 19, 0, time.UTC)) {
+		t.Fatalf("ParseRMC = %+v", r)
+	}
+
+	s, err = Parse("$GPGST,123519.00,0.006,0.023,0.020,273.6,0.023,0.020,0.031*5E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := ParseGST(s)
+	if err != nil {
+		t.Fatalf("ParseGST returned error: %v", err)
+	}
+	if !almostEqual(g.LatitudeError, 0.023) || !almostEqual(g.LongitudeError, 0.020) || !almostEqual(g.AltitudeError, 0.031) {
+		t.Fatalf("ParseGST = %+v", g)
+	}
+}
@@ -0,0 +1,89 @@
+package sdi12
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"tide/tide_client/connWrap"
+)
+
+// HasCRC reports whether a measurement command such as "MC1", "CC" or "RC0" requests replies with a CRC.
+func HasCRC(command string) bool {
+	return len(command) > 1 && command[1] == 'C'
+}
+
+// Identification is the reply of the aI! command.
+type Identification struct {
+	Address      string `json:"address"`
+	SDI12Version string `json:"sdi12_version"`
+	Vendor       string `json:"vendor"`
+	Model        string `json:"model"`
+	Version      string `json:"version"`
+	// Serial is the optional vendor specific field after the version, usually the serial number.
+	Serial string `json:"serial,omitempty"`
+}
+
+// Acknowledge sends "a!" and reports whether a sensor answers at the address.
+func (s *Session) Acknowledge(addr string, extraWakeTime byte) (bool, error) {
+	reply, err := s.Command(addr+"!", extraWakeTime)
+	if err != nil {
+		if errors.Is(err, connWrap.ErrTimeout) {
+			return false, nil
+		}
+		return false, err
+	}
+	return reply == addr, nil
+}
+
+// QueryAddress sends "?!" and returns the address of the sensor. Only one sensor may be on the bus,
+// otherwise the replies collide.
+func (s *Session) QueryAddress(extraWakeTime byte) (addr string, err error) {
+	reply, err := s.Command("?!", extraWakeTime)
+	if err != nil {
+		return "", err
+	}
+	if len(reply) != 1 {
+		return "", fmt.Errorf("unexpected address reply %q", reply)
+	}
+	return reply, nil
+}
+
+// Identify sends "aI!" and parses the allccccccccmmmmmmvvvxxx... reply.
+func (s *Session) Identify(addr string, extraWakeTime byte) (id Identification, err error) {
+	reply, err := s.Command(addr+"I!", extraWakeTime)
+	if err != nil {
+		return id, err
+	}
+	return parseIdentification(addr, reply)
+}
+
+func parseIdentification(addr string, reply string) (id Identification, err error) {
+	const minLen = 1 + 2 + 8 + 6 + 3
+	if len(reply) < minLen || !strings.HasPrefix(reply, addr) {
+		return id, fmt.Errorf("unexpected identification reply %q", reply)
+	}
+	return Identification{
+		Address:      reply[:1],
+		SDI12Version: reply[1:3],
+		Vendor:       strings.TrimSpace(reply[3:11]),
+		Model:        strings.TrimSpace(reply[11:17]),
+		Version:      strings.TrimSpace(reply[17:20]),
+		Serial:       strings.TrimSpace(reply[20:]),
+	}, nil
+}
+
+// ChangeAddress sends "aAb!" and checks the sensor answers with its new address.
+func (s *Session) ChangeAddress(addr, newAddr string, extraWakeTime byte) error {
+	if len(newAddr) != 1 {
+		return fmt.Errorf("invalid address %q", newAddr)
+	}
+	reply, err := s.Command(addr+"A"+newAddr+"!", extraWakeTime)
+	if err != nil {
+		return err
+	}
+	if reply != newAddr {
+		return fmt.Errorf("unexpected change address reply %q", reply)
+	}
+	return nil
+}
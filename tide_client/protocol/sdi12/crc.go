@@ -0,0 +1,43 @@
+package sdi12
+
+import "errors"
+
+// ErrCRC is returned when the CRC of a reply does not match its content, usually because of noise on the bus.
+var ErrCRC = errors.New("crc mismatch")
+
+// crc16 computes the CRC-16 (polynomial 0xA001, initial value 0) the SDI-12 specification uses.
+func crc16(data string) uint16 {
+	var crc uint16
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i])
+		for range 8 {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+// encodeCRC encodes the CRC as the three printable characters appended to a reply.
+func encodeCRC(crc uint16) string {
+	return string([]byte{
+		0x40 | byte(crc>>12),
+		0x40 | byte(crc>>6)&0x3F,
+		0x40 | byte(crc)&0x3F,
+	})
+}
+
+// checkCRC verifies the CRC at the end of the reply and returns the reply without it.
+func checkCRC(reply string) (string, error) {
+	if len(reply) < 3 {
+		return "", ErrCRC
+	}
+	body := reply[:len(reply)-3]
+	if encodeCRC(crc16(body)) != reply[len(reply)-3:] {
+		return "", ErrCRC
+	}
+	return body, nil
+}
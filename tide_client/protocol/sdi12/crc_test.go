@@ -0,0 +1,25 @@
+package sdi12
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckCRC(t *testing.T) {
+	t.Parallel()
+
+	// Example from the SDI-12 specification.
+	body, err := checkCRC("0+3.14OqZ")
+	if err != nil {
+		t.Fatalf("checkCRC returned error: %v", err)
+	}
+	if body != "0+3.14" {
+		t.Fatalf("body = %q, want %q", body, "0+3.14")
+	}
+
+	for _, reply := range []string{"0+3.15OqZ", "0+3.14OqY", "Oq"} {
+		if _, err := checkCRC(reply); !errors.Is(err, ErrCRC) {
+			t.Fatalf("checkCRC(%q) error = %v, want ErrCRC", reply, err)
+		}
+	}
+}
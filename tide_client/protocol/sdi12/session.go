@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"tide/tide_client/connWrap"
@@ -28,6 +29,15 @@ func NewSession(bus *connWrap.Bus, mode Mode) *Session {
 	return &Session{bus: bus, mode: mode}
 }
 
+// input frames a command for the transport, the Arduino bridge needs the wake time and a terminator.
+func (s *Session) input(command string, extraWakeTime byte) []byte {
+	input := []byte(command)
+	if s.mode == ModeArduino {
+		input = append(input, extraWakeTime, arduinoCommandEnd)
+	}
+	return input
+}
+
 func (s *Session) unlockBus(err *error) {
 	// A malformed SDI-12 reply may still be draining from the sensor after
 	// parsing fails. Keep the bus reserved long enough for that tail to end
@@ -39,10 +49,7 @@ func (s *Session) unlockBus(err *error) {
 }
 
 func (s *Session) ConcurrentMeasurement(addr string, extraWakeTime byte, output string, wait time.Duration) (err error) {
-	input := []byte(addr + "C!")
-	if s.mode == ModeArduino {
-		input = append(input, extraWakeTime, arduinoCommandEnd)
-	}
+	input := s.input(addr+"C!", extraWakeTime)
 
 	{
 		s.bus.Lock()
@@ -62,51 +69,201 @@ func (s *Session) ConcurrentMeasurement(addr string, extraWakeTime byte, output
 }
 
 func (s *Session) GetData(addr string, extraWakeTime byte, resultsExpected int) (values []*float64, err error) {
+	return s.getData(addr, extraWakeTime, resultsExpected, false)
+}
+
+// GetDataCRC collects the values of a measurement started with a CRC variant such as "MC" or "CC",
+// and rejects replies whose CRC does not match.
+func (s *Session) GetDataCRC(addr string, extraWakeTime byte, resultsExpected int) (values []*float64, err error) {
+	return s.getData(addr, extraWakeTime, resultsExpected, true)
+}
+
+func (s *Session) getData(addr string, extraWakeTime byte, resultsExpected int, crc bool) (values []*float64, err error) {
 	s.bus.Lock()
 	defer s.unlockBus(&err)
 
-	var resultsReceived, cmdNumber int
+	var cmdNumber int
 
 	reader := bufio.NewReader(s.bus)
-	for resultsReceived < resultsExpected && cmdNumber <= 9 {
-		input := []byte(addr + "D" + strconv.Itoa(cmdNumber) + "!")
-		if s.mode == ModeArduino {
-			input = append(input, extraWakeTime, arduinoCommandEnd)
-		}
+	for len(values) < resultsExpected && cmdNumber <= 9 {
+		input := s.input(addr+"D"+strconv.Itoa(cmdNumber)+"!", extraWakeTime)
 		if _, err = s.bus.Write(input); err != nil {
 			return nil, &connWrap.Error{Type: connWrap.ErrIO, Send: input, Err: err}
 		}
-		if _, err = reader.Discard(1); err != nil {
-			return nil, &connWrap.Error{Type: connWrap.ErrIO, Err: err}
+		resp, err := readValues(reader, input, addr, crc)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp) == 0 {
+			return nil, &connWrap.Error{Type: connWrap.ErrIO, Err: errors.New("wrong number of data")}
 		}
+		values = append(values, resp...)
+		cmdNumber++
+	}
+	if len(values) != resultsExpected {
+		return nil, &connWrap.Error{Type: connWrap.ErrParse, Err: errors.New("wrong number of data")}
+	}
+	return values, nil
+}
 
-		var resultsInResp int
-		for {
-			// 1+0.01+0.000
-			if bs, err := reader.Peek(1); err != nil {
-				return nil, &connWrap.Error{Type: connWrap.ErrIO, Err: err}
-			} else if bs[0] == '\r' {
-				if _, err = reader.Discard(2); err != nil {
-					return nil, &connWrap.Error{Type: connWrap.ErrIO, Err: err}
-				}
-				if resultsInResp == 0 {
-					return nil, &connWrap.Error{Type: connWrap.ErrIO, Err: errors.New("wrong number of data")}
-				}
-				break
-			}
+// Measure sends a measurement command such as "M", "M1", "MC", "C" or "CC1" and waits the time the
+// sensor announces in its atttn (atttnn for concurrent measurements) reply. It returns the number of
+// values that can then be collected with GetData, or GetDataCRC for the CRC variants.
+// A standard (M) measurement keeps the bus reserved while waiting, because any traffic on the bus
+// aborts it, and ends the wait early when the sensor sends its service request; a concurrent (C)
+// measurement leaves the bus to the other sensors.
+func (s *Session) Measure(addr string, extraWakeTime byte, command string) (count int, err error) {
+	concurrent := strings.HasPrefix(command, "C")
+	input := s.input(addr+command+"!", extraWakeTime)
 
-			var f float64
-			if _, err = fmt.Fscan(reader, &f); err != nil {
-				return nil, &connWrap.Error{Type: connWrap.ErrParse, Send: input, Err: err}
+	s.bus.Lock()
+	locked := true
+	defer func() {
+		if locked {
+			s.unlockBus(&err)
+		}
+	}()
+
+	if _, writeErr := s.bus.Write(input); writeErr != nil {
+		err = &connWrap.Error{Type: connWrap.ErrIO, Send: input, Err: writeErr}
+		return 0, err
+	}
+	reader := bufio.NewReader(s.bus)
+	line, readErr := reader.ReadString('\n')
+	if readErr != nil {
+		err = &connWrap.Error{Type: connWrap.ErrIO, Send: input, Received: []byte(line), Err: readErr}
+		return 0, err
+	}
+	countDigits := 1
+	if concurrent {
+		countDigits = 2
+	}
+	wait, count, parseErr := parseMeasurementReply(addr, strings.TrimRight(line, "\r\n"), countDigits)
+	if parseErr != nil {
+		err = &connWrap.Error{Type: connWrap.ErrParse, Send: input, Received: []byte(line), Err: parseErr}
+		return 0, err
+	}
+
+	if concurrent {
+		locked = false
+		s.bus.Unlock()
+		time.Sleep(wait)
+		return count, nil
+	}
+	if wait > 0 {
+		waitServiceRequest(reader, addr, wait)
+	}
+	return count, nil
+}
+
+// waitServiceRequest waits until the sensor sends its "a\r\n" service request or the announced time has passed.
+func waitServiceRequest(reader *bufio.Reader, addr string, wait time.Duration) {
+	deadline := time.Now().Add(wait)
+	var pending string
+	for time.Now().Before(deadline) {
+		line, err := reader.ReadString('\n')
+		pending += line
+		if err == nil {
+			if strings.TrimRight(pending, "\r\n") == addr {
+				return
 			}
-			values = append(values, &f)
-			resultsReceived++
-			resultsInResp++
+			pending = ""
+			continue
+		}
+		if !errors.Is(err, connWrap.ErrTimeout) {
+			// The transport cannot wait for the request, fall back to the announced time.
+			time.Sleep(time.Until(deadline))
+			return
 		}
-		cmdNumber++
 	}
-	if resultsReceived != resultsExpected {
-		return nil, &connWrap.Error{Type: connWrap.ErrParse, Err: errors.New("wrong number of data")}
+}
+
+// ContinuousMeasurement sends a continuous measurement command such as "R0" or "RC0" and returns the
+// values of the reply, which the sensor sends right away. The CRC of "RC" replies is verified.
+func (s *Session) ContinuousMeasurement(addr string, extraWakeTime byte, command string) (values []*float64, err error) {
+	input := s.input(addr+command+"!", extraWakeTime)
+
+	s.bus.Lock()
+	defer s.unlockBus(&err)
+
+	if _, err = s.bus.Write(input); err != nil {
+		return nil, &connWrap.Error{Type: connWrap.ErrIO, Send: input, Err: err}
+	}
+	return readValues(bufio.NewReader(s.bus), input, addr, HasCRC(command))
+}
+
+// Command sends a raw command such as "0I!" and returns the response line without the trailing CRLF.
+func (s *Session) Command(command string, extraWakeTime byte) (response string, err error) {
+	input := s.input(command, extraWakeTime)
+
+	s.bus.Lock()
+	defer s.unlockBus(&err)
+
+	if _, writeErr := s.bus.Write(input); writeErr != nil {
+		err = &connWrap.Error{Type: connWrap.ErrIO, Send: input, Err: writeErr}
+		return "", err
+	}
+	line, readErr := bufio.NewReader(s.bus).ReadString('\n')
+	if readErr != nil {
+		err = &connWrap.Error{Type: connWrap.ErrIO, Send: input, Received: []byte(line), Err: readErr}
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readValues parses one data reply such as "1+0.01+0.000\r\n", with a CRC before the CRLF if crc is set.
+func readValues(reader *bufio.Reader, input []byte, addr string, crc bool) (values []*float64, err error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, &connWrap.Error{Type: connWrap.ErrIO, Send: input, Received: []byte(line), Err: err}
+	}
+	body := strings.TrimRight(line, "\r\n")
+	if crc {
+		if body, err = checkCRC(body); err != nil {
+			return nil, &connWrap.Error{Type: connWrap.ErrParse, Send: input, Received: []byte(line), Err: err}
+		}
+	}
+	if !strings.HasPrefix(body, addr) {
+		return nil, &connWrap.Error{Type: connWrap.ErrParse, Send: input, Received: []byte(line), Err: errors.New("unexpected address")}
+	}
+	if values, err = parseValues(body[len(addr):]); err != nil {
+		return nil, &connWrap.Error{Type: connWrap.ErrParse, Send: input, Received: []byte(line), Err: err}
 	}
 	return values, nil
 }
+
+// parseValues parses values that each start with their sign, such as "+0.01-2.5".
+func parseValues(s string) (values []*float64, err error) {
+	for len(s) > 0 {
+		if s[0] != '+' && s[0] != '-' {
+			return nil, fmt.Errorf("value %q does not start with a sign", s)
+		}
+		end := strings.IndexAny(s[1:], "+-") + 1
+		if end == 0 {
+			end = len(s)
+		}
+		f, err := strconv.ParseFloat(s[:end], 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, &f)
+		s = s[end:]
+	}
+	return values, nil
+}
+
+// parseMeasurementReply parses the atttn reply of a measurement command, countDigits is the width of n.
+func parseMeasurementReply(addr string, reply string, countDigits int) (wait time.Duration, count int, err error) {
+	if len(reply) != len(addr)+3+countDigits || !strings.HasPrefix(reply, addr) {
+		return 0, 0, fmt.Errorf("unexpected measurement reply %q", reply)
+	}
+	seconds, err := strconv.Atoi(reply[len(addr) : len(addr)+3])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected measurement reply %q", reply)
+	}
+	count, err = strconv.Atoi(reply[len(addr)+3:])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected measurement reply %q", reply)
+	}
+	return time.Duration(seconds) * time.Second, count, nil
+}
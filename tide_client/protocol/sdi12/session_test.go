@@ -208,3 +208,187 @@ func TestGetDataHoldsBusAfterParseError(t *testing.T) {
 		t.Fatalf("bus unlocked after %v, want at least 800ms settle time", lockedAfter)
 	}
 }
+
+func TestCommandReturnsResponseLine(t *testing.T) {
+	t.Parallel()
+
+	rawConn := &stubConnCommon{
+		readResults: []stubReadResult{{data: []byte("013OTT HydrometPLS  1.0\r\n")}},
+	}
+	session := NewSession(connWrap.NewBusWithQuietTime(rawConn, 0), ModeArduino)
+
+	response, err := session.Command("0I!", 2)
+	if err != nil {
+		t.Fatalf("Command returned error: %v", err)
+	}
+	if response != "013OTT HydrometPLS  1.0" {
+		t.Fatalf("response = %q", response)
+	}
+	want := []byte{'0', 'I', '!', 2, arduinoCommandEnd}
+	if len(rawConn.writes) != 1 || !bytes.Equal(rawConn.writes[0], want) {
+		t.Fatalf("writes = %v, want %v", rawConn.writes, want)
+	}
+}
+
+func TestMeasureParsesReply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		command   string
+		reply     string
+		wantCount int
+		wantErr   bool
+	}{
+		{name: "standard", command: "M", reply: "00003\r\n", wantCount: 3},
+		{name: "concurrent", command: "C1", reply: "000012\r\n", wantCount: 12},
+		{name: "concurrentShortReply", command: "C", reply: "00003\r\n", wantErr: true},
+		{name: "wrongAddr", command: "M", reply: "10003\r\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rawConn := &stubConnCommon{
+				readResults: []stubReadResult{{data: []byte(tt.reply)}},
+			}
+			session := NewSession(connWrap.NewBusWithQuietTime(rawConn, 0), ModeNative)
+
+			count, err := session.Measure("0", 0, tt.command)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Measure returned nil error, want parse error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Measure returned error: %v", err)
+			}
+			if count != tt.wantCount {
+				t.Fatalf("count = %d, want %d", count, tt.wantCount)
+			}
+			want := []byte("0" + tt.command + "!")
+			if len(rawConn.writes) != 1 || !bytes.Equal(rawConn.writes[0], want) {
+				t.Fatalf("writes = %q, want %q", rawConn.writes, want)
+			}
+		})
+	}
+}
+
+func TestContinuousMeasurementReturnsValues(t *testing.T) {
+	t.Parallel()
+
+	rawConn := &stubConnCommon{
+		readResults: []stubReadResult{{data: []byte("0+1.5-2\r\n")}},
+	}
+	session := NewSession(connWrap.NewBusWithQuietTime(rawConn, 0), ModeArduino)
+
+	values, err := session.ContinuousMeasurement("0", 1, "R0")
+	if err != nil {
+		t.Fatalf("ContinuousMeasurement returned error: %v", err)
+	}
+	if len(values) != 2 || *values[0] != 1.5 || *values[1] != -2 {
+		t.Fatalf("values = %v, want [1.5 -2]", values)
+	}
+	want := []byte{'0', 'R', '0', '!', 1, arduinoCommandEnd}
+	if len(rawConn.writes) != 1 || !bytes.Equal(rawConn.writes[0], want) {
+		t.Fatalf("writes = %v, want %v", rawConn.writes, want)
+	}
+}
+
+func TestGetDataCRCVerifiesReplies(t *testing.T) {
+	t.Parallel()
+
+	rawConn := &stubConnCommon{
+		readResults: []stubReadResult{{data: []byte("0+3.14OqZ\r\n")}},
+	}
+	session := NewSession(connWrap.NewBusWithQuietTime(rawConn, 0), ModeNative)
+
+	values, err := session.GetDataCRC("0", 0, 1)
+	if err != nil {
+		t.Fatalf("GetDataCRC returned error: %v", err)
+	}
+	if len(values) != 1 || *values[0] != 3.14 {
+		t.Fatalf("values = %v, want [3.14]", values)
+	}
+}
+
+func TestGetDataCRCRejectsCorruptedReply(t *testing.T) {
+	t.Parallel()
+
+	rawConn := &stubConnCommon{
+		readResults: []stubReadResult{{data: []byte("0+3.15OqZ\r\n")}},
+	}
+	session := NewSession(connWrap.NewBusWithQuietTime(rawConn, 0), ModeNative)
+
+	if _, err := session.GetDataCRC("0", 0, 1); !errors.Is(err, ErrCRC) {
+		t.Fatalf("GetDataCRC error = %v, want ErrCRC", err)
+	}
+}
+
+func TestMeasureEndsWaitOnServiceRequest(t *testing.T) {
+	t.Parallel()
+
+	rawConn := &stubConnCommon{
+		readResults: []stubReadResult{{data: []byte("00052\r\n")}, {data: []byte("0\r\n")}},
+	}
+	session := NewSession(connWrap.NewBusWithQuietTime(rawConn, 0), ModeNative)
+
+	start := time.Now()
+	count, err := session.Measure("0", 0, "MC")
+	if err != nil {
+		t.Fatalf("Measure returned error: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("count = %d, want 2", count)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Measure returned after %v, want the service request to end the 5s wait", elapsed)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	t.Parallel()
+
+	rawConn := &stubConnCommon{
+		readResults: []stubReadResult{{data: []byte("114OTT HYD PLS_C 110SN123456\r\n")}},
+	}
+	session := NewSession(connWrap.NewBusWithQuietTime(rawConn, 0), ModeNative)
+
+	id, err := session.Identify("1", 0)
+	if err != nil {
+		t.Fatalf("Identify returned error: %v", err)
+	}
+	want := Identification{Address: "1", SDI12Version: "14", Vendor: "OTT HYD", Model: "PLS_C", Version: "110", Serial: "SN123456"}
+	if id != want {
+		t.Fatalf("Identify = %+v, want %+v", id, want)
+	}
+}
+
+func TestQueryAndChangeAddress(t *testing.T) {
+	t.Parallel()
+
+	rawConn := &stubConnCommon{
+		readResults: []stubReadResult{{data: []byte("0\r\n")}, {data: []byte("3\r\n")}},
+	}
+	session := NewSession(connWrap.NewBusWithQuietTime(rawConn, 0), ModeNative)
+
+	addr, err := session.QueryAddress(0)
+	if err != nil {
+		t.Fatalf("QueryAddress returned error: %v", err)
+	}
+	if addr != "0" {
+		t.Fatalf("QueryAddress = %q, want 0", addr)
+	}
+	if err = session.ChangeAddress(addr, "3", 0); err != nil {
+		t.Fatalf("ChangeAddress returned error: %v", err)
+	}
+	wantWrites := []string{"?!", "0A3!"}
+	for i, want := range wantWrites {
+		if string(rawConn.writes[i]) != want {
+			t.Fatalf("writes[%d] = %q, want %q", i, rawConn.writes[i], want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package syncv2
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	syncpb "tide/pkg/pb/syncproto"
+)
+
+// maxUnackedBatches bounds the tracked batches when the server stops acknowledging.
+// Dropping is safe for points that are in the outbox, the seq replay resends them.
+const maxUnackedBatches = 10000
+
+// UnackedBatches tracks realtime DataBatch frames the server has not acknowledged yet.
+// It outlives a session so the next one can resend what the previous one may have lost.
+// The zero value is ready to use.
+type UnackedBatches struct {
+	mu      sync.Mutex
+	lastID  uint64
+	batches []*syncpb.DataBatch // ordered by batch id
+}
+
+// add assigns the next batch id to batch and tracks it until acknowledged.
+func (u *UnackedBatches) add(batch *syncpb.DataBatch) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.lastID++
+	batch.BatchId = u.lastID
+	if len(u.batches) >= maxUnackedBatches {
+		u.batches = slices.Delete(u.batches, 0, 1)
+	}
+	u.batches = append(u.batches, batch)
+}
+
+// ack stops tracking a batch, it returns the newest outbox seq of its points, 0 if it has none or is not tracked.
+func (u *UnackedBatches) ack(batchID uint64) int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	i, ok := slices.BinarySearchFunc(u.batches, batchID, func(b *syncpb.DataBatch, id uint64) int {
+		return cmp.Compare(b.BatchId, id)
+	})
+	if !ok {
+		return 0
+	}
+	var seq int64
+	for _, p := range u.batches[i].Points {
+		seq = max(seq, p.Seq)
+	}
+	u.batches = slices.Delete(u.batches, i, i+1)
+	return seq
+}
+
+// pending returns the number of batches waiting for an acknowledgement.
+func (u *UnackedBatches) pending() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return len(u.batches)
+}
+
+// take removes and returns the points of every unacknowledged batch in send order.
+func (u *UnackedBatches) take() []*syncpb.DataPoint {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	var points []*syncpb.DataPoint
+	for _, b := range u.batches {
+		points = append(points, b.Points...)
+	}
+	u.batches = nil
+	return points
+}
@@ -0,0 +1,49 @@
+package syncv2
+
+import (
+	"testing"
+
+	syncpb "tide/pkg/pb/syncproto"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnackedBatches(t *testing.T) {
+	var u UnackedBatches
+
+	b1 := &syncpb.DataBatch{Points: []*syncpb.DataPoint{{ItemName: "item1", Seq: 1}}}
+	b2 := &syncpb.DataBatch{Points: []*syncpb.DataPoint{{ItemName: "item1", Seq: 2}}}
+	b3 := &syncpb.DataBatch{Points: []*syncpb.DataPoint{{ItemName: "item2"}}}
+	u.add(b1)
+	u.add(b2)
+	u.add(b3)
+	require.Equal(t, []uint64{1, 2, 3}, []uint64{b1.BatchId, b2.BatchId, b3.BatchId})
+	require.Equal(t, 3, u.pending())
+
+	require.Equal(t, int64(2), u.ack(b2.BatchId))
+	require.Zero(t, u.ack(42)) // unknown ids are ignored
+	require.Equal(t, 2, u.pending())
+
+	points := u.take()
+	require.Len(t, points, 2)
+	require.Equal(t, int64(1), points[0].Seq)
+	require.Equal(t, "item2", points[1].ItemName)
+	require.Zero(t, u.pending())
+
+	// Ids keep increasing across sessions, a late ack never matches a new batch.
+	b4 := &syncpb.DataBatch{}
+	u.add(b4)
+	require.Equal(t, uint64(4), b4.BatchId)
+}
+
+func TestUnackedBatches_DropsOldest(t *testing.T) {
+	var u UnackedBatches
+	for range maxUnackedBatches + 1 {
+		u.add(&syncpb.DataBatch{})
+	}
+	require.Equal(t, maxUnackedBatches, u.pending())
+	u.ack(1)
+	require.Equal(t, maxUnackedBatches, u.pending())
+	u.ack(2)
+	require.Equal(t, maxUnackedBatches-1, u.pending())
+}
@@ -0,0 +1,35 @@
+package syncv2
+
+import "time"
+
+const (
+	replayDataBatchSize    = 128 // initial size
+	minReplayDataBatchSize = 16
+	maxReplayDataBatchSize = 4096
+	// replayBatchDuration is the send time aimed at per replay batch: large enough to amortize
+	// frame and compression overhead, small enough that a slow link still makes visible progress.
+	replayBatchDuration = 2 * time.Second
+)
+
+// batchSizer adapts the replay batch size to the observed link throughput.
+// The size changes at most by a factor of two per batch, so a single stall does not collapse it.
+type batchSizer struct {
+	size int
+}
+
+func newBatchSizer() *batchSizer {
+	return &batchSizer{size: replayDataBatchSize}
+}
+
+// observe records that a batch of n points took elapsed to send.
+func (b *batchSizer) observe(n int, elapsed time.Duration) {
+	if n < b.size {
+		// A partial batch is the end of the backlog, it says nothing about the link.
+		return
+	}
+	ideal := 2 * b.size
+	if elapsed > 0 {
+		ideal = int(float64(n) * float64(replayBatchDuration) / float64(elapsed))
+	}
+	b.size = min(max(ideal, b.size/2, minReplayDataBatchSize), 2*b.size, maxReplayDataBatchSize)
+}
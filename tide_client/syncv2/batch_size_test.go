@@ -0,0 +1,38 @@
+package syncv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchSizer(t *testing.T) {
+	b := newBatchSizer()
+	require.Equal(t, replayDataBatchSize, b.size)
+
+	// Fast link: grows, at most doubling per batch, up to the max.
+	b.observe(b.size, time.Millisecond)
+	require.Equal(t, 2*replayDataBatchSize, b.size)
+	for range 10 {
+		b.observe(b.size, time.Millisecond)
+	}
+	require.Equal(t, maxReplayDataBatchSize, b.size)
+
+	// Slow link: shrinks, at most halving per batch, down to the min.
+	b.observe(b.size, time.Minute)
+	require.Equal(t, maxReplayDataBatchSize/2, b.size)
+	for range 10 {
+		b.observe(b.size, time.Minute)
+	}
+	require.Equal(t, minReplayDataBatchSize, b.size)
+
+	// Converges to the batch that takes replayBatchDuration.
+	b.size = 1000
+	b.observe(1000, replayBatchDuration*2)
+	require.Equal(t, 500, b.size)
+
+	// The last, partial batch is ignored.
+	b.observe(10, time.Minute)
+	require.Equal(t, 500, b.size)
+}
@@ -2,32 +2,59 @@ package syncv2
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"log/slog"
-	"net"
 	"net/http"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"tide/common"
 	internalsyncv2 "tide/internal/syncv2"
+	"tide/pkg/logring"
 	"tide/pkg/pubsub"
 )
 
-type GetDataHistoryFn func(item string, startUnixMs, endUnixMs int64) ([]common.DataTimeStruct, error)
+type GetOutboxAfterFn func(afterSeq int64, limit int) ([]common.SeqItemNameDataTimeStruct, error)
+type CountOutboxAfterFn func(afterSeq int64) (int64, error)
+type SaveStoredSeqFn func(seq int64) error
 type GetItemStatusLogAfterFn func(afterRowID int64) ([]common.RowIdItemStatusStruct, error)
 type SubscribeFn func(*pubsub.Subscriber, pubsub.TopicSet)
 type UnsubscribeFn func(*pubsub.Subscriber)
 type GetCameraFn func(name string) (snapshotURL, username, password string, ok bool)
 type SnapshotFn func(url, username, password string) ([]byte, error)
+type ApplyDeviceConfigFn func(version int64, config []byte) error
+type DeviceCommandFn func(cmd DeviceCommand) ([]byte, error)
+
+// DeviceCommand is a raw command for a sensor on one of the station's buses, see syncpb.DeviceCommandRequest.
+type DeviceCommand struct {
+	Bus      string
+	Protocol string
+	Command  []byte
+	SlaveID  byte
+	Function byte
+	Address  uint16
+	Quantity uint16
+}
 
 type Config struct {
 	Addr              string
 	StationIdentifier string
+	AuthKey           string
+	OutboxID          string
+	// TLS is used for https addrs when Deps.HTTPClient is nil, e.g. to present a client certificate.
+	TLS *tls.Config
 }
 
 type Deps struct {
-	StationInfoFn         func() common.StationInfoStruct
-	GetDataHistory        GetDataHistoryFn
+	StationInfoFn  func() common.StationInfoStruct
+	GetOutboxAfter GetOutboxAfterFn
+	// CountOutboxAfter reports the replay backlog in the station health, it is optional.
+	CountOutboxAfter CountOutboxAfterFn
+	// SaveStoredSeq records the newest outbox seq the server is known to have stored, so that older rows can be pruned.
+	// It is optional.
+	SaveStoredSeq         SaveStoredSeqFn
 	GetItemStatusLogAfter GetItemStatusLogAfterFn
 	Subscribe             SubscribeFn
 	Unsubscribe           UnsubscribeFn
@@ -36,6 +63,16 @@ type Deps struct {
 	Snapshot              SnapshotFn
 	HTTPClient            *http.Client
 	Logger                *slog.Logger
+	// Unacked should be shared by the clients of consecutive sessions, so unacknowledged data is resent.
+	Unacked *UnackedBatches
+	// ApplyDeviceConfig accepts a device config pushed by the server, it is optional.
+	ApplyDeviceConfig ApplyDeviceConfigFn
+	// DeviceCommand runs commands the server sends to sensors, it is optional.
+	DeviceCommand DeviceCommandFn
+	// Logs is read by LogRequest, it is optional.
+	Logs *logring.Buffer
+	// ClockOffset receives the offset of the server clock to the station clock measured in each handshake, it is optional.
+	ClockOffset func(offset time.Duration)
 }
 
 type Client struct {
@@ -43,6 +80,8 @@ type Client struct {
 	deps Deps
 
 	mainSendMu sync.Mutex
+	// storedSeq is the newest outbox seq the server is known to have stored.
+	storedSeq atomic.Int64
 }
 
 func NewClient(cfg Config, deps Deps) (*Client, error) {
@@ -52,11 +91,17 @@ func NewClient(cfg Config, deps Deps) (*Client, error) {
 	if cfg.StationIdentifier == "" {
 		return nil, errors.New("empty station identifier")
 	}
+	if cfg.AuthKey == "" {
+		return nil, errors.New("empty auth key")
+	}
+	if cfg.OutboxID == "" {
+		return nil, errors.New("empty outbox id")
+	}
 	if deps.StationInfoFn == nil {
 		return nil, errors.New("station info func is nil")
 	}
-	if deps.GetDataHistory == nil {
-		return nil, errors.New("get data history func is nil")
+	if deps.GetOutboxAfter == nil {
+		return nil, errors.New("get outbox after func is nil")
 	}
 	if deps.GetItemStatusLogAfter == nil {
 		return nil, errors.New("get item status log after func is nil")
@@ -76,8 +121,18 @@ func NewClient(cfg Config, deps Deps) (*Client, error) {
 	if deps.Snapshot == nil {
 		return nil, errors.New("snapshot func is nil")
 	}
+	if deps.Unacked == nil {
+		deps.Unacked = &UnackedBatches{}
+	}
 	if deps.HTTPClient == nil {
 		deps.HTTPClient = &http.Client{}
+		if cfg.TLS != nil {
+			// A custom TLS config keeps the transport on HTTP/1.1, which the upgrade needs.
+			deps.HTTPClient.Transport = &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: cfg.TLS,
+			}
+		}
 	}
 
 	return &Client{
@@ -105,11 +160,7 @@ func (c *Client) Run(ctx context.Context) error {
 	}
 	defer func() { _ = conn.Close() }()
 
-	netConn, ok := conn.(net.Conn)
-	if !ok {
-		return errors.New("upgrade connection is not net.Conn")
-	}
-	return c.runOnConn(ctx, netConn)
+	return c.runOnConn(ctx, conn)
 }
 
 func (c *Client) logger() *slog.Logger {
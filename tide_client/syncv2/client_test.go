@@ -1,9 +1,13 @@
 package syncv2
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"io"
+	"log/slog"
 	"net"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -12,7 +16,9 @@ import (
 	"tide/common"
 	internalsyncv2 "tide/internal/syncv2"
 	"tide/pkg/custype"
+	"tide/pkg/logring"
 	syncpb "tide/pkg/pb/syncproto"
+	"tide/pkg/pbstream"
 	"tide/pkg/pubsub"
 
 	"github.com/hashicorp/yamux"
@@ -20,12 +26,18 @@ import (
 )
 
 type fakeStore struct {
-	dataByItem map[string][]common.DataTimeStruct
+	outbox     []common.SeqItemNameDataTimeStruct
 	statusLogs []common.RowIdItemStatusStruct
 }
 
-func (s fakeStore) GetDataHistory(item string, startUnixMs, endUnixMs int64) ([]common.DataTimeStruct, error) {
-	return s.dataByItem[item], nil
+func (s fakeStore) GetOutboxAfter(afterSeq int64, limit int) ([]common.SeqItemNameDataTimeStruct, error) {
+	var ds []common.SeqItemNameDataTimeStruct
+	for _, d := range s.outbox {
+		if d.Seq > afterSeq && len(ds) < limit {
+			ds = append(ds, d)
+		}
+	}
+	return ds, nil
 }
 
 func (s fakeStore) GetItemStatusLogAfter(afterRowID int64) ([]common.RowIdItemStatusStruct, error) {
@@ -100,6 +112,25 @@ func openServerCommandStream(t *testing.T, session *yamux.Session) internalsyncv
 	return stream
 }
 
+const (
+	testAuthKey  = "key1"
+	testOutboxID = "outbox1"
+)
+
+var testAuthChallenge = []byte("challenge")
+
+func sendServerHelloAndRecvAuth(t *testing.T, stream internalsyncv2.StationMessageStream) {
+	t.Helper()
+
+	require.NoError(t, stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ServerHello{
+		ServerHello: &syncpb.ServerHello{ServerVersion: "test", AuthChallenge: testAuthChallenge},
+	}}))
+	f := recvStationFrame(t, stream)
+	auth, ok := f.Body.(*syncpb.StationMessage_ClientAuth)
+	require.True(t, ok)
+	require.True(t, internalsyncv2.VerifyAuthMAC(testAuthKey, testAuthChallenge, "station1", auth.ClientAuth.Mac))
+}
+
 func setupClientConn(t *testing.T, ctx context.Context, c *Client) (internalsyncv2.StationMessageStream, *yamux.Session, chan error) {
 	t.Helper()
 
@@ -127,9 +158,11 @@ func TestClient_RunOnConn_ReplayRealtimeAndSnapshot(t *testing.T) {
 	t.Cleanup(cancel)
 
 	store := fakeStore{
-		dataByItem: map[string][]common.DataTimeStruct{
-			"item1": {{Value: 1, Millisecond: custype.UnixMs(1100)}},
-			"item2": {{Value: 2, Millisecond: custype.UnixMs(1200)}},
+		outbox: []common.SeqItemNameDataTimeStruct{
+			{Seq: 1, ItemNameDataTimeStruct: common.ItemNameDataTimeStruct{ItemName: "item1", DataTimeStruct: common.DataTimeStruct{Value: 0, Millisecond: custype.UnixMs(1000)}}},
+			{Seq: 2, ItemNameDataTimeStruct: common.ItemNameDataTimeStruct{ItemName: "item1", DataTimeStruct: common.DataTimeStruct{Value: 1, Millisecond: custype.UnixMs(1100)}}},
+			// backfilled with an older timestamp, still replayed because its seq is newer
+			{Seq: 3, ItemNameDataTimeStruct: common.ItemNameDataTimeStruct{ItemName: "item2", DataTimeStruct: common.DataTimeStruct{Value: 2, Millisecond: custype.UnixMs(900)}}},
 		},
 		statusLogs: []common.RowIdItemStatusStruct{
 			{
@@ -147,11 +180,23 @@ func TestClient_RunOnConn_ReplayRealtimeAndSnapshot(t *testing.T) {
 	broker := &fakeBroker{subscribeCh: make(chan *pubsub.Subscriber, 1)}
 	cameraLookup := fakeCameraLookup{ok: true}
 	snapshotter := fakeSnapshotter{data: []byte("abcd")}
+	// Left over from a previous session: the point with a seq comes back through the outbox replay.
+	unacked := &UnackedBatches{}
+	var (
+		storedSeqMu sync.Mutex
+		storedSeqs  []int64
+	)
+	unacked.add(&syncpb.DataBatch{Points: []*syncpb.DataPoint{
+		{ItemName: "item1", Value: 1, UnixMs: 1100, Kind: syncpb.DataKind_DATA_KIND_NORMAL, Seq: 2},
+		{ItemName: "item2", Value: 5, UnixMs: 1500, Kind: syncpb.DataKind_DATA_KIND_GPIO},
+	}})
 
 	c, err := NewClient(
 		Config{
 			Addr:              "http://station.example",
 			StationIdentifier: "station1",
+			AuthKey:           testAuthKey,
+			OutboxID:          testOutboxID,
 		},
 		Deps{
 			StationInfoFn: func() common.StationInfoStruct {
@@ -166,13 +211,20 @@ func TestClient_RunOnConn_ReplayRealtimeAndSnapshot(t *testing.T) {
 					Cameras: []string{"cam1"},
 				}
 			},
-			GetDataHistory:        store.GetDataHistory,
+			GetOutboxAfter:        store.GetOutboxAfter,
 			GetItemStatusLogAfter: store.GetItemStatusLogAfter,
 			Subscribe:             broker.Subscribe,
 			Unsubscribe:           broker.Unsubscribe,
 			IngestLock:            &sync.Mutex{},
 			GetCamera:             cameraLookup.GetCamera,
 			Snapshot:              snapshotter.Snapshot,
+			Unacked:               unacked,
+			SaveStoredSeq: func(seq int64) error {
+				storedSeqMu.Lock()
+				defer storedSeqMu.Unlock()
+				storedSeqs = append(storedSeqs, seq)
+				return nil
+			},
 		},
 	)
 	require.NoError(t, err)
@@ -186,10 +238,9 @@ func TestClient_RunOnConn_ReplayRealtimeAndSnapshot(t *testing.T) {
 		require.True(t, ok)
 		require.Equal(t, "station1", hello.ClientHello.StationIdentifier)
 		require.Equal(t, internalsyncv2.ProtocolVersion, hello.ClientHello.ProtocolVersion)
+		require.Equal(t, testOutboxID, hello.ClientHello.OutboxId)
 	}
-	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ServerHello{
-		ServerHello: &syncpb.ServerHello{ServerVersion: "test"},
-	}}))
+	sendServerHelloAndRecvAuth(t, serverStream)
 	{
 		f := recvStationFrame(t, serverStream)
 		info, ok := f.Body.(*syncpb.StationMessage_StationInfo)
@@ -197,14 +248,27 @@ func TestClient_RunOnConn_ReplayRealtimeAndSnapshot(t *testing.T) {
 		require.Equal(t, "station1", info.StationInfo.Identifier)
 	}
 
-	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ItemsLatest{
-		ItemsLatest: &syncpb.ItemsLatest{LatestUnixMs: map[string]int64{"item1": 0, "item2": 0}},
+	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataCursor{
+		DataCursor: &syncpb.DataCursor{LastSeq: 1},
 	}}))
 	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StatusLatest{
 		StatusLatest: &syncpb.StatusLatest{LatestRowId: 0},
 	}}))
 
 	// ---- replay ----
+	var resentBatchID uint64
+	{
+		f := recvStationFrame(t, serverStream)
+		db, ok := f.Body.(*syncpb.StationMessage_DataBatch)
+		require.True(t, ok)
+		require.True(t, db.DataBatch.Replay)
+		require.NotZero(t, db.DataBatch.BatchId)
+		require.Len(t, db.DataBatch.Points, 1)
+		require.Equal(t, "item2", db.DataBatch.Points[0].ItemName)
+		require.Equal(t, float64(5), db.DataBatch.Points[0].Value)
+		require.Equal(t, syncpb.DataKind_DATA_KIND_GPIO, db.DataBatch.Points[0].Kind)
+		resentBatchID = db.DataBatch.BatchId
+	}
 	{
 		f := recvStationFrame(t, serverStream)
 		db, ok := f.Body.(*syncpb.StationMessage_DataBatch)
@@ -212,18 +276,17 @@ func TestClient_RunOnConn_ReplayRealtimeAndSnapshot(t *testing.T) {
 		require.True(t, db.DataBatch.Replay)
 		require.Len(t, db.DataBatch.Points, 2)
 
-		got := make(map[string]*syncpb.DataPoint)
-		for _, p := range db.DataBatch.Points {
-			got[p.ItemName] = p
-		}
-		require.Contains(t, got, "item1")
-		require.Contains(t, got, "item2")
-		require.Equal(t, float64(1), got["item1"].Value)
-		require.Equal(t, int64(1100), got["item1"].UnixMs)
-		require.Equal(t, syncpb.DataKind_DATA_KIND_NORMAL, got["item1"].Kind)
-		require.Equal(t, float64(2), got["item2"].Value)
-		require.Equal(t, int64(1200), got["item2"].UnixMs)
-		require.Equal(t, syncpb.DataKind_DATA_KIND_NORMAL, got["item2"].Kind)
+		p1, p2 := db.DataBatch.Points[0], db.DataBatch.Points[1]
+		require.Equal(t, int64(2), p1.Seq)
+		require.Equal(t, "item1", p1.ItemName)
+		require.Equal(t, float64(1), p1.Value)
+		require.Equal(t, int64(1100), p1.UnixMs)
+		require.Equal(t, syncpb.DataKind_DATA_KIND_NORMAL, p1.Kind)
+		require.Equal(t, int64(3), p2.Seq)
+		require.Equal(t, "item2", p2.ItemName)
+		require.Equal(t, float64(2), p2.Value)
+		require.Equal(t, int64(900), p2.UnixMs)
+		require.Equal(t, syncpb.DataKind_DATA_KIND_NORMAL, p2.Kind)
 	}
 	{
 		f := recvStationFrame(t, serverStream)
@@ -262,11 +325,14 @@ func TestClient_RunOnConn_ReplayRealtimeAndSnapshot(t *testing.T) {
 
 	subscriber.Ch <- common.SendMsgStruct{
 		Type: common.MsgGpioData,
-		Body: common.ItemNameDataTimeStruct{
-			ItemName: "item1",
-			DataTimeStruct: common.DataTimeStruct{
-				Value:       9,
-				Millisecond: custype.UnixMs(2000),
+		Body: common.SeqItemNameDataTimeStruct{
+			Seq: 4,
+			ItemNameDataTimeStruct: common.ItemNameDataTimeStruct{
+				ItemName: "item1",
+				DataTimeStruct: common.DataTimeStruct{
+					Value:       9,
+					Millisecond: custype.UnixMs(2000),
+				},
 			},
 		},
 	}
@@ -281,6 +347,22 @@ func TestClient_RunOnConn_ReplayRealtimeAndSnapshot(t *testing.T) {
 		require.Equal(t, float64(9), db.DataBatch.Points[0].Value)
 		require.Equal(t, int64(2000), db.DataBatch.Points[0].UnixMs)
 		require.Equal(t, syncpb.DataKind_DATA_KIND_GPIO, db.DataBatch.Points[0].Kind)
+		require.Equal(t, int64(4), db.DataBatch.Points[0].Seq)
+		require.NotZero(t, db.DataBatch.BatchId)
+		require.Equal(t, 2, unacked.pending())
+
+		for _, id := range []uint64{resentBatchID, db.DataBatch.BatchId} {
+			require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataAck{
+				DataAck: &syncpb.DataAck{BatchId: id},
+			}}))
+		}
+		require.Eventually(t, func() bool { return unacked.pending() == 0 }, 2*time.Second, 10*time.Millisecond)
+		// The cursor of the handshake, then the seq of the acknowledged realtime point.
+		require.Eventually(t, func() bool {
+			storedSeqMu.Lock()
+			defer storedSeqMu.Unlock()
+			return slices.Equal(storedSeqs, []int64{1, 4})
+		}, 2*time.Second, 10*time.Millisecond)
 	}
 
 	cancel()
@@ -292,7 +374,7 @@ func TestClient_RunOnConn_SnapshotCameraNotFound(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
 
-	store := fakeStore{dataByItem: map[string][]common.DataTimeStruct{}}
+	store := fakeStore{}
 	broker := &fakeBroker{}
 	cameraLookup := fakeCameraLookup{ok: false}
 	snapshotter := fakeSnapshotter{data: []byte("ignored")}
@@ -301,6 +383,8 @@ func TestClient_RunOnConn_SnapshotCameraNotFound(t *testing.T) {
 		Config{
 			Addr:              "http://station.example",
 			StationIdentifier: "station1",
+			AuthKey:           testAuthKey,
+			OutboxID:          testOutboxID,
 		},
 		Deps{
 			StationInfoFn: func() common.StationInfoStruct {
@@ -312,7 +396,7 @@ func TestClient_RunOnConn_SnapshotCameraNotFound(t *testing.T) {
 					Cameras: []string{},
 				}
 			},
-			GetDataHistory:        store.GetDataHistory,
+			GetOutboxAfter:        store.GetOutboxAfter,
 			GetItemStatusLogAfter: store.GetItemStatusLogAfter,
 			Subscribe:             broker.Subscribe,
 			Unsubscribe:           broker.Unsubscribe,
@@ -326,12 +410,10 @@ func TestClient_RunOnConn_SnapshotCameraNotFound(t *testing.T) {
 	serverStream, serverSession, clientErrCh := setupClientConn(t, ctx, c)
 
 	_ = recvStationFrame(t, serverStream) // client hello
-	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ServerHello{
-		ServerHello: &syncpb.ServerHello{ServerVersion: "test"},
-	}}))
+	sendServerHelloAndRecvAuth(t, serverStream)
 	_ = recvStationFrame(t, serverStream) // station info
-	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ItemsLatest{
-		ItemsLatest: &syncpb.ItemsLatest{LatestUnixMs: map[string]int64{"item1": 0}},
+	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataCursor{
+		DataCursor: &syncpb.DataCursor{LastSeq: 0},
 	}}))
 	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StatusLatest{
 		StatusLatest: &syncpb.StatusLatest{LatestRowId: 0},
@@ -353,17 +435,90 @@ func TestClient_RunOnConn_SnapshotCameraNotFound(t *testing.T) {
 	_ = <-clientErrCh
 }
 
+func TestClient_RunOnConn_FollowLogs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	store := fakeStore{}
+	broker := &fakeBroker{}
+	logs := logring.NewBuffer(10)
+	logger := slog.New(logring.NewHandler(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}), logs))
+	logger.Info("buffered info")
+	logger.Warn("buffered warn", "device", "PWD50")
+
+	c, err := NewClient(
+		Config{
+			Addr:              "http://station.example",
+			StationIdentifier: "station1",
+			AuthKey:           testAuthKey,
+			OutboxID:          testOutboxID,
+		},
+		Deps{
+			StationInfoFn: func() common.StationInfoStruct {
+				return common.StationInfoStruct{Identifier: "station1", Devices: common.StringMapMap{"dev1": {"t1": "item1"}}}
+			},
+			GetOutboxAfter:        store.GetOutboxAfter,
+			GetItemStatusLogAfter: store.GetItemStatusLogAfter,
+			Subscribe:             broker.Subscribe,
+			Unsubscribe:           broker.Unsubscribe,
+			IngestLock:            &sync.Mutex{},
+			GetCamera:             fakeCameraLookup{}.GetCamera,
+			Snapshot:              fakeSnapshotter{}.Snapshot,
+			Logs:                  logs,
+		},
+	)
+	require.NoError(t, err)
+
+	serverStream, serverSession, clientErrCh := setupClientConn(t, ctx, c)
+
+	_ = recvStationFrame(t, serverStream) // client hello
+	sendServerHelloAndRecvAuth(t, serverStream)
+	_ = recvStationFrame(t, serverStream) // station info
+	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataCursor{
+		DataCursor: &syncpb.DataCursor{LastSeq: 0},
+	}}))
+	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StatusLatest{
+		StatusLatest: &syncpb.StatusLatest{LatestRowId: 0},
+	}}))
+
+	cmdStream := openServerCommandStream(t, serverSession)
+	require.NoError(t, cmdStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_LogRequest{
+		LogRequest: &syncpb.LogRequest{MinLevel: int32(slog.LevelWarn), Follow: true},
+	}}))
+	f := recvStationFrame(t, cmdStream)
+	batch, ok := f.Body.(*syncpb.StationMessage_LogBatch)
+	require.True(t, ok)
+	require.Len(t, batch.LogBatch.Entries, 1)
+	require.Equal(t, "buffered warn", batch.LogBatch.Entries[0].Message)
+	require.Len(t, batch.LogBatch.Entries[0].Attrs, 1)
+	require.Equal(t, "PWD50", batch.LogBatch.Entries[0].Attrs[0].Value)
+
+	logger.Info("new info")
+	logger.Error("new error")
+	f = recvStationFrame(t, cmdStream)
+	batch, ok = f.Body.(*syncpb.StationMessage_LogBatch)
+	require.True(t, ok)
+	require.Len(t, batch.LogBatch.Entries, 1)
+	require.Equal(t, "new error", batch.LogBatch.Entries[0].Message)
+	require.Equal(t, int32(slog.LevelError), batch.LogBatch.Entries[0].Level)
+
+	cancel()
+	_ = <-clientErrCh
+}
+
 func TestClient_RunOnConn_DroppedSubscriberClosesSession(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
 
-	store := fakeStore{dataByItem: map[string][]common.DataTimeStruct{}}
+	store := fakeStore{}
 	broker := &fakeBroker{subscribeCh: make(chan *pubsub.Subscriber, 1)}
 
 	c, err := NewClient(
 		Config{
 			Addr:              "http://station.example",
 			StationIdentifier: "station1",
+			AuthKey:           testAuthKey,
+			OutboxID:          testOutboxID,
 		},
 		Deps{
 			StationInfoFn: func() common.StationInfoStruct {
@@ -374,7 +529,7 @@ func TestClient_RunOnConn_DroppedSubscriberClosesSession(t *testing.T) {
 					},
 				}
 			},
-			GetDataHistory:        store.GetDataHistory,
+			GetOutboxAfter:        store.GetOutboxAfter,
 			GetItemStatusLogAfter: store.GetItemStatusLogAfter,
 			Subscribe:             broker.Subscribe,
 			Unsubscribe:           broker.Unsubscribe,
@@ -388,12 +543,10 @@ func TestClient_RunOnConn_DroppedSubscriberClosesSession(t *testing.T) {
 	serverStream, _, clientErrCh := setupClientConn(t, ctx, c)
 
 	_ = recvStationFrame(t, serverStream)
-	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ServerHello{
-		ServerHello: &syncpb.ServerHello{ServerVersion: "test"},
-	}}))
+	sendServerHelloAndRecvAuth(t, serverStream)
 	_ = recvStationFrame(t, serverStream)
-	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ItemsLatest{
-		ItemsLatest: &syncpb.ItemsLatest{LatestUnixMs: map[string]int64{"item1": 0}},
+	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataCursor{
+		DataCursor: &syncpb.DataCursor{LastSeq: 0},
 	}}))
 	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StatusLatest{
 		StatusLatest: &syncpb.StatusLatest{LatestRowId: 0},
@@ -414,3 +567,205 @@ func TestClient_RunOnConn_DroppedSubscriberClosesSession(t *testing.T) {
 		t.Fatal("timeout waiting for dropped subscriber to close session")
 	}
 }
+
+func TestClient_RunOnConn_Unauthenticated(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	store := fakeStore{}
+	broker := &fakeBroker{}
+
+	c, err := NewClient(
+		Config{
+			Addr:              "http://station.example",
+			StationIdentifier: "station1",
+			AuthKey:           testAuthKey,
+			OutboxID:          testOutboxID,
+		},
+		Deps{
+			StationInfoFn: func() common.StationInfoStruct {
+				return common.StationInfoStruct{
+					Identifier: "station1",
+					Devices: common.StringMapMap{
+						"dev1": {"t1": "item1"},
+					},
+				}
+			},
+			GetOutboxAfter:        store.GetOutboxAfter,
+			GetItemStatusLogAfter: store.GetItemStatusLogAfter,
+			Subscribe:             broker.Subscribe,
+			Unsubscribe:           broker.Unsubscribe,
+			IngestLock:            &sync.Mutex{},
+			GetCamera:             fakeCameraLookup{}.GetCamera,
+			Snapshot:              fakeSnapshotter{}.Snapshot,
+		},
+	)
+	require.NoError(t, err)
+
+	serverStream, _, clientErrCh := setupClientConn(t, ctx, c)
+
+	_ = recvStationFrame(t, serverStream) // client hello
+	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_Error{
+		Error: &syncpb.ErrorFrame{Code: internalsyncv2.ErrCodeUnauthenticated, Message: "station authentication failed"},
+	}}))
+
+	select {
+	case err = <-clientErrCh:
+		require.EqualError(t, err, "station authentication failed")
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for client to stop")
+	}
+}
+
+func TestClient_RunOnConn_ReportsClockOffset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	store := fakeStore{}
+	broker := &fakeBroker{}
+	offsetCh := make(chan time.Duration, 1)
+
+	c, err := NewClient(
+		Config{
+			Addr:              "http://station.example",
+			StationIdentifier: "station1",
+			AuthKey:           testAuthKey,
+			OutboxID:          testOutboxID,
+		},
+		Deps{
+			StationInfoFn:         func() common.StationInfoStruct { return common.StationInfoStruct{Identifier: "station1"} },
+			GetOutboxAfter:        store.GetOutboxAfter,
+			GetItemStatusLogAfter: store.GetItemStatusLogAfter,
+			Subscribe:             broker.Subscribe,
+			Unsubscribe:           broker.Unsubscribe,
+			IngestLock:            &sync.Mutex{},
+			GetCamera:             fakeCameraLookup{}.GetCamera,
+			Snapshot:              fakeSnapshotter{}.Snapshot,
+			ClockOffset:           func(offset time.Duration) { offsetCh <- offset },
+		},
+	)
+	require.NoError(t, err)
+
+	serverStream, _, _ := setupClientConn(t, ctx, c)
+	_ = recvStationFrame(t, serverStream) // client hello
+	// The server clock is an hour ahead of the station.
+	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ServerHello{
+		ServerHello: &syncpb.ServerHello{
+			ServerVersion: "test",
+			AuthChallenge: testAuthChallenge,
+			ServerUnixMs:  time.Now().Add(time.Hour).UnixMilli(),
+		},
+	}}))
+	f := recvStationFrame(t, serverStream)
+	auth, ok := f.Body.(*syncpb.StationMessage_ClientAuth)
+	require.True(t, ok)
+	require.InDelta(t, time.Hour.Milliseconds(), auth.ClientAuth.ClockOffsetMs, 1000)
+	select {
+	case offset := <-offsetCh:
+		require.InDelta(t, time.Hour, offset, float64(time.Second))
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for clock offset")
+	}
+}
+
+func TestServerClockOffset(t *testing.T) {
+	sentAt := time.UnixMilli(10_000)
+	// Sent at 10s, received at 10.4s, the server stamped 70.2s: the station is a minute behind.
+	require.Equal(t, time.Minute, serverClockOffset(sentAt, sentAt.Add(400*time.Millisecond), 70_200))
+}
+
+func TestClient_RunOnConn_CompressedColumnarReplay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	store := fakeStore{}
+	for i := range 300 {
+		store.outbox = append(store.outbox, common.SeqItemNameDataTimeStruct{
+			Seq: int64(i + 1),
+			ItemNameDataTimeStruct: common.ItemNameDataTimeStruct{
+				ItemName:       []string{"item1", "item2"}[i%2],
+				DataTimeStruct: common.DataTimeStruct{Value: float64(i) / 100, Millisecond: custype.UnixMs(int64(i) * 60000)},
+			},
+		})
+	}
+	broker := &fakeBroker{}
+
+	c, err := NewClient(
+		Config{
+			Addr:              "http://station.example",
+			StationIdentifier: "station1",
+			AuthKey:           testAuthKey,
+			OutboxID:          testOutboxID,
+		},
+		Deps{
+			StationInfoFn: func() common.StationInfoStruct {
+				return common.StationInfoStruct{
+					Identifier: "station1",
+					Devices:    common.StringMapMap{"dev1": {"t1": "item1", "t2": "item2"}},
+				}
+			},
+			GetOutboxAfter:        store.GetOutboxAfter,
+			GetItemStatusLogAfter: store.GetItemStatusLogAfter,
+			Subscribe:             broker.Subscribe,
+			Unsubscribe:           broker.Unsubscribe,
+			IngestLock:            &sync.Mutex{},
+			GetCamera:             fakeCameraLookup{}.GetCamera,
+			Snapshot:              fakeSnapshotter{}.Snapshot,
+		},
+	)
+	require.NoError(t, err)
+
+	serverStream, _, clientErrCh := setupClientConn(t, ctx, c)
+
+	{
+		f := recvStationFrame(t, serverStream)
+		hello, ok := f.Body.(*syncpb.StationMessage_ClientHello)
+		require.True(t, ok)
+		require.Equal(t, pbstream.Compressions(), hello.ClientHello.Compressions)
+	}
+	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ServerHello{
+		ServerHello: &syncpb.ServerHello{
+			ServerVersion: "test",
+			AuthChallenge: testAuthChallenge,
+			Compression:   pbstream.CompressionGzip,
+			ColumnarData:  true,
+		},
+	}}))
+	require.NoError(t, serverStream.SetCompression(pbstream.CompressionGzip))
+	{
+		f := recvStationFrame(t, serverStream)
+		_, ok := f.Body.(*syncpb.StationMessage_ClientAuth)
+		require.True(t, ok)
+	}
+	_ = recvStationFrame(t, serverStream) // station info
+	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataCursor{
+		DataCursor: &syncpb.DataCursor{LastSeq: 0},
+	}}))
+	require.NoError(t, serverStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StatusLatest{
+		StatusLatest: &syncpb.StatusLatest{LatestRowId: 0},
+	}}))
+
+	var got []*syncpb.DataPoint
+	for len(got) < len(store.outbox) {
+		f := recvStationFrame(t, serverStream)
+		db, ok := f.Body.(*syncpb.StationMessage_DataBatch)
+		require.True(t, ok)
+		require.True(t, db.DataBatch.Replay)
+		require.Empty(t, db.DataBatch.Points)
+		require.NotEmpty(t, db.DataBatch.Columns)
+		points, err := internalsyncv2.DataBatchPoints(db.DataBatch)
+		require.NoError(t, err)
+		got = append(got, points...)
+	}
+	require.Len(t, got, len(store.outbox))
+	slices.SortFunc(got, func(a, b *syncpb.DataPoint) int { return cmp.Compare(a.Seq, b.Seq) })
+	for i, d := range store.outbox {
+		require.Equal(t, d.Seq, got[i].Seq)
+		require.Equal(t, d.ItemName, got[i].ItemName)
+		require.Equal(t, d.Value, got[i].Value)
+		require.Equal(t, d.Millisecond.ToInt64(), got[i].UnixMs)
+	}
+
+	cancel()
+	_ = <-clientErrCh
+}
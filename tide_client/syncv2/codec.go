@@ -2,8 +2,11 @@ package syncv2
 
 import (
 	"fmt"
+	"math"
 
 	"tide/common"
+	internalsyncv2 "tide/internal/syncv2"
+	"tide/pkg/logring"
 	syncpb "tide/pkg/pb/syncproto"
 )
 
@@ -14,7 +17,7 @@ func unexpectedStationFrameError(where string) error {
 func buildRealtimeFrame(msg common.SendMsgStruct) (*syncpb.StationMessage, bool) {
 	switch msg.Type {
 	case common.MsgData, common.MsgGpioData:
-		body, ok := msg.Body.(common.ItemNameDataTimeStruct)
+		body, ok := msg.Body.(common.SeqItemNameDataTimeStruct)
 		if !ok {
 			return nil, false
 		}
@@ -30,6 +33,8 @@ func buildRealtimeFrame(msg common.SendMsgStruct) (*syncpb.StationMessage, bool)
 						Value:    body.Value,
 						UnixMs:   body.Millisecond.ToInt64(),
 						Kind:     kind,
+						Seq:      body.Seq,
+						Flag:     uint32(body.Flag),
 					},
 				},
 			},
@@ -54,15 +59,12 @@ func buildRealtimeFrame(msg common.SendMsgStruct) (*syncpb.StationMessage, bool)
 		}}, true
 
 	case common.MsgRpiStatus:
-		body, ok := msg.Body.(common.RpiStatusTimeStruct)
+		body, ok := msg.Body.(common.StationHealthTimeStruct)
 		if !ok {
 			return nil, false
 		}
-		return &syncpb.StationMessage{Body: &syncpb.StationMessage_RpiStatus{
-			RpiStatus: &syncpb.RpiStatus{
-				CpuTemp: body.CpuTemp,
-				UnixMs:  body.Millisecond.ToInt64(),
-			},
+		return &syncpb.StationMessage{Body: &syncpb.StationMessage_StationHealth{
+			StationHealth: internalsyncv2.StationHealthToPB(body),
 		}}, true
 	}
 	return nil, false
@@ -108,3 +110,55 @@ func buildSnapshotResponseFrame(req *syncpb.CameraSnapshotRequest, getCamera Get
 		},
 	}}
 }
+
+func buildDeviceConfigResultFrame(push *syncpb.DeviceConfigPush, apply ApplyDeviceConfigFn) *syncpb.StationMessage {
+	result := &syncpb.DeviceConfigResult{Version: push.GetVersion()}
+	if apply == nil {
+		result.Error = "device config is not supported"
+	} else if err := apply(push.GetVersion(), push.GetConfig()); err != nil {
+		result.Error = err.Error()
+	}
+	return &syncpb.StationMessage{Body: &syncpb.StationMessage_DeviceConfigResult{DeviceConfigResult: result}}
+}
+
+func buildDeviceCommandResponseFrame(req *syncpb.DeviceCommandRequest, run DeviceCommandFn) *syncpb.StationMessage {
+	resp := &syncpb.DeviceCommandResponse{}
+	switch {
+	case run == nil:
+		resp.Error = "device commands are not supported"
+	case req.GetSlaveId() > math.MaxUint8 || req.GetFunction() > math.MaxUint8 ||
+		req.GetAddress() > math.MaxUint16 || req.GetQuantity() > math.MaxUint16:
+		resp.Error = "modbus parameter out of range"
+	default:
+		output, err := run(DeviceCommand{
+			Bus:      req.GetBus(),
+			Protocol: req.GetProtocol(),
+			Command:  req.GetCommand(),
+			SlaveID:  byte(req.GetSlaveId()),
+			Function: byte(req.GetFunction()),
+			Address:  uint16(req.GetAddress()),
+			Quantity: uint16(req.GetQuantity()),
+		})
+		resp.Output = output
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	}
+	return &syncpb.StationMessage{Body: &syncpb.StationMessage_DeviceCommandResponse{DeviceCommandResponse: resp}}
+}
+
+func buildLogBatchFrame(entries []logring.Entry) *syncpb.StationMessage {
+	batch := &syncpb.LogBatch{Entries: make([]*syncpb.LogEntry, 0, len(entries))}
+	for _, e := range entries {
+		pe := &syncpb.LogEntry{
+			UnixMs:  e.Time.UnixMilli(),
+			Level:   int32(e.Level),
+			Message: e.Message,
+		}
+		for _, a := range e.Attrs {
+			pe.Attrs = append(pe.Attrs, &syncpb.LogAttr{Key: a.Key, Value: a.Value})
+		}
+		batch.Entries = append(batch.Entries, pe)
+	}
+	return &syncpb.StationMessage{Body: &syncpb.StationMessage_LogBatch{LogBatch: batch}}
+}
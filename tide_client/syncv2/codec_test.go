@@ -14,11 +14,15 @@ import (
 func TestBuildRealtimeFrame_Data(t *testing.T) {
 	frame, ok := buildRealtimeFrame(common.SendMsgStruct{
 		Type: common.MsgData,
-		Body: common.ItemNameDataTimeStruct{
-			ItemName: "item1",
-			DataTimeStruct: common.DataTimeStruct{
-				Value:       1.23,
-				Millisecond: custype.UnixMs(1000),
+		Body: common.SeqItemNameDataTimeStruct{
+			Seq: 7,
+			ItemNameDataTimeStruct: common.ItemNameDataTimeStruct{
+				ItemName: "item1",
+				DataTimeStruct: common.DataTimeStruct{
+					Value:       1.23,
+					Millisecond: custype.UnixMs(1000),
+					Flag:        common.QCProbablyBad,
+				},
 			},
 		},
 	})
@@ -27,16 +31,21 @@ func TestBuildRealtimeFrame_Data(t *testing.T) {
 	require.True(t, ok)
 	require.Len(t, body.DataBatch.Points, 1)
 	require.Equal(t, syncpb.DataKind_DATA_KIND_NORMAL, body.DataBatch.Points[0].Kind)
+	require.Equal(t, int64(7), body.DataBatch.Points[0].Seq)
+	require.EqualValues(t, common.QCProbablyBad, body.DataBatch.Points[0].Flag)
 }
 
 func TestBuildRealtimeFrame_Gpio(t *testing.T) {
 	frame, ok := buildRealtimeFrame(common.SendMsgStruct{
 		Type: common.MsgGpioData,
-		Body: common.ItemNameDataTimeStruct{
-			ItemName: "item1",
-			DataTimeStruct: common.DataTimeStruct{
-				Value:       9.9,
-				Millisecond: custype.UnixMs(2000),
+		Body: common.SeqItemNameDataTimeStruct{
+			Seq: 7,
+			ItemNameDataTimeStruct: common.ItemNameDataTimeStruct{
+				ItemName: "item1",
+				DataTimeStruct: common.DataTimeStruct{
+					Value:       9.9,
+					Millisecond: custype.UnixMs(2000),
+				},
 			},
 		},
 	})
@@ -67,21 +76,29 @@ func TestBuildRealtimeFrame_ItemStatus(t *testing.T) {
 	require.Equal(t, int64(7), body.ItemStatusBatch.Logs[0].RowId)
 }
 
-func TestBuildRealtimeFrame_RpiStatus(t *testing.T) {
+func TestBuildRealtimeFrame_StationHealth(t *testing.T) {
+	cpuTemp := 45.6
 	frame, ok := buildRealtimeFrame(common.SendMsgStruct{
 		Type: common.MsgRpiStatus,
-		Body: common.RpiStatusTimeStruct{
-			RpiStatusStruct: common.RpiStatusStruct{
-				CpuTemp: 45.6,
+		Body: common.StationHealthTimeStruct{
+			StationHealthStruct: common.StationHealthStruct{
+				CpuTemp:       &cpuTemp,
+				Disks:         []common.DiskUsageStruct{{Path: "/data", FreeBytes: 10, TotalBytes: 20}},
+				Load1:         0.5,
+				SerialReopens: map[string]uint32{"/dev/ttyUSB0": 2},
 			},
 			Millisecond: custype.UnixMs(4000),
 		},
 	})
 	require.True(t, ok)
-	body, ok := frame.Body.(*syncpb.StationMessage_RpiStatus)
+	body, ok := frame.Body.(*syncpb.StationMessage_StationHealth)
 	require.True(t, ok)
-	require.Equal(t, float64(45.6), body.RpiStatus.CpuTemp)
-	require.Equal(t, int64(4000), body.RpiStatus.UnixMs)
+	require.Equal(t, 45.6, body.StationHealth.GetCpuTemp())
+	require.Equal(t, int64(4000), body.StationHealth.UnixMs)
+	require.Len(t, body.StationHealth.Disks, 1)
+	require.Equal(t, uint64(10), body.StationHealth.Disks[0].FreeBytes)
+	require.Equal(t, 0.5, body.StationHealth.Load1)
+	require.Equal(t, map[string]uint32{"/dev/ttyUSB0": 2}, body.StationHealth.SerialReopens)
 }
 
 func TestBuildSnapshotResponseFrame_NilRequest(t *testing.T) {
@@ -128,3 +145,53 @@ func TestBuildSnapshotResponseFrame_Success(t *testing.T) {
 	require.Equal(t, []byte("img"), body.CameraSnapshotResponse.Data)
 	require.Equal(t, "", body.CameraSnapshotResponse.Error)
 }
+
+func TestBuildDeviceConfigResultFrame(t *testing.T) {
+	push := &syncpb.DeviceConfigPush{Version: 2, Config: []byte(`{"uart":[]}`)}
+
+	frame := buildDeviceConfigResultFrame(push, nil)
+	body, ok := frame.Body.(*syncpb.StationMessage_DeviceConfigResult)
+	require.True(t, ok)
+	require.Equal(t, int64(2), body.DeviceConfigResult.Version)
+	require.Equal(t, "device config is not supported", body.DeviceConfigResult.Error)
+
+	var applied []byte
+	frame = buildDeviceConfigResultFrame(push, func(version int64, config []byte) error {
+		applied = config
+		return nil
+	})
+	body = frame.Body.(*syncpb.StationMessage_DeviceConfigResult)
+	require.Equal(t, "", body.DeviceConfigResult.Error)
+	require.Equal(t, push.Config, applied)
+
+	frame = buildDeviceConfigResultFrame(push, func(int64, []byte) error { return errors.New("invalid") })
+	body = frame.Body.(*syncpb.StationMessage_DeviceConfigResult)
+	require.Equal(t, "invalid", body.DeviceConfigResult.Error)
+}
+
+func TestBuildDeviceCommandResponseFrame(t *testing.T) {
+	req := &syncpb.DeviceCommandRequest{Bus: "/dev/ttyUSB0", Protocol: "modbus", SlaveId: 2, Function: 4, Address: 1, Quantity: 2}
+
+	frame := buildDeviceCommandResponseFrame(req, nil)
+	body, ok := frame.Body.(*syncpb.StationMessage_DeviceCommandResponse)
+	require.True(t, ok)
+	require.Equal(t, "device commands are not supported", body.DeviceCommandResponse.Error)
+
+	var got DeviceCommand
+	frame = buildDeviceCommandResponseFrame(req, func(cmd DeviceCommand) ([]byte, error) {
+		got = cmd
+		return []byte{0, 1, 0, 2}, nil
+	})
+	body = frame.Body.(*syncpb.StationMessage_DeviceCommandResponse)
+	require.Equal(t, "", body.DeviceCommandResponse.Error)
+	require.Equal(t, []byte{0, 1, 0, 2}, body.DeviceCommandResponse.Output)
+	require.Equal(t, DeviceCommand{Bus: "/dev/ttyUSB0", Protocol: "modbus", SlaveID: 2, Function: 4, Address: 1, Quantity: 2}, got)
+
+	req.Address = 1 << 16
+	frame = buildDeviceCommandResponseFrame(req, func(DeviceCommand) ([]byte, error) {
+		t.Fatal("out of range command must not run")
+		return nil, nil
+	})
+	body = frame.Body.(*syncpb.StationMessage_DeviceCommandResponse)
+	require.Equal(t, "modbus parameter out of range", body.DeviceCommandResponse.Error)
+}
@@ -4,24 +4,26 @@ import (
 	"context"
 	"errors"
 	"io"
+	"log/slog"
 	"net"
+	"slices"
 	"time"
 
 	"tide/common"
 	internalsyncv2 "tide/internal/syncv2"
+	"tide/pkg/logring"
 	syncpb "tide/pkg/pb/syncproto"
+	"tide/pkg/pbstream"
 	"tide/pkg/pubsub"
 
 	"github.com/hashicorp/yamux"
 )
 
-const replayDataBatchSize = 128
-
 type stationCommandSession interface {
 	Accept() (net.Conn, error)
 }
 
-func (c *Client) runOnConn(ctx context.Context, conn net.Conn) error {
+func (c *Client) runOnConn(ctx context.Context, conn io.ReadWriteCloser) error {
 	muxCfg := yamux.DefaultConfig()
 	muxCfg.EnableKeepAlive = false
 	muxCfg.ConnectionWriteTimeout = 30 * time.Second
@@ -50,22 +52,22 @@ func (c *Client) runOnConn(ctx context.Context, conn net.Conn) error {
 		commandErrCh <- c.serveCommandSubstreams(ctx, session)
 	}()
 
+	helloSentAt := time.Now()
 	if err := c.sendMainFrame(ctx, stream, &syncpb.StationMessage{Body: &syncpb.StationMessage_ClientHello{
 		ClientHello: &syncpb.ClientHello{
 			StationIdentifier: c.cfg.StationIdentifier,
 			ProtocolVersion:   internalsyncv2.ProtocolVersion,
+			OutboxId:          c.cfg.OutboxID,
+			Compressions:      pbstream.Compressions(),
 		},
 	}}); err != nil {
 		return err
 	}
 
-	first, err := stream.Recv()
+	serverHello, err := c.authenticate(ctx, stream, helloSentAt)
 	if err != nil {
 		return err
 	}
-	if _, ok := first.Body.(*syncpb.StationMessage_ServerHello); !ok {
-		return errors.New("expected server_hello")
-	}
 
 	info := c.deps.StationInfoFn()
 	if err := c.sendMainFrame(ctx, stream, &syncpb.StationMessage{
@@ -74,14 +76,20 @@ func (c *Client) runOnConn(ctx context.Context, conn net.Conn) error {
 		return err
 	}
 
-	itemsLatest, latestStatusLogRowID, err := c.recvLatestFrames(stream)
+	lastSeq, latestStatusLogRowID, err := c.recvLatestFrames(stream)
 	if err != nil {
 		return err
 	}
+	c.setStoredSeq(lastSeq)
 
 	subscriber := pubsub.NewSubscriber(10000, func() { _ = session.Close() })
 	c.deps.IngestLock.Lock()
-	err = c.sendReplayData(ctx, stream, info, itemsLatest)
+	err = c.resendUnackedData(ctx, stream)
+	if err != nil {
+		c.deps.IngestLock.Unlock()
+		return err
+	}
+	err = c.sendReplayData(ctx, stream, lastSeq, serverHello.ColumnarData)
 	if err != nil {
 		c.deps.IngestLock.Unlock()
 		return err
@@ -94,6 +102,11 @@ func (c *Client) runOnConn(ctx context.Context, conn net.Conn) error {
 	c.deps.Subscribe(subscriber, nil)
 	defer c.deps.Unsubscribe(subscriber)
 	c.deps.IngestLock.Unlock()
+	defer func() {
+		if n := c.deps.Unacked.pending(); n > 0 {
+			log.Info("sync v2 data batches not acknowledged", "count", n)
+		}
+	}()
 
 	recvErrCh := make(chan error, 1)
 	go func() {
@@ -150,31 +163,79 @@ func (c *Client) sendMainFrame(ctx context.Context, stream internalsyncv2.Statio
 	return stream.Send(frame)
 }
 
-func (c *Client) recvLatestFrames(stream internalsyncv2.StationMessageStream) (map[string]int64, int64, error) {
-	gotItems := false
+// authenticate answers the auth challenge of ServerHello and switches to the compression it selected.
+// It reports the clock offset to the server measured from helloSentAt to the arrival of ServerHello.
+func (c *Client) authenticate(ctx context.Context, stream internalsyncv2.StationMessageStream, helloSentAt time.Time) (*syncpb.ServerHello, error) {
+	first, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	helloRecvAt := time.Now()
+	var hello *syncpb.ServerHello
+	switch body := first.Body.(type) {
+	case *syncpb.StationMessage_ServerHello:
+		hello = body.ServerHello
+	case *syncpb.StationMessage_Error:
+		return nil, errors.New(body.Error.Message)
+	default:
+		return nil, errors.New("expected server_hello")
+	}
+	if hello == nil || len(hello.AuthChallenge) == 0 {
+		return nil, errors.New("server_hello without auth challenge")
+	}
+	if hello.Compression != "" {
+		if err = stream.SetCompression(hello.Compression); err != nil {
+			return nil, err
+		}
+	}
+
+	var clockOffset time.Duration
+	if hello.ServerUnixMs != 0 {
+		clockOffset = serverClockOffset(helloSentAt, helloRecvAt, hello.ServerUnixMs)
+		if c.deps.ClockOffset != nil {
+			c.deps.ClockOffset(clockOffset)
+		}
+	}
+
+	return hello, c.sendMainFrame(ctx, stream, &syncpb.StationMessage{Body: &syncpb.StationMessage_ClientAuth{
+		ClientAuth: &syncpb.ClientAuth{
+			Mac:           internalsyncv2.AuthMAC(c.cfg.AuthKey, hello.AuthChallenge, c.cfg.StationIdentifier),
+			ClockOffsetMs: clockOffset.Milliseconds(),
+		},
+	}})
+}
+
+// serverClockOffset estimates the server clock minus the station clock, assuming the server
+// stamped ServerHello halfway between sending ClientHello and receiving ServerHello.
+func serverClockOffset(sentAt, recvAt time.Time, serverUnixMs int64) time.Duration {
+	midpoint := sentAt.Add(recvAt.Sub(sentAt) / 2)
+	return time.UnixMilli(serverUnixMs).Sub(midpoint)
+}
+
+func (c *Client) recvLatestFrames(stream internalsyncv2.StationMessageStream) (int64, int64, error) {
+	gotCursor := false
 	gotStatus := false
-	itemsLatest := make(map[string]int64)
-	var latestStatusLogRowID int64
+	var lastSeq, latestStatusLogRowID int64
 
-	for !gotItems || !gotStatus {
+	for !gotCursor || !gotStatus {
 		frame, err := stream.Recv()
 		if err != nil {
-			return nil, 0, err
+			return 0, 0, err
 		}
 		switch body := frame.Body.(type) {
-		case *syncpb.StationMessage_ItemsLatest:
-			itemsLatest = body.ItemsLatest.LatestUnixMs
-			gotItems = true
+		case *syncpb.StationMessage_DataCursor:
+			lastSeq = body.DataCursor.LastSeq
+			gotCursor = true
 		case *syncpb.StationMessage_StatusLatest:
 			latestStatusLogRowID = body.StatusLatest.LatestRowId
 			gotStatus = true
 		case *syncpb.StationMessage_Error:
-			return nil, 0, errors.New(body.Error.Message)
+			return 0, 0, errors.New(body.Error.Message)
 		default:
-			return nil, 0, unexpectedStationFrameError("waiting latest state")
+			return 0, 0, unexpectedStationFrameError("waiting latest state")
 		}
 	}
-	return itemsLatest, latestStatusLogRowID, nil
+	return lastSeq, latestStatusLogRowID, nil
 }
 
 func (c *Client) recvStationFrames(stream internalsyncv2.StationMessageStream) error {
@@ -184,6 +245,10 @@ func (c *Client) recvStationFrames(stream internalsyncv2.StationMessageStream) e
 			return err
 		}
 		switch body := frame.Body.(type) {
+		case *syncpb.StationMessage_DataAck:
+			if seq := c.deps.Unacked.ack(body.DataAck.BatchId); seq > c.storedSeq.Load() {
+				c.setStoredSeq(seq)
+			}
 		case *syncpb.StationMessage_Error:
 			return errors.New(body.Error.Message)
 		default:
@@ -192,6 +257,17 @@ func (c *Client) recvStationFrames(stream internalsyncv2.StationMessageStream) e
 	}
 }
 
+// setStoredSeq records that the server stored the outbox up to seq.
+func (c *Client) setStoredSeq(seq int64) {
+	c.storedSeq.Store(seq)
+	if c.deps.SaveStoredSeq == nil {
+		return
+	}
+	if err := c.deps.SaveStoredSeq(seq); err != nil {
+		c.logger().Warn("save stored outbox seq failed", "seq", seq, "error", err)
+	}
+}
+
 func (c *Client) serveCommandSubstreams(ctx context.Context, session stationCommandSession) error {
 	log := c.logger()
 
@@ -227,6 +303,12 @@ func (c *Client) handleCommandStream(ctx context.Context, conn io.ReadWriteClose
 	switch body := frame.Body.(type) {
 	case *syncpb.StationMessage_CameraSnapshotRequest:
 		return stream.Send(buildSnapshotResponseFrame(body.CameraSnapshotRequest, c.deps.GetCamera, c.deps.Snapshot))
+	case *syncpb.StationMessage_DeviceConfigPush:
+		return stream.Send(buildDeviceConfigResultFrame(body.DeviceConfigPush, c.deps.ApplyDeviceConfig))
+	case *syncpb.StationMessage_DeviceCommandRequest:
+		return stream.Send(buildDeviceCommandResponseFrame(body.DeviceCommandRequest, c.deps.DeviceCommand))
+	case *syncpb.StationMessage_LogRequest:
+		return c.serveLogs(ctx, stream, body.LogRequest)
 	case *syncpb.StationMessage_Error:
 		return errors.New(body.Error.Message)
 	default:
@@ -234,41 +316,90 @@ func (c *Client) handleCommandStream(ctx context.Context, conn io.ReadWriteClose
 	}
 }
 
-func (c *Client) sendReplayData(ctx context.Context, stream internalsyncv2.StationMessageStream, info common.StationInfoStruct, itemsLatest map[string]int64) error {
-	var points []*syncpb.DataPoint
-	for _, dv := range info.Devices {
-		for _, itemName := range dv {
-			ds, err := c.deps.GetDataHistory(itemName, itemsLatest[itemName], 0)
-			if err != nil {
-				return err
+// logFollowInterval is how long followed log entries are collected before they are sent together.
+const logFollowInterval = 200 * time.Millisecond
+
+// serveLogs sends the buffered log entries, then the new ones until the server closes the stream if req.Follow is set.
+func (c *Client) serveLogs(ctx context.Context, stream internalsyncv2.StationMessageStream, req *syncpb.LogRequest) error {
+	if c.deps.Logs == nil {
+		return stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_Error{
+			Error: &syncpb.ErrorFrame{Code: "unsupported", Message: "log buffer is not available"},
+		}})
+	}
+	filter := logring.Filter{MinLevel: slog.Level(req.MinLevel), Device: req.Device}
+
+	var (
+		entries <-chan logring.Entry
+		cancel  func()
+	)
+	if req.Follow {
+		// Subscribed first, an entry logged meanwhile may be sent twice but is never missed.
+		entries, cancel = c.deps.Logs.Subscribe(1000)
+		defer cancel()
+	}
+	if err := stream.Send(buildLogBatchFrame(c.deps.Logs.Entries(filter, int(req.Limit)))); err != nil {
+		return err
+	}
+	if !req.Follow {
+		return nil
+	}
+
+	// The server only closes the stream, a read returns when it does.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, err := stream.Recv(); err != nil {
+				return
 			}
-			for _, d := range ds {
-				points = append(points, &syncpb.DataPoint{
-					ItemName: itemName,
-					Value:    d.Value,
-					UnixMs:   d.Millisecond.ToInt64(),
-					Kind:     syncpb.DataKind_DATA_KIND_NORMAL,
-				})
-				if len(points) >= replayDataBatchSize {
-					if err = c.sendMainFrame(ctx, stream, &syncpb.StationMessage{Body: &syncpb.StationMessage_DataBatch{
-						DataBatch: &syncpb.DataBatch{
-							Replay: true,
-							Points: points,
-						},
-					}}); err != nil {
-						return err
-					}
-					points = nil
-				}
+		}
+	}()
+
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+	var pending []logring.Entry
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-closed:
+			return nil
+		case e := <-entries:
+			if filter.Match(e) {
+				pending = append(pending, e)
+			}
+		case <-ticker.C:
+			if len(pending) == 0 {
+				continue
+			}
+			if err := stream.Send(buildLogBatchFrame(pending)); err != nil {
+				return err
 			}
+			pending = nil
+		}
+	}
+}
+
+// resendUnackedData resends the realtime points of batches the server never acknowledged.
+// Points with a seq are left to sendReplayData: they are either stored already (seq <= last_seq)
+// or still in the outbox, only points that failed to be saved locally have no other way to the server.
+func (c *Client) resendUnackedData(ctx context.Context, stream internalsyncv2.StationMessageStream) error {
+	var points []*syncpb.DataPoint
+	for _, p := range c.deps.Unacked.take() {
+		if p.Seq == 0 {
+			points = append(points, p)
 		}
 	}
-	if len(points) > 0 {
+	var batches []*syncpb.DataBatch
+	for chunk := range slices.Chunk(points, replayDataBatchSize) {
+		batch := &syncpb.DataBatch{Replay: true, Points: chunk}
+		// Tracked before sending, so a failed session keeps them for the next one.
+		c.deps.Unacked.add(batch)
+		batches = append(batches, batch)
+	}
+	for _, batch := range batches {
 		if err := c.sendMainFrame(ctx, stream, &syncpb.StationMessage{Body: &syncpb.StationMessage_DataBatch{
-			DataBatch: &syncpb.DataBatch{
-				Replay: true,
-				Points: points,
-			},
+			DataBatch: batch,
 		}}); err != nil {
 			return err
 		}
@@ -276,6 +407,45 @@ func (c *Client) sendReplayData(ctx context.Context, stream internalsyncv2.Stati
 	return nil
 }
 
+// sendReplayData sends every outbox row after lastSeq in seq order, in batches sized to the link throughput.
+func (c *Client) sendReplayData(ctx context.Context, stream internalsyncv2.StationMessageStream, lastSeq int64, columnar bool) error {
+	sizer := newBatchSizer()
+	for {
+		ds, err := c.deps.GetOutboxAfter(lastSeq, sizer.size)
+		if err != nil {
+			return err
+		}
+		if len(ds) == 0 {
+			return nil
+		}
+		points := make([]*syncpb.DataPoint, 0, len(ds))
+		for _, d := range ds {
+			points = append(points, &syncpb.DataPoint{
+				ItemName: d.ItemName,
+				Value:    d.Value,
+				UnixMs:   d.Millisecond.ToInt64(),
+				Kind:     syncpb.DataKind_DATA_KIND_NORMAL,
+				Seq:      d.Seq,
+				Flag:     uint32(d.Flag),
+			})
+		}
+		batch := &syncpb.DataBatch{Replay: true}
+		if columnar {
+			batch.Columns = internalsyncv2.DataColumns(points)
+		} else {
+			batch.Points = points
+		}
+		start := time.Now()
+		if err = c.sendMainFrame(ctx, stream, &syncpb.StationMessage{Body: &syncpb.StationMessage_DataBatch{
+			DataBatch: batch,
+		}}); err != nil {
+			return err
+		}
+		sizer.observe(len(ds), time.Since(start))
+		lastSeq = ds[len(ds)-1].Seq
+	}
+}
+
 func (c *Client) sendReplayStatus(ctx context.Context, stream internalsyncv2.StationMessageStream, latestStatusLogRowID int64) error {
 	logs, err := c.deps.GetItemStatusLogAfter(latestStatusLogRowID)
 	if err != nil {
@@ -311,5 +481,17 @@ func (c *Client) forwardRealtimeData(ctx context.Context, stream internalsyncv2.
 	if !ok {
 		return nil
 	}
+	switch body := frame.Body.(type) {
+	case *syncpb.StationMessage_DataBatch:
+		c.deps.Unacked.add(body.DataBatch)
+	case *syncpb.StationMessage_StationHealth:
+		if c.deps.CountOutboxAfter != nil {
+			n, err := c.deps.CountOutboxAfter(c.storedSeq.Load())
+			if err != nil {
+				c.logger().Warn("count outbox rows failed", "error", err)
+			}
+			body.StationHealth.ReplayBacklog = uint64(n)
+		}
+	}
 	return c.sendMainFrame(ctx, stream, frame)
 }
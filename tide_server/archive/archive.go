@@ -0,0 +1,253 @@
+// Package archive keeps the rows deleted by the retention job in gzip compressed CSV files.
+//
+// The points of an item are kept by month in observations/<station id>/<item>/<yyyy-mm>.csv.gz, a line per point:
+// timestamp in unix milliseconds,value,flag, the CSV format of the data import. The rows of the log tables are kept by
+// month in <table>/<yyyy-mm>.csv.gz, with a header line of the column names.
+// An archive run appends a gzip member to the file of a month, which reads as a single stream.
+package archive
+
+import (
+	"cmp"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"tide/common"
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+)
+
+const (
+	observationsDir = "observations"
+	fileExt         = ".csv.gz"
+	monthLayout     = "2006-01"
+)
+
+// Archive is the directory of the archive files.
+type Archive struct {
+	Dir string
+}
+
+func monthFile(month time.Time) string {
+	return month.UTC().Format(monthLayout) + fileExt
+}
+
+func (a Archive) itemDir(stationId uuid.UUID, itemName string) string {
+	// Dots are escaped too, so that no item name refers to a parent directory.
+	return filepath.Join(a.Dir, observationsDir, stationId.String(), strings.ReplaceAll(url.PathEscape(itemName), ".", "%2E"))
+}
+
+// fileWriter appends a gzip member of CSV records to a file.
+type fileWriter struct {
+	f  *os.File
+	gz *gzip.Writer
+	w  *csv.Writer
+}
+
+// openFile opens name to append records, header is written first if the file is new.
+func openFile(name string, header []string) (*fileWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	fw := &fileWriter{f: f, gz: gzip.NewWriter(f)}
+	fw.w = csv.NewWriter(fw.gz)
+	if header != nil {
+		info, err := f.Stat()
+		if err == nil && info.Size() == 0 {
+			err = fw.w.Write(header)
+		}
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	return fw, nil
+}
+
+// Close ends the gzip member and syncs the file, the records are on disk once it returns.
+func (fw *fileWriter) Close() error {
+	fw.w.Flush()
+	err := fw.w.Error()
+	if err == nil {
+		err = fw.gz.Close()
+	}
+	if err == nil {
+		err = fw.f.Sync()
+	}
+	if closeErr := fw.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ObservationWriter writes the points of a month, ordered by station and item.
+type ObservationWriter struct {
+	a         Archive
+	month     time.Time
+	stationId uuid.UUID
+	itemName  string
+	fw        *fileWriter
+	record    [3]string
+}
+
+// NewObservationWriter returns a writer of the points stamped in month.
+func (a Archive) NewObservationWriter(month time.Time) *ObservationWriter {
+	return &ObservationWriter{a: a, month: month}
+}
+
+// Write adds a point of an item, the file of the previous item is closed when the item changes.
+func (ow *ObservationWriter) Write(stationId uuid.UUID, itemName string, d common.DataTimeStruct) error {
+	if ow.fw == nil || stationId != ow.stationId || itemName != ow.itemName {
+		if err := ow.Close(); err != nil {
+			return err
+		}
+		fw, err := openFile(filepath.Join(ow.a.itemDir(stationId, itemName), monthFile(ow.month)), nil)
+		if err != nil {
+			return err
+		}
+		ow.fw, ow.stationId, ow.itemName = fw, stationId, itemName
+	}
+	ow.record[0] = strconv.FormatInt(int64(d.Millisecond), 10)
+	ow.record[1] = strconv.FormatFloat(d.Value, 'g', -1, 64)
+	ow.record[2] = strconv.Itoa(int(d.Flag))
+	return ow.fw.w.Write(ow.record[:])
+}
+
+// Close closes the file of the current item.
+func (ow *ObservationWriter) Close() error {
+	if ow.fw == nil {
+		return nil
+	}
+	err := ow.fw.Close()
+	ow.fw = nil
+	return err
+}
+
+// OpenRows returns a writer appending the rows of table deleted in month, columns is the header of a new file.
+func (a Archive) OpenRows(table string, month time.Time, columns []string) (*RowWriter, error) {
+	fw, err := openFile(filepath.Join(a.Dir, table, monthFile(month)), columns)
+	if err != nil {
+		return nil, err
+	}
+	return &RowWriter{fw: fw}, nil
+}
+
+// RowWriter writes the rows of a log table.
+type RowWriter struct {
+	fw *fileWriter
+}
+
+// Write adds a row, the values in the order of the columns.
+func (rw *RowWriter) Write(record []string) error {
+	return rw.fw.w.Write(record)
+}
+
+// Close closes the file, the rows are on disk once it returns.
+func (rw *RowWriter) Close() error {
+	return rw.fw.Close()
+}
+
+// months returns the months of the archive files of an item.
+func (a Archive) months(stationId uuid.UUID, itemName string) ([]time.Time, error) {
+	entries, err := os.ReadDir(a.itemDir(stationId, itemName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var months []time.Time
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), fileExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if month, err := time.Parse(monthLayout, name); err == nil {
+			months = append(months, month)
+		}
+	}
+	return months, nil
+}
+
+func readObservationFile(name string, start, end custype.UnixMs, points map[custype.UnixMs]common.DataTimeStruct) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	cr := csv.NewReader(gz)
+	cr.FieldsPerRecord = 3
+	cr.ReuseRecord = true
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		var d common.DataTimeStruct
+		ms, err := strconv.ParseInt(record[0], 10, 64)
+		if err == nil {
+			d.Value, err = strconv.ParseFloat(record[1], 64)
+		}
+		var flag uint64
+		if err == nil {
+			flag, err = strconv.ParseUint(record[2], 10, 8)
+		}
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return fmt.Errorf("%s: line %d: %w", name, line, err)
+		}
+		d.Millisecond, d.Flag = custype.UnixMs(ms), common.QCFlag(flag)
+		if d.Millisecond > start && (end == 0 || d.Millisecond < end) {
+			// A point archived again after a failed run is read twice, the last one wins.
+			points[d.Millisecond] = d
+		}
+	}
+}
+
+// ReadData returns the archived points of an item stamped after start and before end, or after start if end is 0,
+// ordered by time.
+func (a Archive) ReadData(stationId uuid.UUID, itemName string, start, end custype.UnixMs) ([]common.DataTimeStruct, error) {
+	months, err := a.months(stationId, itemName)
+	if err != nil {
+		return nil, err
+	}
+	points := make(map[custype.UnixMs]common.DataTimeStruct)
+	for _, month := range months {
+		if month.AddDate(0, 1, 0).Before(start.ToTime()) || end != 0 && !month.Before(end.ToTime()) {
+			continue
+		}
+		if err = readObservationFile(filepath.Join(a.itemDir(stationId, itemName), monthFile(month)), start, end, points); err != nil {
+			return nil, err
+		}
+	}
+	ds := make([]common.DataTimeStruct, 0, len(points))
+	for _, d := range points {
+		ds = append(ds, d)
+	}
+	slices.SortFunc(ds, func(a, b common.DataTimeStruct) int {
+		return cmp.Compare(a.Millisecond, b.Millisecond)
+	})
+	return ds, nil
+}
@@ -0,0 +1,126 @@
+package archive
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tide/common"
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ms(s string) custype.UnixMs {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return custype.ToUnixMs(t)
+}
+
+func writeMonth(t *testing.T, a Archive, month string, points map[uuid.UUID]map[string][]common.DataTimeStruct, order []uuid.UUID) {
+	t.Helper()
+	ow := a.NewObservationWriter(ms(month).ToTime())
+	for _, stationId := range order {
+		for _, itemName := range []string{"item1", "item2"} {
+			for _, d := range points[stationId][itemName] {
+				require.NoError(t, ow.Write(stationId, itemName, d))
+			}
+		}
+	}
+	require.NoError(t, ow.Close())
+}
+
+func TestArchive_ReadData(t *testing.T) {
+	a := Archive{Dir: t.TempDir()}
+	station1, station2 := uuid.New(), uuid.New()
+	jan := []common.DataTimeStruct{
+		{Millisecond: ms("2020-01-01T00:00:00Z"), Value: 1.5, Flag: common.QCGood},
+		{Millisecond: ms("2020-01-31T23:59:00Z"), Value: -0.25},
+	}
+	feb := []common.DataTimeStruct{
+		{Millisecond: ms("2020-02-10T00:00:00Z"), Value: 2, Flag: common.QCBad},
+	}
+	writeMonth(t, a, "2020-01-01T00:00:00Z", map[uuid.UUID]map[string][]common.DataTimeStruct{
+		station1: {"item1": jan, "item2": {{Millisecond: jan[0].Millisecond, Value: 9}}},
+		station2: {"item1": {{Millisecond: jan[0].Millisecond, Value: 8}}},
+	}, []uuid.UUID{station1, station2})
+	writeMonth(t, a, "2020-02-01T00:00:00Z", map[uuid.UUID]map[string][]common.DataTimeStruct{
+		station1: {"item1": feb},
+	}, []uuid.UUID{station1})
+
+	ds, err := a.ReadData(station1, "item1", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, append(jan, feb...), ds)
+
+	ds, err = a.ReadData(station1, "item1", jan[0].Millisecond, feb[0].Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, jan[1:], ds)
+
+	ds, err = a.ReadData(station1, "item1", ms("2020-02-01T00:00:00Z"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, feb, ds)
+
+	ds, err = a.ReadData(station2, "item1", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []common.DataTimeStruct{{Millisecond: jan[0].Millisecond, Value: 8}}, ds)
+
+	ds, err = a.ReadData(station2, "item2", 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, ds)
+}
+
+func TestArchive_Append(t *testing.T) {
+	a := Archive{Dir: t.TempDir()}
+	stationId := uuid.New()
+	month := ms("2020-01-01T00:00:00Z").ToTime()
+	d1 := common.DataTimeStruct{Millisecond: ms("2020-01-02T00:00:00Z"), Value: 1}
+	d2 := common.DataTimeStruct{Millisecond: ms("2020-01-03T00:00:00Z"), Value: 2}
+
+	// The second run archives d1 again, as the first one failed before the partition was dropped.
+	for _, ds := range [][]common.DataTimeStruct{{d1}, {d1, d2}} {
+		ow := a.NewObservationWriter(month)
+		for _, d := range ds {
+			require.NoError(t, ow.Write(stationId, "item1", d))
+		}
+		require.NoError(t, ow.Close())
+	}
+	ds, err := a.ReadData(stationId, "item1", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []common.DataTimeStruct{d1, d2}, ds)
+}
+
+func TestArchive_ItemDir(t *testing.T) {
+	a := Archive{Dir: t.TempDir()}
+	stationId := uuid.New()
+	dir := a.itemDir(stationId, "..")
+	assert.Equal(t, filepath.Join(a.Dir, observationsDir, stationId.String()), filepath.Dir(dir))
+	assert.Equal(t, "a%2Fb", filepath.Base(a.itemDir(stationId, "a/b")))
+}
+
+func TestArchive_OpenRows(t *testing.T) {
+	a := Archive{Dir: t.TempDir()}
+	month := ms("2020-01-01T00:00:00Z").ToTime()
+	columns := []string{"station_id", "timestamp", "cpu_temp"}
+	for _, record := range [][]string{{"s1", "2020-01-01 00:00:00+00", "40.5"}, {"s1", "2020-01-02 00:00:00+00", ""}} {
+		rw, err := a.OpenRows("rpi_status_log", month, columns)
+		require.NoError(t, err)
+		require.NoError(t, rw.Write(record))
+		require.NoError(t, rw.Close())
+	}
+
+	f, err := os.Open(filepath.Join(a.Dir, "rpi_status_log", "2020-01.csv.gz"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	b, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "station_id,timestamp,cpu_temp\ns1,2020-01-01 00:00:00+00,40.5\ns1,2020-01-02 00:00:00+00,\n", string(b))
+}
@@ -61,6 +61,12 @@ func Init() {
 
 	authorization = permission.NewPostgres(db.TideDB)
 
+	if err := initExport(); err != nil {
+		slog.Error("Failed to initialize data export", "error", err)
+		os.Exit(1)
+	}
+	initRetention()
+
 	// Initialize pubsub instances and hub
 	dataBroker := pubsub.NewBroker()
 	delayedDataBroker := pubsub.NewDelayedBroker(dataBroker, global.Config.Tide.DataDelaySec*time.Second)
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"tide/common"
+	"tide/pkg/custype"
+	"tide/tide_server/db"
+
+	"github.com/google/uuid"
+)
+
+type editDataRequest struct {
+	StationId uuid.UUID      `json:"station_id"`
+	ItemName  string         `json:"item_name"`
+	Action    string         `json:"action"`
+	Start     custype.UnixMs `json:"start"`
+	End       custype.UnixMs `json:"end"`
+	Flag      common.QCFlag  `json:"flag"`
+	Offset    float64        `json:"offset"`
+}
+
+// valid reports whether the request is a complete edit: flag needs one of the suspect flags, offset a finite non-zero offset.
+func (req editDataRequest) valid() bool {
+	if req.ItemName == "" || common.ContainsIllegalCharacter(req.ItemName) || req.Start < 0 || req.End < req.Start {
+		return false
+	}
+	switch req.Action {
+	case db.DataEditFlag:
+		return req.Flag == common.QCProbablyGood || req.Flag == common.QCProbablyBad || req.Flag == common.QCBad
+	case db.DataEditOffset:
+		return req.Offset != 0 && !math.IsNaN(req.Offset) && !math.IsInf(req.Offset, 0)
+	case db.DataEditUnflag, db.DataEditMissing:
+		return true
+	}
+	return false
+}
+
+// EditData flags, unflags, offset-corrects or flags as missing the data of an item in a time range.
+// The edit is recorded with the values before and after it, and the changed values are sent to the downstream servers.
+// An edit reads at most db.MaxEditPoints points, a longer range is rejected.
+func EditData(w http.ResponseWriter, r *http.Request) {
+	var req editDataRequest
+	if !readJSONOrBadRequest(w, r, &req) {
+		return
+	}
+	if !req.valid() {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	e := db.DataEdit{
+		StationId: req.StationId,
+		ItemName:  req.ItemName,
+		Action:    req.Action,
+		Start:     req.Start,
+		End:       req.End,
+		Flag:      req.Flag,
+		Offset:    req.Offset,
+		Username:  requestUsername(r),
+	}
+
+	editMu.Lock()
+	defer editMu.Unlock()
+	n, err := db.EditData(&e)
+	if errors.Is(err, db.ErrTooManyPoints) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("more than %d points to edit, narrow the range", db.MaxEditPoints)})
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to edit data", "station_id", e.StationId, "item_name", e.ItemName, "action", e.Action, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if n > 0 {
+		slog.Info("Edited data", "username", e.Username, "id", e.Id, "station_id", e.StationId, "item_name", e.ItemName, "action", e.Action, "edited", n)
+		stationItem := common.StationItemStruct{StationId: e.StationId, ItemName: e.ItemName}
+		hub.Publish(BrokerMissingData, forwardPointsStruct{Type: kMsgEditData, StationItemStruct: stationItem, Points: e.After, EditId: e.Id}, stationItem)
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"id": e.Id, "edited": n})
+}
+
+// ListDataEdit returns the audit trail of the data edits, latest first.
+func ListDataEdit(w http.ResponseWriter, r *http.Request) {
+	stationId, limit, ok := parseStationLimitQuery(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	es, err := db.GetDataEdits(stationId, limit)
+	if err != nil {
+		slog.Error("Failed to get data edits", "station_id", stationId, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, es)
+}
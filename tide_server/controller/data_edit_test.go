@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"math"
+	"testing"
+
+	"tide/common"
+	"tide/tide_server/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDataRequest_Valid(t *testing.T) {
+	base := editDataRequest{ItemName: "item1", Start: 1000, End: 2000}
+	tests := []struct {
+		name   string
+		modify func(*editDataRequest)
+		want   bool
+	}{
+		{"flag", func(r *editDataRequest) { r.Action, r.Flag = db.DataEditFlag, common.QCBad }, true},
+		{"flag good", func(r *editDataRequest) { r.Action, r.Flag = db.DataEditFlag, common.QCGood }, false},
+		{"flag without flag", func(r *editDataRequest) { r.Action = db.DataEditFlag }, false},
+		{"unflag", func(r *editDataRequest) { r.Action = db.DataEditUnflag }, true},
+		{"missing", func(r *editDataRequest) { r.Action = db.DataEditMissing }, true},
+		{"offset", func(r *editDataRequest) { r.Action, r.Offset = db.DataEditOffset, -0.12 }, true},
+		{"zero offset", func(r *editDataRequest) { r.Action = db.DataEditOffset }, false},
+		{"infinite offset", func(r *editDataRequest) { r.Action, r.Offset = db.DataEditOffset, math.Inf(1) }, false},
+		{"sync", func(r *editDataRequest) { r.Action = db.DataEditSync }, false},
+		{"end before start", func(r *editDataRequest) { r.Action, r.End = db.DataEditMissing, 999 }, false},
+		{"evil item name", func(r *editDataRequest) { r.Action, r.ItemName = db.DataEditMissing, "item1;drop" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := base
+			tt.modify(&req)
+			assert.Equal(t, tt.want, req.valid())
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"tide/common"
+	"tide/tide_server/dataimport"
+	"tide/tide_server/db"
+
+	"github.com/google/uuid"
+)
+
+const maxImportBytes = 256 << 20
+
+// publishImportedData sends the imported points to the downstream servers as missing data.
+func publishImportedData(stationItem common.StationItemStruct, ds []common.DataTimeStruct) {
+	if len(ds) == 0 {
+		return
+	}
+	hub.Publish(BrokerMissingData, forwardPointsStruct{Type: kMsgMissData, StationItemStruct: stationItem, Points: ds}, stationItem)
+}
+
+// importFormat returns the format of the query, or the one of the content type, csv if neither is given.
+func importFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		return dataimport.FormatJSON
+	}
+	return dataimport.FormatCSV
+}
+
+// ImportData stores historical data of an item of a local station sent as CSV or JSON. Points already stored are kept,
+// the new ones are sent to the downstream servers with the missing data.
+func ImportData(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	stationId, err := uuid.Parse(q.Get("station_id"))
+	itemName := q.Get("item_name")
+	format := importFormat(r)
+	if err != nil || itemName == "" || !dataimport.ValidFormat(format) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	upstream, err := db.IsUpstreamStation(stationId)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		slog.Error("Failed to get station", "station_id", stationId, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if upstream {
+		// The data of upstream stations comes from their upstream.
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "upstream station"})
+		return
+	}
+	exists, err := db.ItemExists(stationId, itemName)
+	if err != nil {
+		slog.Error("Failed to get item", "station_id", stationId, "item_name", itemName, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown item " + itemName})
+		return
+	}
+
+	ds, err := dataimport.Parse(http.MaxBytesReader(w, r.Body, maxImportBytes), format)
+	if err == nil {
+		err = dataimport.Validate(ds, time.Now())
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	imported, err := db.ImportDataHistory(stationId, itemName, ds)
+	if err != nil {
+		slog.Error("Failed to import data", "station_id", stationId, "item_name", itemName, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Imported data", "username", requestUsername(r), "station_id", stationId, "item_name", itemName, "received", len(ds), "imported", len(imported))
+	publishImportedData(common.StationItemStruct{StationId: stationId, ItemName: itemName}, imported)
+	writeJSON(w, http.StatusOK, dataimport.Result{Received: len(ds), Imported: len(imported)})
+}
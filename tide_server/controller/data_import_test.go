@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tide/common"
+	"tide/pkg/custype"
+	"tide/pkg/pubsub"
+	"tide/tide_server/dataimport"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportFormat(t *testing.T) {
+	tests := []struct {
+		query       string
+		contentType string
+		want        string
+	}{
+		{"", "", dataimport.FormatCSV},
+		{"", "text/csv", dataimport.FormatCSV},
+		{"", "application/json; charset=utf-8", dataimport.FormatJSON},
+		{"?format=csv", "application/json", dataimport.FormatCSV},
+		{"?format=xlsx", "", "xlsx"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/importData"+tt.query, nil)
+		r.Header.Set("Content-Type", tt.contentType)
+		assert.Equal(t, tt.want, importFormat(r), tt.query+" "+tt.contentType)
+	}
+}
+
+// pointWriter counts the messages written to a sync data stream.
+type pointWriter chan struct{}
+
+func (w pointWriter) Write(b []byte) (int, error) {
+	w <- struct{}{}
+	return len(b), nil
+}
+
+func TestPublishImportedData_MorePointsThanBuffered(t *testing.T) {
+	oldHub := hub
+	dataBroker := pubsub.NewBroker()
+	hub = NewSyncHub(
+		dataBroker,
+		pubsub.NewDelayedBroker(dataBroker, 0),
+		pubsub.NewBroker(),
+		pubsub.NewBroker(),
+		pubsub.NewBroker(),
+		nil,
+		nil,
+	)
+	defer func() { hub = oldHub }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	written := make(pointWriter)
+	subscriber := hub.NewSubscriber(ctx, cancel, syncDataWriter(written))
+	hub.Subscribe(BrokerMissingData, subscriber, nil)
+
+	// More points than the subscriber buffers messages.
+	ds := make([]common.DataTimeStruct, 5*cap(subscriber.Ch))
+	for i := range ds {
+		ds[i].Millisecond = custype.UnixMs(i)
+	}
+	publishImportedData(common.StationItemStruct{StationId: uuid.New(), ItemName: "item1"}, ds)
+
+	for range ds {
+		select {
+		case <-written:
+		case <-ctx.Done():
+			t.Fatal("subscriber dropped")
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for imported points")
+		}
+	}
+	assert.NoError(t, ctx.Err())
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	syncpb "tide/pkg/pb/syncproto"
+	"tide/tide_server/db"
+	syncv2station "tide/tide_server/syncv2/station"
+
+	"github.com/google/uuid"
+)
+
+const deviceCommandTimeout = 30 * time.Second
+
+type deviceCommandRequest struct {
+	StationId uuid.UUID `json:"station_id"`
+	Bus       string    `json:"bus"`
+	Protocol  string    `json:"protocol"`
+	Command   string    `json:"command,omitempty"`
+	SlaveId   uint32    `json:"slave_id,omitempty"`
+	Function  uint32    `json:"function,omitempty"`
+	Address   uint32    `json:"address,omitempty"`
+	Quantity  uint32    `json:"quantity,omitempty"`
+}
+
+// DeviceCommand sends a raw command to a sensor of a station connected over Sync V2 and returns its reply.
+// Every command that reaches a station is recorded in device_command_log.
+func DeviceCommand(w http.ResponseWriter, r *http.Request) {
+	var req deviceCommandRequest
+	if !readJSONOrBadRequest(w, r, &req) {
+		return
+	}
+	if req.StationId == uuid.Nil || req.Bus == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	switch req.Protocol {
+	case "text", "sdi12":
+		if req.Command == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	case "modbus":
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	resp, err := v2DeviceCommand(req.StationId, &syncpb.DeviceCommandRequest{
+		Bus:      req.Bus,
+		Protocol: req.Protocol,
+		Command:  []byte(req.Command),
+		SlaveId:  req.SlaveId,
+		Function: req.Function,
+		Address:  req.Address,
+		Quantity: req.Quantity,
+	}, deviceCommandTimeout)
+	if errors.Is(err, syncv2station.ErrStationNotConnected) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		resp = &syncpb.DeviceCommandResponse{Error: err.Error()}
+	}
+
+	username := requestUsername(r)
+	rawReq, _ := json.Marshal(req)
+	entry := db.DeviceCommandLog{StationId: req.StationId, Username: username, Request: rawReq, Output: resp.Output, Error: resp.Error}
+	if err = db.SaveDeviceCommandLog(&entry); err != nil {
+		slog.Error("Failed to save device command log", "station_id", req.StationId, "error", err)
+	}
+	slog.Info("Device command sent", "station_id", req.StationId, "username", username,
+		"bus", req.Bus, "protocol", req.Protocol, "command", req.Command, "error", resp.Error)
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"output":     string(resp.Output),
+		"output_hex": hex.EncodeToString(resp.Output),
+		"error":      resp.Error,
+	})
+}
+
+// ListDeviceCommandLog returns the latest device commands, of one station if station_id is set.
+func ListDeviceCommandLog(w http.ResponseWriter, r *http.Request) {
+	stationId, limit, ok := parseStationLimitQuery(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	logs, err := db.GetDeviceCommandLogs(stationId, limit)
+	if err != nil {
+		slog.Error("Failed to get device command logs", "station_id", stationId, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, logs)
+}
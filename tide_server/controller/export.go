@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"tide/pkg/custype"
+	"tide/tide_server/auth"
+	"tide/tide_server/db"
+	"tide/tide_server/export"
+	"tide/tide_server/global"
+
+	"github.com/google/uuid"
+)
+
+const exportJobConcurrency = 2
+
+var exportJobs *export.Jobs
+
+// exportJobResponse is an export job with the link to download its file once it is done.
+type exportJobResponse struct {
+	export.Job
+	Download string `json:"download"`
+}
+
+func newExportJobResponse(job export.Job) exportJobResponse {
+	return exportJobResponse{Job: job, Download: "/exportDownload?id=" + url.QueryEscape(job.Id)}
+}
+
+func initExport() error {
+	dir := global.Config.Export.Dir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "tide_export")
+	}
+	expire := global.Config.Export.JobExpireHours * time.Hour
+	if expire == 0 {
+		expire = 24 * time.Hour
+	}
+	var err error
+	exportJobs, err = export.NewJobs(dir, expire, exportJobConcurrency)
+	return err
+}
+
+func exportSyncMaxRange() custype.UnixMs {
+	days := global.Config.Export.SyncMaxDays
+	if days == 0 {
+		days = 31
+	}
+	return custype.UnixMs(time.Duration(days) * 24 * time.Hour / time.Millisecond)
+}
+
+// parseExportQuery parses the station, the comma separated items, the range and the format, csv if not set,
+// of an export. The station is left to the caller.
+func parseExportQuery(q url.Values) (req export.Request, ok bool) {
+	var err error
+	if req.Station.Id, err = uuid.Parse(q.Get("station_id")); err != nil {
+		return req, false
+	}
+	start, err1 := strconv.ParseInt(q.Get("start"), 10, 64)
+	end, err2 := strconv.ParseInt(q.Get("end"), 10, 64)
+	if err1 != nil || err2 != nil || start < 0 || end <= start {
+		return req, false
+	}
+	req.Start, req.End = custype.UnixMs(start), custype.UnixMs(end)
+	if raw := q.Get("items"); raw != "" {
+		for item := range strings.SplitSeq(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				return req, false
+			}
+			req.Items = append(req.Items, item)
+		}
+		slices.Sort(req.Items)
+		req.Items = slices.Compact(req.Items)
+	}
+	req.Format = q.Get("format")
+	if req.Format == "" {
+		req.Format = export.FormatCSV
+	}
+	return req, export.ValidFormat(req.Format)
+}
+
+// ExportData exports the data of items of a station in a range, all the items the user may see if none is given.
+// Ranges longer than sync_max_days, or any range with async=true, are exported by a background job:
+// the job is returned with 202 and its file is downloaded from /exportDownload once it is done.
+func ExportData(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req, ok := parseExportQuery(q)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	station, err := db.GetStation(req.Station.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		slog.Error("Failed to get station", "station_id", req.Station.Id, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	req.Station = station
+
+	username := requestUsername(r)
+	admin := requestRole(r) >= auth.Admin
+	if len(req.Items) == 0 {
+		items, err := db.GetItems(station.Id)
+		if err != nil {
+			slog.Error("Failed to get items", "station_id", station.Id, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		for _, item := range items {
+			if admin || authorization.CheckPermission(username, station.Id, item.Name) {
+				req.Items = append(req.Items, item.Name)
+			}
+		}
+		slices.Sort(req.Items)
+	} else if !admin {
+		for _, item := range req.Items {
+			if !authorization.CheckPermission(username, station.Id, item) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+	}
+
+	if q.Get("async") == "true" || req.End-req.Start > exportSyncMaxRange() {
+		job := exportJobs.Start(username, req)
+		slog.Info("Started export job", "username", username, "job_id", job.Id, "station_id", station.Id, "format", req.Format)
+		writeJSON(w, http.StatusAccepted, newExportJobResponse(job))
+		return
+	}
+
+	w.Header().Set("Content-Type", req.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": req.FileName()}))
+	if err = export.Write(w, req); err != nil {
+		// The status is sent with the first bytes, the client sees a truncated file.
+		slog.Error("Failed to export data", "station_id", station.Id, "format", req.Format, "error", err)
+	}
+}
+
+// ExportJob returns an export job of the user.
+func ExportJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := exportJobs.Get(r.URL.Query().Get("id"), requestUsername(r))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, newExportJobResponse(job))
+}
+
+// ExportDownload sends the file of a done export job of the user.
+func ExportDownload(w http.ResponseWriter, r *http.Request) {
+	f, job, ok := exportJobs.Open(r.URL.Query().Get("id"), requestUsername(r))
+	if !ok {
+		if job.Id != "" {
+			// Not done yet, or failed.
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	w.Header().Set("Content-Type", job.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": job.FileName}))
+	http.ServeContent(w, r, "", job.FinishedAt.ToTime(), f)
+}
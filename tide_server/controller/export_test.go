@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"net/url"
+	"testing"
+
+	"tide/tide_server/export"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExportQuery(t *testing.T) {
+	const station = "station_id=1048a910-2a2b-11eb-9abd-d89ef3266df6&"
+	tests := []struct {
+		query  string
+		items  []string
+		format string
+		ok     bool
+	}{
+		{station + "start=0&end=1000", nil, export.FormatCSV, true},
+		{station + "start=0&end=1000&items=b,a,b&format=netcdf", []string{"a", "b"}, export.FormatNetCDF, true},
+		{station + "start=0&end=1000&format=xlsx", nil, "", false},
+		{station + "start=0&end=1000&items=a,,b", nil, "", false},
+		{station + "start=1000&end=1000", nil, "", false},
+		{station + "end=1000", nil, "", false},
+		{"start=0&end=1000", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			assert.NoError(t, err)
+			req, ok := parseExportQuery(q)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.items, req.Items)
+				assert.Equal(t, tt.format, req.Format)
+			}
+		})
+	}
+}
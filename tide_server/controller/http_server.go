@@ -2,17 +2,19 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
 	"time"
 
+	internalsyncv2 "tide/internal/syncv2"
 	"tide/pkg/project"
 	"tide/tide_server/global"
 )
 
 func runHTTPServer(r http.Handler) error {
-	server := &http.Server{
-		Addr:    global.Config.Listen,
-		Handler: r,
+	server, err := newHTTPServer(r)
+	if err != nil {
+		return err
 	}
 
 	project.RegisterReleaseFunc(func() {
@@ -21,5 +23,34 @@ func runHTTPServer(r http.Handler) error {
 		_ = server.Shutdown(ctx)
 	})
 
-	return server.ListenAndServe()
+	tlsCfg := global.Config.Tls
+	if tlsCfg.CertFile == "" {
+		return server.ListenAndServe()
+	}
+	return server.ListenAndServeTLS(tlsCfg.CertFile, tlsCfg.KeyFile)
+}
+
+func newHTTPServer(r http.Handler) (*http.Server, error) {
+	server := &http.Server{
+		Addr:    global.Config.Listen,
+		Handler: r,
+	}
+	tlsCfg := global.Config.Tls
+	if tlsCfg.CertFile == "" {
+		return server, nil
+	}
+	server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	// The sync endpoints hijack the connection to upgrade it, which HTTP/2 does not allow.
+	// A non-nil map without "h2" keeps it from being offered to clients.
+	server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	if tlsCfg.ClientCaFile != "" {
+		pool, err := internalsyncv2.LoadCertPool(tlsCfg.ClientCaFile)
+		if err != nil {
+			return nil, err
+		}
+		// Client certificates are only used by stations; browsers and API users still log in with tokens.
+		server.TLSConfig.ClientCAs = pool
+		server.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return server, nil
 }
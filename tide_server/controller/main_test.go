@@ -137,7 +137,7 @@ truncate table stations restart identity cascade;
 truncate table permissions_camera_status restart identity cascade;
 truncate table item_status_log restart identity cascade;
 truncate table rpi_status_log restart identity cascade;
-drop table if exists item1 cascade;
+truncate table observations;
 `)
 	require.NoError(t, err)
 }
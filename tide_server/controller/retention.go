@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"tide/common"
+	"tide/pkg/custype"
+	"tide/tide_server/archive"
+	"tide/tide_server/db"
+	"tide/tide_server/global"
+
+	"github.com/google/uuid"
+)
+
+// dataArchive reads the points archived by the retention, nil if they are not archived.
+var dataArchive *archive.Archive
+
+func initRetention() {
+	conf := global.Config.Retention
+	if conf.ArchiveDir != "" {
+		dataArchive = &archive.Archive{Dir: conf.ArchiveDir}
+	}
+	if conf.DataDays == 0 && conf.HealthDays == 0 && conf.StatusDays == 0 {
+		return
+	}
+	interval := conf.IntervalHours * time.Hour
+	if interval == 0 {
+		interval = 24 * time.Hour
+	}
+	go func() {
+		for {
+			runRetention(time.Now())
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// runRetention deletes the rows older than the retention of their table, archiving them first if configured.
+func runRetention(now time.Time) {
+	conf := global.Config.Retention
+	if conf.DataDays > 0 {
+		retainObservations(now.AddDate(0, 0, -conf.DataDays))
+	}
+	if conf.HealthDays > 0 {
+		retainLog(db.LogHealth, now.AddDate(0, 0, -conf.HealthDays))
+	}
+	if conf.StatusDays > 0 {
+		retainLog(db.LogStatus, now.AddDate(0, 0, -conf.StatusDays))
+	}
+}
+
+func retainObservations(before time.Time) {
+	months, err := db.ObservationMonthsBefore(before)
+	if err != nil {
+		slog.Error("Failed to get observation partitions", "error", err)
+		return
+	}
+	for i, month := range months {
+		var oa db.ObservationArchive
+		if dataArchive != nil {
+			oa = dataArchive.NewObservationWriter(month)
+		}
+		n, err := db.DropObservationPartition(month, oa)
+		if err != nil {
+			slog.Error("Failed to drop observation partition", "month", month.Format("2006-01"), "error", err)
+			return
+		}
+		slog.Info("Dropped observation partition", "month", month.Format("2006-01"), "rows", n, "archived", dataArchive != nil,
+			"progress", fmt.Sprintf("%d/%d", i+1, len(months)))
+	}
+}
+
+func retainLog(table string, before time.Time) {
+	oldest, err := db.OldestLogTime(table)
+	if err != nil {
+		slog.Error("Failed to get oldest log row", "table", table, "error", err)
+		return
+	}
+	if oldest.IsZero() || !oldest.Before(before) {
+		return
+	}
+	var total int64
+	oldest = oldest.UTC()
+	for month := time.Date(oldest.Year(), oldest.Month(), 1, 0, 0, 0, 0, time.UTC); month.Before(before); month = month.AddDate(0, 1, 0) {
+		end := month.AddDate(0, 1, 0)
+		if end.After(before) {
+			end = before
+		}
+		var open func(columns []string) (db.RowArchive, error)
+		if dataArchive != nil {
+			open = func(columns []string) (db.RowArchive, error) {
+				return dataArchive.OpenRows(table, month, columns)
+			}
+		}
+		n, err := db.DeleteLogRows(table, month, end, open)
+		if err != nil {
+			slog.Error("Failed to delete log rows", "table", table, "month", month.Format("2006-01"), "error", err)
+			return
+		}
+		total += n
+		if n > 0 {
+			slog.Info("Deleted log rows", "table", table, "month", month.Format("2006-01"), "rows", n, "archived", dataArchive != nil)
+		}
+	}
+	slog.Info("Applied log retention", "table", table, "before", before.Format(time.DateTime), "rows", total)
+}
+
+// archivedDataHistory returns the data history with the points archived by the retention, the stored point winning
+// over an archived one of the same time. The points are aggregated if interval is not 0.
+func archivedDataHistory(stationId uuid.UUID, itemName string, start, end custype.UnixMs, interval time.Duration, agg string) ([]common.DataTimeStruct, error) {
+	archived, err := dataArchive.ReadData(stationId, itemName, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(archived) == 0 {
+		if interval > 0 {
+			return db.GetDataHistoryAgg(stationId, itemName, start, end, interval, agg)
+		}
+		return db.GetDataHistory(stationId, itemName, start, end)
+	}
+	stored, err := db.GetDataHistory(stationId, itemName, start, end)
+	if err != nil {
+		return nil, err
+	}
+	ds := mergeDataHistory(archived, stored)
+	if interval > 0 {
+		ds = db.AggregateData(ds, interval, agg)
+	}
+	return ds, nil
+}
+
+// mergeDataHistory merges two lists of points ordered by time, the point of b is kept if both have a time.
+func mergeDataHistory(a, b []common.DataTimeStruct) []common.DataTimeStruct {
+	ds := make([]common.DataTimeStruct, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].Millisecond < b[j].Millisecond:
+			ds = append(ds, a[i])
+			i++
+		case a[i].Millisecond > b[j].Millisecond:
+			ds = append(ds, b[j])
+			j++
+		default:
+			ds = append(ds, b[j])
+			i++
+			j++
+		}
+	}
+	ds = append(ds, a[i:]...)
+	return append(ds, b[j:]...)
+}
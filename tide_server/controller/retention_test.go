@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"testing"
+
+	"tide/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeDataHistory(t *testing.T) {
+	archived := []common.DataTimeStruct{{Millisecond: 1, Value: 1}, {Millisecond: 2, Value: 2}, {Millisecond: 4, Value: 4}}
+	stored := []common.DataTimeStruct{{Millisecond: 2, Value: 20, Flag: common.QCChanged}, {Millisecond: 3, Value: 3}, {Millisecond: 5, Value: 5}}
+	assert.Equal(t, []common.DataTimeStruct{
+		{Millisecond: 1, Value: 1},
+		{Millisecond: 2, Value: 20, Flag: common.QCChanged},
+		{Millisecond: 3, Value: 3},
+		{Millisecond: 4, Value: 4},
+		{Millisecond: 5, Value: 5},
+	}, mergeDataHistory(archived, stored))
+	assert.Equal(t, stored, mergeDataHistory(nil, stored))
+	assert.Equal(t, archived, mergeDataHistory(archived, nil))
+}
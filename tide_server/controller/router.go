@@ -49,6 +49,7 @@ func setupRouter() http.Handler {
 	// WebSocket routes.
 	handle(http.MethodGet, "/ws/global", GlobalWebsocket, upgradeWsMiddleware, validateWsMiddleware)
 	handle(http.MethodGet, "/ws/data", DataWebsocket, upgradeWsMiddleware, validateWsMiddleware)
+	handle(http.MethodGet, "/ws/stationLogs", StationLogsWebsocket, upgradeWsMiddleware, validateWsMiddleware)
 
 	// Auth routes.
 	handle(http.MethodPost, "/applyAccount", ApplyAccount)
@@ -81,11 +82,29 @@ func setupRouter() http.Handler {
 	// Data routes.
 	handle(http.MethodGet, "/dataHistory", DataHistory, authMW...)
 	handle(http.MethodGet, "/itemStatusLogs", ListItemStatusLogs, authMW...)
+	handle(http.MethodPost, "/editData", EditData, adminMW...)
+	handle(http.MethodGet, "/listDataEdit", ListDataEdit, adminMW...)
+	handle(http.MethodPost, "/importData", ImportData, adminMW...)
+	handle(http.MethodGet, "/exportData", ExportData, authMW...)
+	handle(http.MethodGet, "/exportJob", ExportJob, authMW...)
+	handle(http.MethodGet, "/exportDownload", ExportDownload, authMW...)
 
 	// Station routes.
 	handle(http.MethodGet, "/listStation", ListStation, authMW...)
 	handle(http.MethodPost, "/editStation", EditStation, adminMW...)
 	handle(http.MethodPost, "/delStation", DelStation, adminMW...)
+	handle(http.MethodPost, "/rotateStationKey", RotateStationKey, adminMW...)
+	handle(http.MethodGet, "/stationDeviceConfig", GetStationDeviceConfig, adminMW...)
+	handle(http.MethodPost, "/editStationDeviceConfig", EditStationDeviceConfig, adminMW...)
+	handle(http.MethodPost, "/deviceCommand", DeviceCommand, adminMW...)
+	handle(http.MethodGet, "/listDeviceCommandLog", ListDeviceCommandLog, adminMW...)
+	handle(http.MethodGet, "/stationLogs", StationLogs, adminMW...)
+	handle(http.MethodGet, "/stationHealth", StationHealth, adminMW...)
+	handle(http.MethodGet, "/listStationClock", ListStationClock, adminMW...)
+	handle(http.MethodPost, "/editStationInstalledAt", EditStationInstalledAt, adminMW...)
+	handle(http.MethodGet, "/listQuarantinedData", ListQuarantinedData, adminMW...)
+	handle(http.MethodPost, "/releaseQuarantinedData", ReleaseQuarantinedData, adminMW...)
+	handle(http.MethodPost, "/delQuarantinedData", DelQuarantinedData, adminMW...)
 
 	// Device routes.
 	handle(http.MethodGet, "/listDevice", ListDevice, authMW...)
@@ -1,13 +1,23 @@
 package controller
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
+	"tide/common"
+	internalsyncv2 "tide/internal/syncv2"
 	"tide/pkg/custype"
+	"tide/pkg/lttb"
 	"tide/tide_server/auth"
 	"tide/tide_server/db"
+	syncv2station "tide/tide_server/syncv2/station"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/yamux"
@@ -67,6 +77,40 @@ func DelStation(w http.ResponseWriter, r *http.Request) {
 	writeOK(w)
 }
 
+// RotateStationKey generates a new Sync V2 auth key for a local station and returns it once.
+// The station keeps its current session; the new key is required from the next handshake.
+func RotateStationKey(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	stationId, err := uuid.Parse(r.Form.Get("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	authKey, err := internalsyncv2.NewAuthKey()
+	if err != nil {
+		slog.Error("Failed to generate station auth key", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	// Can only rotate keys of local stations
+	n, err := db.SetStationAuthKey(stationId, authKey)
+	if err != nil {
+		slog.Error("Failed to set station auth key", "station_id", stationId, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	slog.Info("Station auth key rotated", "station_id", stationId, "username", requestUsername(r))
+	writeJSON(w, http.StatusOK, map[string]string{"auth_key": authKey})
+}
+
 func ListDevice(w http.ResponseWriter, r *http.Request) {
 	stationId, _ := uuid.Parse(r.URL.Query().Get("station_id"))
 	devices, err := db.GetDevices(stationId)
@@ -163,6 +207,12 @@ func DataHistory(w http.ResponseWriter, r *http.Request) {
 
 	start, _ := strconv.ParseInt(q.Get("start"), 10, 64)
 	end, _ := strconv.ParseInt(q.Get("end"), 10, 64)
+	interval, agg, maxPoints, ok := parseDataHistoryReduce(q)
+	// Without a range only the latest point is returned, there is nothing to aggregate.
+	if !ok || interval > 0 && start == 0 && end == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 
 	if requestRole(r) < auth.Admin {
 		if !authorization.CheckPermission(requestUsername(r), stationId, itemName) {
@@ -171,11 +221,69 @@ func DataHistory(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	ds, err := db.GetDataHistory(stationId, itemName, custype.UnixMs(start), custype.UnixMs(end))
+	var ds []common.DataTimeStruct
+	if q.Get("archive") == "true" && dataArchive != nil && (start != 0 || end != 0) {
+		ds, err = archivedDataHistory(stationId, itemName, custype.UnixMs(start), custype.UnixMs(end), interval, agg)
+	} else if interval > 0 {
+		ds, err = db.GetDataHistoryAgg(stationId, itemName, custype.UnixMs(start), custype.UnixMs(end), interval, agg)
+	} else {
+		ds, err = db.GetDataHistory(stationId, itemName, custype.UnixMs(start), custype.UnixMs(end))
+	}
 	if err != nil {
 		slog.Error("Failed to get data history", "station_id", stationId, "item_name", itemName, "error", err)
 		return
 	}
+	if maxPoints > 0 {
+		ds = lttb.Downsample(ds, maxPoints, func(d common.DataTimeStruct) (float64, float64) {
+			return float64(d.Millisecond), d.Value
+		})
+	}
+	writeJSON(w, http.StatusOK, ds)
+}
+
+// parseDataHistoryReduce parses the interval and agg parameters aggregating the data history, and max_points
+// downsampling it. agg defaults to the mean and needs an interval.
+func parseDataHistoryReduce(q url.Values) (interval time.Duration, agg string, maxPoints int, ok bool) {
+	if raw := q.Get("interval"); raw != "" {
+		var err error
+		if interval, err = time.ParseDuration(raw); err != nil || interval < time.Second {
+			return 0, "", 0, false
+		}
+		agg = db.AggMean
+	}
+	if raw := q.Get("agg"); raw != "" {
+		if interval == 0 || !db.ValidAgg(raw) {
+			return 0, "", 0, false
+		}
+		agg = raw
+	}
+	if raw := q.Get("max_points"); raw != "" {
+		n, err := strconv.ParseUint(raw, 10, 31)
+		if err != nil || n < 3 {
+			return 0, "", 0, false
+		}
+		maxPoints = int(n)
+	}
+	return interval, agg, maxPoints, true
+}
+
+// StationHealth returns the health reports of a station between start and end, or the latest one without them.
+func StationHealth(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	stationId, err := uuid.Parse(q.Get("station_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	start, _ := strconv.ParseInt(q.Get("start"), 10, 64)
+	end, _ := strconv.ParseInt(q.Get("end"), 10, 64)
+
+	ds, err := db.GetStationHealth(stationId, custype.UnixMs(start), custype.UnixMs(end))
+	if err != nil {
+		slog.Error("Failed to get station health", "station_id", stationId, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 	writeJSON(w, http.StatusOK, ds)
 }
 
@@ -192,3 +300,81 @@ func DataHistory(w http.ResponseWriter, r *http.Request) {
 //		return true
 //	}
 //}
+
+// GetStationDeviceConfig returns the device config stored for a Sync V2 station.
+func GetStationDeviceConfig(w http.ResponseWriter, r *http.Request) {
+	stationId, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	config, err := db.GetStationDeviceConfig(stationId)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		slog.Error("Failed to get station device config", "station_id", stationId, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, config)
+}
+
+// EditStationDeviceConfig stores a new device config for a local station and pushes it if the station is connected.
+// The station validates the config and restarts with it; the applied version shows up in its station info.
+func EditStationDeviceConfig(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Id     uuid.UUID       `json:"id"`
+		Config json.RawMessage `json:"config"`
+	}
+	if !readJSONOrBadRequest(w, r, &req) {
+		return
+	}
+	if err := validateDeviceConfig(req.Config); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	editMu.Lock()
+	version, err := db.SaveStationDeviceConfig(req.Id, req.Config)
+	editMu.Unlock()
+	if errors.Is(err, sql.ErrNoRows) { // Not a local station
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		slog.Error("Failed to save station device config", "station_id", req.Id, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Station device config saved", "station_id", req.Id, "version", version, "username", requestUsername(r))
+
+	resp := struct {
+		Version int64  `json:"version"`
+		Pushed  bool   `json:"pushed"`
+		Error   string `json:"error,omitempty"`
+	}{Version: version}
+	if err = v2PushDeviceConfig(req.Id); err == nil {
+		resp.Pushed = true
+	} else if !errors.Is(err, syncv2station.ErrStationNotConnected) {
+		slog.Warn("Failed to push station device config", "station_id", req.Id, "version", version, "error", err)
+		resp.Error = err.Error()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// validateDeviceConfig checks the shape map[conn_type][]device_config, the station checks the rest.
+func validateDeviceConfig(config json.RawMessage) error {
+	var devices map[string][]json.RawMessage
+	if err := json.Unmarshal(config, &devices); err != nil || devices == nil {
+		return errors.New("config must be an object of device config arrays")
+	}
+	for connType, list := range devices {
+		for _, device := range list {
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(device, &obj); err != nil || obj == nil {
+				return fmt.Errorf("%s: device config must be an object", connType)
+			}
+		}
+	}
+	return nil
+}
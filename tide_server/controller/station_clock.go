@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"tide/pkg/custype"
+	"tide/tide_server/db"
+
+	"github.com/google/uuid"
+)
+
+// ListStationClock returns the installation time and the last measured clock offset of the local stations.
+func ListStationClock(w http.ResponseWriter, _ *http.Request) {
+	cs, err := db.GetStationClocks()
+	if err != nil {
+		slog.Error("Failed to get station clocks", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, cs)
+}
+
+// EditStationInstalledAt sets the time a station was installed, data stamped before it is quarantined.
+func EditStationInstalledAt(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		StationId   uuid.UUID      `json:"station_id"`
+		InstalledAt custype.UnixMs `json:"installed_at"`
+	}
+	if !readJSONOrBadRequest(w, r, &req) {
+		return
+	}
+	if req.InstalledAt < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	n, err := db.SetStationInstalledAt(req.StationId, req.InstalledAt)
+	if err != nil {
+		slog.Error("Failed to set station installation time", "station_id", req.StationId, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeOK(w)
+}
+
+// ListQuarantinedData returns the data points rejected for implausible timestamps, latest first.
+func ListQuarantinedData(w http.ResponseWriter, r *http.Request) {
+	stationId, limit, ok := parseStationLimitQuery(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	ds, err := db.GetQuarantinedData(stationId, limit)
+	if err != nil {
+		slog.Error("Failed to get quarantined data", "station_id", stationId, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, ds)
+}
+
+// ReleaseQuarantinedData stores quarantined points in the data history after an admin checked them.
+func ReleaseQuarantinedData(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Ids []int64 `json:"ids"`
+	}
+	if !readJSONOrBadRequest(w, r, &req) {
+		return
+	}
+	editMu.Lock()
+	defer editMu.Unlock()
+	released := 0
+	for _, id := range req.Ids {
+		d, err := db.ReleaseQuarantinedData(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			slog.Error("Failed to release quarantined data", "id", id, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		slog.Info("Released quarantined data", "username", requestUsername(r), "station_id", d.StationId, "item_name", d.ItemName, "timestamp", d.Timestamp.ToTime())
+		released++
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"released": released})
+}
+
+func DelQuarantinedData(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Ids []int64 `json:"ids"`
+	}
+	if !readJSONOrBadRequest(w, r, &req) {
+		return
+	}
+	var deleted int64
+	for _, id := range req.Ids {
+		n, err := db.DelQuarantinedData(id)
+		if err != nil {
+			slog.Error("Failed to delete quarantined data", "id", id, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		deleted += n
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"deleted": deleted})
+}
+
+// parseStationLimitQuery reads the optional station_id and limit (1-1000, default 100) of list queries.
+func parseStationLimitQuery(r *http.Request) (stationId uuid.UUID, limit uint, ok bool) {
+	q := r.URL.Query()
+	if raw := q.Get("station_id"); raw != "" {
+		var err error
+		if stationId, err = uuid.Parse(raw); err != nil {
+			return uuid.Nil, 0, false
+		}
+	}
+	limit = 100
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || n < 1 || n > 1000 {
+			return uuid.Nil, 0, false
+		}
+		limit = uint(n)
+	}
+	return stationId, limit, true
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tide/pkg/custype"
+	syncpb "tide/pkg/pb/syncproto"
+	"tide/tide_server/auth"
+	syncv2station "tide/tide_server/syncv2/station"
+
+	"github.com/coder/websocket"
+	"github.com/google/uuid"
+)
+
+const stationLogsTimeout = 10 * time.Second
+
+type stationLogEntry struct {
+	Time    custype.UnixMs    `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+func toStationLogEntries(entries []*syncpb.LogEntry) []stationLogEntry {
+	ret := make([]stationLogEntry, 0, len(entries))
+	for _, e := range entries {
+		le := stationLogEntry{
+			Time:    custype.UnixMs(e.UnixMs),
+			Level:   slog.Level(e.Level).String(),
+			Message: e.Message,
+		}
+		if len(e.Attrs) > 0 {
+			le.Attrs = make(map[string]string, len(e.Attrs))
+			for _, a := range e.Attrs {
+				le.Attrs[a.Key] = a.Value
+			}
+		}
+		ret = append(ret, le)
+	}
+	return ret
+}
+
+// parseStationLogRequest reads station_id, level (debug, info, warn, error), device and limit from the query.
+func parseStationLogRequest(r *http.Request) (uuid.UUID, *syncpb.LogRequest, bool) {
+	q := r.URL.Query()
+	stationId, err := uuid.Parse(q.Get("station_id"))
+	if err != nil {
+		return uuid.Nil, nil, false
+	}
+	req := &syncpb.LogRequest{MinLevel: int32(slog.LevelDebug), Device: q.Get("device")}
+	if raw := q.Get("level"); raw != "" {
+		var level slog.Level
+		if err = level.UnmarshalText([]byte(raw)); err != nil {
+			return uuid.Nil, nil, false
+		}
+		req.MinLevel = int32(level)
+	}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return uuid.Nil, nil, false
+		}
+		req.Limit = uint32(n)
+	}
+	return stationId, req, true
+}
+
+// StationLogs returns the log entries a station connected over Sync V2 keeps in memory.
+func StationLogs(w http.ResponseWriter, r *http.Request) {
+	stationId, req, ok := parseStationLogRequest(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	entries, err := v2RequestLogs(stationId, req, stationLogsTimeout)
+	if errors.Is(err, syncv2station.ErrStationNotConnected) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Warn("Failed to request station logs", "station_id", stationId, "error", err)
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, toStationLogEntries(entries))
+}
+
+// StationLogsWebsocket sends the buffered and then the live log entries of a station, one JSON array per message.
+func StationLogsWebsocket(w http.ResponseWriter, r *http.Request) {
+	wsw, ok := requestWSConn(r)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if requestRole(r) < auth.Admin {
+		_ = wsw.Close(wsStatusUnauthorized, http.StatusText(http.StatusForbidden))
+		return
+	}
+	stationId, req, ok := parseStationLogRequest(r)
+	if !ok {
+		_ = wsw.Close(websocket.StatusPolicyViolation, http.StatusText(http.StatusBadRequest))
+		return
+	}
+
+	// The browser sends nothing, reading detects when it goes away.
+	ctx := wsw.CloseRead(r.Context())
+	write := wsHubJSONWriter(ctx, wsw)
+	err := v2FollowLogs(ctx, stationId, req, func(entries []*syncpb.LogEntry) error {
+		return write(toStationLogEntries(entries))
+	})
+	if ctx.Err() != nil {
+		return
+	}
+	slog.Info("Station log stream ended", "station_id", stationId, "username", requestUsername(r), "error", err)
+	_ = wsw.Close(websocket.StatusNormalClosure, errorString(err))
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	syncpb "tide/pkg/pb/syncproto"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStationLogRequest(t *testing.T) {
+	stationId := uuid.New()
+
+	gotId, req, ok := parseStationLogRequest(httptest.NewRequest("GET", "/stationLogs?station_id="+stationId.String(), nil))
+	require.True(t, ok)
+	require.Equal(t, stationId, gotId)
+	require.Equal(t, int32(slog.LevelDebug), req.MinLevel)
+
+	_, req, ok = parseStationLogRequest(httptest.NewRequest("GET", "/stationLogs?station_id="+stationId.String()+"&level=warn&device=PWD50&limit=50", nil))
+	require.True(t, ok)
+	require.Equal(t, int32(slog.LevelWarn), req.MinLevel)
+	require.Equal(t, "PWD50", req.Device)
+	require.Equal(t, uint32(50), req.Limit)
+
+	for _, query := range []string{"", "station_id=x", "station_id=" + stationId.String() + "&level=loud", "station_id=" + stationId.String() + "&limit=-1"} {
+		_, _, ok = parseStationLogRequest(httptest.NewRequest("GET", "/stationLogs?"+query, nil))
+		require.False(t, ok, query)
+	}
+}
+
+func TestToStationLogEntries(t *testing.T) {
+	entries := toStationLogEntries([]*syncpb.LogEntry{{
+		UnixMs:  1000,
+		Level:   int32(slog.LevelError),
+		Message: "Failed to read line from PWD50 device",
+		Attrs:   []*syncpb.LogAttr{{Key: "error", Value: "timeout"}},
+	}})
+	require.Equal(t, []stationLogEntry{{
+		Time:    1000,
+		Level:   "ERROR",
+		Message: "Failed to read line from PWD50 device",
+		Attrs:   map[string]string{"error": "timeout"},
+	}}, entries)
+}
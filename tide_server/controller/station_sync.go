@@ -143,7 +143,7 @@ func handleStationConnStream1(conn net.Conn, session *yamux.Session) {
 				return
 			}
 			// save and publish
-			if _, err = db.SaveDataHistory(stationId, body.ItemName, body.Value, body.Millisecond.ToTime()); err != nil {
+			if _, err = db.SaveDataHistory(stationId, body.ItemName, body.Value, body.Flag, body.Millisecond.ToTime()); err != nil {
 				slog.Error("Failed to save data history", "item_name", body.ItemName, "error", err)
 				return
 			}
@@ -163,7 +163,7 @@ func handleStationConnStream1(conn net.Conn, session *yamux.Session) {
 				slog.Error("Failed to update item status", "item_name", body.ItemName, "error", err)
 				return
 			}
-			if _, err = db.SaveDataHistory(stationId, body.ItemName, body.Value, body.Millisecond.ToTime()); err != nil {
+			if _, err = db.SaveDataHistory(stationId, body.ItemName, body.Value, body.Flag, body.Millisecond.ToTime()); err != nil {
 				slog.Error("Failed to save GPIO data history", "item_name", body.ItemName, "error", err)
 				return
 			}
@@ -174,13 +174,13 @@ func handleStationConnStream1(conn net.Conn, session *yamux.Session) {
 				DataTimeStruct:    body.DataTimeStruct,
 			}, stationItem)
 		case common.MsgRpiStatus:
-			var body common.RpiStatusTimeStruct
+			var body common.StationHealthTimeStruct
 			if err = json.Unmarshal(msg.Body, &body); err != nil {
-				slog.Error("Failed to unmarshal RPI status message", "error", err)
+				slog.Error("Failed to unmarshal station health message", "error", err)
 				return
 			}
-			if err = db.SaveRpiStatus(stationId, body.CpuTemp, body.Millisecond.ToTime()); err != nil {
-				slog.Error("Failed to save RPI status", "station_id", stationId, "error", err)
+			if err = db.SaveStationHealth(stationId, body.StationHealthStruct, body.Millisecond.ToTime()); err != nil {
+				slog.Error("Failed to save station health", "station_id", stationId, "error", err)
 			}
 		case common.MsgItemStatus:
 			var body common.RowIdItemStatusStruct
@@ -156,7 +156,7 @@ func ReadMissData(decoder *json.Decoder, stationId uuid.UUID) (retOk bool) {
 	for itemName, ds := range missData {
 		slog.Debug("Processing miss data", "station_id", stationId, "item_name", itemName, "data_count", len(ds))
 		for _, dataTime := range ds {
-			if n, err := db.SaveDataHistory(stationId, itemName, dataTime.Value, dataTime.Millisecond.ToTime()); err != nil {
+			if n, err := db.SaveDataHistory(stationId, itemName, dataTime.Value, dataTime.Flag, dataTime.Millisecond.ToTime()); err != nil {
 				slog.Error("Failed to save miss data history", "station_id", stationId, "item_name", itemName, "error", err)
 				return
 			} else if n > 0 {
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"tide/tide_server/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDataHistoryReduce(t *testing.T) {
+	tests := []struct {
+		query     string
+		interval  time.Duration
+		agg       string
+		maxPoints int
+		ok        bool
+	}{
+		{"", 0, "", 0, true},
+		{"interval=1h", time.Hour, db.AggMean, 0, true},
+		{"interval=10m&agg=max", 10 * time.Minute, db.AggMax, 0, true},
+		{"interval=1d", 0, "", 0, false},
+		{"interval=100ms", 0, "", 0, false},
+		{"agg=max", 0, "", 0, false},
+		{"interval=1h&agg=median", 0, "", 0, false},
+		{"max_points=1000", 0, "", 1000, true},
+		{"interval=1m&agg=last&max_points=500", time.Minute, db.AggLast, 500, true},
+		{"max_points=2", 0, "", 0, false},
+		{"max_points=-1", 0, "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			assert.NoError(t, err)
+			interval, agg, maxPoints, ok := parseDataHistoryReduce(q)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.interval, interval)
+			assert.Equal(t, tt.agg, agg)
+			assert.Equal(t, tt.maxPoints, maxPoints)
+		})
+	}
+}
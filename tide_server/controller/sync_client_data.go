@@ -19,7 +19,6 @@ func syncDataClient(conn net.Conn) {
 	decoder := json.NewDecoder(conn)
 
 	var err error
-	var receivedItems = make(map[common.StationItemStruct]struct{})
 	for {
 		var msg forwardDataStruct
 		if err = decoder.Decode(&msg); err != nil {
@@ -29,18 +28,19 @@ func syncDataClient(conn net.Conn) {
 			break
 		}
 
-		// First time received
-		if _, ok := receivedItems[msg.StationItemStruct]; !ok {
-			receivedItems[msg.StationItemStruct] = struct{}{}
-			slog.Debug("First time receiving data", "station_id", msg.StationId, "item_name", msg.ItemName)
-			// First make sure the table exists
-			if err = db.MakeSureTableExist(msg.ItemName); err != nil {
-				slog.Error("Failed to create table for item", "item_name", msg.ItemName, "error", err)
+		if msg.Type == kMsgEditData {
+			n, err := db.SyncEditedData(msg.StationId, msg.ItemName, msg.DataTimeStruct)
+			if err != nil {
+				slog.Error("Failed to sync edited data", "station_id", msg.StationId, "item_name", msg.ItemName, "error", err)
 				return
 			}
+			if n > 0 {
+				hub.Publish(BrokerMissingData, forwardPointsStruct{Type: kMsgEditData, StationItemStruct: msg.StationItemStruct, Points: []common.DataTimeStruct{msg.DataTimeStruct}}, msg.StationItemStruct)
+			}
+			continue
 		}
 		// save and publish
-		if n, err := db.SaveDataHistory(msg.StationId, msg.ItemName, msg.Value, msg.Millisecond.ToTime()); err != nil {
+		if n, err := db.SaveDataHistory(msg.StationId, msg.ItemName, msg.Value, msg.Flag, msg.Millisecond.ToTime()); err != nil {
 			slog.Error("Failed to save data history", "station_id", msg.StationId, "item_name", msg.ItemName, "error", err)
 			return
 		} else if n > 0 {
@@ -96,11 +96,7 @@ func fillMissDataClient(conn net.Conn) (retOk bool) {
 		for itemName, ds := range missData {
 			slog.Debug("Processing miss data", "item_name", itemName, "data_count", len(ds))
 			for _, data := range ds {
-				if err = db.MakeSureTableExist(itemName); err != nil {
-					slog.Error("Failed to create table for item", "item_name", itemName, "error", err)
-					return
-				}
-				if n, err := db.SaveDataHistory(stationId, itemName, data.Value, data.Millisecond.ToTime()); err != nil {
+				if n, err := db.SaveDataHistory(stationId, itemName, data.Value, data.Flag, data.Millisecond.ToTime()); err != nil {
 					slog.Error("Failed to save miss data history", "station_id", stationId, "item_name", itemName, "error", err)
 					return
 				} else if n > 0 {
@@ -29,6 +29,7 @@ const (
 	kMsgMissData              = "MissData"
 	kMsgData                  = "data"
 	kMsgDataGpio              = "data_gpio"
+	kMsgEditData              = "EditData"
 )
 
 type forwardDataStruct struct {
@@ -37,6 +38,17 @@ type forwardDataStruct struct {
 	common.DataTimeStruct
 }
 
+// forwardPointsStruct carries points of an item published at once to the downstream servers, the missing data
+// (kMsgMissData) of an import or the points changed by a data edit (kMsgEditData). A single message is published
+// however many points there are, so that they do not overflow the buffers of the subscribers.
+// EditId is the id of the data edit made here, which the Sync V2 downstream servers record to replay the later ones.
+type forwardPointsStruct struct {
+	Type string
+	common.StationItemStruct
+	Points []common.DataTimeStruct
+	EditId int64
+}
+
 type SendMsgStruct struct {
 	Type string `json:"type"`
 	Body any    `json:"body"`
@@ -71,6 +83,24 @@ const (
 	connTypeAny = -1
 )
 
+// syncDataWriter is jsonWriter for the sync data stream, which carries one point per message,
+// so the points published at once are written one by one.
+func syncDataWriter(w io.Writer) func(any) error {
+	write := jsonWriter(w)
+	return func(val any) error {
+		fp, ok := val.(forwardPointsStruct)
+		if !ok {
+			return write(val)
+		}
+		for _, d := range fp.Points {
+			if err := write(forwardDataStruct{Type: fp.Type, StationItemStruct: fp.StationItemStruct, DataTimeStruct: d}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 // jsonWriter returns a write function that JSON-encodes values to w.
 func jsonWriter(w io.Writer) func(any) error {
 	return func(val any) error {
@@ -3,7 +3,6 @@ package controller
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"io"
 	"log/slog"
 	"net"
@@ -17,7 +16,6 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/yamux"
-	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func Sync(w http.ResponseWriter, r *http.Request) {
@@ -227,7 +225,7 @@ func syncDataServer(username string, session *yamux.Session, parentCtx context.C
 	defer cancel()
 	permissions, permissionTopics := currentSyncDataScope(username)
 
-	subscriber := hub.NewSubscriber(ctx, cancel, jsonWriter(stream3))
+	subscriber := hub.NewSubscriber(ctx, cancel, syncDataWriter(stream3))
 	go func() { <-ctx.Done(); _ = stream3.Close() }()
 	{
 		hub.TrackSubscriber(username, subscriber, connTypeSyncData)
@@ -289,10 +287,6 @@ func fillMissDataServer(conn net.Conn, permissions common.UUIDStringsMap) {
 			if msec > 0 {
 				ds, err := db.GetDataHistory(stationID, itemName, msec, 0)
 				if err != nil {
-					if pgErr, ok := errors.AsType[*pgconn.PgError](err); ok && pgErr.Code == "42P01" {
-						// relation Table does not exist
-						continue
-					}
 					slog.Error("Failed to get data history for miss data", "station_id", stationID, "item_name", itemName, "error", err)
 					return
 				}
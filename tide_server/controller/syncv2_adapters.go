@@ -27,16 +27,19 @@ var (
 
 func initSyncV2() {
 	v2StationServer = &syncv2station.Server{
-		Store:      syncv2station.DBStore{},
-		InfoSyncer: syncV2StationInfoSyncer{},
-		Notifier:   syncV2StationNotifier{},
-		Logger:     slog.Default(),
+		Store:               syncv2station.DBStore{},
+		InfoSyncer:          syncV2StationInfoSyncer{},
+		Notifier:            syncV2StationNotifier{},
+		Logger:              slog.Default(),
+		ClockDriftThreshold: global.Config.SyncV2.ClockDriftSec * time.Second,
+		MaxFutureSkew:       global.Config.SyncV2.MaxFutureSec * time.Second,
 	}
 	v2StationHandler = &syncv2station.Handler{
-		Enabled:       func() bool { return global.Config.SyncV2.Enabled },
-		Server:        v2StationServer,
-		MaxFrameBytes: internalsyncv2.DefaultMaxFrameBytes,
-		Logger:        slog.Default(),
+		Enabled:           func() bool { return global.Config.SyncV2.Enabled },
+		RequireClientCert: func() bool { return global.Config.SyncV2.RequireClientCert },
+		Server:            v2StationServer,
+		MaxFrameBytes:     internalsyncv2.DefaultMaxFrameBytes,
+		Logger:            slog.Default(),
 	}
 
 	upstreamServer := &syncv2relay.UpstreamServer{
@@ -119,6 +122,10 @@ func (syncV2RelayDownstreamNotifier) PublishMissData(topic common.StationItemStr
 	}, topic)
 }
 
+func (syncV2RelayDownstreamNotifier) PublishEditedData(topic common.StationItemStruct, points []common.DataTimeStruct) {
+	hub.Publish(BrokerMissingData, forwardPointsStruct{Type: kMsgEditData, StationItemStruct: topic, Points: points}, topic)
+}
+
 func (syncV2RelayDownstreamNotifier) PublishData(topic common.StationItemStruct, data common.DataTimeStruct, gpio bool) {
 	msgType := kMsgData
 	if gpio {
@@ -183,14 +190,12 @@ func (syncV2RelayFrameSource) Subscribe(ctx context.Context, username string, pe
 				if !ok {
 					return
 				}
-				frame := relayDataMessageToFrame(raw)
-				if frame == nil {
-					continue
-				}
-				select {
-				case dataOut <- frame:
-				case <-dataCtx.Done():
-					return
+				for _, frame := range relayDataMessageToFrames(raw) {
+					select {
+					case dataOut <- frame:
+					case <-dataCtx.Done():
+						return
+					}
 				}
 			}
 		}
@@ -265,6 +270,17 @@ func relayConfigMessageToFrame(val any) *syncpb.RelayMessage {
 	}}
 }
 
+func relayDataMessageToFrames(val any) []*syncpb.RelayMessage {
+	if fp, ok := val.(forwardPointsStruct); ok {
+		return syncv2relay.DataFrames(fp.StationId, fp.ItemName, fp.Type, fp.Points, fp.EditId)
+	}
+	frame := relayDataMessageToFrame(val)
+	if frame == nil {
+		return nil
+	}
+	return []*syncpb.RelayMessage{frame}
+}
+
 func relayDataMessageToFrame(val any) *syncpb.RelayMessage {
 	msg, ok := val.(forwardDataStruct)
 	if !ok {
@@ -287,6 +303,7 @@ func relayDataMessageToFrame(val any) *syncpb.RelayMessage {
 					Value:    msg.Value,
 					UnixMs:   msg.Millisecond.ToInt64(),
 					Kind:     kind,
+					Flag:     uint32(msg.Flag),
 				},
 			},
 		},
@@ -320,3 +337,31 @@ func v2SnapshotOrErr(stationID uuid.UUID, cameraName string, timeout time.Durati
 	}
 	return v2StationServer.RequestSnapshot(stationID, cameraName, timeout)
 }
+
+func v2PushDeviceConfig(stationID uuid.UUID) error {
+	if v2StationServer == nil {
+		return syncv2station.ErrStationNotConnected
+	}
+	return v2StationServer.PushDeviceConfig(stationID)
+}
+
+func v2DeviceCommand(stationID uuid.UUID, req *syncpb.DeviceCommandRequest, timeout time.Duration) (*syncpb.DeviceCommandResponse, error) {
+	if v2StationServer == nil {
+		return nil, syncv2station.ErrStationNotConnected
+	}
+	return v2StationServer.RequestDeviceCommand(stationID, req, timeout)
+}
+
+func v2RequestLogs(stationID uuid.UUID, req *syncpb.LogRequest, timeout time.Duration) ([]*syncpb.LogEntry, error) {
+	if v2StationServer == nil {
+		return nil, syncv2station.ErrStationNotConnected
+	}
+	return v2StationServer.RequestLogs(stationID, req, timeout)
+}
+
+func v2FollowLogs(ctx context.Context, stationID uuid.UUID, req *syncpb.LogRequest, fn func([]*syncpb.LogEntry) error) error {
+	if v2StationServer == nil {
+		return syncv2station.ErrStationNotConnected
+	}
+	return v2StationServer.FollowLogs(ctx, stationID, req, fn)
+}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"testing"
 
+	"tide/common"
+	"tide/pkg/custype"
+	syncpb "tide/pkg/pb/syncproto"
 	"tide/pkg/pubsub"
+	syncv2relay "tide/tide_server/syncv2/relay"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -55,3 +60,66 @@ func TestSyncV2RelayFrameSource_StopDoesNotCloseSubscriberChannels(t *testing.T)
 		}
 	})
 }
+
+func TestRelayDataMessageToFrame_CarriesFlag(t *testing.T) {
+	stationID := uuid.New()
+	frame := relayDataMessageToFrame(forwardDataStruct{
+		Type:              kMsgDataGpio,
+		StationItemStruct: common.StationItemStruct{StationId: stationID, ItemName: "item1"},
+		DataTimeStruct:    common.DataTimeStruct{Value: 1.5, Millisecond: 1000, Flag: common.QCProbablyBad},
+	})
+	require.NotNil(t, frame)
+
+	batch := frame.GetDataBatch()
+	require.NotNil(t, batch)
+	assert.Equal(t, stationID.String(), batch.StationId)
+	require.Len(t, batch.Points, 1)
+	assert.Equal(t, uint32(common.QCProbablyBad), batch.Points[0].Flag)
+	assert.Equal(t, syncpb.DataKind_DATA_KIND_GPIO, batch.Points[0].Kind)
+}
+
+func TestRelayDataMessageToFrames_EditData(t *testing.T) {
+	stationID := uuid.New()
+	frames := relayDataMessageToFrames(forwardPointsStruct{
+		Type:              kMsgEditData,
+		StationItemStruct: common.StationItemStruct{StationId: stationID, ItemName: "item1"},
+		Points: []common.DataTimeStruct{
+			{Value: 1.5, Millisecond: 1000, Flag: common.QCChanged},
+			{Value: 2.5, Millisecond: 2000, Flag: common.QCChanged},
+		},
+	})
+	require.Len(t, frames, 1)
+
+	batch := frames[0].GetDataBatch()
+	require.NotNil(t, batch)
+	assert.Equal(t, kMsgEditData, batch.DataType)
+	require.Len(t, batch.Points, 2)
+	assert.Equal(t, "item1", batch.Points[1].ItemName)
+	assert.Equal(t, 2.5, batch.Points[1].Value)
+	assert.Equal(t, int64(2000), batch.Points[1].UnixMs)
+	assert.Equal(t, uint32(common.QCChanged), batch.Points[1].Flag)
+}
+
+func TestRelayDataMessageToFrames_Batches(t *testing.T) {
+	ds := make([]common.DataTimeStruct, 2*syncv2relay.DataBatchSize+1)
+	for i := range ds {
+		ds[i].Millisecond = custype.UnixMs(i)
+	}
+	frames := relayDataMessageToFrames(forwardPointsStruct{
+		Type:              kMsgMissData,
+		StationItemStruct: common.StationItemStruct{StationId: uuid.New(), ItemName: "item1"},
+		Points:            ds,
+	})
+	require.Len(t, frames, 3)
+	var n int
+	for _, frame := range frames {
+		batch := frame.GetDataBatch()
+		assert.Equal(t, kMsgMissData, batch.DataType)
+		assert.LessOrEqual(t, len(batch.Points), syncv2relay.DataBatchSize)
+		for _, point := range batch.Points {
+			assert.Equal(t, int64(n), point.UnixMs)
+			n++
+		}
+	}
+	assert.Equal(t, len(ds), n)
+}
@@ -0,0 +1,85 @@
+package dataimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"tide/common"
+
+	"github.com/google/uuid"
+)
+
+// Result is the response of /importData: the points received, and the ones imported as they were not stored yet.
+type Result struct {
+	Received int `json:"received"`
+	Imported int `json:"imported"`
+}
+
+// Client imports data through the /importData endpoint of a server, as an admin.
+type Client struct {
+	Server string
+	HTTP   *http.Client
+	token  string
+}
+
+func responseError(resp *http.Response) error {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(b, &body) == nil && body.Error != "" {
+		return fmt.Errorf("%s: %s", resp.Status, body.Error)
+	}
+	return fmt.Errorf("%s", resp.Status)
+}
+
+// Login gets the access token of username.
+func (c *Client) Login(username, password string) error {
+	resp, err := c.HTTP.PostForm(strings.TrimSuffix(c.Server, "/")+"/login", url.Values{"username": {username}, "password": {password}})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("login: %w", responseError(resp))
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return err
+	}
+	c.token = token.AccessToken
+	return nil
+}
+
+// Import sends the points of an item of a station.
+func (c *Client) Import(stationId uuid.UUID, itemName string, ds []common.DataTimeStruct) (Result, error) {
+	var result Result
+	body, err := json.Marshal(ds)
+	if err != nil {
+		return result, err
+	}
+	q := url.Values{"station_id": {stationId.String()}, "item_name": {itemName}, "format": {FormatJSON}}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.Server, "/")+"/importData?"+q.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return result, responseError(resp)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
@@ -0,0 +1,141 @@
+// Package dataimport reads historical data of an item, such as digitised paper charts or the records of previous
+// loggers, from CSV or JSON.
+package dataimport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"tide/common"
+	"tide/pkg/custype"
+)
+
+// Formats of the imported data.
+const (
+	// FormatCSV has a line per point: timestamp,value[,flag]. The timestamp is in unix milliseconds or a date time,
+	// RFC 3339 or "2006-01-02 15:04:05" in UTC. A first line that is not a point is taken as a header,
+	// and lines starting with # are comments.
+	FormatCSV = "csv"
+	// FormatJSON is an array of points as returned by /dataHistory: [{"msec":1724411007076,"val":0.5,"flag":1}].
+	FormatJSON = "json"
+)
+
+// ValidFormat reports whether format is one of the import formats.
+func ValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatJSON
+}
+
+var dateTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04"}
+
+func parseTimestamp(s string) (custype.UnixMs, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return custype.UnixMs(ms), nil
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return custype.ToUnixMs(t), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid timestamp %q", s)
+}
+
+func parseCSVRecord(record []string) (d common.DataTimeStruct, err error) {
+	if len(record) < 2 || len(record) > 3 {
+		return d, fmt.Errorf("%d fields, want timestamp,value[,flag]", len(record))
+	}
+	if d.Millisecond, err = parseTimestamp(strings.TrimSpace(record[0])); err != nil {
+		return d, err
+	}
+	if d.Value, err = strconv.ParseFloat(strings.TrimSpace(record[1]), 64); err != nil {
+		return d, fmt.Errorf("invalid value %q", record[1])
+	}
+	if len(record) == 3 && strings.TrimSpace(record[2]) != "" {
+		flag, err := strconv.ParseUint(strings.TrimSpace(record[2]), 10, 8)
+		if err != nil {
+			return d, fmt.Errorf("invalid flag %q", record[2])
+		}
+		d.Flag = common.QCFlag(flag)
+	}
+	return d, nil
+}
+
+func parseCSV(r io.Reader) ([]common.DataTimeStruct, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+	var ds []common.DataTimeStruct
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return ds, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		d, err := parseCSVRecord(record)
+		if err != nil {
+			if first {
+				continue
+			}
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ds = append(ds, d)
+	}
+}
+
+// Parse reads the points in format from r.
+func Parse(r io.Reader, format string) ([]common.DataTimeStruct, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatJSON:
+		var ds []common.DataTimeStruct
+		if err := json.NewDecoder(r).Decode(&ds); err != nil {
+			return nil, err
+		}
+		return ds, nil
+	}
+	return nil, fmt.Errorf("unknown import format: %s", format)
+}
+
+var validFlags = []common.QCFlag{
+	common.QCNone, common.QCGood, common.QCProbablyGood, common.QCProbablyBad, common.QCBad, common.QCChanged, common.QCMissing,
+}
+
+// Validate checks that the points are stamped after the epoch and not after now, with finite values and
+// SeaDataNet flags, and that no timestamp is given twice.
+func Validate(ds []common.DataTimeStruct, now time.Time) error {
+	if len(ds) == 0 {
+		return errors.New("no data")
+	}
+	latest := custype.ToUnixMs(now)
+	seen := make(map[custype.UnixMs]struct{}, len(ds))
+	for i, d := range ds {
+		switch {
+		case d.Millisecond <= 0:
+			return fmt.Errorf("point %d: timestamp %d before the epoch", i+1, d.Millisecond)
+		case d.Millisecond > latest:
+			return fmt.Errorf("point %d: timestamp %s in the future", i+1, d.Millisecond.ToTime().UTC().Format(time.RFC3339))
+		case math.IsNaN(d.Value) || math.IsInf(d.Value, 0):
+			return fmt.Errorf("point %d: value %v", i+1, d.Value)
+		case !slices.Contains(validFlags, d.Flag):
+			return fmt.Errorf("point %d: flag %d", i+1, d.Flag)
+		}
+		if _, ok := seen[d.Millisecond]; ok {
+			return fmt.Errorf("point %d: timestamp %s given twice", i+1, d.Millisecond.ToTime().UTC().Format(time.RFC3339Nano))
+		}
+		seen[d.Millisecond] = struct{}{}
+	}
+	return nil
+}
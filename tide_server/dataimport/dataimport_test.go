@@ -0,0 +1,138 @@
+package dataimport
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tide/common"
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_CSV(t *testing.T) {
+	in := `timestamp,value,flag
+# digitised from chart 12
+1577836800000,1.5
+2020-01-01 00:01:00, 1.6, 1
+2020-01-01T00:02:00+08:00,-0.25,4
+2020-01-01 00:03,2,
+`
+	ds, err := Parse(strings.NewReader(in), FormatCSV)
+	require.NoError(t, err)
+	ms := func(s string) custype.UnixMs {
+		tm, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return custype.ToUnixMs(tm)
+	}
+	assert.Equal(t, []common.DataTimeStruct{
+		{Millisecond: 1577836800000, Value: 1.5},
+		{Millisecond: ms("2020-01-01T00:01:00Z"), Value: 1.6, Flag: common.QCGood},
+		{Millisecond: ms("2019-12-31T16:02:00Z"), Value: -0.25, Flag: common.QCBad},
+		{Millisecond: ms("2020-01-01T00:03:00Z"), Value: 2},
+	}, ds)
+}
+
+func TestParse_CSVErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1577836800000,1.5\n1577836860000,abc\n", "line 2: invalid value"},
+		{"1577836800000,1.5\n01/01/2020,1\n", "line 2: invalid timestamp"},
+		{"1577836800000,1.5\n1577836860000\n", "line 2: 1 fields"},
+		{"1577836800000,1.5\n1577836860000,1,300\n", "line 2: invalid flag"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.in), FormatCSV)
+		assert.ErrorContains(t, err, tt.want, tt.in)
+	}
+}
+
+func TestParse_JSON(t *testing.T) {
+	ds, err := Parse(strings.NewReader(`[{"val":0.5,"msec":1724411007076},{"val":0,"msec":1724411097075,"flag":3}]`), FormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []common.DataTimeStruct{
+		{Millisecond: 1724411007076, Value: 0.5},
+		{Millisecond: 1724411097075, Flag: common.QCProbablyBad},
+	}, ds)
+
+	_, err = Parse(strings.NewReader(`{"val":0.5}`), FormatJSON)
+	assert.Error(t, err)
+	_, err = Parse(strings.NewReader(``), "xlsx")
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	now := time.UnixMilli(10_000)
+	tests := []struct {
+		name string
+		ds   []common.DataTimeStruct
+		ok   bool
+	}{
+		{"valid", []common.DataTimeStruct{{Millisecond: 1, Value: 1}, {Millisecond: 10_000, Value: 2, Flag: common.QCMissing}}, true},
+		{"empty", nil, false},
+		{"epoch", []common.DataTimeStruct{{Millisecond: 0, Value: 1}}, false},
+		{"future", []common.DataTimeStruct{{Millisecond: 10_001, Value: 1}}, false},
+		{"nan", []common.DataTimeStruct{{Millisecond: 1, Value: math.NaN()}}, false},
+		{"flag", []common.DataTimeStruct{{Millisecond: 1, Value: 1, Flag: 7}}, false},
+		{"twice", []common.DataTimeStruct{{Millisecond: 1, Value: 1}, {Millisecond: 1, Value: 2}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.ds, now)
+			assert.Equal(t, tt.ok, err == nil, err)
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	stationId := uuid.New()
+	ds := []common.DataTimeStruct{{Millisecond: 1, Value: 1}, {Millisecond: 2, Value: 2, Flag: common.QCGood}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("username") != "admin" || r.PostFormValue("password") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token1", "token_type": "Bearer"})
+	})
+	mux.HandleFunc("POST /importData", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.Header.Get("Authorization") != "Bearer token1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if q.Get("station_id") != stationId.String() || q.Get("format") != FormatJSON || q.Get("item_name") != "item1" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "unknown item " + q.Get("item_name")})
+			return
+		}
+		got, err := Parse(r.Body, FormatJSON)
+		if err != nil || len(got) != len(ds) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Result{Received: len(got), Imported: 1})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := Client{Server: srv.URL + "/", HTTP: srv.Client()}
+	assert.ErrorContains(t, c.Login("admin", "wrong"), "401")
+	require.NoError(t, c.Login("admin", "secret"))
+
+	result, err := c.Import(stationId, "item1", ds)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Received: 2, Imported: 1}, result)
+
+	_, err = c.Import(stationId, "item2", ds)
+	assert.ErrorContains(t, err, "unknown item item2")
+}
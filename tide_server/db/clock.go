@@ -0,0 +1,57 @@
+package db
+
+import (
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+)
+
+// StationClock is the clock state of a local station. Data stamped before InstalledAt is quarantined,
+// ClockOffsetMs is the server clock minus the station clock measured in the last Sync V2 handshake.
+type StationClock struct {
+	StationId     uuid.UUID      `json:"station_id"`
+	Identifier    string         `json:"identifier"`
+	InstalledAt   custype.UnixMs `json:"installed_at"`
+	ClockOffsetMs int64          `json:"clock_offset_ms"`
+	ClockDrift    bool           `json:"clock_drift"`
+}
+
+func GetStationClocks() ([]StationClock, error) {
+	rows, err := TideDB.Query(`select id, identifier, installed_at, clock_offset_ms, clock_drift from stations where upstream=false and deleted_at is null order by identifier`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var (
+		c  StationClock
+		cs []StationClock
+	)
+	for rows.Next() {
+		if err = rows.Scan(&c.StationId, &c.Identifier, &c.InstalledAt, &c.ClockOffsetMs, &c.ClockDrift); err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+	return cs, rows.Err()
+}
+
+// GetStationInstalledAt returns 0 if the installation time of the station is not set.
+func GetStationInstalledAt(stationId uuid.UUID) (installedAt custype.UnixMs, err error) {
+	err = TideDB.QueryRow(`select installed_at from stations where id=$1`, stationId).Scan(&installedAt)
+	return
+}
+
+// SetStationInstalledAt sets the installation time of a local station, 0 clears it.
+func SetStationInstalledAt(stationId uuid.UUID, installedAt custype.UnixMs) (int64, error) {
+	var t any
+	if installedAt != 0 {
+		t = installedAt
+	}
+	res, err := TideDB.Exec(`update stations set installed_at=$2 where id=$1 and upstream=false and deleted_at is null`, stationId, t)
+	return checkResult(res, err)
+}
+
+func SaveStationClockOffset(stationId uuid.UUID, offsetMs int64, drift bool) (int64, error) {
+	res, err := TideDB.Exec(`update stations set clock_offset_ms=$2, clock_drift=$3 where id=$1`, stationId, offsetMs, drift)
+	return checkResult(res, err)
+}
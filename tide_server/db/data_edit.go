@@ -0,0 +1,283 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"tide/common"
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+)
+
+// Actions of data edits. DataEditMissing flags the values as missing, they are kept so that unflag can bring them back.
+// DataEditSync is a change received from an upstream server.
+const (
+	DataEditFlag    = "flag"
+	DataEditUnflag  = "unflag"
+	DataEditOffset  = "offset"
+	DataEditMissing = "missing"
+	DataEditSync    = "sync"
+)
+
+// MaxEditPoints is the number of points an edit may read, an edit of a longer range fails with ErrTooManyPoints.
+const MaxEditPoints = 100000
+
+var ErrTooManyPoints = errors.New("too many points to edit")
+
+// DataEdit is a change of the data of an item between Start and End, both included, with the values before and after it.
+type DataEdit struct {
+	Id        int64                   `json:"id"`
+	StationId uuid.UUID               `json:"station_id"`
+	ItemName  string                  `json:"item_name"`
+	Action    string                  `json:"action"`
+	Start     custype.UnixMs          `json:"start"`
+	End       custype.UnixMs          `json:"end"`
+	Flag      common.QCFlag           `json:"flag"`
+	Offset    float64                 `json:"offset"`
+	Username  string                  `json:"username"`
+	Before    []common.DataTimeStruct `json:"before"`
+	After     []common.DataTimeStruct `json:"after"`
+	CreatedAt custype.UnixMs          `json:"created_at"`
+
+	// flagChanges are the flag changes of the earlier edits of the points, latest first, which unflag reverts.
+	flagChanges map[custype.UnixMs][]flagChange
+}
+
+type flagChange struct {
+	before, after common.QCFlag
+}
+
+// edited returns d after the edit: flagged with Flag, unflagged to the flag it had before the latest flag or missing
+// edit that gave it its flag, corrected by Offset and marked changed, or flagged missing.
+func (e *DataEdit) edited(d common.DataTimeStruct) common.DataTimeStruct {
+	switch e.Action {
+	case DataEditFlag:
+		d.Flag = e.Flag
+	case DataEditUnflag:
+		for _, c := range e.flagChanges[d.Millisecond] {
+			if c.after == d.Flag {
+				d.Flag = c.before
+				break
+			}
+		}
+	case DataEditOffset:
+		d.Value += e.Offset
+		d.Flag = common.QCChanged
+	case DataEditMissing:
+		d.Flag = common.QCMissing
+	}
+	return d
+}
+
+// loadFlagChanges reads the flag changes of the flag and missing edits of the points of e from the audit trail.
+func (e *DataEdit) loadFlagChanges(tx *sql.Tx) error {
+	rows, err := tx.Query(`select before, after from data_edits
+where station_id=$1 and item_name=$2 and action in ($3, $4) and start_at<=$6 and end_at>=$5 order by id desc`,
+		e.StationId, e.ItemName, DataEditFlag, DataEditMissing, e.Start, e.End)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	e.flagChanges = make(map[custype.UnixMs][]flagChange)
+	for rows.Next() {
+		var (
+			before, after []byte
+			bs, as        []common.DataTimeStruct
+		)
+		if err = rows.Scan(&before, &after); err != nil {
+			return err
+		}
+		if err = json.Unmarshal(before, &bs); err != nil {
+			return err
+		}
+		if err = json.Unmarshal(after, &as); err != nil {
+			return err
+		}
+		// The points before and after an edit are recorded in the same order.
+		for i := range min(len(bs), len(as)) {
+			e.flagChanges[as[i].Millisecond] = append(e.flagChanges[as[i].Millisecond], flagChange{before: bs[i].Flag, after: as[i].Flag})
+		}
+	}
+	return rows.Err()
+}
+
+// EditData applies e to the data of its item and records it with the values it changed, it returns the number of them.
+// Nothing is recorded if no value changed.
+func EditData(e *DataEdit) (int64, error) {
+	tx, err := TideDB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if e.Action == DataEditUnflag {
+		if err = e.loadFlagChanges(tx); err != nil {
+			return 0, err
+		}
+	}
+	rows, err := tx.Query(`select ts, value, flag from observations where station_id=$1 and item=$2 and ts>=$3 and ts<=$4 order by ts for update`,
+		e.StationId, e.ItemName, e.Start, e.End)
+	if err != nil {
+		return 0, err
+	}
+	e.Before, e.After = nil, nil
+	var n int
+	for rows.Next() {
+		if n++; n > MaxEditPoints {
+			_ = rows.Close()
+			return 0, ErrTooManyPoints
+		}
+		var d common.DataTimeStruct
+		if err = rows.Scan(&d.Millisecond, &d.Value, &d.Flag); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		if after := e.edited(d); after != d {
+			e.Before, e.After = append(e.Before, d), append(e.After, after)
+		}
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(e.After) == 0 {
+		return 0, nil
+	}
+
+	for _, d := range e.After {
+		if _, err = tx.Exec(`update observations set value=$4, flag=$5 where station_id=$1 and item=$2 and ts=$3`,
+			e.StationId, e.ItemName, d.Millisecond, d.Value, d.Flag); err != nil {
+			return 0, err
+		}
+	}
+	if err = saveDataEdit(tx, e); err != nil {
+		return 0, err
+	}
+	return int64(len(e.After)), tx.Commit()
+}
+
+// SyncEditedData stores a value edited on an upstream server if it differs from the point here. A point that is not
+// here is added if a later one is, the points after the latest one arrive edited with the missing data.
+func SyncEditedData(stationId uuid.UUID, itemName string, d common.DataTimeStruct) (int64, error) {
+	tx, err := TideDB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before := common.DataTimeStruct{Millisecond: d.Millisecond}
+	err = tx.QueryRow(`select value, flag from observations where station_id=$1 and item=$2 and ts=$3 for update`, stationId, itemName, d.Millisecond).
+		Scan(&before.Value, &before.Flag)
+	if errors.Is(err, sql.ErrNoRows) {
+		return insertEditedData(tx, stationId, itemName, d)
+	}
+	if err != nil || before == d {
+		return 0, err
+	}
+	if _, err = tx.Exec(`update observations set value=$4, flag=$5 where station_id=$1 and item=$2 and ts=$3`,
+		stationId, itemName, d.Millisecond, d.Value, d.Flag); err != nil {
+		return 0, err
+	}
+	return saveSyncEdit(tx, stationId, itemName, []common.DataTimeStruct{before}, d)
+}
+
+func insertEditedData(tx *sql.Tx, stationId uuid.UUID, itemName string, d common.DataTimeStruct) (int64, error) {
+	if err := ensureObservationPartition(d.Millisecond.ToTime()); err != nil {
+		return 0, err
+	}
+	n, err := checkResult(tx.Exec(`insert into observations(station_id, item, ts, value, flag)
+select $1, $2, $3, $4, $5 where exists (select 1 from observations where station_id=$1 and item=$2 and ts>$3)
+on conflict do nothing`, stationId, itemName, d.Millisecond, d.Value, d.Flag))
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	return saveSyncEdit(tx, stationId, itemName, []common.DataTimeStruct{}, d)
+}
+
+func saveSyncEdit(tx *sql.Tx, stationId uuid.UUID, itemName string, before []common.DataTimeStruct, d common.DataTimeStruct) (int64, error) {
+	e := DataEdit{
+		StationId: stationId,
+		ItemName:  itemName,
+		Action:    DataEditSync,
+		Start:     d.Millisecond,
+		End:       d.Millisecond,
+		Flag:      d.Flag,
+		Before:    before,
+		After:     []common.DataTimeStruct{d},
+	}
+	if err := saveDataEdit(tx, &e); err != nil {
+		return 0, err
+	}
+	return 1, tx.Commit()
+}
+
+func saveDataEdit(tx *sql.Tx, e *DataEdit) error {
+	before, err := json.Marshal(e.Before)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(e.After)
+	if err != nil {
+		return err
+	}
+	return tx.QueryRow(`insert into data_edits(station_id, item_name, action, start_at, end_at, flag, "offset", username, before, after)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) returning id, created_at`,
+		e.StationId, e.ItemName, e.Action, e.Start, e.End, e.Flag, e.Offset, e.Username, before, after).Scan(&e.Id, &e.CreatedAt)
+}
+
+// GetDataEdits returns the latest edits first, of all stations if stationId is uuid.Nil.
+func GetDataEdits(stationId uuid.UUID, limit uint) ([]DataEdit, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if stationId == uuid.Nil {
+		rows, err = TideDB.Query(`select `+dataEditColumns+` from data_edits order by id desc limit $1`, limit)
+	} else {
+		rows, err = TideDB.Query(`select `+dataEditColumns+` from data_edits where station_id=$1 order by id desc limit $2`, stationId, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scanDataEdits(rows)
+}
+
+// GetDataEditsAfter returns the first edits with an id greater than afterId, in the order they were made.
+func GetDataEditsAfter(afterId int64, limit uint) ([]DataEdit, error) {
+	rows, err := TideDB.Query(`select `+dataEditColumns+` from data_edits where id>$1 order by id limit $2`, afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanDataEdits(rows)
+}
+
+// LatestDataEditId returns the id of the latest edit, 0 if there is none.
+func LatestDataEditId() (id int64, err error) {
+	err = TideDB.QueryRow(`select coalesce(max(id), 0) from data_edits`).Scan(&id)
+	return
+}
+
+const dataEditColumns = `id, station_id, item_name, action, start_at, end_at, flag, "offset", username, before, after, created_at`
+
+func scanDataEdits(rows *sql.Rows) ([]DataEdit, error) {
+	defer func() { _ = rows.Close() }()
+	var es []DataEdit
+	for rows.Next() {
+		var (
+			e             DataEdit
+			before, after []byte
+		)
+		if err := rows.Scan(&e.Id, &e.StationId, &e.ItemName, &e.Action, &e.Start, &e.End, &e.Flag, &e.Offset, &e.Username, &before, &after, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(before, &e.Before); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(after, &e.After); err != nil {
+			return nil, err
+		}
+		es = append(es, e)
+	}
+	return es, rows.Err()
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"time"
+
+	"tide/common"
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+)
+
+func (s *dbSuite) TestEditData() {
+	e := DataEdit{StationId: station1.Id, ItemName: item1.Name, Action: DataEditOffset, Start: 1, End: 1, Offset: 0.5, Username: "admin"}
+	n, err := EditData(&e)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+	s.NotZero(e.Id)
+	s.Equal([]common.DataTimeStruct{data[0]}, e.Before)
+	s.Equal([]common.DataTimeStruct{{Value: 0.6, Millisecond: 1, Flag: common.QCChanged}}, e.After)
+
+	e = DataEdit{StationId: station1.Id, ItemName: item1.Name, Action: DataEditFlag, Start: 0, End: 2, Flag: common.QCBad, Username: "admin"}
+	n, err = EditData(&e)
+	s.Require().NoError(err)
+	s.EqualValues(2, n)
+
+	// Nothing changes, nothing is recorded.
+	n, err = EditData(&e)
+	s.Require().NoError(err)
+	s.Zero(n)
+
+	got, err := GetDataHistory(station1.Id, item1.Name, 0, 3)
+	s.Require().NoError(err)
+	s.Equal([]common.DataTimeStruct{
+		{Value: 0.6, Millisecond: 1, Flag: common.QCBad},
+		{Value: 0.1, Millisecond: 2, Flag: common.QCBad},
+	}, got)
+
+	es, err := GetDataEdits(station1.Id, 10)
+	s.Require().NoError(err)
+	s.Require().Len(es, 2)
+	s.Equal(DataEditFlag, es[0].Action)
+	s.Equal("admin", es[0].Username)
+	s.Len(es[0].After, 2)
+	s.Equal(DataEditOffset, es[1].Action)
+	s.Equal(0.5, es[1].Offset)
+	s.NotZero(es[1].CreatedAt)
+
+	all, err := GetDataEdits(station1.Id, 10)
+	s.Require().NoError(err)
+	es, err = GetDataEdits(uuid.New(), 10)
+	s.Require().NoError(err)
+	s.Empty(es)
+
+	after, err := GetDataEditsAfter(0, 1)
+	s.Require().NoError(err)
+	s.Require().Len(after, 1)
+	s.Equal(all[1].Id, after[0].Id)
+	after, err = GetDataEditsAfter(after[0].Id, 10)
+	s.Require().NoError(err)
+	s.Require().Len(after, 1)
+	s.Equal(DataEditFlag, after[0].Action)
+	id, err := LatestDataEditId()
+	s.Require().NoError(err)
+	s.Equal(after[0].Id, id)
+}
+
+func (s *dbSuite) TestEditData_Unflag() {
+	edit := func(action string, flag common.QCFlag) int64 {
+		n, err := EditData(&DataEdit{StationId: station1.Id, ItemName: item1.Name, Action: action, Start: 2, End: 2, Flag: flag, Username: "admin"})
+		s.Require().NoError(err)
+		return n
+	}
+	s.EqualValues(1, edit(DataEditFlag, common.QCBad))
+	s.EqualValues(1, edit(DataEditMissing, 0))
+
+	// Each unflag reverts the latest edit that gave the point its flag.
+	s.EqualValues(1, edit(DataEditUnflag, 0))
+	got, err := GetDataHistory(station1.Id, item1.Name, 1, 3)
+	s.Require().NoError(err)
+	s.Equal([]common.DataTimeStruct{{Value: 0.1, Millisecond: 2, Flag: common.QCBad}}, got)
+	s.EqualValues(1, edit(DataEditUnflag, 0))
+	got, err = GetDataHistory(station1.Id, item1.Name, 1, 3)
+	s.Require().NoError(err)
+	s.Equal([]common.DataTimeStruct{data[1]}, got)
+
+	// The station's flag is not changed by unflag.
+	s.Zero(edit(DataEditUnflag, 0))
+	n, err := EditData(&DataEdit{StationId: station1.Id, ItemName: item1.Name, Action: DataEditUnflag, Start: 0, End: 1})
+	s.Require().NoError(err)
+	s.Zero(n)
+}
+
+func (s *dbSuite) TestSyncEditedData() {
+	edited := common.DataTimeStruct{Value: 0.1, Millisecond: 2, Flag: common.QCMissing}
+	n, err := SyncEditedData(station1.Id, item1.Name, edited)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+
+	// Applying it again or to a point after the latest one changes nothing, that point arrives with the missing data.
+	n, err = SyncEditedData(station1.Id, item1.Name, edited)
+	s.Require().NoError(err)
+	s.Zero(n)
+	n, err = SyncEditedData(station1.Id, item1.Name, common.DataTimeStruct{Value: 1, Millisecond: 5})
+	s.Require().NoError(err)
+	s.Zero(n)
+
+	got, err := GetDataHistory(station1.Id, item1.Name, 1, 3)
+	s.Require().NoError(err)
+	s.Equal([]common.DataTimeStruct{edited}, got)
+
+	// A point missing here before the latest one is added.
+	missing := common.DataTimeStruct{Value: 0.2, Millisecond: 0, Flag: common.QCChanged}
+	n, err = SyncEditedData(station1.Id, item1.Name, missing)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+	got, err = GetDataHistory(station1.Id, item1.Name, -1, 2)
+	s.Require().NoError(err)
+	s.Equal([]common.DataTimeStruct{missing, data[0]}, got)
+
+	es, err := GetDataEdits(station1.Id, 10)
+	s.Require().NoError(err)
+	s.Require().Len(es, 2)
+	s.Equal(DataEditSync, es[0].Action)
+	s.Empty(es[0].Before)
+	s.Equal([]common.DataTimeStruct{missing}, es[0].After)
+	s.Equal(DataEditSync, es[1].Action)
+	s.Equal([]common.DataTimeStruct{data[1]}, es[1].Before)
+}
+
+func (s *dbSuite) TestEditData_ReleasedData() {
+	// The month of the released point has no partition yet.
+	ts := custype.ToUnixMs(time.Date(2031, 6, 15, 0, 0, 0, 0, time.UTC))
+	s.Require().NoError(SaveQuarantinedData(station1.Id, item1.Name, 1.5, common.QCNone, ts, "future"))
+	ds, err := GetQuarantinedData(station1.Id, 1)
+	s.Require().NoError(err)
+	s.Require().Len(ds, 1)
+	_, err = ReleaseQuarantinedData(ds[0].Id)
+	s.Require().NoError(err)
+
+	e := DataEdit{StationId: station1.Id, ItemName: item1.Name, Action: DataEditOffset, Start: ts, End: ts, Offset: -0.5, Username: "admin"}
+	n, err := EditData(&e)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+	n, err = SyncEditedData(station1.Id, item1.Name, common.DataTimeStruct{Value: 1, Millisecond: ts, Flag: common.QCProbablyGood})
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+
+	got, err := GetDataHistory(station1.Id, item1.Name, ts-1, ts+1)
+	s.Require().NoError(err)
+	s.Equal([]common.DataTimeStruct{{Value: 1, Millisecond: ts, Flag: common.QCProbablyGood}}, got)
+
+	// Another item of the station is left alone.
+	n, err = EditData(&DataEdit{StationId: station1.Id, ItemName: "item2", Action: DataEditFlag, Start: 0, End: ts, Flag: common.QCBad})
+	s.Require().NoError(err)
+	s.Zero(n)
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+)
+
+// DeviceCommandLog records a raw sensor command an admin sent to a station, and what came back.
+type DeviceCommandLog struct {
+	Id        int64           `json:"id"`
+	StationId uuid.UUID       `json:"station_id"`
+	Username  string          `json:"username"`
+	Request   json.RawMessage `json:"request"`
+	Output    []byte          `json:"output"`
+	Error     string          `json:"error"`
+	CreatedAt custype.UnixMs  `json:"created_at"`
+}
+
+func SaveDeviceCommandLog(l *DeviceCommandLog) error {
+	if l.Output == nil {
+		l.Output = []byte{}
+	}
+	return TideDB.QueryRow(`insert into device_command_log(station_id, username, request, output, error) VALUES ($1,$2,$3,$4,$5) returning id, created_at`,
+		l.StationId, l.Username, l.Request, l.Output, l.Error).Scan(&l.Id, &l.CreatedAt)
+}
+
+// GetDeviceCommandLogs returns the latest logs first, of all stations if stationId is uuid.Nil.
+func GetDeviceCommandLogs(stationId uuid.UUID, limit uint) ([]DeviceCommandLog, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if stationId == uuid.Nil {
+		rows, err = TideDB.Query(`select id, station_id, username, request, output, error, created_at from device_command_log order by id desc limit $1`, limit)
+	} else {
+		rows, err = TideDB.Query(`select id, station_id, username, request, output, error, created_at from device_command_log where station_id=$1 order by id desc limit $2`, stationId, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var (
+		l  DeviceCommandLog
+		ls []DeviceCommandLog
+	)
+	for rows.Next() {
+		if err = rows.Scan(&l.Id, &l.StationId, &l.Username, &l.Request, &l.Output, &l.Error, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		ls = append(ls, l)
+	}
+	return ls, rows.Err()
+}
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+func (s *dbSuite) TestDeviceCommandLog() {
+	l1 := DeviceCommandLog{StationId: station1.Id, Username: "admin", Request: json.RawMessage(`{"bus":"/dev/ttyUSB0","protocol":"sdi12","command":"0I!"}`), Output: []byte("013OTT")}
+	s.Require().NoError(SaveDeviceCommandLog(&l1))
+	s.NotZero(l1.Id)
+	s.NotZero(l1.CreatedAt)
+	l2 := DeviceCommandLog{StationId: station1.Id, Username: "admin", Request: json.RawMessage(`{"bus":"x"}`), Error: "unknown bus"}
+	s.Require().NoError(SaveDeviceCommandLog(&l2))
+
+	logs, err := GetDeviceCommandLogs(station1.Id, 10)
+	s.Require().NoError(err)
+	s.Require().Len(logs, 2)
+	s.Equal(l2.Id, logs[0].Id)
+	s.Equal("unknown bus", logs[0].Error)
+	s.Equal([]byte("013OTT"), logs[1].Output)
+
+	logs, err = GetDeviceCommandLogs(uuid.Nil, 1)
+	s.Require().NoError(err)
+	s.Len(logs, 1)
+
+	logs, err = GetDeviceCommandLogs(upstream1Station1.Id, 10)
+	s.Require().NoError(err)
+	s.Empty(logs)
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"encoding/json"
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+)
+
+// StationDeviceConfig is the device configuration the server pushes to a Sync V2 station.
+// Config has the shape map[conn_type][]device_config, see the client's devices config.
+type StationDeviceConfig struct {
+	StationId      uuid.UUID       `json:"station_id"`
+	Config         json.RawMessage `json:"config"`
+	Version        int64           `json:"version"`
+	UpdatedAt      custype.UnixMs  `json:"updated_at"`
+	AppliedVersion int64           `json:"applied_version"`
+	ApplyError     string          `json:"apply_error"`
+}
+
+func GetStationDeviceConfig(stationId uuid.UUID) (StationDeviceConfig, error) {
+	var c StationDeviceConfig
+	err := TideDB.QueryRow(`select station_id, config, version, updated_at, applied_version, apply_error from station_device_config where station_id=$1`, stationId).
+		Scan(&c.StationId, &c.Config, &c.Version, &c.UpdatedAt, &c.AppliedVersion, &c.ApplyError)
+	return c, err
+}
+
+// SaveStationDeviceConfig stores a new config for a local station and returns its version.
+func SaveStationDeviceConfig(stationId uuid.UUID, config json.RawMessage) (version int64, err error) {
+	err = TideDB.QueryRow(`insert into station_device_config(station_id, config) select id, $2 from stations where id=$1 and upstream=false and deleted_at is null
+on conflict (station_id) do update set config=excluded.config, version=station_device_config.version+1, updated_at=now(), apply_error=''
+returning version`, stationId, config).Scan(&version)
+	return
+}
+
+// UpdateStationDeviceConfigApplied records the version a station reports as applied, and why the last push was rolled back if it was.
+func UpdateStationDeviceConfigApplied(stationId uuid.UUID, appliedVersion int64, applyError string) (int64, error) {
+	res, err := TideDB.Exec(`update station_device_config set applied_version=$2, apply_error=$3 where station_id=$1`, stationId, appliedVersion, applyError)
+	return checkResult(res, err)
+}
+
+// UpdateStationDeviceConfigError records why a station rejected the pushed config.
+func UpdateStationDeviceConfigError(stationId uuid.UUID, applyError string) (int64, error) {
+	res, err := TideDB.Exec(`update station_device_config set apply_error=$2 where station_id=$1`, stationId, applyError)
+	return checkResult(res, err)
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+)
+
+func (s *dbSuite) TestStationDeviceConfig() {
+	_, err := GetStationDeviceConfig(station1.Id)
+	s.ErrorIs(err, sql.ErrNoRows)
+
+	version, err := SaveStationDeviceConfig(station1.Id, json.RawMessage(`{"uart":[{"port":"/dev/ttyUSB0"}]}`))
+	s.Require().NoError(err)
+	s.EqualValues(1, version)
+
+	n, err := UpdateStationDeviceConfigError(station1.Id, "unknown conn type")
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+
+	// A new config clears the error of the previous one.
+	version, err = SaveStationDeviceConfig(station1.Id, json.RawMessage(`{"tcp":[]}`))
+	s.Require().NoError(err)
+	s.EqualValues(2, version)
+
+	n, err = UpdateStationDeviceConfigApplied(station1.Id, 2, "")
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+
+	got, err := GetStationDeviceConfig(station1.Id)
+	s.Require().NoError(err)
+	s.Equal(station1.Id, got.StationId)
+	s.JSONEq(`{"tcp":[]}`, string(got.Config))
+	s.EqualValues(2, got.Version)
+	s.EqualValues(2, got.AppliedVersion)
+	s.Empty(got.ApplyError)
+
+	// Configs are only pushed to local stations.
+	_, err = SaveStationDeviceConfig(upstream1Station1.Id, json.RawMessage(`{}`))
+	s.ErrorIs(err, sql.ErrNoRows)
+}
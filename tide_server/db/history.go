@@ -1,28 +1,22 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"tide/common"
 	"tide/pkg/custype"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func GetItemsLatest(stationId uuid.UUID, itemsLatest common.StringMsecMap) error {
 	var t sql.NullTime
 	for itemName := range itemsLatest {
-		if common.ContainsIllegalCharacter(itemName) {
-			return errors.New("evil table name: " + itemName)
-		}
-		err := TideDB.QueryRow("select"+" max(timestamp) from "+itemName+" where station_id=$1", stationId).Scan(&t)
+		err := TideDB.QueryRow(`select max(ts) from observations where station_id=$1 and item=$2`, stationId, itemName).Scan(&t)
 		if err != nil {
-			if pgErr, ok := errors.AsType[*pgconn.PgError](err); ok && pgErr.Code == "42P01" {
-				// relation Table does not exist
-				continue
-			}
 			return err
 		}
 		if t.Valid {
@@ -33,16 +27,14 @@ func GetItemsLatest(stationId uuid.UUID, itemsLatest common.StringMsecMap) error
 }
 
 func GetDataHistory(stationId uuid.UUID, itemName string, start, end custype.UnixMs) ([]common.DataTimeStruct, error) {
-	if common.ContainsIllegalCharacter(itemName) {
-		return nil, errors.New("Table name contains illegal characters: " + itemName)
-	}
 	var (
 		err error
 		d   common.DataTimeStruct
 		ds  []common.DataTimeStruct
 	)
 	if start == 0 && end == 0 {
-		err = TideDB.QueryRow("select"+" timestamp, value from "+itemName+" where station_id=$1 order by timestamp desc limit 1", stationId).Scan(&d.Millisecond, &d.Value)
+		err = TideDB.QueryRow(`select ts, value, flag from observations where station_id=$1 and item=$2 order by ts desc limit 1`, stationId, itemName).
+			Scan(&d.Millisecond, &d.Value, &d.Flag)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				err = nil
@@ -53,16 +45,16 @@ func GetDataHistory(stationId uuid.UUID, itemName string, start, end custype.Uni
 	} else {
 		var rows *sql.Rows
 		if end == 0 {
-			rows, err = TideDB.Query("select"+" timestamp, value from "+itemName+" where station_id=$1 and timestamp>$2 order by timestamp", stationId, start)
+			rows, err = TideDB.Query(`select ts, value, flag from observations where station_id=$1 and item=$2 and ts>$3 order by ts`, stationId, itemName, start)
 		} else {
-			rows, err = TideDB.Query("select"+" timestamp, value from "+itemName+" where station_id=$1 and timestamp>$2 and timestamp<$3 order by timestamp", stationId, start, end)
+			rows, err = TideDB.Query(`select ts, value, flag from observations where station_id=$1 and item=$2 and ts>$3 and ts<$4 order by ts`, stationId, itemName, start, end)
 		}
 		if err != nil {
 			return ds, err
 		}
 		defer func() { _ = rows.Close() }()
 		for rows.Next() {
-			err = rows.Scan(&d.Millisecond, &d.Value)
+			err = rows.Scan(&d.Millisecond, &d.Value, &d.Flag)
 			if err != nil {
 				return nil, err
 			}
@@ -75,19 +67,205 @@ func GetDataHistory(stationId uuid.UUID, itemName string, start, end custype.Uni
 	return ds, err
 }
 
-func SaveDataHistory(stationId uuid.UUID, itemName string, itemValue float64, tm time.Time) (int64, error) {
-	if common.ContainsIllegalCharacter(itemName) {
-		return 0, errors.New("Table name contains illegal characters: " + itemName)
+// Aggregations of the data of an interval.
+const (
+	AggMean  = "mean"
+	AggMin   = "min"
+	AggMax   = "max"
+	AggFirst = "first"
+	AggLast  = "last"
+	AggCount = "count"
+)
+
+var aggExprs = map[string]string{
+	AggMean:  "avg(value)",
+	AggMin:   "min(value)",
+	AggMax:   "max(value)",
+	AggFirst: "(array_agg(value order by ts))[1]",
+	AggLast:  "(array_agg(value order by ts desc))[1]",
+	AggCount: "count(*)::double precision",
+}
+
+// ValidAgg reports whether agg is one of the aggregations.
+func ValidAgg(agg string) bool {
+	_, ok := aggExprs[agg]
+	return ok
+}
+
+// GetDataHistoryAgg returns agg of the data of every interval between start and end having data, stamped with the start
+// of the interval. Intervals are aligned to the unix epoch, and values flagged bad or missing are left out.
+func GetDataHistoryAgg(stationId uuid.UUID, itemName string, start, end custype.UnixMs, interval time.Duration, agg string) ([]common.DataTimeStruct, error) {
+	expr, ok := aggExprs[agg]
+	if !ok {
+		return nil, fmt.Errorf("unknown aggregation: %s", agg)
+	}
+	query := `select date_bin(make_interval(secs => $3), ts, 'epoch') as bucket, ` + expr + `
+from observations where station_id=$1 and item=$2 and flag<>$4 and flag<>$5 and ts>$6`
+	args := []any{stationId, itemName, interval.Seconds(), common.QCBad, common.QCMissing, start}
+	if end != 0 {
+		query += ` and ts<$7`
+		args = append(args, end)
+	}
+	rows, err := TideDB.Query(query+` group by bucket order by bucket`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var ds []common.DataTimeStruct
+	for rows.Next() {
+		var d common.DataTimeStruct
+		if err = rows.Scan(&d.Millisecond, &d.Value); err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	return ds, rows.Err()
+}
+
+// AggregateData returns agg of the points ds, ordered by time, for every interval having data, like GetDataHistoryAgg
+// does for the stored ones.
+func AggregateData(ds []common.DataTimeStruct, interval time.Duration, agg string) []common.DataTimeStruct {
+	width := custype.UnixMs(interval / time.Millisecond)
+	var (
+		result []common.DataTimeStruct
+		n      int
+	)
+	for _, d := range ds {
+		if d.Flag == common.QCBad || d.Flag == common.QCMissing {
+			continue
+		}
+		bucket := d.Millisecond - d.Millisecond%width
+		if d.Millisecond%width < 0 {
+			bucket -= width
+		}
+		if len(result) == 0 || result[len(result)-1].Millisecond != bucket {
+			if n > 0 && agg == AggMean {
+				result[len(result)-1].Value /= float64(n)
+			}
+			result = append(result, common.DataTimeStruct{Millisecond: bucket, Value: d.Value})
+			n = 1
+			if agg == AggCount {
+				result[len(result)-1].Value = 1
+			}
+			continue
+		}
+		last := &result[len(result)-1]
+		n++
+		switch agg {
+		case AggMean:
+			last.Value += d.Value
+		case AggMin:
+			last.Value = min(last.Value, d.Value)
+		case AggMax:
+			last.Value = max(last.Value, d.Value)
+		case AggLast:
+			last.Value = d.Value
+		case AggCount:
+			last.Value = float64(n)
+		}
+	}
+	if n > 0 && agg == AggMean {
+		result[len(result)-1].Value /= float64(n)
+	}
+	return result
+}
+
+// ObservationQuery selects the data of items of a station from Start, included, to End, ordered by time then item,
+// or by item then time if ByItem is set.
+type ObservationQuery struct {
+	StationId uuid.UUID
+	Items     []string
+	Start     custype.UnixMs
+	End       custype.UnixMs
+	ByItem    bool
+}
+
+// ScanObservations calls fn with each point selected by q, in a snapshot of the data. If count is not nil it is
+// called first with the number of distinct timestamps of the points.
+func ScanObservations(q ObservationQuery, count func(n int) error, fn func(item string, d common.DataTimeStruct) error) error {
+	tx, err := TideDB.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const where = ` from observations where station_id=$1 and item=any($2) and ts>=$3 and ts<$4`
+	if count != nil {
+		var n int
+		if err = tx.QueryRow(`select count(distinct ts)`+where, q.StationId, q.Items, q.Start, q.End).Scan(&n); err != nil {
+			return err
+		}
+		if err = count(n); err != nil {
+			return err
+		}
+	}
+	order := ` order by ts, item`
+	if q.ByItem {
+		order = ` order by item, ts`
+	}
+	rows, err := tx.Query(`select item, ts, value, flag`+where+order, q.StationId, q.Items, q.Start, q.End)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var (
+			item string
+			d    common.DataTimeStruct
+		)
+		if err = rows.Scan(&item, &d.Millisecond, &d.Value, &d.Flag); err != nil {
+			return err
+		}
+		if err = fn(item, d); err != nil {
+			return err
+		}
 	}
-	res, err := TideDB.Exec("insert"+" into "+itemName+" (station_id, value, timestamp) VALUES ($1,$2,$3) on conflict do nothing", stationId, itemValue, tm)
+	return rows.Err()
+}
+
+// SaveDataHistory stores a data point with its quality flag, common.QCNone if it was not checked.
+func SaveDataHistory(stationId uuid.UUID, itemName string, itemValue float64, flag common.QCFlag, tm time.Time) (int64, error) {
+	if err := ensureObservationPartition(tm); err != nil {
+		return 0, err
+	}
+	res, err := TideDB.Exec(`insert into observations(station_id, item, ts, value, flag) VALUES ($1,$2,$3,$4,$5) on conflict do nothing`,
+		stationId, itemName, tm, itemValue, flag)
 	return checkResult(res, err)
 }
 
-func GetLatestDataTime(stationId uuid.UUID, itemName string) (ts custype.UnixMs, err error) {
-	if common.ContainsIllegalCharacter(itemName) {
-		return 0, errors.New("Table name contains illegal characters: " + itemName)
+// ImportDataHistory stores the points of an item like SaveDataHistory in a transaction, the points already stored
+// are kept. It returns the points stored.
+func ImportDataHistory(stationId uuid.UUID, itemName string, ds []common.DataTimeStruct) ([]common.DataTimeStruct, error) {
+	for _, d := range ds {
+		if err := ensureObservationPartition(d.Millisecond.ToTime()); err != nil {
+			return nil, err
+		}
+	}
+	tx, err := TideDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.Prepare(`insert into observations(station_id, item, ts, value, flag) VALUES ($1,$2,$3,$4,$5) on conflict do nothing`)
+	if err != nil {
+		return nil, err
 	}
-	err = TideDB.QueryRow("select"+" timestamp from "+itemName+" where station_id=$1 order by timestamp desc limit 1", stationId).Scan(&ts)
+	defer func() { _ = stmt.Close() }()
+	var imported []common.DataTimeStruct
+	for _, d := range ds {
+		n, err := checkResult(stmt.Exec(stationId, itemName, d.Millisecond, d.Value, d.Flag))
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			imported = append(imported, d)
+		}
+	}
+	return imported, tx.Commit()
+}
+
+func GetLatestDataTime(stationId uuid.UUID, itemName string) (ts custype.UnixMs, err error) {
+	err = TideDB.QueryRow(`select ts from observations where station_id=$1 and item=$2 order by ts desc limit 1`, stationId, itemName).Scan(&ts)
 	if err != nil && errors.Is(err, sql.ErrNoRows) {
 		return 0, nil
 	}
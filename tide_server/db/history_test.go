@@ -3,6 +3,7 @@ package db
 import (
 	"time"
 
+	"tide/common"
 	"tide/pkg/custype"
 
 	"github.com/google/uuid"
@@ -27,7 +28,7 @@ func (s *dbSuite) TestGetLatestDataTime() {
 }
 
 func (s *dbSuite) TestSaveDataHistory_InsertAlreadyExists() {
-	got, err := SaveDataHistory(station1.Id, item1.Name, data[1].Value, data[1].Millisecond.ToTime())
+	got, err := SaveDataHistory(station1.Id, item1.Name, data[1].Value, data[1].Flag, data[1].Millisecond.ToTime())
 	s.Require().NoError(err)
 	s.EqualValues(0, got)
 }
@@ -39,7 +40,146 @@ func (s *dbSuite) TestGetDataHistory_NoRowsDoesNotError() {
 }
 
 func (s *dbSuite) TestSaveDataHistory_NewPoint() {
-	got, err := SaveDataHistory(station1.Id, item1.Name, 0.2, time.UnixMilli(3))
+	got, err := SaveDataHistory(station1.Id, item1.Name, 0.2, common.QCProbablyBad, time.UnixMilli(3))
 	s.Require().NoError(err)
 	s.EqualValues(1, got)
+
+	ds, err := GetDataHistory(station1.Id, item1.Name, 2, 0)
+	s.Require().NoError(err)
+	s.Equal([]common.DataTimeStruct{{Value: 0.2, Millisecond: 3, Flag: common.QCProbablyBad}}, ds)
+}
+
+func (s *dbSuite) TestSaveDataHistory_NewMonth() {
+	tm := time.Date(2001, 2, 28, 23, 0, 0, 0, time.UTC)
+	got, err := SaveDataHistory(station1.Id, item1.Name, 0.3, common.QCNone, tm)
+	s.Require().NoError(err)
+	s.EqualValues(1, got)
+
+	var n int
+	s.Require().NoError(TideDB.QueryRow(`select count(*) from observations_y2001m02`).Scan(&n))
+	s.Equal(1, n)
+}
+
+func (s *dbSuite) TestMigrateItemTables() {
+	_, err := TideDB.Exec(`drop table if exists item1 cascade;
+create table item1
+(
+    station_id uuid             not null,
+    value      double precision not null,
+    timestamp  timestamptz      not null
+);
+insert into item1 values ($1, 0.5, '2001-01-31T23:59:59Z'), ($1, 0.6, '2001-03-01T00:00:00Z')`, station1.Id)
+	s.Require().NoError(err)
+	defer func() { _, _ = TideDB.Exec(`drop table item1`) }()
+
+	s.Require().NoError(migrateItemTables())
+	got, err := GetDataHistory(station1.Id, item1.Name, custype.UnixMs(time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()), 0)
+	s.Require().NoError(err)
+	s.Equal([]common.DataTimeStruct{
+		{Value: 0.5, Millisecond: custype.UnixMs(time.Date(2001, 1, 31, 23, 59, 59, 0, time.UTC).UnixMilli())},
+		{Value: 0.6, Millisecond: custype.UnixMs(time.Date(2001, 3, 1, 0, 0, 0, 0, time.UTC).UnixMilli())},
+	}, got)
+
+	// The points already copied are kept.
+	s.Require().NoError(migrateItemTables())
+	got, err = GetDataHistory(station1.Id, item1.Name, custype.UnixMs(time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()), 0)
+	s.Require().NoError(err)
+	s.Len(got, 2)
+}
+
+func (s *dbSuite) TestGetDataHistoryAgg() {
+	for _, d := range []common.DataTimeStruct{
+		{Millisecond: 1500, Value: 0.5},
+		{Millisecond: 1600, Value: 0.9, Flag: common.QCBad},
+		{Millisecond: 2100, Value: 0.3},
+	} {
+		_, err := SaveDataHistory(station1.Id, item1.Name, d.Value, d.Flag, d.Millisecond.ToTime())
+		s.Require().NoError(err)
+	}
+
+	tests := []struct {
+		agg  string
+		want []common.DataTimeStruct
+	}{
+		{AggMean, []common.DataTimeStruct{{Millisecond: 0, Value: 0.1}, {Millisecond: 1000, Value: 0.5}, {Millisecond: 2000, Value: 0.3}}},
+		{AggCount, []common.DataTimeStruct{{Millisecond: 0, Value: 2}, {Millisecond: 1000, Value: 1}, {Millisecond: 2000, Value: 1}}},
+		{AggLast, []common.DataTimeStruct{{Millisecond: 0, Value: 0.1}, {Millisecond: 1000, Value: 0.5}, {Millisecond: 2000, Value: 0.3}}},
+	}
+	for _, tt := range tests {
+		got, err := GetDataHistoryAgg(station1.Id, item1.Name, 0, 3000, time.Second, tt.agg)
+		s.Require().NoError(err)
+		s.Equal(tt.want, got, tt.agg)
+	}
+
+	_, err := GetDataHistoryAgg(station1.Id, item1.Name, 0, 3000, time.Second, "median")
+	s.Error(err)
+}
+
+func (s *dbSuite) TestAggregateData() {
+	for _, d := range []common.DataTimeStruct{
+		{Millisecond: 1500, Value: 0.5},
+		{Millisecond: 1600, Value: 0.9, Flag: common.QCBad},
+		{Millisecond: 1700, Value: 0.25, Flag: common.QCProbablyGood},
+		{Millisecond: 2100, Value: 0.3},
+	} {
+		_, err := SaveDataHistory(station1.Id, item1.Name, d.Value, d.Flag, d.Millisecond.ToTime())
+		s.Require().NoError(err)
+	}
+	ds, err := GetDataHistory(station1.Id, item1.Name, 0, 3000)
+	s.Require().NoError(err)
+
+	for agg := range aggExprs {
+		want, err := GetDataHistoryAgg(station1.Id, item1.Name, 0, 3000, time.Second, agg)
+		s.Require().NoError(err)
+		s.Equal(want, AggregateData(ds, time.Second, agg), agg)
+	}
+	s.Empty(AggregateData(nil, time.Second, AggMean))
+}
+
+func (s *dbSuite) TestScanObservations() {
+	_, err := SaveDataHistory(station1.Id, "item2", 5, common.QCNone, time.UnixMilli(1))
+	s.Require().NoError(err)
+
+	type point struct {
+		item string
+		common.DataTimeStruct
+	}
+	var (
+		got   []point
+		times int
+	)
+	q := ObservationQuery{StationId: station1.Id, Items: []string{item1.Name, "item2"}, Start: 1, End: 3, ByItem: true}
+	err = ScanObservations(q, func(n int) error {
+		times = n
+		return nil
+	}, func(item string, d common.DataTimeStruct) error {
+		got = append(got, point{item, d})
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Equal(2, times)
+	s.Equal([]point{{item1.Name, data[0]}, {item1.Name, data[1]}, {"item2", common.DataTimeStruct{Millisecond: 1, Value: 5}}}, got)
+
+	got = nil
+	q.ByItem = false
+	s.Require().NoError(ScanObservations(q, nil, func(item string, d common.DataTimeStruct) error {
+		got = append(got, point{item, d})
+		return nil
+	}))
+	s.Equal([]point{{item1.Name, data[0]}, {"item2", common.DataTimeStruct{Millisecond: 1, Value: 5}}, {item1.Name, data[1]}}, got)
+}
+
+func (s *dbSuite) TestImportDataHistory() {
+	ds := []common.DataTimeStruct{
+		data[1],
+		{Millisecond: custype.UnixMs(time.Date(1999, 5, 1, 0, 0, 0, 0, time.UTC).UnixMilli()), Value: 1.2, Flag: common.QCGood},
+	}
+	got, err := ImportDataHistory(station1.Id, item1.Name, ds)
+	s.Require().NoError(err)
+	// The stored point is kept.
+	s.Equal(ds[1:], got)
+
+	history, err := GetDataHistory(station1.Id, item1.Name, 1, 0)
+	s.Require().NoError(err)
+	s.Equal([]common.DataTimeStruct{data[1], ds[1]}, history)
 }
@@ -2,7 +2,6 @@ package db
 
 import (
 	"database/sql"
-	"errors"
 	"strconv"
 	"tide/common"
 	"tide/pkg/custype"
@@ -51,32 +50,12 @@ func GetItems(stationId uuid.UUID) ([]Item, error) {
 	return items, err
 }
 
-func MakeSureTableExist(name string) (err error) {
-	if common.ContainsIllegalCharacter(name) {
-		return errors.New("Table name contains illegal characters: " + name)
-	}
-	var n int
-	err = TideDB.QueryRow("select count(to_regclass($1))", name).Scan(&n)
-	if err != nil {
-		return err
-	}
-	if n == 0 {
-		_, err = TideDB.Exec(`create table ` + name + `
-(
-    station_id uuid             not null,
-    value      double precision not null,
-    timestamp  timestamptz      not null
-);
-create unique index on ` + name + ` (station_id, timestamp);`)
-		return err
-	}
-	return nil
+func ItemExists(stationId uuid.UUID, name string) (exists bool, err error) {
+	err = TideDB.QueryRow(`select exists(select 1 from items where station_id=$1 and name=$2)`, stationId, name).Scan(&exists)
+	return exists, err
 }
 
 func EditItem(i Item) (err error) {
-	if err = MakeSureTableExist(i.Name); err != nil {
-		return err
-	}
 	tx, err := TideDB.Begin()
 	if err != nil {
 		return err
@@ -29,9 +29,14 @@ func (s *dbSuite) TestGetItems() {
 	s.Equal([]Item{item1}, got)
 }
 
-func (s *dbSuite) TestMakeSureTableExist_NewTable() {
-	err := MakeSureTableExist("test_table_name")
+func (s *dbSuite) TestItemExists() {
+	got, err := ItemExists(station1.Id, item1.Name)
 	s.Require().NoError(err)
+	s.True(got)
+
+	got, err = ItemExists(station1.Id, "item2")
+	s.Require().NoError(err)
+	s.False(got)
 }
 
 func (s *dbSuite) TestRemoveAllAvailable() {
@@ -77,7 +77,7 @@ truncate table stations restart identity cascade;
 truncate table permissions_camera_status restart identity cascade;
 truncate table item_status_log restart identity cascade;
 truncate table rpi_status_log restart identity cascade;
-drop table if exists item1 cascade;
+truncate table observations;
 `)
 	require.NoError(t, err)
 
@@ -206,16 +206,16 @@ drop table if exists item1 cascade;
 
 	data = []common.DataTimeStruct{
 		{Millisecond: 1, Value: 0.1},
-		{Millisecond: 2, Value: 0.1},
+		{Millisecond: 2, Value: 0.1, Flag: common.QCGood},
 	}
 
 	for _, d := range data {
-		n, err = SaveDataHistory(station1.Id, item1.Name, d.Value, d.Millisecond.ToTime())
+		n, err = SaveDataHistory(station1.Id, item1.Name, d.Value, d.Flag, d.Millisecond.ToTime())
 		require.NoError(t, err)
 		require.EqualValues(t, 1, n)
 	}
 	for _, d := range data {
-		n, err = SaveDataHistory(upstream1Station1.Id, upstream1Item1.Name, d.Value, d.Millisecond.ToTime())
+		n, err = SaveDataHistory(upstream1Station1.Id, upstream1Item1.Name, d.Value, d.Flag, d.Millisecond.ToTime())
 		require.NoError(t, err)
 		require.EqualValues(t, 1, n)
 	}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// The observations table is partitioned by month of ts, the partition of a month is created with its first point.
+var (
+	partitionMu     sync.Mutex
+	partitionMonths = make(map[time.Time]bool)
+)
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func observationPartitionName(month time.Time) string {
+	return fmt.Sprintf("observations_y%04dm%02d", month.Year(), int(month.Month()))
+}
+
+// ensureObservationPartition creates the partition holding the points stamped t if it does not exist.
+func ensureObservationPartition(t time.Time) error {
+	month := monthStart(t)
+	partitionMu.Lock()
+	defer partitionMu.Unlock()
+	if partitionMonths[month] {
+		return nil
+	}
+	_, err := TideDB.Exec(fmt.Sprintf(`create table if not exists %s partition of observations for values from ('%s') to ('%s')`,
+		observationPartitionName(month), month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339)))
+	if err != nil {
+		return err
+	}
+	partitionMonths[month] = true
+	return nil
+}
+
+// ensureObservationPartitions creates the partitions of the months from start to end.
+func ensureObservationPartitions(start, end time.Time) error {
+	for month := monthStart(start); !month.After(end); month = month.AddDate(0, 1, 0) {
+		if err := ensureObservationPartition(month); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyItemTables returns the tables of the schema used before the observations table, one per item
+// with the columns station_id, value, timestamp and, from the quality flags on, flag.
+func legacyItemTables() ([]string, error) {
+	rows, err := TideDB.Query(`select table_name from information_schema.columns
+where table_schema = current_schema()
+group by table_name
+having bool_and(column_name in ('station_id', 'value', 'timestamp', 'flag'))
+   and count(*) filter (where column_name in ('station_id', 'value', 'timestamp')) = 3
+order by table_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// MigrateItemTables copies the per-item tables into the observations table, points already there are kept.
+// The tables are left in place, it can run again after a server of the old version stored more points in them.
+func MigrateItemTables() error {
+	if err := openDB(); err != nil {
+		return err
+	}
+	return migrateItemTables()
+}
+
+func migrateItemTables() error {
+	tables, err := legacyItemTables()
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		n, err := migrateItemTable(table)
+		if err != nil {
+			return fmt.Errorf("migrate %s: %w", table, err)
+		}
+		slog.Info("Copied item table into observations", "table", table, "rows", n)
+	}
+	return nil
+}
+
+func migrateItemTable(table string) (int64, error) {
+	var start, end sql.NullTime
+	if err := TideDB.QueryRow(`select min(timestamp), max(timestamp) from `+pgx.Identifier{table}.Sanitize()).Scan(&start, &end); err != nil {
+		return 0, err
+	}
+	if !start.Valid {
+		return 0, nil
+	}
+	if err := ensureObservationPartitions(start.Time, end.Time); err != nil {
+		return 0, err
+	}
+
+	var hasFlag bool
+	if err := TideDB.QueryRow(`select exists(select 1 from information_schema.columns where table_schema = current_schema() and table_name = $1 and column_name = 'flag')`, table).
+		Scan(&hasFlag); err != nil {
+		return 0, err
+	}
+	flag := "0"
+	if hasFlag {
+		flag = "t.flag"
+	}
+	// Item names were folded to lower case in table names, the item of the station keeps its case.
+	res, err := TideDB.Exec(`insert into observations(station_id, item, ts, value, flag)
+select t.station_id, coalesce((select i.name from items i where i.station_id = t.station_id and lower(i.name) = $1 limit 1), $1), t.timestamp, t.value, `+flag+`
+from `+pgx.Identifier{table}.Sanitize()+` t
+on conflict do nothing`, table)
+	return checkResult(res, err)
+}
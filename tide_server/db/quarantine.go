@@ -0,0 +1,83 @@
+package db
+
+import (
+	"database/sql"
+	"tide/common"
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+)
+
+// QuarantinedData is a data point the server did not store because its timestamp is implausible.
+type QuarantinedData struct {
+	Id         int64          `json:"id"`
+	StationId  uuid.UUID      `json:"station_id"`
+	ItemName   string         `json:"item_name"`
+	Value      float64        `json:"value"`
+	Flag       common.QCFlag  `json:"flag"`
+	Timestamp  custype.UnixMs `json:"timestamp"`
+	Reason     string         `json:"reason"`
+	ReceivedAt custype.UnixMs `json:"received_at"`
+}
+
+func SaveQuarantinedData(stationId uuid.UUID, itemName string, value float64, flag common.QCFlag, timestamp custype.UnixMs, reason string) error {
+	_, err := TideDB.Exec(`insert into data_quarantine(station_id, item_name, value, flag, timestamp, reason) VALUES ($1,$2,$3,$4,$5,$6)`,
+		stationId, itemName, value, flag, timestamp, reason)
+	return err
+}
+
+// GetQuarantinedData returns the latest points first, of all stations if stationId is uuid.Nil.
+func GetQuarantinedData(stationId uuid.UUID, limit uint) ([]QuarantinedData, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	const columns = `id, station_id, item_name, value, flag, timestamp, reason, received_at`
+	if stationId == uuid.Nil {
+		rows, err = TideDB.Query(`select `+columns+` from data_quarantine order by id desc limit $1`, limit)
+	} else {
+		rows, err = TideDB.Query(`select `+columns+` from data_quarantine where station_id=$1 order by id desc limit $2`, stationId, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var (
+		d  QuarantinedData
+		ds []QuarantinedData
+	)
+	for rows.Next() {
+		if err = rows.Scan(&d.Id, &d.StationId, &d.ItemName, &d.Value, &d.Flag, &d.Timestamp, &d.Reason, &d.ReceivedAt); err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	return ds, rows.Err()
+}
+
+// ReleaseQuarantinedData moves a quarantined point into the data history.
+func ReleaseQuarantinedData(id int64) (QuarantinedData, error) {
+	var d QuarantinedData
+	tx, err := TideDB.Begin()
+	if err != nil {
+		return d, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err = tx.QueryRow(`delete from data_quarantine where id=$1 returning id, station_id, item_name, value, flag, timestamp, reason, received_at`, id).
+		Scan(&d.Id, &d.StationId, &d.ItemName, &d.Value, &d.Flag, &d.Timestamp, &d.Reason, &d.ReceivedAt); err != nil {
+		return d, err
+	}
+	if err = ensureObservationPartition(d.Timestamp.ToTime()); err != nil {
+		return d, err
+	}
+	if _, err = tx.Exec(`insert into observations(station_id, item, ts, value, flag) VALUES ($1,$2,$3,$4,$5) on conflict do nothing`,
+		d.StationId, d.ItemName, d.Timestamp, d.Value, d.Flag); err != nil {
+		return d, err
+	}
+	return d, tx.Commit()
+}
+
+func DelQuarantinedData(id int64) (int64, error) {
+	res, err := TideDB.Exec(`delete from data_quarantine where id=$1`, id)
+	return checkResult(res, err)
+}
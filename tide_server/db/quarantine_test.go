@@ -0,0 +1,70 @@
+package db
+
+import (
+	"database/sql"
+	"tide/common"
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+)
+
+func (s *dbSuite) TestStationClock() {
+	installedAt, err := GetStationInstalledAt(station1.Id)
+	s.Require().NoError(err)
+	s.Zero(installedAt)
+
+	n, err := SetStationInstalledAt(station1.Id, 1_700_000_000_000)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+	n, err = SaveStationClockOffset(station1.Id, -90_000, true)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+
+	cs, err := GetStationClocks()
+	s.Require().NoError(err)
+	s.Equal([]StationClock{{StationId: station1.Id, Identifier: station1.Identifier, InstalledAt: 1_700_000_000_000, ClockOffsetMs: -90_000, ClockDrift: true}}, cs)
+
+	n, err = SetStationInstalledAt(upstream1Station1.Id, 1)
+	s.Require().NoError(err)
+	s.Zero(n)
+	_, err = SetStationInstalledAt(station1.Id, 0)
+	s.Require().NoError(err)
+	installedAt, err = GetStationInstalledAt(station1.Id)
+	s.Require().NoError(err)
+	s.Zero(installedAt)
+}
+
+func (s *dbSuite) TestQuarantinedData() {
+	s.Require().NoError(SaveQuarantinedData(station1.Id, item1.Name, 1.5, common.QCNone, custype.UnixMs(1000), "before_installation"))
+	s.Require().NoError(SaveQuarantinedData(station1.Id, item1.Name, 2.5, common.QCProbablyBad, custype.UnixMs(4_000_000_000_000), "future"))
+
+	ds, err := GetQuarantinedData(station1.Id, 10)
+	s.Require().NoError(err)
+	s.Require().Len(ds, 2)
+	s.Equal("future", ds[0].Reason)
+	s.Equal(1.5, ds[1].Value)
+	s.NotZero(ds[1].ReceivedAt)
+
+	ds, err = GetQuarantinedData(uuid.Nil, 1)
+	s.Require().NoError(err)
+	s.Len(ds, 1)
+
+	released, err := ReleaseQuarantinedData(ds[0].Id)
+	s.Require().NoError(err)
+	s.Equal(2.5, released.Value)
+	history, err := GetDataHistory(station1.Id, item1.Name, 3_999_999_999_999, 4_000_000_000_001)
+	s.Require().NoError(err)
+	s.Equal([]common.DataTimeStruct{{Value: 2.5, Millisecond: 4_000_000_000_000, Flag: common.QCProbablyBad}}, history)
+	_, err = ReleaseQuarantinedData(ds[0].Id)
+	s.ErrorIs(err, sql.ErrNoRows)
+
+	ds, err = GetQuarantinedData(station1.Id, 10)
+	s.Require().NoError(err)
+	s.Require().Len(ds, 1)
+	n, err := DelQuarantinedData(ds[0].Id)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+	ds, err = GetQuarantinedData(station1.Id, 10)
+	s.Require().NoError(err)
+	s.Empty(ds)
+}
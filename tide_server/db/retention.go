@@ -0,0 +1,299 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"tide/common"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Log tables deleted by the retention.
+const (
+	LogHealth = "rpi_status_log"
+	LogStatus = "item_status_log"
+)
+
+var logTimeColumns = map[string]string{
+	LogHealth: "timestamp",
+	LogStatus: "changed_at",
+}
+
+// ObservationArchive keeps the points of a partition before it is dropped, it is closed before the drop commits.
+type ObservationArchive interface {
+	Write(stationId uuid.UUID, itemName string, d common.DataTimeStruct) error
+	Close() error
+}
+
+// RowArchive keeps the rows of a log table before they are deleted, it is closed before the deletion commits.
+type RowArchive interface {
+	Write(record []string) error
+	Close() error
+}
+
+// ObservationMonthsBefore returns the months of the partitions of the observations table ending before t,
+// with the months of the partitions detached by a drop that did not complete.
+func ObservationMonthsBefore(t time.Time) ([]time.Time, error) {
+	rows, err := TideDB.Query(`select c.relname from pg_class c
+where c.relnamespace = current_schema()::regnamespace and c.relkind = 'r' and c.relname like 'observations\_y%'
+order by c.relname`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var months []time.Time
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		suffix, _ := strings.CutPrefix(strings.TrimSuffix(name, detachedSuffix), "observations_y")
+		month, err := time.Parse("2006m01", suffix)
+		if err != nil || month.AddDate(0, 1, 0).After(t) {
+			continue
+		}
+		months = append(months, month)
+	}
+	return slices.CompactFunc(months, time.Time.Equal), rows.Err()
+}
+
+// A partition is renamed with detachedSuffix when it is detached, so that the points written to its month
+// afterwards create the partition again.
+const detachedSuffix = "_detached"
+
+// The drop gives up waiting for the lock of the observations table after partitionLockTimeout, so that the
+// points written meanwhile are not held back, and tries again up to partitionLockRetries times.
+const (
+	partitionLockTimeout = 5 * time.Second
+	partitionLockRetries = 3
+)
+
+// DropObservationPartition drops the partition of month, after its points are written to archive if it is not nil.
+// It returns the number of points dropped.
+func DropObservationPartition(month time.Time, archive ObservationArchive) (n int64, err error) {
+	if archive != nil {
+		defer func() {
+			if err != nil {
+				_ = archive.Close()
+			}
+		}()
+	}
+	name := observationPartitionName(month) + detachedSuffix
+	var detached bool
+	if err = TideDB.QueryRow(`select to_regclass($1) is not null`, name).Scan(&detached); err != nil {
+		return 0, err
+	}
+	// A partition detached by a previous drop is archived first, the points written to the month since are
+	// in a new partition dropped next time.
+	if !detached {
+		if err = detachObservationPartition(month); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := TideDB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	// Nothing writes to the detached partition, no lock is held while it is archived.
+	table := pgx.Identifier{name}.Sanitize()
+	if archive == nil {
+		err = tx.QueryRow(`select count(*) from ` + table).Scan(&n)
+	} else {
+		n, err = archiveObservations(tx, table, archive)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if _, err = tx.Exec(`drop table ` + table); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// detachObservationPartition detaches the partition of month and renames it with detachedSuffix. The points
+// written to the month before wait for the detach and are archived with the partition, the ones written after
+// create the partition again. A point whose partition was checked before the month is forgotten fails once.
+func detachObservationPartition(month time.Time) error {
+	for i := 1; ; i++ {
+		err := detachObservationPartitionOnce(month)
+		if pgErr, ok := errors.AsType[*pgconn.PgError](err); ok && pgErr.Code == "55P03" && i < partitionLockRetries { // lock_not_available
+			time.Sleep(time.Duration(i) * partitionLockTimeout)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		partitionMu.Lock()
+		delete(partitionMonths, monthStart(month))
+		partitionMu.Unlock()
+		return nil
+	}
+}
+
+func detachObservationPartitionOnce(month time.Time) error {
+	tx, err := TideDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err = tx.Exec(fmt.Sprintf(`set local lock_timeout = %d`, partitionLockTimeout.Milliseconds())); err != nil {
+		return err
+	}
+	// The observations table is locked before the partition, as the inserts do, so they wait for each other
+	// instead of deadlocking.
+	name := observationPartitionName(month)
+	if _, err = tx.Exec(`alter table observations detach partition ` + pgx.Identifier{name}.Sanitize()); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(`alter table ` + pgx.Identifier{name}.Sanitize() + ` rename to ` + pgx.Identifier{name + detachedSuffix}.Sanitize()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func archiveObservations(tx *sql.Tx, table string, archive ObservationArchive) (int64, error) {
+	rows, err := tx.Query(`select station_id, item, ts, value, flag from ` + table + ` order by station_id, item, ts`)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = rows.Close() }()
+	var (
+		n         int64
+		stationId uuid.UUID
+		itemName  string
+		d         common.DataTimeStruct
+	)
+	for rows.Next() {
+		if err = rows.Scan(&stationId, &itemName, &d.Millisecond, &d.Value, &d.Flag); err != nil {
+			return 0, err
+		}
+		if err = archive.Write(stationId, itemName, d); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	return n, archive.Close()
+}
+
+// OldestLogTime returns the time of the oldest row of a log table, zero if it is empty.
+func OldestLogTime(table string) (time.Time, error) {
+	column, ok := logTimeColumns[table]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown log table: %s", table)
+	}
+	var t sql.NullTime
+	err := TideDB.QueryRow(`select min(` + pgx.Identifier{column}.Sanitize() + `) from ` + pgx.Identifier{table}.Sanitize()).Scan(&t)
+	return t.Time, err
+}
+
+func tableColumns(tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.Query(`select column_name from information_schema.columns
+where table_schema = current_schema() and table_name = $1 order by ordinal_position`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err = rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// DeleteLogRows deletes the rows of a log table stamped from start and before end. If open is not nil, the rows are
+// written first to the archive it opens with the column names, as text, null as an empty string.
+// It returns the number of rows deleted.
+func DeleteLogRows(table string, start, end time.Time, open func(columns []string) (RowArchive, error)) (int64, error) {
+	column, ok := logTimeColumns[table]
+	if !ok {
+		return 0, fmt.Errorf("unknown log table: %s", table)
+	}
+	// The deletion sees the rows archived, not the ones written meanwhile.
+	tx, err := TideDB.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	where := ` from ` + pgx.Identifier{table}.Sanitize() + ` where ` + pgx.Identifier{column}.Sanitize() + `>=$1 and ` +
+		pgx.Identifier{column}.Sanitize() + `<$2`
+	if open != nil {
+		if err = archiveLogRows(tx, table, where, start, end, open); err != nil {
+			return 0, err
+		}
+	}
+	n, err := checkResult(tx.Exec(`delete`+where, start, end))
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+func archiveLogRows(tx *sql.Tx, table, where string, start, end time.Time, open func(columns []string) (RowArchive, error)) error {
+	columns, err := tableColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	exprs := make([]string, len(columns))
+	for i, column := range columns {
+		exprs[i] = pgx.Identifier{column}.Sanitize() + `::text`
+	}
+	rows, err := tx.Query(`select `+strings.Join(exprs, ", ")+where+` order by `+pgx.Identifier{logTimeColumns[table]}.Sanitize(), start, end)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	var archive RowArchive
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+	for rows.Next() {
+		if archive == nil {
+			if archive, err = open(columns); err != nil {
+				return err
+			}
+			defer func() {
+				if archive != nil {
+					_ = archive.Close()
+				}
+			}()
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i, v := range values {
+			record[i] = v.String
+		}
+		if err = archive.Write(record); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil || archive == nil {
+		return err
+	}
+	err = archive.Close()
+	archive = nil
+	return err
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"time"
+
+	"tide/common"
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+)
+
+type fakeArchive struct {
+	columns []string
+	records [][]string
+	points  []common.DataTimeStruct
+	closed  bool
+}
+
+func (a *fakeArchive) Write(stationId uuid.UUID, itemName string, d common.DataTimeStruct) error {
+	a.records = append(a.records, []string{stationId.String(), itemName})
+	a.points = append(a.points, d)
+	return nil
+}
+
+func (a *fakeArchive) Close() error {
+	a.closed = true
+	return nil
+}
+
+type fakeRowArchive struct {
+	*fakeArchive
+}
+
+func (a fakeRowArchive) Write(record []string) error {
+	a.records = append(a.records, append([]string(nil), record...))
+	return nil
+}
+
+func (s *dbSuite) TestDropObservationPartition() {
+	tm := time.Date(2001, 2, 28, 23, 0, 0, 0, time.UTC)
+	_, err := SaveDataHistory(station1.Id, item1.Name, 0.3, common.QCGood, tm)
+	s.Require().NoError(err)
+	month := time.Date(2001, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	months, err := ObservationMonthsBefore(month.AddDate(0, 0, 27))
+	s.Require().NoError(err)
+	s.NotContains(months, month)
+	months, err = ObservationMonthsBefore(month.AddDate(0, 1, 0))
+	s.Require().NoError(err)
+	s.Contains(months, month)
+
+	archive := new(fakeArchive)
+	n, err := DropObservationPartition(month, archive)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+	s.True(archive.closed)
+	s.Equal([][]string{{station1.Id.String(), item1.Name}}, archive.records)
+	s.Equal([]common.DataTimeStruct{{Millisecond: custype.ToUnixMs(tm), Value: 0.3, Flag: common.QCGood}}, archive.points)
+	s.False(partitionMonths[month])
+
+	months, err = ObservationMonthsBefore(month.AddDate(0, 1, 0))
+	s.Require().NoError(err)
+	s.NotContains(months, month)
+
+	// The partition is created again for the points of the month written later.
+	got, err := SaveDataHistory(station1.Id, item1.Name, 0.3, common.QCGood, tm)
+	s.Require().NoError(err)
+	s.EqualValues(1, got)
+}
+
+func (s *dbSuite) TestDropObservationPartition_Detached() {
+	tm := time.Date(2001, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err := SaveDataHistory(station1.Id, item1.Name, 0.3, common.QCGood, tm)
+	s.Require().NoError(err)
+	month := monthStart(tm)
+
+	// A drop detached the partition but did not complete, then a point of the month was written again.
+	s.Require().NoError(detachObservationPartition(month))
+	s.False(partitionMonths[month])
+	_, err = SaveDataHistory(station1.Id, item1.Name, 0.4, common.QCGood, tm.Add(time.Hour))
+	s.Require().NoError(err)
+
+	months, err := ObservationMonthsBefore(month.AddDate(0, 1, 0))
+	s.Require().NoError(err)
+	s.Equal([]time.Time{month}, filterMonth(months, month))
+
+	archive := new(fakeArchive)
+	n, err := DropObservationPartition(month, archive)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+	s.Equal([]common.DataTimeStruct{{Millisecond: custype.ToUnixMs(tm), Value: 0.3, Flag: common.QCGood}}, archive.points)
+
+	// The partition of the later point is dropped next.
+	months, err = ObservationMonthsBefore(month.AddDate(0, 1, 0))
+	s.Require().NoError(err)
+	s.Contains(months, month)
+	n, err = DropObservationPartition(month, nil)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+	months, err = ObservationMonthsBefore(month.AddDate(0, 1, 0))
+	s.Require().NoError(err)
+	s.NotContains(months, month)
+}
+
+func filterMonth(months []time.Time, month time.Time) []time.Time {
+	var got []time.Time
+	for _, m := range months {
+		if m.Equal(month) {
+			got = append(got, m)
+		}
+	}
+	return got
+}
+
+func (s *dbSuite) TestDeleteLogRows() {
+	archive := new(fakeArchive)
+	open := func(columns []string) (RowArchive, error) {
+		archive.columns = columns
+		return fakeRowArchive{archive}, nil
+	}
+	n, err := DeleteLogRows(LogStatus, time.UnixMilli(0), station1StatusLogs[0].ChangedAt.ToTime().Add(time.Millisecond), open)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+	s.True(archive.closed)
+	s.Equal([]string{"station_id", "row_id", "item_name", "status", "changed_at"}, archive.columns)
+	s.Require().Len(archive.records, 1)
+	s.Equal([]string{station1.Id.String(), "1", item1.Name}, archive.records[0][:3])
+
+	oldest, err := OldestLogTime(LogStatus)
+	s.Require().NoError(err)
+	s.True(oldest.Equal(station1StatusLogs[1].ChangedAt.ToTime()))
+
+	n, err = DeleteLogRows(LogHealth, time.UnixMilli(0), time.Now(), nil)
+	s.Require().NoError(err)
+	s.EqualValues(0, n)
+}
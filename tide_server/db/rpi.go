@@ -1,11 +1,75 @@
 package db
 
 import (
-	"github.com/google/uuid"
+	"database/sql"
+	"encoding/json"
+	"tide/common"
+	"tide/pkg/custype"
 	"time"
+
+	"github.com/google/uuid"
 )
 
-func SaveRpiStatus(stationId uuid.UUID, cpuTemp float64, t time.Time) error {
-	_, err := TideDB.Exec("insert into rpi_status_log(station_id, cpu_temp, timestamp) VALUES ($1,$2,$3)", stationId, cpuTemp, t)
+func SaveStationHealth(stationId uuid.UUID, h common.StationHealthStruct, t time.Time) error {
+	disks, err := json.Marshal(h.Disks)
+	if err != nil {
+		return err
+	}
+	if h.Disks == nil {
+		disks = []byte("[]")
+	}
+	reopens, err := json.Marshal(h.SerialReopens)
+	if err != nil {
+		return err
+	}
+	if h.SerialReopens == nil {
+		reopens = []byte("{}")
+	}
+	_, err = TideDB.Exec(`insert into rpi_status_log(station_id, cpu_temp, timestamp, disks, mem_total_bytes, mem_available_bytes, load1, load5, load15,
+                           uptime_seconds, db_size_bytes, replay_backlog, serial_reopens, clock_offset_ms, clock_synced)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)`,
+		stationId, h.CpuTemp, t, disks, h.MemTotalBytes, h.MemAvailableBytes, h.Load1, h.Load5, h.Load15,
+		h.UptimeSeconds, h.DbSizeBytes, h.ReplayBacklog, reopens, h.ClockOffsetMs, h.ClockSynced)
 	return err
 }
+
+// GetStationHealth returns the health reports between start and end, or the latest one if both are 0.
+func GetStationHealth(stationId uuid.UUID, start, end custype.UnixMs) ([]common.StationHealthTimeStruct, error) {
+	const columns = `timestamp, cpu_temp, disks, mem_total_bytes, mem_available_bytes, load1, load5, load15,
+       uptime_seconds, db_size_bytes, replay_backlog, serial_reopens, clock_offset_ms, clock_synced`
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	switch {
+	case start == 0 && end == 0:
+		rows, err = TideDB.Query(`select `+columns+` from rpi_status_log where station_id=$1 order by timestamp desc limit 1`, stationId)
+	case end == 0:
+		rows, err = TideDB.Query(`select `+columns+` from rpi_status_log where station_id=$1 and timestamp>$2 order by timestamp`, stationId, start)
+	default:
+		rows, err = TideDB.Query(`select `+columns+` from rpi_status_log where station_id=$1 and timestamp>$2 and timestamp<$3 order by timestamp`, stationId, start, end)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var ds []common.StationHealthTimeStruct
+	for rows.Next() {
+		var (
+			d              common.StationHealthTimeStruct
+			disks, reopens []byte
+		)
+		if err = rows.Scan(&d.Millisecond, &d.CpuTemp, &disks, &d.MemTotalBytes, &d.MemAvailableBytes, &d.Load1, &d.Load5, &d.Load15,
+			&d.UptimeSeconds, &d.DbSizeBytes, &d.ReplayBacklog, &reopens, &d.ClockOffsetMs, &d.ClockSynced); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(disks, &d.Disks); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(reopens, &d.SerialReopens); err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	return ds, rows.Err()
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"tide/common"
+	"tide/pkg/custype"
+	"time"
+)
+
+func (s *dbSuite) TestStationHealth() {
+	cpuTemp := 48.5
+	t1 := time.UnixMilli(1000)
+	s.Require().NoError(SaveStationHealth(station1.Id, common.StationHealthStruct{CpuTemp: &cpuTemp}, t1))
+	h := common.StationHealthStruct{
+		Disks:         []common.DiskUsageStruct{{Path: "/home/pi", FreeBytes: 1 << 30, TotalBytes: 1 << 34}},
+		MemTotalBytes: 4 << 30,
+		Load1:         0.25,
+		UptimeSeconds: 3600,
+		ReplayBacklog: 7,
+		SerialReopens: map[string]uint32{"/dev/ttyUSB0": 2},
+		ClockOffsetMs: -3,
+		ClockSynced:   true,
+	}
+	s.Require().NoError(SaveStationHealth(station1.Id, h, time.UnixMilli(2000)))
+
+	ds, err := GetStationHealth(station1.Id, 0, 0)
+	s.Require().NoError(err)
+	s.Equal([]common.StationHealthTimeStruct{{StationHealthStruct: h, Millisecond: 2000}}, ds)
+
+	ds, err = GetStationHealth(station1.Id, custype.UnixMs(1), 0)
+	s.Require().NoError(err)
+	s.Require().Len(ds, 2)
+	s.Equal(cpuTemp, *ds[0].CpuTemp)
+	s.Empty(ds[0].Disks)
+	s.Nil(ds[1].CpuTemp)
+
+	ds, err = GetStationHealth(upstream1Station1.Id, 0, 0)
+	s.Require().NoError(err)
+	s.Empty(ds)
+}
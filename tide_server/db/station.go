@@ -29,6 +29,30 @@ func GetLocalStationIdByIdentifier(identifier string) (uuid.UUID, error) {
 	return stationId, err
 }
 
+// GetStationAuthKey returns the Sync V2 auth key of a local station, empty if it has none.
+func GetStationAuthKey(stationId uuid.UUID) (string, error) {
+	var authKey string
+	err := TideDB.QueryRow(`select auth_key from stations where id=$1 and upstream=false and deleted_at is null`, stationId).Scan(&authKey)
+	return authKey, err
+}
+
+// SetStationAuthKey replaces the Sync V2 auth key of a local station.
+func SetStationAuthKey(stationId uuid.UUID, authKey string) (int64, error) {
+	res, err := TideDB.Exec(`update stations set auth_key=$2 where id=$1 and upstream=false and deleted_at is null`, stationId, authKey)
+	return checkResult(res, err)
+}
+
+// GetStationDataCursor returns the client outbox id and the last contiguous seq the server has stored for a local station.
+func GetStationDataCursor(stationId uuid.UUID) (outboxId string, seq int64, err error) {
+	err = TideDB.QueryRow(`select data_outbox_id, data_seq from stations where id=$1 and upstream=false and deleted_at is null`, stationId).Scan(&outboxId, &seq)
+	return
+}
+
+func SaveStationDataCursor(stationId uuid.UUID, outboxId string, seq int64) (int64, error) {
+	res, err := TideDB.Exec(`update stations set data_outbox_id=$2, data_seq=$3 where id=$1 and upstream=false and deleted_at is null`, stationId, outboxId, seq)
+	return checkResult(res, err)
+}
+
 func GetStations() ([]Station, error) {
 	var (
 		rows *sql.Rows
@@ -56,6 +80,12 @@ func GetStations() ([]Station, error) {
 	return ss, err
 }
 
+func GetStation(id uuid.UUID) (s Station, err error) {
+	err = TideDB.QueryRow(`select id, identifier, name, ip_addr, location, partner, cameras, status, status_changed_at, upstream from stations where id=$1 and deleted_at is null`, id).
+		Scan(&s.Id, &s.Identifier, &s.Name, &s.IpAddr, &s.Location, &s.Partner, &s.Cameras, &s.Status, &s.StatusChangedAt, &s.Upstream)
+	return s, err
+}
+
 func EditStation(s *Station) (err error) {
 	tx, err := TideDB.Begin()
 	if err != nil {
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"database/sql"
 	"encoding/json"
 
 	"github.com/google/uuid"
@@ -34,6 +35,45 @@ func (s *dbSuite) TestGetLocalStationIdByIdentifier() {
 	s.Equal(station1.Id, got)
 }
 
+func (s *dbSuite) TestStationAuthKey() {
+	got, err := GetStationAuthKey(station1.Id)
+	s.Require().NoError(err)
+	s.Empty(got)
+
+	n, err := SetStationAuthKey(station1.Id, "key1")
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+
+	got, err = GetStationAuthKey(station1.Id)
+	s.Require().NoError(err)
+	s.Equal("key1", got)
+
+	// Upstream stations are authenticated by their own server.
+	n, err = SetStationAuthKey(upstream1Station1.Id, "key1")
+	s.Require().NoError(err)
+	s.EqualValues(0, n)
+}
+
+func (s *dbSuite) TestStationDataCursor() {
+	outboxId, seq, err := GetStationDataCursor(station1.Id)
+	s.Require().NoError(err)
+	s.Empty(outboxId)
+	s.Zero(seq)
+
+	n, err := SaveStationDataCursor(station1.Id, "outbox1", 42)
+	s.Require().NoError(err)
+	s.EqualValues(1, n)
+
+	outboxId, seq, err = GetStationDataCursor(station1.Id)
+	s.Require().NoError(err)
+	s.Equal("outbox1", outboxId)
+	s.EqualValues(42, seq)
+
+	n, err = SaveStationDataCursor(upstream1Station1.Id, "outbox1", 42)
+	s.Require().NoError(err)
+	s.EqualValues(0, n)
+}
+
 func (s *dbSuite) TestGetStations() {
 	got, err := GetStations()
 	s.Require().NoError(err)
@@ -41,6 +81,15 @@ func (s *dbSuite) TestGetStations() {
 	s.ElementsMatch([]Station{station1, upstream1Station1}, got)
 }
 
+func (s *dbSuite) TestGetStation() {
+	got, err := GetStation(station1.Id)
+	s.Require().NoError(err)
+	s.Equal(station1, got)
+
+	_, err = GetStation(uuid.New())
+	s.ErrorIs(err, sql.ErrNoRows)
+}
+
 func (s *dbSuite) TestGetStationsFullInfo() {
 	got, err := GetStationsFullInfo()
 	s.Require().NoError(err)
@@ -37,12 +37,26 @@ func EditUpstream(up *Upstream) (err error) {
 			`insert into upstreams (username, password, url) values ($1,$2,$3) returning id`,
 			up.Username, up.Password, up.Url).Scan(&up.Id)
 	} else {
-		_, err = TideDB.Exec(`update upstreams set username=$1, password=$2, url=$3 where id=$4`,
+		// The edit ids of another server mean nothing here.
+		_, err = TideDB.Exec(`update upstreams set username=$1, password=$2, url=$3,
+edit_id=case when url=$3 then edit_id else 0 end where id=$4`,
 			up.Username, up.Password, up.Url, up.Id)
 	}
 	return err
 }
 
+// GetUpstreamEditId returns the id of the latest data edit of the upstream applied here.
+func GetUpstreamEditId(id int) (editId int64, err error) {
+	err = TideDB.QueryRow(`select edit_id from upstreams where id=$1`, id).Scan(&editId)
+	return
+}
+
+// SetUpstreamEditId records the id of the latest data edit of the upstream applied here, if it is later.
+func SetUpstreamEditId(id int, editId int64) error {
+	_, err := TideDB.Exec(`update upstreams set edit_id=$2 where id=$1 and edit_id<$2`, id, editId)
+	return err
+}
+
 // DelUpstream delete this upstream from upstream_stations，and
 // then delete the stations that only belong to this upstream
 func DelUpstream(id int) ([]uuid.UUID, error) {
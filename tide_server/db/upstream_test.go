@@ -11,6 +11,28 @@ func (s *dbSuite) TestEditUpstream() {
 	s.Require().NoError(err)
 }
 
+func (s *dbSuite) TestUpstreamEditId() {
+	s.Require().NoError(SetUpstreamEditId(upstream1.Id, 5))
+	// The cursor does not move back.
+	s.Require().NoError(SetUpstreamEditId(upstream1.Id, 3))
+	got, err := GetUpstreamEditId(upstream1.Id)
+	s.Require().NoError(err)
+	s.EqualValues(5, got)
+
+	// It is kept while the upstream is the same server.
+	s.Require().NoError(EditUpstream(&upstream1))
+	got, err = GetUpstreamEditId(upstream1.Id)
+	s.Require().NoError(err)
+	s.EqualValues(5, got)
+
+	up := upstream1
+	up.Url = "http://other.example"
+	s.Require().NoError(EditUpstream(&up))
+	got, err = GetUpstreamEditId(upstream1.Id)
+	s.Require().NoError(err)
+	s.Zero(got)
+}
+
 func (s *dbSuite) TestGetStationsByUpstreamId() {
 	// GetStationsByUpstreamId does not return the Upstream bool field.
 	tmp := upstream1Station1
@@ -0,0 +1,336 @@
+// Package export writes the data of items of a station as CSV, netCDF, IOC JSON or a zip of a file per item.
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"tide/common"
+	"tide/pkg/custype"
+	"tide/pkg/netcdf"
+	"tide/tide_server/db"
+)
+
+// Formats of the exports.
+const (
+	// FormatCSV has a row per point: timestamp(ms),item,value,flag.
+	FormatCSV = "csv"
+	// FormatCSVWide has a row per timestamp with the value and flag columns of every item, empty without a point.
+	FormatCSVWide = "csv_wide"
+	// FormatNetCDF is a netCDF file following the CF conventions with a variable and a quality flag variable per item.
+	FormatNetCDF = "netcdf"
+	// FormatZip has a file per item named after it, each line being timestamp(ms),value.
+	FormatZip = "zip"
+	// FormatIOC is the JSON of the data service of the IOC Sea Level Station Monitoring Facility, ordered by time:
+	// [{"slevel":1.234,"stime":"2024-08-23 11:03:27","sensor":"item1"}], stime in UTC. The format has no quality flags,
+	// the values flagged bad or missing are left out.
+	FormatIOC = "ioc"
+)
+
+var formatFiles = map[string]struct{ ext, contentType string }{
+	FormatCSV:     {".csv", "text/csv"},
+	FormatCSVWide: {".csv", "text/csv"},
+	FormatNetCDF:  {".nc", "application/x-netcdf"},
+	FormatZip:     {".zip", "application/zip"},
+	FormatIOC:     {".json", "application/json"},
+}
+
+// scanObservations reads the data, replaced in tests.
+var scanObservations = db.ScanObservations
+
+// ValidFormat reports whether format is one of the export formats.
+func ValidFormat(format string) bool {
+	_, ok := formatFiles[format]
+	return ok
+}
+
+// Request is the data of Items of Station from Start, included, to End.
+type Request struct {
+	Station db.Station
+	Items   []string
+	Start   custype.UnixMs
+	End     custype.UnixMs
+	Format  string
+}
+
+func (r Request) FileName() string {
+	return fmt.Sprintf("%s_%d_%d%s", r.Station.Identifier, r.Start, r.End, formatFiles[r.Format].ext)
+}
+
+func (r Request) ContentType() string {
+	return formatFiles[r.Format].contentType
+}
+
+func (r Request) query(byItem bool) db.ObservationQuery {
+	return db.ObservationQuery{StationId: r.Station.Id, Items: r.Items, Start: r.Start, End: r.End, ByItem: byItem}
+}
+
+// Write writes the export of r to w.
+func Write(w io.Writer, r Request) error {
+	switch r.Format {
+	case FormatCSV:
+		return writeCSV(w, r)
+	case FormatCSVWide:
+		return writeCSVWide(w, r)
+	case FormatNetCDF:
+		return writeNetCDF(w, r)
+	case FormatZip:
+		return writeZip(w, r)
+	case FormatIOC:
+		return writeIOC(w, r)
+	}
+	return fmt.Errorf("unknown export format: %s", r.Format)
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func writeCSV(w io.Writer, r Request) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"timestamp(ms)", "item", "value", "flag"}); err != nil {
+		return err
+	}
+	err := scanObservations(r.query(false), nil, func(item string, d common.DataTimeStruct) error {
+		return cw.Write([]string{strconv.FormatInt(d.Millisecond.ToInt64(), 10), item, formatValue(d.Value), strconv.Itoa(int(d.Flag))})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeCSVWide(w io.Writer, r Request) error {
+	cw := csv.NewWriter(w)
+	header := []string{"timestamp(ms)"}
+	for _, item := range r.Items {
+		header = append(header, item, item+"_flag")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(header))
+	err := scanByTime(r, nil, func(ms custype.UnixMs, points []common.DataTimeStruct, ok []bool) error {
+		record[0] = strconv.FormatInt(ms.ToInt64(), 10)
+		for i, d := range points {
+			if ok[i] {
+				record[2*i+1], record[2*i+2] = formatValue(d.Value), strconv.Itoa(int(d.Flag))
+			} else {
+				record[2*i+1], record[2*i+2] = "", ""
+			}
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeZip(w io.Writer, r Request) error {
+	zw := zip.NewWriter(w)
+	var (
+		file    io.Writer
+		current string
+	)
+	err := scanObservations(r.query(true), nil, func(item string, d common.DataTimeStruct) error {
+		if file == nil || item != current {
+			var err error
+			if file, err = zw.Create(item); err != nil {
+				return err
+			}
+			current = item
+		}
+		_, err := io.WriteString(file, strconv.FormatInt(d.Millisecond.ToInt64(), 10)+","+formatValue(d.Value)+"\n")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+type iocPoint struct {
+	Level  float64 `json:"slevel"`
+	Time   string  `json:"stime"`
+	Sensor string  `json:"sensor"`
+}
+
+func writeIOC(w io.Writer, r Request) error {
+	sep := "["
+	err := scanObservations(r.query(false), nil, func(item string, d common.DataTimeStruct) error {
+		if d.Flag == common.QCBad || d.Flag == common.QCMissing {
+			return nil
+		}
+		b, err := json.Marshal(iocPoint{Level: d.Value, Time: d.Millisecond.ToTime().UTC().Format(time.DateTime), Sensor: item})
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(w, sep); err != nil {
+			return err
+		}
+		sep = ","
+		_, err = w.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if sep == "[" {
+		_, err = io.WriteString(w, "[]")
+	} else {
+		_, err = io.WriteString(w, "]")
+	}
+	return err
+}
+
+// scanByTime calls fn with the points of the items at each timestamp, ok[i] tells whether Items[i] has one there.
+func scanByTime(r Request, count func(n int) error, fn func(ms custype.UnixMs, points []common.DataTimeStruct, ok []bool) error) error {
+	index := make(map[string]int, len(r.Items))
+	for i, item := range r.Items {
+		index[item] = i
+	}
+	var (
+		points  = make([]common.DataTimeStruct, len(r.Items))
+		ok      = make([]bool, len(r.Items))
+		ms      custype.UnixMs
+		pending bool
+	)
+	flush := func() error {
+		if !pending {
+			return nil
+		}
+		pending = false
+		err := fn(ms, points, ok)
+		clear(ok)
+		return err
+	}
+	err := scanObservations(r.query(false), count, func(item string, d common.DataTimeStruct) error {
+		if pending && d.Millisecond != ms {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		ms, pending = d.Millisecond, true
+		i := index[item]
+		points[i], ok[i] = d, true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// stationPosition returns the latitude and longitude in the position of the location of s, "latitude,longitude".
+func stationPosition(s db.Station) (lat, lon float64, ok bool) {
+	var location struct {
+		Position string `json:"position"`
+	}
+	if json.Unmarshal(s.Location, &location) != nil {
+		return 0, 0, false
+	}
+	rawLat, rawLon, found := strings.Cut(location.Position, ",")
+	if !found {
+		return 0, 0, false
+	}
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(rawLat), 64)
+	lon, errLon := strconv.ParseFloat(strings.TrimSpace(rawLon), 64)
+	if errLat != nil || errLon != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+// netCDFFile describes the netCDF file of r with n timestamps: the time, then the value and quality flag of each item.
+func netCDFFile(r Request, n int) netcdf.File {
+	f := netcdf.File{
+		RecordDim: "time",
+		NumRecs:   n,
+		Attrs: []netcdf.Attr{
+			{Name: "Conventions", Value: "CF-1.8"},
+			{Name: "title", Value: r.Station.Name},
+			{Name: "station_id", Value: r.Station.Id.String()},
+			{Name: "station_identifier", Value: r.Station.Identifier},
+			{Name: "station_name", Value: r.Station.Name},
+		},
+	}
+	var coordinates string
+	if lat, lon, ok := stationPosition(r.Station); ok {
+		coordinates = "lat lon"
+		f.Vars = append(f.Vars,
+			netcdf.Var{Name: "lat", Type: netcdf.Double, Scalar: true, Value: lat, Attrs: []netcdf.Attr{
+				{Name: "standard_name", Value: "latitude"},
+				{Name: "long_name", Value: "station latitude"},
+				{Name: "units", Value: "degrees_north"},
+			}},
+			netcdf.Var{Name: "lon", Type: netcdf.Double, Scalar: true, Value: lon, Attrs: []netcdf.Attr{
+				{Name: "standard_name", Value: "longitude"},
+				{Name: "long_name", Value: "station longitude"},
+				{Name: "units", Value: "degrees_east"},
+			}},
+		)
+	}
+	f.Vars = append(f.Vars, netcdf.Var{Name: "time", Type: netcdf.Double, Attrs: []netcdf.Attr{
+		{Name: "standard_name", Value: "time"},
+		{Name: "long_name", Value: "time"},
+		{Name: "units", Value: "milliseconds since 1970-01-01 00:00:00 UTC"},
+		{Name: "calendar", Value: "standard"},
+		{Name: "axis", Value: "T"},
+	}})
+	for _, item := range r.Items {
+		attrs := []netcdf.Attr{
+			{Name: "long_name", Value: item},
+			{Name: "_FillValue", Value: netcdf.FillDouble},
+			{Name: "ancillary_variables", Value: item + "_qc"},
+		}
+		if coordinates != "" {
+			attrs = append(attrs, netcdf.Attr{Name: "coordinates", Value: coordinates})
+		}
+		f.Vars = append(f.Vars,
+			netcdf.Var{Name: item, Type: netcdf.Double, Attrs: attrs},
+			netcdf.Var{Name: item + "_qc", Type: netcdf.Byte, Attrs: []netcdf.Attr{
+				{Name: "long_name", Value: item + " quality flag"},
+				{Name: "_FillValue", Value: netcdf.FillByte},
+				{Name: "flag_values", Value: []int8{
+					int8(common.QCNone), int8(common.QCGood), int8(common.QCProbablyGood), int8(common.QCProbablyBad),
+					int8(common.QCBad), int8(common.QCChanged), int8(common.QCMissing),
+				}},
+				{Name: "flag_meanings", Value: "no_quality_control good_value probably_good_value probably_bad_value bad_value changed_value missing_value"},
+			}},
+		)
+	}
+	return f
+}
+
+func writeNetCDF(w io.Writer, r Request) error {
+	var enc *netcdf.Encoder
+	values := make([]any, 1+2*len(r.Items))
+	err := scanByTime(r, func(n int) error {
+		var err error
+		enc, err = netcdf.NewEncoder(w, netCDFFile(r, n))
+		return err
+	}, func(ms custype.UnixMs, points []common.DataTimeStruct, ok []bool) error {
+		values[0] = float64(ms)
+		for i, d := range points {
+			if ok[i] {
+				values[2*i+1], values[2*i+2] = d.Value, int8(d.Flag)
+			} else {
+				values[2*i+1], values[2*i+2] = netcdf.FillDouble, netcdf.FillByte
+			}
+		}
+		return enc.WriteRecord(values...)
+	})
+	if err != nil {
+		return err
+	}
+	return enc.Close()
+}
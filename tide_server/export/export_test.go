@@ -0,0 +1,245 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"tide/common"
+	"tide/pkg/custype"
+	"tide/pkg/netcdf"
+	"tide/tide_server/db"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type observation struct {
+	item string
+	common.DataTimeStruct
+}
+
+var observations = []observation{
+	{"item1", common.DataTimeStruct{Millisecond: 1000, Value: 0.5, Flag: common.QCGood}},
+	{"item2", common.DataTimeStruct{Millisecond: 1000, Value: 12}},
+	{"item1", common.DataTimeStruct{Millisecond: 2000, Value: 0.75, Flag: common.QCBad}},
+	{"item2", common.DataTimeStruct{Millisecond: 3000, Value: 13}},
+	{"item3", common.DataTimeStruct{Millisecond: 3000, Value: 1}},
+}
+
+// fakeScan serves observations like db.ScanObservations.
+func fakeScan(q db.ObservationQuery, count func(n int) error, fn func(item string, d common.DataTimeStruct) error) error {
+	var selected []observation
+	times := make(map[custype.UnixMs]bool)
+	for _, o := range observations {
+		if slices.Contains(q.Items, o.item) && o.Millisecond >= q.Start && o.Millisecond < q.End {
+			selected = append(selected, o)
+			times[o.Millisecond] = true
+		}
+	}
+	slices.SortStableFunc(selected, func(a, b observation) int {
+		if q.ByItem && a.item != b.item {
+			return strings.Compare(a.item, b.item)
+		}
+		return cmp.Compare(a.Millisecond, b.Millisecond)
+	})
+	if count != nil {
+		if err := count(len(times)); err != nil {
+			return err
+		}
+	}
+	for _, o := range selected {
+		if err := fn(o.item, o.DataTimeStruct); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testRequest(format string) Request {
+	return Request{
+		Station: db.Station{
+			Id:         uuid.MustParse("1048a910-2a2b-11eb-9abd-d89ef3266df6"),
+			Identifier: "station1",
+			Name:       "station1",
+			Location:   json.RawMessage(`{"name":"L1","position":"51.5,-0.1"}`),
+		},
+		Items:  []string{"item1", "item2"},
+		Start:  1000,
+		End:    4000,
+		Format: format,
+	}
+}
+
+func TestMain(m *testing.M) {
+	scanObservations = fakeScan
+	m.Run()
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testRequest(FormatCSV)))
+	assert.Equal(t, "timestamp(ms),item,value,flag\n"+
+		"1000,item1,0.5,1\n"+
+		"1000,item2,12,0\n"+
+		"2000,item1,0.75,4\n"+
+		"3000,item2,13,0\n", buf.String())
+}
+
+func TestWrite_CSVWide(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testRequest(FormatCSVWide)))
+	assert.Equal(t, "timestamp(ms),item1,item1_flag,item2,item2_flag\n"+
+		"1000,0.5,1,12,0\n"+
+		"2000,0.75,4,,\n"+
+		"3000,,,13,0\n", buf.String())
+}
+
+func TestWrite_Zip(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testRequest(FormatZip)))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		files[f.Name] = string(b)
+	}
+	assert.Equal(t, map[string]string{
+		"item1": "1000,0.5\n2000,0.75\n",
+		"item2": "1000,12\n3000,13\n",
+	}, files)
+}
+
+func TestWrite_IOC(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testRequest(FormatIOC)))
+	// The value of item1 at 2000 is flagged bad.
+	assert.JSONEq(t, `[
+{"slevel":0.5,"stime":"1970-01-01 00:00:01","sensor":"item1"},
+{"slevel":12,"stime":"1970-01-01 00:00:01","sensor":"item2"},
+{"slevel":13,"stime":"1970-01-01 00:00:03","sensor":"item2"}]`, buf.String())
+
+	buf.Reset()
+	r := testRequest(FormatIOC)
+	r.Items = []string{"item9"}
+	require.NoError(t, Write(&buf, r))
+	assert.Equal(t, "[]", buf.String())
+}
+
+func TestWrite_NetCDF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testRequest(FormatNetCDF)))
+	b := buf.Bytes()
+	require.Equal(t, "CDF\x02", string(b[:4]))
+	assert.EqualValues(t, 3, binary.BigEndian.Uint32(b[4:]))
+
+	// A record is time, item1, item1_qc and item2, item2_qc, the flags padded to 4 bytes.
+	record := func(ms, item1 float64, flag1 int8, item2 float64, flag2 int8) []byte {
+		var r bytes.Buffer
+		_ = binary.Write(&r, binary.BigEndian, ms)
+		_ = binary.Write(&r, binary.BigEndian, item1)
+		_ = binary.Write(&r, binary.BigEndian, [4]int8{flag1})
+		_ = binary.Write(&r, binary.BigEndian, item2)
+		_ = binary.Write(&r, binary.BigEndian, [4]int8{flag2})
+		return r.Bytes()
+	}
+	records := slices.Concat(
+		record(1000, 0.5, 1, 12, 0),
+		record(2000, 0.75, 4, netcdf.FillDouble, netcdf.FillByte),
+		record(3000, netcdf.FillDouble, netcdf.FillByte, 13, 0),
+	)
+	assert.Equal(t, records, b[len(b)-len(records):])
+	// The lat and lon scalars come before the records.
+	scalars := make([]byte, 16)
+	binary.BigEndian.PutUint64(scalars, math.Float64bits(51.5))
+	binary.BigEndian.PutUint64(scalars[8:], math.Float64bits(-0.1))
+	assert.Equal(t, scalars, b[len(b)-len(records)-16:len(b)-len(records)])
+}
+
+func TestNetCDFFile(t *testing.T) {
+	f := netCDFFile(testRequest(FormatNetCDF), 3)
+	var names []string
+	for _, v := range f.Vars {
+		names = append(names, v.Name)
+	}
+	assert.Equal(t, []string{"lat", "lon", "time", "item1", "item1_qc", "item2", "item2_qc"}, names)
+	assert.Equal(t, 51.5, f.Vars[0].Value)
+	assert.Equal(t, -0.1, f.Vars[1].Value)
+
+	r := testRequest(FormatNetCDF)
+	r.Station.Location = json.RawMessage(`{"name":"L1"}`)
+	f = netCDFFile(r, 3)
+	assert.Equal(t, "time", f.Vars[0].Name)
+}
+
+func TestStationPosition(t *testing.T) {
+	tests := []struct {
+		location string
+		lat, lon float64
+		ok       bool
+	}{
+		{`{"position":"51.51557344578067,-0.10129627561850096"}`, 51.51557344578067, -0.10129627561850096, true},
+		{`{"position":" 22.1 , 113.5 "}`, 22.1, 113.5, true},
+		{`{"position":"91,0"}`, 0, 0, false},
+		{`{"position":"abc"}`, 0, 0, false},
+		{`{}`, 0, 0, false},
+		{`null`, 0, 0, false},
+	}
+	for _, tt := range tests {
+		lat, lon, ok := stationPosition(db.Station{Location: json.RawMessage(tt.location)})
+		assert.Equal(t, tt.ok, ok, tt.location)
+		assert.Equal(t, tt.lat, lat, tt.location)
+		assert.Equal(t, tt.lon, lon, tt.location)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	dir := t.TempDir()
+	js, err := NewJobs(dir, time.Hour, 1)
+	require.NoError(t, err)
+
+	job := js.Start("user1", testRequest(FormatCSV))
+	assert.Equal(t, "station1_1000_4000.csv", job.FileName)
+	assert.Equal(t, "text/csv", job.ContentType())
+
+	require.Eventually(t, func() bool {
+		got, ok := js.Get(job.Id, "user1")
+		return ok && got.Status == JobDone
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, ok := js.Get(job.Id, "user2")
+	assert.False(t, ok)
+	_, _, ok = js.Open(job.Id, "user2")
+	assert.False(t, ok)
+
+	f, got, ok := js.Open(job.Id, "user1")
+	require.True(t, ok)
+	b, err := io.ReadAll(f)
+	require.NoError(t, err)
+	_ = f.Close()
+	assert.EqualValues(t, len(b), got.Size)
+	assert.Contains(t, string(b), "3000,item2,13,0")
+
+	// Finished jobs expire with their files.
+	js.expire = -time.Hour
+	_, ok = js.Get(job.Id, "user1")
+	assert.False(t, ok)
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
@@ -0,0 +1,173 @@
+package export
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"tide/pkg/custype"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of the export jobs.
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// Job is an export written to a file in the background.
+type Job struct {
+	Id         string         `json:"id"`
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	FileName   string         `json:"file_name"`
+	Size       int64          `json:"size,omitempty"`
+	CreatedAt  custype.UnixMs `json:"created_at"`
+	FinishedAt custype.UnixMs `json:"finished_at,omitempty"`
+
+	username    string
+	contentType string
+}
+
+func (j Job) ContentType() string {
+	return j.contentType
+}
+
+// Jobs runs the export jobs, a few at a time, and keeps their files in a directory until they expire.
+// Jobs are lost when the server restarts.
+type Jobs struct {
+	mu     sync.Mutex
+	dir    string
+	expire time.Duration
+	sem    chan struct{}
+	jobs   map[string]*Job
+}
+
+const jobFileExt = ".export"
+
+// NewJobs keeps the files of the jobs in dir, removing the ones left by a previous run.
+func NewJobs(dir string, expire time.Duration, concurrency int) (*Jobs, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+jobFileExt))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		_ = os.Remove(f)
+	}
+	return &Jobs{dir: dir, expire: expire, sem: make(chan struct{}, max(concurrency, 1)), jobs: make(map[string]*Job)}, nil
+}
+
+func (js *Jobs) path(id string) string {
+	return filepath.Join(js.dir, id+jobFileExt)
+}
+
+// Start queues the export of r for username.
+func (js *Jobs) Start(username string, r Request) Job {
+	job := &Job{
+		Id:          uuid.NewString(),
+		Status:      JobQueued,
+		FileName:    r.FileName(),
+		CreatedAt:   custype.ToUnixMs(time.Now()),
+		username:    username,
+		contentType: r.ContentType(),
+	}
+	js.mu.Lock()
+	js.removeExpiredLocked()
+	js.jobs[job.Id] = job
+	started := *job
+	js.mu.Unlock()
+
+	go js.run(job, r)
+	return started
+}
+
+func (js *Jobs) run(job *Job, r Request) {
+	js.sem <- struct{}{}
+	defer func() { <-js.sem }()
+	js.setStatus(job, JobRunning, nil, 0)
+
+	size, err := js.write(job.Id, r)
+	if err != nil {
+		slog.Error("Failed to export data", "job_id", job.Id, "station_id", r.Station.Id, "format", r.Format, "error", err)
+		_ = os.Remove(js.path(job.Id))
+		js.setStatus(job, JobFailed, err, 0)
+		return
+	}
+	slog.Info("Exported data", "job_id", job.Id, "station_id", r.Station.Id, "format", r.Format, "size", size)
+	js.setStatus(job, JobDone, nil, size)
+}
+
+func (js *Jobs) write(id string, r Request) (int64, error) {
+	f, err := os.Create(js.path(id))
+	if err != nil {
+		return 0, err
+	}
+	if err = Write(f, r); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	if err = f.Close(); err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(js.path(id))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (js *Jobs) setStatus(job *Job, status string, err error, size int64) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	job.Status, job.Size = status, size
+	if err != nil {
+		job.Error = err.Error()
+	}
+	if status == JobDone || status == JobFailed {
+		job.FinishedAt = custype.ToUnixMs(time.Now())
+	}
+}
+
+// Get returns the job id of username.
+func (js *Jobs) Get(id, username string) (Job, bool) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.removeExpiredLocked()
+	job, ok := js.jobs[id]
+	if !ok || job.username != username {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Open opens the file of the done job id of username.
+func (js *Jobs) Open(id, username string) (*os.File, Job, bool) {
+	job, ok := js.Get(id, username)
+	if !ok || job.Status != JobDone {
+		return nil, job, false
+	}
+	f, err := os.Open(js.path(id))
+	if err != nil {
+		return nil, job, false
+	}
+	return f, job, true
+}
+
+// removeExpiredLocked removes the jobs finished longer than expire ago with their files.
+func (js *Jobs) removeExpiredLocked() {
+	deadline := custype.ToUnixMs(time.Now().Add(-js.expire))
+	for id, job := range js.jobs {
+		if job.FinishedAt != 0 && job.FinishedAt < deadline {
+			_ = os.Remove(js.path(id))
+			delete(js.jobs, id)
+		}
+	}
+}
@@ -14,8 +14,18 @@ import (
 var Config struct {
 	Debug  bool
 	Listen string `json:"listen"`
+	Tls    struct {
+		CertFile     string `json:"cert_file"`
+		KeyFile      string `json:"key_file"`
+		ClientCaFile string `json:"client_ca_file"`
+	} `json:"tls"`
 	SyncV2 struct {
-		Enabled bool `json:"enabled"`
+		Enabled           bool `json:"enabled"`
+		RequireClientCert bool `json:"require_client_cert"`
+		// ClockDriftSec flags stations whose clock differs more from the server, 60 if 0.
+		ClockDriftSec time.Duration `json:"clock_drift_sec"`
+		// MaxFutureSec quarantines data stamped further ahead of the server clock, 600 if 0.
+		MaxFutureSec time.Duration `json:"max_future_sec"`
 	} `json:"sync_v2"`
 	Tide struct {
 		Listen string `json:"listen"`
@@ -25,6 +35,25 @@ var Config struct {
 		} `json:"camera"`
 		DataDelaySec time.Duration `json:"data_delay_sec"`
 	} `json:"tide"`
+	Export struct {
+		// Dir keeps the files of the export jobs, tide_export under the temporary directory if empty.
+		Dir string `json:"dir"`
+		// SyncMaxDays exports longer ranges in a background job, 31 if 0.
+		SyncMaxDays int `json:"sync_max_days"`
+		// JobExpireHours removes the files of the jobs finished longer ago, 24 if 0.
+		JobExpireHours time.Duration `json:"job_expire_hours"`
+	} `json:"export"`
+	Retention struct {
+		// DataDays, HealthDays and StatusDays keep the observations, the rpi_status_log rows and the item_status_log rows
+		// for so many days, forever if 0. The observations are dropped by month, once the whole month is older.
+		DataDays   int `json:"data_days"`
+		HealthDays int `json:"health_days"`
+		StatusDays int `json:"status_days"`
+		// ArchiveDir keeps the rows in gzip compressed CSV files before they are deleted, they are not kept if empty.
+		ArchiveDir string `json:"archive_dir"`
+		// IntervalHours runs the retention job, 24 if 0.
+		IntervalHours time.Duration `json:"interval_hours"`
+	} `json:"retention"`
 	Smtp     smtpConfigStruct `json:"smtp"`
 	Keycloak struct {
 		BasePath       string `json:"base_path"`
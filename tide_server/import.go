@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tide/tide_server/dataimport"
+
+	"github.com/google/uuid"
+	"golang.org/x/term"
+)
+
+// runImport imports a CSV or JSON file into an item of a station through a running server, which sends the new
+// points downstream: tide_server import -server URL -username NAME -station UUID -item NAME FILE.
+// The password is read from TIDE_PASSWORD, or asked for.
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	server := fs.String("server", "http://localhost:7100", "server URL")
+	username := fs.String("username", "", "admin username")
+	station := fs.String("station", "", "station id")
+	item := fs.String("item", "", "item name")
+	format := fs.String("format", "", "csv or json, from the file extension if empty")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "Usage: tide_server import [flags] FILE, - for stdin")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	stationId, err := uuid.Parse(*station)
+	if err != nil || *item == "" || *username == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	name := fs.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	}
+	if !dataimport.ValidFormat(*format) {
+		log.Fatalf("unknown format %q, use -format csv or json", *format)
+	}
+
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+	ds, err := dataimport.Parse(r, *format)
+	if err == nil {
+		err = dataimport.Validate(ds, time.Now())
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", name, err)
+	}
+
+	password := os.Getenv("TIDE_PASSWORD")
+	if password == "" && name == "-" {
+		log.Fatal("set TIDE_PASSWORD to read the data from stdin")
+	}
+	if password == "" {
+		fmt.Printf("Enter password for %s: ", *username)
+		b, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			log.Fatal(err)
+		}
+		password = string(b)
+	}
+
+	client := dataimport.Client{Server: *server, HTTP: &http.Client{Timeout: 10 * time.Minute}}
+	if err = client.Login(*username, password); err != nil {
+		log.Fatal(err)
+	}
+	result, err := client.Import(stationId, *item, ds)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Imported %d of %d points, the others were already stored\n", result.Imported, result.Received)
+}
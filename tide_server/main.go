@@ -9,6 +9,7 @@ import (
 	"os"
 	"tide/pkg/project"
 	"tide/tide_server/controller"
+	"tide/tide_server/db"
 	"tide/tide_server/global"
 	"tide/tide_server/test"
 	"time"
@@ -19,7 +20,13 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		runImport(os.Args[2:])
+		return
+	}
+
 	initKeycloak := flag.Bool("initKeycloak", false, "initialize keycloak")
+	migrateObservations := flag.Bool("migrateObservations", false, "copy the per-item data tables into the observations table")
 	wkDir := flag.String("dir", ".", "working dir")
 	flag.BoolVar(&global.Config.Debug, "debug", true, "debug mode")
 	cfgName := flag.String("config", "config.json", "Config file")
@@ -46,6 +53,13 @@ func main() {
 		return
 	}
 
+	if *migrateObservations {
+		if err := db.MigrateItemTables(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	controller.Init()
 
 	project.Run(startRunningStatus, stopRunningStatus, abortedRunningStatus)
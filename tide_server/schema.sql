@@ -13,6 +13,12 @@ create table stations
     status_changed_at timestamptz default 'epoch'        not null,
     cameras           jsonb       default 'null'         not null,
     upstream          boolean     default false          not null,
+    auth_key          varchar     default ''             not null,
+    data_outbox_id    varchar     default ''             not null,
+    data_seq          bigint      default 0              not null,
+    installed_at      timestamptz,
+    clock_offset_ms   bigint      default 0              not null,
+    clock_drift       boolean     default false          not null,
     deleted_at        timestamptz
 );
 create index on stations (deleted_at);
@@ -51,12 +57,36 @@ create table device_record
     version          integer     default 1     not null
 );
 
+create table station_device_config
+(
+    station_id      uuid                      not null primary key references stations on delete cascade,
+    config          jsonb                     not null,
+    version         bigint      default 1     not null,
+    updated_at      timestamptz default now() not null,
+    applied_version bigint      default 0     not null,
+    apply_error     varchar     default ''    not null
+);
+
+create table device_command_log
+(
+    id         bigserial                 not null primary key,
+    station_id uuid                      not null references stations on delete cascade,
+    username   varchar                   not null,
+    request    jsonb                     not null,
+    output     bytea                     not null,
+    error      varchar     default ''    not null,
+    created_at timestamptz default now() not null
+);
+
+create index on device_command_log (station_id, created_at);
+
 create table upstreams
 (
     id       serial  not null primary key,
     username varchar not null,
     password varchar not null,
     url      varchar not null,
+    edit_id  bigint  not null default 0,
     unique (username, url)
 );
 
@@ -104,10 +134,63 @@ create table item_status_log
 
 create table rpi_status_log
 (
+    station_id          uuid             not null references stations on delete cascade,
+    cpu_temp            double precision,
+    timestamp           timestamptz      not null,
+    disks               jsonb            not null default '[]',
+    mem_total_bytes     bigint           not null default 0,
+    mem_available_bytes bigint           not null default 0,
+    load1               double precision not null default 0,
+    load5               double precision not null default 0,
+    load15              double precision not null default 0,
+    uptime_seconds      bigint           not null default 0,
+    db_size_bytes       bigint           not null default 0,
+    replay_backlog      bigint           not null default 0,
+    serial_reopens      jsonb            not null default '{}',
+    clock_offset_ms     bigint           not null default 0,
+    clock_synced        boolean          not null default false
+);
+create index on rpi_status_log (station_id, timestamp);
+
+create table observations
+(
+    station_id uuid             not null,
+    item       varchar          not null,
+    ts         timestamptz      not null,
+    value      double precision not null,
+    flag       smallint         not null default 0,
+    primary key (station_id, item, ts)
+) partition by range (ts);
+
+create table data_quarantine
+(
+    id          bigserial primary key,
+    station_id  uuid             not null references stations on delete cascade,
+    item_name   varchar          not null,
+    value       double precision not null,
+    flag        smallint         not null default 0,
+    timestamp   timestamptz      not null,
+    reason      varchar          not null,
+    received_at timestamptz      not null default now()
+);
+create index on data_quarantine (station_id, id);
+
+create table data_edits
+(
+    id         bigserial primary key,
     station_id uuid             not null references stations on delete cascade,
-    cpu_temp   double precision not null,
-    timestamp  timestamptz      not null
+    item_name  varchar          not null,
+    action     varchar          not null,
+    start_at   timestamptz      not null,
+    end_at     timestamptz      not null,
+    flag       smallint         not null default 0,
+    "offset"   double precision not null default 0,
+    username   varchar          not null,
+    before     jsonb            not null,
+    after      jsonb            not null,
+    created_at timestamptz      not null default now()
 );
+create index on data_edits (station_id, id);
 
 insert into users(username, role, live_camera, password_hash)
 VALUES ('tgm-admin', 2, true, '$argon2id$v=19$m=7168,t=5,p=1$1AiO4aIwfRRNwUCPyXDPcQ$0MBbcUwAFanJZFmEim7vOH6V0WNJ4sRgU+OW5Z1rDFU');
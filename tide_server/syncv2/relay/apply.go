@@ -29,6 +29,7 @@ const (
 	MsgMissData              = "MissData"
 	MsgData                  = "data"
 	MsgDataGpio              = "data_gpio"
+	MsgEditData              = "EditData"
 )
 
 func uuidStringsMapToTopics(permissions common.UUIDStringsMap) pubsub.TopicSet {
@@ -416,7 +417,7 @@ func applyStatusEvent(deps DownstreamDeps, evt *syncpb.RelayStatusEvent) {
 	}
 }
 
-func applyDataBatch(deps DownstreamDeps, batch *syncpb.RelayDataBatch) {
+func applyDataBatch(cfg DownstreamConfig, deps DownstreamDeps, batch *syncpb.RelayDataBatch) {
 	if batch == nil || batch.StationId == "" || len(batch.Points) == 0 {
 		return
 	}
@@ -424,13 +425,18 @@ func applyDataBatch(deps DownstreamDeps, batch *syncpb.RelayDataBatch) {
 	if err != nil {
 		return
 	}
+	if batch.DataType == MsgEditData {
+		// The edit is replayed on the next connection if it is not applied.
+		if applyEditedData(deps, stationID, batch.Points) == nil {
+			_ = saveEditID(cfg, deps, batch.EditId)
+		}
+		return
+	}
 
 	for _, point := range batch.Points {
-		if err = deps.Store.EnsureDataTable(point.ItemName); err != nil {
-			return
-		}
 		tm := custype.UnixMs(point.UnixMs)
-		inserted, saveErr := deps.Store.SaveDataHistory(stationID, point.ItemName, point.Value, tm.ToTime())
+		flag := common.QCFlag(point.Flag)
+		inserted, saveErr := deps.Store.SaveDataHistory(stationID, point.ItemName, point.Value, flag, tm.ToTime())
 		if saveErr != nil {
 			return
 		}
@@ -443,7 +449,7 @@ func applyDataBatch(deps DownstreamDeps, batch *syncpb.RelayDataBatch) {
 			_, _ = deps.Store.UpdateItemStatus(stationID, point.ItemName, common.NoStatus, tm.ToTime())
 		}
 
-		data := common.DataTimeStruct{Value: point.Value, Millisecond: tm}
+		data := common.DataTimeStruct{Value: point.Value, Millisecond: tm, Flag: flag}
 		if batch.DataType == MsgMissData {
 			deps.Notifier.PublishMissData(stationItem, data)
 			continue
@@ -451,3 +457,31 @@ func applyDataBatch(deps DownstreamDeps, batch *syncpb.RelayDataBatch) {
 		deps.Notifier.PublishData(stationItem, data, point.Kind == syncpb.DataKind_DATA_KIND_GPIO)
 	}
 }
+
+// saveEditID records the id of the latest data edit of the upstream applied here, 0 is not an edit.
+func saveEditID(cfg DownstreamConfig, deps DownstreamDeps, editID int64) error {
+	if editID <= 0 {
+		return nil
+	}
+	return deps.Store.SetUpstreamEditID(cfg.UpstreamID, editID)
+}
+
+// applyEditedData stores the points changed by a data edit upstream, the points after the latest one stored here are
+// skipped as they arrive edited with the missing data.
+func applyEditedData(deps DownstreamDeps, stationID uuid.UUID, points []*syncpb.DataPoint) error {
+	edited := make(map[string][]common.DataTimeStruct)
+	for _, point := range points {
+		d := common.DataTimeStruct{Value: point.Value, Millisecond: custype.UnixMs(point.UnixMs), Flag: common.QCFlag(point.Flag)}
+		changed, err := deps.Store.SyncEditedData(stationID, point.ItemName, d)
+		if err != nil {
+			return err
+		}
+		if changed {
+			edited[point.ItemName] = append(edited[point.ItemName], d)
+		}
+	}
+	for itemName, ds := range edited {
+		deps.Notifier.PublishEditedData(common.StationItemStruct{StationId: stationID, ItemName: itemName}, ds)
+	}
+	return nil
+}
@@ -167,7 +167,7 @@ func runDownstreamBootstrap(stream internalsyncv2.RelayMessageStream, cfg Downst
 	if err = syncMissStatus(stream, deps, cfgBody.ConfigBatch.Stations); err != nil {
 		return err
 	}
-	if err = syncMissData(stream, deps); err != nil {
+	if err = syncMissData(stream, cfg, deps); err != nil {
 		return err
 	}
 	return nil
@@ -193,7 +193,7 @@ func runDownstreamIncremental(stream internalsyncv2.RelayMessageStream, cfg Down
 		case *syncpb.RelayMessage_StatusEvent:
 			applyStatusEvent(deps, body.StatusEvent)
 		case *syncpb.RelayMessage_DataBatch:
-			applyDataBatch(deps, body.DataBatch)
+			applyDataBatch(cfg, deps, body.DataBatch)
 		case *syncpb.RelayMessage_AvailableItems:
 			if err := applyAvailableItems(cfg, deps, body.AvailableItems); err != nil {
 				log.Error("v2 downstream: handle available items failed", "error", err)
@@ -260,11 +260,15 @@ func syncMissStatus(stream internalsyncv2.RelayMessageStream, deps DownstreamDep
 	return nil
 }
 
-func syncMissData(stream internalsyncv2.RelayMessageStream, deps DownstreamDeps) error {
+func syncMissData(stream internalsyncv2.RelayMessageStream, cfg DownstreamConfig, deps DownstreamDeps) error {
 	items, err := deps.Store.GetAllItems()
 	if err != nil {
 		return err
 	}
+	editID, err := deps.Store.UpstreamEditID(cfg.UpstreamID)
+	if err != nil {
+		return err
+	}
 
 	stationsLatest := make(map[string]*syncpb.ItemsLatest)
 	byStation := make(map[uuid.UUID][]string)
@@ -280,7 +284,7 @@ func syncMissData(stream internalsyncv2.RelayMessageStream, deps DownstreamDeps)
 	}
 
 	if err = stream.Send(&syncpb.RelayMessage{Body: &syncpb.RelayMessage_StationsItemsLatest{
-		StationsItemsLatest: &syncpb.RelayItemsLatest{Stations: stationsLatest},
+		StationsItemsLatest: &syncpb.RelayItemsLatest{Stations: stationsLatest, EditAfterId: editID},
 	}}); err != nil {
 		return err
 	}
@@ -296,24 +300,31 @@ func syncMissData(stream internalsyncv2.RelayMessageStream, deps DownstreamDeps)
 		}
 		batch := dataBatch.DataBatch
 		if batch.StationId == "" && len(batch.Points) == 0 {
-			return nil
+			return saveEditID(cfg, deps, batch.EditId)
 		}
 		stationID, parseErr := uuid.Parse(batch.StationId)
 		if parseErr != nil {
 			continue
 		}
-		for _, point := range batch.Points {
-			if err = deps.Store.EnsureDataTable(point.ItemName); err != nil {
+		if batch.DataType == MsgEditData {
+			if err = applyEditedData(deps, stationID, batch.Points); err != nil {
 				return err
 			}
+			if err = saveEditID(cfg, deps, batch.EditId); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, point := range batch.Points {
 			tm := custype.UnixMs(point.UnixMs)
-			inserted, saveErr := deps.Store.SaveDataHistory(stationID, point.ItemName, point.Value, tm.ToTime())
+			flag := common.QCFlag(point.Flag)
+			inserted, saveErr := deps.Store.SaveDataHistory(stationID, point.ItemName, point.Value, flag, tm.ToTime())
 			if saveErr != nil {
 				return saveErr
 			}
 			if inserted {
 				stationItem := common.StationItemStruct{StationId: stationID, ItemName: point.ItemName}
-				deps.Notifier.PublishMissData(stationItem, common.DataTimeStruct{Value: point.Value, Millisecond: tm})
+				deps.Notifier.PublishMissData(stationItem, common.DataTimeStruct{Value: point.Value, Millisecond: tm, Flag: flag})
 			}
 		}
 	}
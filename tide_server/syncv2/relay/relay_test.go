@@ -77,6 +77,7 @@ type fakeUpstreamStore struct {
 		after     custype.UnixMs
 	}
 	dataHistoryByItem map[string][]common.DataTimeStruct
+	dataEdits         []db.DataEdit
 }
 
 func (s *fakeUpstreamStore) GetAvailableItems() ([]common.StationItemStruct, error) {
@@ -100,6 +101,22 @@ func (s *fakeUpstreamStore) GetDataHistory(stationID uuid.UUID, itemName string,
 	return s.dataHistoryByItem[itemName], nil
 }
 
+func (s *fakeUpstreamStore) GetDataEditsAfter(afterID int64, limit uint) ([]db.DataEdit, error) {
+	var es []db.DataEdit
+	for _, e := range s.dataEdits {
+		if e.Id > afterID && uint(len(es)) < limit {
+			es = append(es, e)
+		}
+	}
+	return es, nil
+}
+func (s *fakeUpstreamStore) LatestDataEditID() (int64, error) {
+	if len(s.dataEdits) == 0 {
+		return 0, nil
+	}
+	return s.dataEdits[len(s.dataEdits)-1].Id, nil
+}
+
 type fakeFrameSource struct {
 	configCh chan *syncpb.RelayMessage
 	dataCh   chan *syncpb.RelayMessage
@@ -125,6 +142,11 @@ func TestUpstreamServer_StreamSync_HandshakeFilterMissDataAndIncremental(t *test
 		dataHistoryByItem: map[string][]common.DataTimeStruct{
 			"item_allowed": {{Value: 1, Millisecond: custype.UnixMs(1100)}},
 		},
+		dataEdits: []db.DataEdit{
+			{Id: 5, StationId: stationAllowed, ItemName: "item_allowed", After: []common.DataTimeStruct{{Value: 1, Millisecond: 900}}},
+			{Id: 6, StationId: stationAllowed, ItemName: "item_allowed", After: []common.DataTimeStruct{{Value: 2, Millisecond: 1000, Flag: common.QCChanged}}},
+			{Id: 7, StationId: stationDenied, ItemName: "item_denied", After: []common.DataTimeStruct{{Value: 3, Millisecond: 1000}}},
+		},
 	}
 
 	fs := fakeFrameSource{
@@ -191,7 +213,7 @@ func TestUpstreamServer_StreamSync_HandshakeFilterMissDataAndIncremental(t *test
 	require.NoError(t, downStream.Send(&syncpb.RelayMessage{Body: &syncpb.RelayMessage_StationsItemsLatest{
 		StationsItemsLatest: &syncpb.RelayItemsLatest{Stations: map[string]*syncpb.ItemsLatest{
 			stationAllowed.String(): {LatestUnixMs: map[string]int64{"item_allowed": 1000, "item_denied": 1000}},
-		}},
+		}, EditAfterId: 5},
 	}}))
 
 	f, err = downStream.Recv()
@@ -204,13 +226,24 @@ func TestUpstreamServer_StreamSync_HandshakeFilterMissDataAndIncremental(t *test
 	require.Equal(t, "item_allowed", dbatch.DataBatch.Points[0].ItemName)
 	require.Equal(t, int64(1100), dbatch.DataBatch.Points[0].UnixMs)
 
-	// Termination empty batch.
+	// The edits made after the one the downstream applied last, on the permitted items.
+	f, err = downStream.Recv()
+	require.NoError(t, err)
+	dbatch, ok = f.Body.(*syncpb.RelayMessage_DataBatch)
+	require.True(t, ok)
+	require.Equal(t, MsgEditData, dbatch.DataBatch.DataType)
+	require.Equal(t, int64(6), dbatch.DataBatch.EditId)
+	require.Len(t, dbatch.DataBatch.Points, 1)
+	require.Equal(t, uint32(common.QCChanged), dbatch.DataBatch.Points[0].Flag)
+
+	// Termination empty batch, with the id of the latest edit.
 	f, err = downStream.Recv()
 	require.NoError(t, err)
 	dbatch, ok = f.Body.(*syncpb.RelayMessage_DataBatch)
 	require.True(t, ok)
 	require.Empty(t, dbatch.DataBatch.StationId)
 	require.Empty(t, dbatch.DataBatch.Points)
+	require.Equal(t, int64(7), dbatch.DataBatch.EditId)
 
 	store.mu.Lock()
 	require.Len(t, store.dataHistoryCalls, 1)
@@ -339,7 +372,8 @@ type fakeDownstreamStore struct {
 	updateAvailableCalls int
 	updateStationCalls   int
 	saveDataCalls        int
-	ensureTableCalls     int
+	syncedEdits          []common.DataTimeStruct
+	editID               int64
 }
 
 func (s *fakeDownstreamStore) UpdateAvailableItems(upstreamID int, items common.UUIDStringsMap) (bool, error) {
@@ -394,18 +428,31 @@ func (s *fakeDownstreamStore) GetAllItems() ([]db.Item, error) { return nil, nil
 func (s *fakeDownstreamStore) ItemsLatest(stationID uuid.UUID, itemNames []string) (map[string]int64, error) {
 	return map[string]int64{}, nil
 }
-func (s *fakeDownstreamStore) EnsureDataTable(itemName string) error {
-	s.mu.Lock()
-	s.ensureTableCalls++
-	s.mu.Unlock()
-	return nil
-}
-func (s *fakeDownstreamStore) SaveDataHistory(stationID uuid.UUID, itemName string, value float64, at time.Time) (bool, error) {
+func (s *fakeDownstreamStore) SaveDataHistory(stationID uuid.UUID, itemName string, value float64, flag common.QCFlag, at time.Time) (bool, error) {
 	s.mu.Lock()
 	s.saveDataCalls++
 	s.mu.Unlock()
 	return true, nil
 }
+func (s *fakeDownstreamStore) SyncEditedData(stationID uuid.UUID, itemName string, data common.DataTimeStruct) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncedEdits = append(s.syncedEdits, data)
+	// Points at 0 are not stored here.
+	return data.Millisecond != 0, nil
+}
+
+func (s *fakeDownstreamStore) UpstreamEditID(upstreamID int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.editID, nil
+}
+func (s *fakeDownstreamStore) SetUpstreamEditID(upstreamID int, editID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.editID = max(s.editID, editID)
+	return nil
+}
 
 type fakeDownstreamNotifier struct {
 	mu sync.Mutex
@@ -413,6 +460,7 @@ type fakeDownstreamNotifier struct {
 	broadcastAvailCalls int
 	publishStatusCalls  int
 	publishDataCalls    int
+	publishedEdits      map[common.StationItemStruct][]common.DataTimeStruct
 }
 
 func (n *fakeDownstreamNotifier) PublishConfig(typeStr string, body any) {}
@@ -433,6 +481,40 @@ func (n *fakeDownstreamNotifier) PublishData(topic common.StationItemStruct, dat
 	n.publishDataCalls++
 	n.mu.Unlock()
 }
+func (n *fakeDownstreamNotifier) PublishEditedData(topic common.StationItemStruct, points []common.DataTimeStruct) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.publishedEdits == nil {
+		n.publishedEdits = make(map[common.StationItemStruct][]common.DataTimeStruct)
+	}
+	n.publishedEdits[topic] = append(n.publishedEdits[topic], points...)
+}
+
+func TestApplyDataBatch_EditedData(t *testing.T) {
+	store := &fakeDownstreamStore{}
+	notifier := &fakeDownstreamNotifier{}
+	deps := DownstreamDeps{Store: store, Notifier: notifier}
+	stationID := uuid.New()
+
+	applyDataBatch(DownstreamConfig{UpstreamID: 1}, deps, &syncpb.RelayDataBatch{
+		StationId: stationID.String(),
+		DataType:  MsgEditData,
+		Points: []*syncpb.DataPoint{
+			{ItemName: "i1", Value: 1.5, UnixMs: 1000, Flag: uint32(common.QCChanged)},
+			{ItemName: "i1", Value: 2.5, UnixMs: 0, Flag: uint32(common.QCChanged)},
+			{ItemName: "i2", Value: 3, UnixMs: 2000, Flag: uint32(common.QCBad)},
+		},
+		EditId: 8,
+	})
+
+	require.Zero(t, store.saveDataCalls)
+	require.Equal(t, int64(8), store.editID)
+	require.Len(t, store.syncedEdits, 3)
+	require.Equal(t, map[common.StationItemStruct][]common.DataTimeStruct{
+		{StationId: stationID, ItemName: "i1"}: {{Value: 1.5, Millisecond: 1000, Flag: common.QCChanged}},
+		{StationId: stationID, ItemName: "i2"}: {{Value: 3, Millisecond: 2000, Flag: common.QCBad}},
+	}, notifier.publishedEdits)
+}
 
 func TestRunDownstream_AppliesIncrementalFrames(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -457,7 +539,7 @@ func TestRunDownstream_AppliesIncrementalFrames(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	store := &fakeDownstreamStore{}
+	store := &fakeDownstreamStore{editID: 3}
 	notifier := &fakeDownstreamNotifier{}
 	deps := DownstreamDeps{
 		AuthClient: authClient,
@@ -509,13 +591,14 @@ func TestRunDownstream_AppliesIncrementalFrames(t *testing.T) {
 			ConfigBatch: &syncpb.RelayConfigBatch{FullSync: false},
 		}}))
 
-		// Miss data request -> respond termination.
+		// Miss data request with the edit cursor -> respond termination with the latest edit id.
 		f, err = upStream.Recv()
 		require.NoError(t, err)
-		_, ok = f.Body.(*syncpb.RelayMessage_StationsItemsLatest)
+		itemsLatest, ok := f.Body.(*syncpb.RelayMessage_StationsItemsLatest)
 		require.True(t, ok)
+		require.Equal(t, int64(3), itemsLatest.StationsItemsLatest.EditAfterId)
 		require.NoError(t, upStream.Send(&syncpb.RelayMessage{Body: &syncpb.RelayMessage_DataBatch{
-			DataBatch: &syncpb.RelayDataBatch{},
+			DataBatch: &syncpb.RelayDataBatch{EditId: 4},
 		}}))
 
 		// Incremental frames.
@@ -562,8 +645,8 @@ func TestRunDownstream_AppliesIncrementalFrames(t *testing.T) {
 	store.mu.Lock()
 	require.GreaterOrEqual(t, store.updateAvailableCalls, 2) // handshake + incremental update_available
 	require.GreaterOrEqual(t, store.updateStationCalls, 1)
-	require.GreaterOrEqual(t, store.ensureTableCalls, 1)
 	require.GreaterOrEqual(t, store.saveDataCalls, 1)
+	require.Equal(t, int64(4), store.editID)
 	store.mu.Unlock()
 
 	notifier.mu.Lock()
@@ -578,3 +661,22 @@ func TestRunDownstream_AppliesIncrementalFrames(t *testing.T) {
 
 	<-upDone
 }
+
+func TestDataFrames(t *testing.T) {
+	stationID := uuid.New()
+	ds := make([]common.DataTimeStruct, DataBatchSize+1)
+	for i := range ds {
+		ds[i].Millisecond = custype.UnixMs(i)
+	}
+	frames := DataFrames(stationID, "i1", MsgEditData, ds, 9)
+	require.Len(t, frames, 2)
+	require.Len(t, frames[0].GetDataBatch().Points, DataBatchSize)
+	require.Zero(t, frames[0].GetDataBatch().EditId)
+	last := frames[1].GetDataBatch()
+	require.Equal(t, stationID.String(), last.StationId)
+	require.Equal(t, MsgEditData, last.DataType)
+	require.Equal(t, int64(9), last.EditId)
+	require.Equal(t, []*syncpb.DataPoint{{ItemName: "i1", UnixMs: int64(DataBatchSize), Kind: syncpb.DataKind_DATA_KIND_NORMAL}}, last.Points)
+
+	require.Empty(t, DataFrames(stationID, "i1", MsgMissData, nil, 0))
+}
@@ -37,6 +37,14 @@ func (DBUpstreamStore) GetDataHistory(stationID uuid.UUID, itemName string, afte
 	return db.GetDataHistory(stationID, itemName, after, 0)
 }
 
+func (DBUpstreamStore) GetDataEditsAfter(afterID int64, limit uint) ([]db.DataEdit, error) {
+	return db.GetDataEditsAfter(afterID, limit)
+}
+
+func (DBUpstreamStore) LatestDataEditID() (int64, error) {
+	return db.LatestDataEditId()
+}
+
 type DBDownstreamStore struct{}
 
 func (DBDownstreamStore) UpdateAvailableItems(upstreamID int, items common.UUIDStringsMap) (bool, error) {
@@ -143,11 +151,20 @@ func (DBDownstreamStore) ItemsLatest(stationID uuid.UUID, itemNames []string) (m
 	return latest, nil
 }
 
-func (DBDownstreamStore) EnsureDataTable(itemName string) error {
-	return db.MakeSureTableExist(itemName)
+func (DBDownstreamStore) SaveDataHistory(stationID uuid.UUID, itemName string, value float64, flag common.QCFlag, at time.Time) (bool, error) {
+	n, err := db.SaveDataHistory(stationID, itemName, value, flag, at)
+	return n > 0, err
 }
 
-func (DBDownstreamStore) SaveDataHistory(stationID uuid.UUID, itemName string, value float64, at time.Time) (bool, error) {
-	n, err := db.SaveDataHistory(stationID, itemName, value, at)
+func (DBDownstreamStore) SyncEditedData(stationID uuid.UUID, itemName string, data common.DataTimeStruct) (bool, error) {
+	n, err := db.SyncEditedData(stationID, itemName, data)
 	return n > 0, err
 }
+
+func (DBDownstreamStore) UpstreamEditID(upstreamID int) (int64, error) {
+	return db.GetUpstreamEditId(upstreamID)
+}
+
+func (DBDownstreamStore) SetUpstreamEditID(upstreamID int, editID int64) error {
+	return db.SetUpstreamEditId(upstreamID, editID)
+}
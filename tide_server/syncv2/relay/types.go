@@ -26,6 +26,9 @@ type UpstreamStore interface {
 	GetItemStatusLogs(stationID uuid.UUID, afterRowID int64) ([]common.RowIdItemStatusStruct, error)
 	GetItemsAllStations() ([]db.Item, error)
 	GetDataHistory(stationID uuid.UUID, itemName string, after custype.UnixMs) ([]common.DataTimeStruct, error)
+	// GetDataEditsAfter returns the first data edits with an id greater than afterID, in order.
+	GetDataEditsAfter(afterID int64, limit uint) ([]db.DataEdit, error)
+	LatestDataEditID() (int64, error)
 }
 
 type UpstreamAuthDeps struct {
@@ -92,8 +95,13 @@ type DownstreamStore interface {
 	// Miss data / data events.
 	GetAllItems() ([]db.Item, error)
 	ItemsLatest(stationID uuid.UUID, itemNames []string) (map[string]int64, error)
-	EnsureDataTable(itemName string) error
-	SaveDataHistory(stationID uuid.UUID, itemName string, value float64, at time.Time) (inserted bool, err error)
+	SaveDataHistory(stationID uuid.UUID, itemName string, value float64, flag common.QCFlag, at time.Time) (inserted bool, err error)
+	// SyncEditedData stores a point edited upstream if it is stored here with another value or flag, or is missing
+	// here before the latest point.
+	SyncEditedData(stationID uuid.UUID, itemName string, data common.DataTimeStruct) (changed bool, err error)
+	// UpstreamEditID is the id of the latest data edit of the upstream applied here, the replay of the edits starts after it.
+	UpstreamEditID(upstreamID int) (int64, error)
+	SetUpstreamEditID(upstreamID int, editID int64) error
 }
 
 type DownstreamNotifier interface {
@@ -103,6 +111,7 @@ type DownstreamNotifier interface {
 
 	PublishMissData(topic common.StationItemStruct, data common.DataTimeStruct)
 	PublishData(topic common.StationItemStruct, data common.DataTimeStruct, gpio bool)
+	PublishEditedData(topic common.StationItemStruct, points []common.DataTimeStruct)
 }
 
 type DownstreamDeps struct {
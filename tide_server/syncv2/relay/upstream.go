@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
 	"tide/common"
@@ -16,7 +17,6 @@ import (
 	"tide/tide_server/auth"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func (s *UpstreamServer) StreamRelay(ctx context.Context, stream internalsyncv2.RelayMessageStream, authenticatedUsername string) error {
@@ -306,37 +306,89 @@ func (s *UpstreamServer) handleMissDataSync(stream internalsyncv2.RelayMessageSt
 
 			ds, queryErr := s.Store.GetDataHistory(stationID, itemName, custype.UnixMs(afterMs))
 			if queryErr != nil {
-				if pgErr, ok2 := errors.AsType[*pgconn.PgError](queryErr); ok2 && pgErr.Code == "42P01" {
-					continue
-				}
 				return fmt.Errorf("failed to get data history: %w", queryErr)
 			}
 			if len(ds) == 0 {
 				continue
 			}
 
-			var points []*syncpb.DataPoint
-			for _, d := range ds {
-				points = append(points, &syncpb.DataPoint{
-					ItemName: itemName,
-					Value:    d.Value,
-					UnixMs:   d.Millisecond.ToInt64(),
-					Kind:     syncpb.DataKind_DATA_KIND_NORMAL,
-				})
-			}
-			if err := sendFrame(&syncpb.RelayMessage{Body: &syncpb.RelayMessage_DataBatch{
-				DataBatch: &syncpb.RelayDataBatch{
-					StationId: stationID.String(),
-					DataType:  MsgMissData,
-					Points:    points,
-				},
-			}}); err != nil {
-				return err
+			for _, frame := range DataFrames(stationID, itemName, MsgMissData, ds, 0) {
+				if err := sendFrame(frame); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
+	editID, err := s.sendDataEdits(sendFrame, effectivePerms, itemsLatest.StationsItemsLatest.EditAfterId)
+	if err != nil {
+		return err
+	}
 	return sendFrame(&syncpb.RelayMessage{Body: &syncpb.RelayMessage_DataBatch{
-		DataBatch: &syncpb.RelayDataBatch{},
+		DataBatch: &syncpb.RelayDataBatch{EditId: editID},
 	}})
 }
+
+// dataEditsPage is the number of data edits read at once for their replay.
+const dataEditsPage = 100
+
+// sendDataEdits replays the data edits of the permitted items made after afterID, the ones made while the downstream
+// was away. It returns the id of the latest edit, the cursor of the downstream. Nothing is replayed to a downstream
+// without a cursor, which gets the edited points with the missing data.
+func (s *UpstreamServer) sendDataEdits(sendFrame func(*syncpb.RelayMessage) error, permissions common.UUIDStringsMap, afterID int64) (int64, error) {
+	if afterID <= 0 {
+		return s.Store.LatestDataEditID()
+	}
+	for {
+		es, err := s.Store.GetDataEditsAfter(afterID, dataEditsPage)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get data edits: %w", err)
+		}
+		for _, e := range es {
+			afterID = e.Id
+			if !slices.Contains(permissions[e.StationId], e.ItemName) {
+				continue
+			}
+			for _, frame := range DataFrames(e.StationId, e.ItemName, MsgEditData, e.After, e.Id) {
+				if err = sendFrame(frame); err != nil {
+					return 0, err
+				}
+			}
+		}
+		if len(es) < dataEditsPage {
+			return afterID, nil
+		}
+	}
+}
+
+// DataBatchSize is the number of points of a data frame carrying many points of an item, far below the frame size limit.
+const DataBatchSize = 1000
+
+// DataFrames splits points of an item into data frames of DataBatchSize points, editID is set on the last one so that
+// the downstream records it once the whole edit is applied.
+func DataFrames(stationID uuid.UUID, itemName, dataType string, ds []common.DataTimeStruct, editID int64) []*syncpb.RelayMessage {
+	frames := make([]*syncpb.RelayMessage, 0, (len(ds)+DataBatchSize-1)/DataBatchSize)
+	for chunk := range slices.Chunk(ds, DataBatchSize) {
+		points := make([]*syncpb.DataPoint, 0, len(chunk))
+		for _, d := range chunk {
+			points = append(points, &syncpb.DataPoint{
+				ItemName: itemName,
+				Value:    d.Value,
+				UnixMs:   d.Millisecond.ToInt64(),
+				Kind:     syncpb.DataKind_DATA_KIND_NORMAL,
+				Flag:     uint32(d.Flag),
+			})
+		}
+		frames = append(frames, &syncpb.RelayMessage{Body: &syncpb.RelayMessage_DataBatch{
+			DataBatch: &syncpb.RelayDataBatch{
+				StationId: stationID.String(),
+				DataType:  dataType,
+				Points:    points,
+			},
+		}})
+	}
+	if len(frames) > 0 {
+		frames[len(frames)-1].GetDataBatch().EditId = editID
+	}
+	return frames
+}
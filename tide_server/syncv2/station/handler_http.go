@@ -13,10 +13,12 @@ import (
 )
 
 type Handler struct {
-	Enabled       func() bool
-	Server        *Server
-	MaxFrameBytes int64
-	Logger        *slog.Logger
+	Enabled func() bool
+	// RequireClientCert rejects stations that did not present a verified TLS client certificate.
+	RequireClientCert func() bool
+	Server            *Server
+	MaxFrameBytes     int64
+	Logger            *slog.Logger
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -34,6 +36,16 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		log = slog.Default()
 	}
 
+	var certIdentities []string
+	if r.TLS != nil {
+		certIdentities = internalsyncv2.PeerIdentities(r.TLS)
+	}
+	if len(certIdentities) == 0 && h.RequireClientCert != nil && h.RequireClientCert() {
+		log.Warn("v2 station rejected without client certificate", "remote", r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
 	conn, err := internalsyncv2.HijackUpgrade(w)
 	if err != nil {
 		return
@@ -69,6 +81,9 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
+	if certIdentities != nil {
+		ctx = WithCertIdentities(ctx, certIdentities)
+	}
 
 	done := make(chan struct{})
 	defer close(done)
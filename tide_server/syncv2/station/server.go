@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
 	"tide/common"
 	internalsyncv2 "tide/internal/syncv2"
 	"tide/pkg/custype"
+	"tide/pkg/pbstream"
 
 	syncpb "tide/pkg/pb/syncproto"
 
@@ -23,12 +25,27 @@ type Server struct {
 	InfoSyncer InfoSyncer
 	Notifier   Notifier
 	Logger     *slog.Logger
+	// ClockDriftThreshold is the clock offset from which a station is flagged, DefaultClockDriftThreshold if 0.
+	ClockDriftThreshold time.Duration
+	// MaxFutureSkew is how far ahead of the server clock data may be stamped, DefaultMaxFutureSkew if 0.
+	MaxFutureSkew time.Duration
 
 	reg registry
 }
 
 var ErrStationNotConnected = errors.New("station not connected")
 
+const (
+	DefaultClockDriftThreshold = time.Minute
+	DefaultMaxFutureSkew       = 10 * time.Minute
+)
+
+// Reasons of quarantined data points.
+const (
+	QuarantineFuture             = "future"
+	QuarantineBeforeInstallation = "before_installation"
+)
+
 type commandStreamOpener func() (internalsyncv2.StationMessageStream, error)
 
 func (s *Server) StreamStation(ctx context.Context, stream internalsyncv2.StationMessageStream, openCommandStream commandStreamOpener, remoteAddr string) error {
@@ -52,9 +69,10 @@ func (s *Server) StreamStation(ctx context.Context, stream internalsyncv2.Statio
 		return err
 	}
 
-	stationID, err := s.Store.StationIDByIdentifier(hello.StationIdentifier)
+	stationID, clockOffset, err := s.authenticate(ctx, stream, hello)
 	if err != nil {
-		return fmt.Errorf("station not found: %w", err)
+		log.Warn("v2 station authentication failed", "identifier", hello.StationIdentifier, "remote", remoteAddr, "error", err)
+		return err
 	}
 
 	conn := newStationConn(openCommandStream, ctx.Done())
@@ -63,14 +81,6 @@ func (s *Server) StreamStation(ctx context.Context, stream internalsyncv2.Statio
 	}
 	defer s.reg.Delete(stationID)
 
-	if err = stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ServerHello{
-		ServerHello: &syncpb.ServerHello{
-			ServerVersion: "sync-v2",
-		},
-	}}); err != nil {
-		return err
-	}
-
 	stationInfo, err := recvStationInfo(stream)
 	if err != nil {
 		return err
@@ -91,20 +101,43 @@ func (s *Server) StreamStation(ctx context.Context, stream internalsyncv2.Statio
 	if err = s.Store.SetStationIP(stationID, remoteAddr); err != nil {
 		return fmt.Errorf("failed to update station ip: %w", err)
 	}
+	if err = s.saveClockOffset(log, stationID, stationInfo.Identifier, clockOffset); err != nil {
+		return fmt.Errorf("failed to save clock offset: %w", err)
+	}
+	installedAt, err := s.Store.StationInstalledAt(stationID)
+	if err != nil {
+		return fmt.Errorf("failed to get station installation time: %w", err)
+	}
 	if err = s.InfoSyncer.SyncStationInfo(stationID, stationInfo); err != nil {
 		return err
 	}
+	if err = s.Store.SaveDeviceConfigApplied(stationID, stationInfo.DeviceConfigVersion, stationInfo.DeviceConfigError); err != nil {
+		return fmt.Errorf("failed to save device config state: %w", err)
+	}
 
 	log.Info("v2 station connected", "identifier", stationInfo.Identifier, "remote", remoteAddr)
 
-	itemsLatest, err := s.Store.ItemsLatest(stationID, stationInfo.Devices)
-	if err != nil {
-		return fmt.Errorf("failed to get item latest: %w", err)
-	}
-	if err = stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ItemsLatest{
-		ItemsLatest: &syncpb.ItemsLatest{LatestUnixMs: itemsLatest},
-	}}); err != nil {
-		return err
+	var cursor *dataCursor
+	if hello.OutboxId == "" {
+		itemsLatest, err := s.Store.ItemsLatest(stationID, stationInfo.Devices)
+		if err != nil {
+			return fmt.Errorf("failed to get item latest: %w", err)
+		}
+		if err = stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ItemsLatest{
+			ItemsLatest: &syncpb.ItemsLatest{LatestUnixMs: itemsLatest},
+		}}); err != nil {
+			return err
+		}
+	} else {
+		cursor, err = s.loadDataCursor(stationID, hello.OutboxId)
+		if err != nil {
+			return fmt.Errorf("failed to get data cursor: %w", err)
+		}
+		if err = stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataCursor{
+			DataCursor: &syncpb.DataCursor{LastSeq: cursor.seq},
+		}}); err != nil {
+			return err
+		}
 	}
 
 	latestStatusRowID, err := s.Store.LatestStatusLogRowID(stationID)
@@ -117,6 +150,12 @@ func (s *Server) StreamStation(ctx context.Context, stream internalsyncv2.Statio
 		return err
 	}
 
+	go func() {
+		if err := s.pushDeviceConfig(conn, stationID, stationInfo.DeviceConfigVersion); err != nil {
+			log.Warn("failed to push device config", "identifier", stationInfo.Identifier, "error", err)
+		}
+	}()
+
 	for {
 		frame, recvErr := stream.Recv()
 		if recvErr != nil {
@@ -125,20 +164,37 @@ func (s *Server) StreamStation(ctx context.Context, stream internalsyncv2.Statio
 
 		switch body := frame.Body.(type) {
 		case *syncpb.StationMessage_DataBatch:
-			if err = s.handleDataBatch(stationID, body.DataBatch); err != nil {
+			if err = s.handleDataBatch(stationID, body.DataBatch, cursor, installedAt); err != nil {
 				return err
 			}
+			if body.DataBatch.BatchId != 0 {
+				if err = stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataAck{
+					DataAck: &syncpb.DataAck{BatchId: body.DataBatch.BatchId},
+				}}); err != nil {
+					return err
+				}
+			}
 		case *syncpb.StationMessage_ItemStatusBatch:
 			if err = s.handleItemStatusBatch(stationID, stationInfo.Identifier, body.ItemStatusBatch); err != nil {
 				return err
 			}
 		case *syncpb.StationMessage_RpiStatus:
 			tm := custype.UnixMs(body.RpiStatus.UnixMs)
-			if err = s.Store.SaveRpiStatus(stationID, body.RpiStatus.CpuTemp, tm.ToTime()); err != nil {
+			cpuTemp := body.RpiStatus.CpuTemp
+			if err = s.Store.SaveStationHealth(stationID, common.StationHealthStruct{CpuTemp: &cpuTemp}, tm.ToTime()); err != nil {
+				return err
+			}
+		case *syncpb.StationMessage_StationHealth:
+			health := internalsyncv2.PBToStationHealth(body.StationHealth)
+			if err = s.Store.SaveStationHealth(stationID, health.StationHealthStruct, health.Millisecond.ToTime()); err != nil {
 				return err
 			}
 		case *syncpb.StationMessage_StationInfo:
-			if err := s.InfoSyncer.SyncStationInfo(stationID, internalsyncv2.PBToStationInfo(body.StationInfo)); err != nil {
+			info := internalsyncv2.PBToStationInfo(body.StationInfo)
+			if err := s.InfoSyncer.SyncStationInfo(stationID, info); err != nil {
+				return err
+			}
+			if err = s.Store.SaveDeviceConfigApplied(stationID, info.DeviceConfigVersion, info.DeviceConfigError); err != nil {
 				return err
 			}
 		default:
@@ -147,6 +203,47 @@ func (s *Server) StreamStation(ctx context.Context, stream internalsyncv2.Statio
 	}
 }
 
+// PushDeviceConfig sends the stored device config to a connected station.
+// A config the station rejects is recorded and returned as an error.
+func (s *Server) PushDeviceConfig(stationID uuid.UUID) error {
+	conn, ok := s.reg.Load(stationID)
+	if !ok {
+		return ErrStationNotConnected
+	}
+	return s.pushDeviceConfig(conn, stationID, 0)
+}
+
+const deviceConfigPushTimeout = 30 * time.Second
+
+// pushDeviceConfig sends the stored device config if it is newer than appliedVersion.
+func (s *Server) pushDeviceConfig(conn *stationConn, stationID uuid.UUID, appliedVersion int64) error {
+	version, config, err := s.Store.DeviceConfig(stationID)
+	if err != nil {
+		return err
+	}
+	if version == 0 || version <= appliedVersion {
+		return nil
+	}
+
+	frame, err := conn.roundTrip(&syncpb.StationMessage{Body: &syncpb.StationMessage_DeviceConfigPush{
+		DeviceConfigPush: &syncpb.DeviceConfigPush{Version: version, Config: config},
+	}}, deviceConfigPushTimeout)
+	if err != nil {
+		return err
+	}
+	body, ok := frame.Body.(*syncpb.StationMessage_DeviceConfigResult)
+	if !ok || body.DeviceConfigResult == nil {
+		return errors.New("expected device_config_result")
+	}
+	if body.DeviceConfigResult.Error != "" {
+		if err = s.Store.SaveDeviceConfigError(stationID, body.DeviceConfigResult.Error); err != nil {
+			return err
+		}
+		return fmt.Errorf("station rejected device config %d: %s", version, body.DeviceConfigResult.Error)
+	}
+	return nil
+}
+
 func (s *Server) RequestSnapshot(stationID uuid.UUID, cameraName string, timeout time.Duration) ([]byte, error) {
 	if strings.TrimSpace(cameraName) == "" {
 		return nil, errors.New("empty camera name")
@@ -158,6 +255,224 @@ func (s *Server) RequestSnapshot(stationID uuid.UUID, cameraName string, timeout
 	return conn.requestSnapshot(cameraName, timeout)
 }
 
+// RequestDeviceCommand sends a raw sensor command to a connected station.
+// An error from the device is returned in the response, err is only set if the station could not be asked.
+func (s *Server) RequestDeviceCommand(stationID uuid.UUID, req *syncpb.DeviceCommandRequest, timeout time.Duration) (*syncpb.DeviceCommandResponse, error) {
+	conn, ok := s.reg.Load(stationID)
+	if !ok {
+		return nil, ErrStationNotConnected
+	}
+	frame, err := conn.roundTrip(&syncpb.StationMessage{Body: &syncpb.StationMessage_DeviceCommandRequest{
+		DeviceCommandRequest: req,
+	}}, timeout)
+	if err != nil {
+		return nil, err
+	}
+	body, ok := frame.Body.(*syncpb.StationMessage_DeviceCommandResponse)
+	if !ok || body.DeviceCommandResponse == nil {
+		return nil, errors.New("expected device_command_response")
+	}
+	return body.DeviceCommandResponse, nil
+}
+
+// RequestLogs returns the buffered log entries of a connected station, req.Follow is ignored.
+func (s *Server) RequestLogs(stationID uuid.UUID, req *syncpb.LogRequest, timeout time.Duration) ([]*syncpb.LogEntry, error) {
+	conn, ok := s.reg.Load(stationID)
+	if !ok {
+		return nil, ErrStationNotConnected
+	}
+	frame, err := conn.roundTrip(&syncpb.StationMessage{Body: &syncpb.StationMessage_LogRequest{
+		LogRequest: &syncpb.LogRequest{MinLevel: req.MinLevel, Device: req.Device, Limit: req.Limit},
+	}}, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return logBatchEntries(frame)
+}
+
+// FollowLogs passes the buffered and then the new log entries of a connected station to fn,
+// until ctx is done, the station disconnects or fn returns an error.
+func (s *Server) FollowLogs(ctx context.Context, stationID uuid.UUID, req *syncpb.LogRequest, fn func([]*syncpb.LogEntry) error) error {
+	conn, ok := s.reg.Load(stationID)
+	if !ok {
+		return ErrStationNotConnected
+	}
+	cmdStream, err := conn.openCommandStream()
+	if err != nil {
+		return err
+	}
+	// Closing the stream tells the station to stop, Recv returns once the station closes its side or disconnects.
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-conn.done:
+		case <-finished:
+		}
+		_ = cmdStream.Close()
+	}()
+
+	if err = cmdStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_LogRequest{
+		LogRequest: &syncpb.LogRequest{MinLevel: req.MinLevel, Device: req.Device, Limit: req.Limit, Follow: true},
+	}}); err != nil {
+		return err
+	}
+	for {
+		frame, err := cmdStream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		entries, err := logBatchEntries(frame)
+		if err != nil {
+			return err
+		}
+		if err = fn(entries); err != nil {
+			return err
+		}
+	}
+}
+
+func logBatchEntries(frame *syncpb.StationMessage) ([]*syncpb.LogEntry, error) {
+	switch body := frame.Body.(type) {
+	case *syncpb.StationMessage_LogBatch:
+		return body.LogBatch.GetEntries(), nil
+	case *syncpb.StationMessage_Error:
+		return nil, errors.New(body.Error.GetMessage())
+	default:
+		return nil, errors.New("expected log_batch")
+	}
+}
+
+type certIdentitiesKey struct{}
+
+// WithCertIdentities attaches the identities of a verified TLS client certificate to ctx.
+// StreamStation then only accepts a ClientHello whose station_identifier is one of them.
+func WithCertIdentities(ctx context.Context, identities []string) context.Context {
+	return context.WithValue(ctx, certIdentitiesKey{}, identities)
+}
+
+func certIdentities(ctx context.Context) ([]string, bool) {
+	ids, ok := ctx.Value(certIdentitiesKey{}).([]string)
+	return ids, ok
+}
+
+// authenticate sends ServerHello with a fresh challenge and the server time, and verifies the ClientAuth reply
+// against the station's auth key. The challenge is sent and the reply read whatever the station, so that
+// failures, reported as an unauthenticated ErrorFrame, do not tell apart unknown stations, stations without
+// a key and wrong keys.
+// It returns the clock offset the station measured, the server clock minus the station clock.
+func (s *Server) authenticate(ctx context.Context, stream internalsyncv2.StationMessageStream, hello *syncpb.ClientHello) (uuid.UUID, time.Duration, error) {
+	stationID, authKey, authErr := s.stationAuthKey(ctx, hello.StationIdentifier)
+	if authErr != nil && !errors.Is(authErr, errAuthRejected) {
+		return uuid.Nil, 0, authErr
+	}
+
+	challenge, err := internalsyncv2.NewAuthChallenge()
+	if err != nil {
+		return uuid.Nil, 0, err
+	}
+	compression := pbstream.SelectCompression(hello.Compressions)
+	if err = stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ServerHello{
+		ServerHello: &syncpb.ServerHello{
+			ServerVersion: "sync-v2",
+			AuthChallenge: challenge,
+			Compression:   compression,
+			ColumnarData:  true,
+			ServerUnixMs:  time.Now().UnixMilli(),
+		},
+	}}); err != nil {
+		return uuid.Nil, 0, err
+	}
+	if compression != "" {
+		if err = stream.SetCompression(compression); err != nil {
+			return uuid.Nil, 0, err
+		}
+	}
+
+	frame, err := stream.Recv()
+	if err != nil {
+		return uuid.Nil, 0, err
+	}
+	clientAuth, ok := frame.Body.(*syncpb.StationMessage_ClientAuth)
+	if !ok || clientAuth.ClientAuth == nil {
+		sendUnauthenticated(stream)
+		return uuid.Nil, 0, errors.New("second frame must be client_auth")
+	}
+	if authErr != nil {
+		sendUnauthenticated(stream)
+		return uuid.Nil, 0, authErr
+	}
+	if !internalsyncv2.VerifyAuthMAC(authKey, challenge, hello.StationIdentifier, clientAuth.ClientAuth.Mac) {
+		sendUnauthenticated(stream)
+		return uuid.Nil, 0, errors.New("invalid auth mac")
+	}
+	return stationID, time.Duration(clientAuth.ClientAuth.ClockOffsetMs) * time.Millisecond, nil
+}
+
+var errAuthRejected = errors.New("station rejected")
+
+// stationAuthKey looks up the station and its auth key. The reasons a station is rejected wrap errAuthRejected,
+// other errors are store failures.
+func (s *Server) stationAuthKey(ctx context.Context, identifier string) (uuid.UUID, string, error) {
+	if ids, ok := certIdentities(ctx); ok && !slices.Contains(ids, identifier) {
+		return uuid.Nil, "", fmt.Errorf("%w: client certificate %v does not match station identifier", errAuthRejected, ids)
+	}
+	stationID, err := s.Store.StationIDByIdentifier(identifier)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("%w: station not found: %w", errAuthRejected, err)
+	}
+	authKey, err := s.Store.StationAuthKey(stationID)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to get station auth key: %w", err)
+	}
+	if authKey == "" {
+		return uuid.Nil, "", fmt.Errorf("%w: station auth key not configured", errAuthRejected)
+	}
+	return stationID, authKey, nil
+}
+
+// saveClockOffset records the clock offset of a station and flags it if the offset exceeds the threshold.
+func (s *Server) saveClockOffset(log *slog.Logger, stationID uuid.UUID, identifier string, offset time.Duration) error {
+	threshold := s.ClockDriftThreshold
+	if threshold <= 0 {
+		threshold = DefaultClockDriftThreshold
+	}
+	drift := offset.Abs() > threshold
+	if drift {
+		log.Warn("v2 station clock drift", "identifier", identifier, "offset", offset, "threshold", threshold)
+	}
+	return s.Store.SaveClockOffset(stationID, offset, drift)
+}
+
+// quarantineReason returns why a point stamped at is implausible, or "" if it is not.
+func (s *Server) quarantineReason(at, installedAt time.Time) string {
+	maxFuture := s.MaxFutureSkew
+	if maxFuture <= 0 {
+		maxFuture = DefaultMaxFutureSkew
+	}
+	if at.After(time.Now().Add(maxFuture)) {
+		return QuarantineFuture
+	}
+	if at.Before(installedAt) {
+		return QuarantineBeforeInstallation
+	}
+	return ""
+}
+
+func sendUnauthenticated(stream internalsyncv2.StationMessageStream) {
+	_ = stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_Error{
+		Error: &syncpb.ErrorFrame{
+			Code:      internalsyncv2.ErrCodeUnauthenticated,
+			Message:   "station authentication failed",
+			Retryable: false,
+		},
+	}})
+}
+
 func (s *Server) updateStationStatusAndNotify(stationID uuid.UUID, identifier string, status common.Status) error {
 	now := custype.ToUnixMs(time.Now())
 	changed, err := s.Store.UpdateStationStatus(stationID, status, now.ToTime())
@@ -196,7 +511,7 @@ func recvStationInfo(stream internalsyncv2.StationMessageStream) (common.Station
 	}
 	info, ok := first.Body.(*syncpb.StationMessage_StationInfo)
 	if !ok || info.StationInfo == nil {
-		return common.StationInfoStruct{}, errors.New("second frame must be station_info")
+		return common.StationInfoStruct{}, errors.New("third frame must be station_info")
 	}
 	ret := internalsyncv2.PBToStationInfo(info.StationInfo)
 	if ret.Identifier == "" || len(ret.Devices) == 0 {
@@ -205,30 +520,87 @@ func recvStationInfo(stream internalsyncv2.StationMessageStream) (common.Station
 	return ret, nil
 }
 
-func (s *Server) handleDataBatch(stationID uuid.UUID, batch *syncpb.DataBatch) error {
-	for _, point := range batch.Points {
+// dataCursor is the last outbox seq of a station up to which every data point is stored.
+type dataCursor struct {
+	outboxID string
+	seq      int64
+}
+
+// loadDataCursor returns the stored cursor, starting over from 0 when the station reports a new outbox.
+func (s *Server) loadDataCursor(stationID uuid.UUID, outboxID string) (*dataCursor, error) {
+	storedID, seq, err := s.Store.DataCursor(stationID)
+	if err != nil {
+		return nil, err
+	}
+	if storedID != outboxID {
+		seq = 0
+		if err = s.Store.SaveDataCursor(stationID, outboxID, seq); err != nil {
+			return nil, err
+		}
+	}
+	return &dataCursor{outboxID: outboxID, seq: seq}, nil
+}
+
+// advance moves the cursor past a stored point. Replay is sent in seq order starting after the cursor,
+// so it always advances; realtime only advances while contiguous, a gap leaves the rest to the next replay.
+func (c *dataCursor) advance(seq int64, replay bool) bool {
+	if seq <= c.seq {
+		return false
+	}
+	if !replay && seq != c.seq+1 {
+		return false
+	}
+	c.seq = seq
+	return true
+}
+
+// handleDataBatch stores a batch of points, points with implausible timestamps are quarantined instead.
+func (s *Server) handleDataBatch(stationID uuid.UUID, batch *syncpb.DataBatch, cursor *dataCursor, installedAt time.Time) error {
+	points, err := internalsyncv2.DataBatchPoints(batch)
+	if err != nil {
+		return err
+	}
+	advanced := false
+	for _, point := range points {
 		tm := custype.UnixMs(point.UnixMs)
+		flag := common.QCFlag(point.Flag)
 		stationItem := common.StationItemStruct{StationId: stationID, ItemName: point.ItemName}
 
+		if reason := s.quarantineReason(tm.ToTime(), installedAt); reason != "" {
+			if err = s.Store.SaveQuarantinedData(stationID, point.ItemName, point.Value, flag, tm.ToTime(), reason); err != nil {
+				return err
+			}
+			if cursor != nil && cursor.advance(point.Seq, batch.Replay) {
+				advanced = true
+			}
+			continue
+		}
+
 		if point.Kind == syncpb.DataKind_DATA_KIND_GPIO {
 			_, _ = s.Store.UpdateItemStatus(stationID, point.ItemName, common.NoStatus, tm.ToTime())
 		}
 
-		inserted, err := s.Store.SaveDataHistory(stationID, point.ItemName, point.Value, tm.ToTime())
+		inserted, err := s.Store.SaveDataHistory(stationID, point.ItemName, point.Value, flag, tm.ToTime())
 		if err != nil {
 			return err
 		}
+		if cursor != nil && cursor.advance(point.Seq, batch.Replay) {
+			advanced = true
+		}
 		if !inserted {
 			continue
 		}
 
-		data := common.DataTimeStruct{Value: point.Value, Millisecond: tm}
+		data := common.DataTimeStruct{Value: point.Value, Millisecond: tm, Flag: flag}
 		if batch.Replay {
 			s.Notifier.PublishMissData(stationItem, data)
 			continue
 		}
 		s.Notifier.PublishRealtimeData(stationItem, data, point.Kind == syncpb.DataKind_DATA_KIND_GPIO)
 	}
+	if advanced {
+		return s.Store.SaveDataCursor(stationID, cursor.outboxID, cursor.seq)
+	}
 	return nil
 }
 
@@ -306,17 +678,36 @@ func newStationConn(openCommandStream commandStreamOpener, done <-chan struct{})
 }
 
 func (c *stationConn) requestSnapshot(cameraName string, timeout time.Duration) ([]byte, error) {
+	frame, err := c.roundTrip(&syncpb.StationMessage{Body: &syncpb.StationMessage_CameraSnapshotRequest{
+		CameraSnapshotRequest: &syncpb.CameraSnapshotRequest{
+			CameraName: cameraName,
+		},
+	}}, timeout)
+	if err != nil {
+		return nil, err
+	}
+	body, ok := frame.Body.(*syncpb.StationMessage_CameraSnapshotResponse)
+	if !ok || body.CameraSnapshotResponse == nil {
+		return nil, errors.New("expected camera_snapshot_response")
+	}
+	if body.CameraSnapshotResponse.Error != "" {
+		return nil, errors.New(body.CameraSnapshotResponse.Error)
+	}
+	if len(body.CameraSnapshotResponse.Data) == 0 {
+		return nil, errors.New("empty snapshot")
+	}
+	return body.CameraSnapshotResponse.Data, nil
+}
+
+// roundTrip sends req on a new command substream and waits for its single response frame.
+func (c *stationConn) roundTrip(req *syncpb.StationMessage, timeout time.Duration) (*syncpb.StationMessage, error) {
 	cmdStream, err := c.openCommandStream()
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = cmdStream.Close() }()
 
-	if err := cmdStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_CameraSnapshotRequest{
-		CameraSnapshotRequest: &syncpb.CameraSnapshotRequest{
-			CameraName: cameraName,
-		},
-	}}); err != nil {
+	if err := cmdStream.Send(req); err != nil {
 		return nil, err
 	}
 
@@ -345,21 +736,11 @@ func (c *stationConn) requestSnapshot(cameraName string, timeout time.Duration)
 		case r := <-recvCh:
 			if r.err != nil {
 				if errors.Is(r.err, io.EOF) {
-					return nil, errors.New("snapshot stream closed")
+					return nil, errors.New("command stream closed")
 				}
 				return nil, r.err
 			}
-			body, ok := r.frame.Body.(*syncpb.StationMessage_CameraSnapshotResponse)
-			if !ok || body.CameraSnapshotResponse == nil {
-				return nil, errors.New("expected camera_snapshot_response")
-			}
-			if body.CameraSnapshotResponse.Error != "" {
-				return nil, errors.New(body.CameraSnapshotResponse.Error)
-			}
-			if len(body.CameraSnapshotResponse.Data) == 0 {
-				return nil, errors.New("empty snapshot")
-			}
-			return body.CameraSnapshotResponse.Data, nil
+			return r.frame, nil
 		}
 	}
 }
@@ -2,6 +2,8 @@ package syncv2station
 
 import (
 	"context"
+	"database/sql"
+	"io"
 	"net"
 	"sync"
 	"testing"
@@ -11,6 +13,7 @@ import (
 	internalsyncv2 "tide/internal/syncv2"
 	"tide/pkg/custype"
 	syncpb "tide/pkg/pb/syncproto"
+	"tide/pkg/pbstream"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/yamux"
@@ -18,7 +21,9 @@ import (
 )
 
 type fakeStore struct {
-	stationID uuid.UUID
+	stationID  uuid.UUID
+	stationErr error
+	authKey    string
 
 	gotIdentifier string
 	gotRemoteAddr string
@@ -26,7 +31,25 @@ type fakeStore struct {
 	itemsLatest       map[string]int64
 	latestStatusRowID int64
 
+	dataOutboxID  string
+	dataSeq       int64
+	savedCursorCh chan int64
+
+	deviceConfigVersion int64
+	deviceConfig        []byte
+	deviceConfigApplied int64
+	deviceConfigErrorCh chan string
+
+	healthCh chan common.StationHealthTimeStruct
+
+	installedAt time.Time
+
 	mu                  sync.Mutex
+	clockOffset         time.Duration
+	clockDrift          bool
+	savedData           []float64
+	savedFlags          []common.QCFlag
+	quarantined         []quarantinedPoint
 	updateItemStatusLog []common.RowIdItemStatusStruct
 	updateItemStatus    []struct {
 		item   string
@@ -37,9 +60,16 @@ type fakeStore struct {
 
 func (s *fakeStore) StationIDByIdentifier(identifier string) (uuid.UUID, error) {
 	s.gotIdentifier = identifier
+	if s.stationErr != nil {
+		return uuid.Nil, s.stationErr
+	}
 	return s.stationID, nil
 }
 
+func (s *fakeStore) StationAuthKey(stationID uuid.UUID) (string, error) {
+	return s.authKey, nil
+}
+
 func (s *fakeStore) SetStationIP(stationID uuid.UUID, remoteAddr string) error {
 	s.gotRemoteAddr = remoteAddr
 	return nil
@@ -53,11 +83,44 @@ func (s *fakeStore) LatestStatusLogRowID(stationID uuid.UUID) (int64, error) {
 	return s.latestStatusRowID, nil
 }
 
+func (s *fakeStore) DataCursor(stationID uuid.UUID) (string, int64, error) {
+	return s.dataOutboxID, s.dataSeq, nil
+}
+
+func (s *fakeStore) SaveDataCursor(stationID uuid.UUID, outboxID string, seq int64) error {
+	s.dataOutboxID, s.dataSeq = outboxID, seq
+	if s.savedCursorCh != nil {
+		s.savedCursorCh <- seq
+	}
+	return nil
+}
+
+func (s *fakeStore) DeviceConfig(stationID uuid.UUID) (int64, []byte, error) {
+	return s.deviceConfigVersion, s.deviceConfig, nil
+}
+
+func (s *fakeStore) SaveDeviceConfigApplied(stationID uuid.UUID, version int64, applyError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deviceConfigApplied = version
+	return nil
+}
+
+func (s *fakeStore) SaveDeviceConfigError(stationID uuid.UUID, applyError string) error {
+	if s.deviceConfigErrorCh != nil {
+		s.deviceConfigErrorCh <- applyError
+	}
+	return nil
+}
+
 func (s *fakeStore) UpdateStationStatus(stationID uuid.UUID, status common.Status, at time.Time) (bool, error) {
 	return true, nil
 }
 
-func (s *fakeStore) SaveRpiStatus(stationID uuid.UUID, cpuTemp float64, at time.Time) error {
+func (s *fakeStore) SaveStationHealth(stationID uuid.UUID, health common.StationHealthStruct, at time.Time) error {
+	if s.healthCh != nil {
+		s.healthCh <- common.StationHealthTimeStruct{StationHealthStruct: health, Millisecond: custype.ToUnixMs(at)}
+	}
 	return nil
 }
 
@@ -72,10 +135,38 @@ func (s *fakeStore) UpdateItemStatus(stationID uuid.UUID, itemName string, statu
 	return true, nil
 }
 
-func (s *fakeStore) SaveDataHistory(stationID uuid.UUID, itemName string, value float64, at time.Time) (bool, error) {
+func (s *fakeStore) SaveDataHistory(stationID uuid.UUID, itemName string, value float64, flag common.QCFlag, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.savedData = append(s.savedData, value)
+	s.savedFlags = append(s.savedFlags, flag)
 	return true, nil
 }
 
+type quarantinedPoint struct {
+	value  float64
+	flag   common.QCFlag
+	reason string
+}
+
+func (s *fakeStore) StationInstalledAt(stationID uuid.UUID) (time.Time, error) {
+	return s.installedAt, nil
+}
+
+func (s *fakeStore) SaveClockOffset(stationID uuid.UUID, offset time.Duration, drift bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clockOffset, s.clockDrift = offset, drift
+	return nil
+}
+
+func (s *fakeStore) SaveQuarantinedData(stationID uuid.UUID, itemName string, value float64, flag common.QCFlag, at time.Time, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quarantined = append(s.quarantined, quarantinedPoint{value: value, flag: flag, reason: reason})
+	return nil
+}
+
 func (s *fakeStore) SaveItemStatusLog(stationID uuid.UUID, rowID int64, itemName string, status common.Status, at time.Time) (bool, error) {
 	return true, nil
 }
@@ -212,34 +303,81 @@ func (s *stationSessions) acceptClientCommandStream(timeout time.Duration) (inte
 	}
 }
 
-func doHandshake(t *testing.T, clientStream internalsyncv2.StationMessageStream, cameras []string) {
+const testAuthKey = "key1"
+
+func sendClientAuth(t *testing.T, clientStream internalsyncv2.StationMessageStream, authKey string) {
+	t.Helper()
+	sendClientAuthWithClockOffset(t, clientStream, authKey, 0)
+}
+
+// sendClientAuthWithClockOffset answers ServerHello as a station whose clock is offset behind the server.
+func sendClientAuthWithClockOffset(t *testing.T, clientStream internalsyncv2.StationMessageStream, authKey string, offset time.Duration) {
 	t.Helper()
 
-	require.NoError(t, clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ClientHello{
-		ClientHello: &syncpb.ClientHello{StationIdentifier: "station1", ProtocolVersion: internalsyncv2.ProtocolVersion},
-	}}))
 	f, err := clientStream.Recv()
 	require.NoError(t, err)
-	_, ok := f.Body.(*syncpb.StationMessage_ServerHello)
+	hello, ok := f.Body.(*syncpb.StationMessage_ServerHello)
 	require.True(t, ok)
+	require.NotEmpty(t, hello.ServerHello.AuthChallenge)
+	require.InDelta(t, time.Now().UnixMilli(), hello.ServerHello.ServerUnixMs, 1000)
 
-	require.NoError(t, clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StationInfo{
-		StationInfo: internalsyncv2.StationInfoToPB(common.StationInfoStruct{
-			Identifier: "station1",
-			Devices:    common.StringMapMap{"dev1": {"t1": "item1"}},
-			Cameras:    cameras,
-		}),
+	require.NoError(t, clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ClientAuth{
+		ClientAuth: &syncpb.ClientAuth{
+			Mac:           internalsyncv2.AuthMAC(authKey, hello.ServerHello.AuthChallenge, "station1"),
+			ClockOffsetMs: offset.Milliseconds(),
+		},
 	}}))
+}
+
+func doHandshake(t *testing.T, clientStream internalsyncv2.StationMessageStream, cameras []string) {
+	t.Helper()
+
+	sendHelloAndInfo(t, clientStream, "", cameras)
+
+	f, err := clientStream.Recv()
+	require.NoError(t, err)
+	_, ok := f.Body.(*syncpb.StationMessage_ItemsLatest)
+	require.True(t, ok)
 
 	f, err = clientStream.Recv()
 	require.NoError(t, err)
-	_, ok = f.Body.(*syncpb.StationMessage_ItemsLatest)
+	_, ok = f.Body.(*syncpb.StationMessage_StatusLatest)
+	require.True(t, ok)
+}
+
+// doCursorHandshake runs the handshake of a station with a data outbox and returns the received cursor.
+func doCursorHandshake(t *testing.T, clientStream internalsyncv2.StationMessageStream, outboxID string) int64 {
+	t.Helper()
+
+	sendHelloAndInfo(t, clientStream, outboxID, nil)
+
+	f, err := clientStream.Recv()
+	require.NoError(t, err)
+	cursor, ok := f.Body.(*syncpb.StationMessage_DataCursor)
 	require.True(t, ok)
 
 	f, err = clientStream.Recv()
 	require.NoError(t, err)
 	_, ok = f.Body.(*syncpb.StationMessage_StatusLatest)
 	require.True(t, ok)
+	return cursor.DataCursor.LastSeq
+}
+
+func sendHelloAndInfo(t *testing.T, clientStream internalsyncv2.StationMessageStream, outboxID string, cameras []string) {
+	t.Helper()
+
+	require.NoError(t, clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ClientHello{
+		ClientHello: &syncpb.ClientHello{StationIdentifier: "station1", ProtocolVersion: internalsyncv2.ProtocolVersion, OutboxId: outboxID},
+	}}))
+	sendClientAuth(t, clientStream, testAuthKey)
+
+	require.NoError(t, clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StationInfo{
+		StationInfo: internalsyncv2.StationInfoToPB(common.StationInfoStruct{
+			Identifier: "station1",
+			Devices:    common.StringMapMap{"dev1": {"t1": "item1"}},
+			Cameras:    cameras,
+		}),
+	}}))
 }
 
 func snapshotRequestCameraName(t *testing.T, stream internalsyncv2.StationMessageStream) string {
@@ -257,6 +395,7 @@ func TestServer_StreamStation_HandshakeAndLatest(t *testing.T) {
 	stationID := uuid.New()
 	store := &fakeStore{
 		stationID:         stationID,
+		authKey:           testAuthKey,
 		itemsLatest:       map[string]int64{"item1": 1234},
 		latestStatusRowID: 42,
 	}
@@ -281,10 +420,7 @@ func TestServer_StreamStation_HandshakeAndLatest(t *testing.T) {
 		ClientHello: &syncpb.ClientHello{StationIdentifier: "station1", ProtocolVersion: internalsyncv2.ProtocolVersion},
 	}}))
 
-	f, err := sessions.clientMainStream.Recv()
-	require.NoError(t, err)
-	_, ok := f.Body.(*syncpb.StationMessage_ServerHello)
-	require.True(t, ok)
+	sendClientAuth(t, sessions.clientMainStream, testAuthKey)
 
 	require.NoError(t, sessions.clientMainStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StationInfo{
 		StationInfo: internalsyncv2.StationInfoToPB(common.StationInfoStruct{
@@ -294,7 +430,7 @@ func TestServer_StreamStation_HandshakeAndLatest(t *testing.T) {
 		}),
 	}}))
 
-	f, err = sessions.clientMainStream.Recv()
+	f, err := sessions.clientMainStream.Recv()
 	require.NoError(t, err)
 	items, ok := f.Body.(*syncpb.StationMessage_ItemsLatest)
 	require.True(t, ok)
@@ -322,7 +458,7 @@ func TestServer_StreamStation_HandshakeAndLatest(t *testing.T) {
 
 func TestServer_RequestSnapshot_RoundTrip(t *testing.T) {
 	stationID := uuid.New()
-	store := &fakeStore{stationID: stationID, itemsLatest: map[string]int64{}, latestStatusRowID: 0}
+	store := &fakeStore{stationID: stationID, authKey: testAuthKey, itemsLatest: map[string]int64{}, latestStatusRowID: 0}
 	srv := &Server{
 		Store:      store,
 		InfoSyncer: &fakeInfoSyncer{},
@@ -373,9 +509,130 @@ func TestServer_RequestSnapshot_RoundTrip(t *testing.T) {
 	_ = <-errCh
 }
 
+func TestServer_RequestDeviceCommand_RoundTrip(t *testing.T) {
+	stationID := uuid.New()
+	store := &fakeStore{stationID: stationID, authKey: testAuthKey, itemsLatest: map[string]int64{}}
+	srv := &Server{Store: store, InfoSyncer: &fakeInfoSyncer{}, Notifier: &fakeNotifier{}}
+
+	_, err := srv.RequestDeviceCommand(stationID, &syncpb.DeviceCommandRequest{}, time.Second)
+	require.ErrorIs(t, err, ErrStationNotConnected)
+
+	sessions := newStationSessions(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.StreamStation(ctx, sessions.serverMainStream, sessions.openServerCommandStream, "1.2.3.4:5555")
+	}()
+	doHandshake(t, sessions.clientMainStream, nil)
+
+	respCh := make(chan *syncpb.DeviceCommandResponse, 1)
+	respErrCh := make(chan error, 1)
+	go func() {
+		resp, err := srv.RequestDeviceCommand(stationID, &syncpb.DeviceCommandRequest{
+			Bus: "/dev/ttyUSB0", Protocol: "text", Command: []byte("PW 1 3\r\n"),
+		}, 2*time.Second)
+		if err != nil {
+			respErrCh <- err
+			return
+		}
+		respCh <- resp
+	}()
+
+	cmdStream, err := sessions.acceptClientCommandStream(2 * time.Second)
+	require.NoError(t, err)
+	defer func() { _ = cmdStream.Close() }()
+	f, err := cmdStream.Recv()
+	require.NoError(t, err)
+	req, ok := f.Body.(*syncpb.StationMessage_DeviceCommandRequest)
+	require.True(t, ok)
+	require.Equal(t, "/dev/ttyUSB0", req.DeviceCommandRequest.Bus)
+	require.Equal(t, []byte("PW 1 3\r\n"), req.DeviceCommandRequest.Command)
+	require.NoError(t, cmdStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DeviceCommandResponse{
+		DeviceCommandResponse: &syncpb.DeviceCommandResponse{Output: []byte("partial"), Error: "timeout"},
+	}}))
+
+	select {
+	case resp := <-respCh:
+		require.Equal(t, []byte("partial"), resp.Output)
+		require.Equal(t, "timeout", resp.Error)
+	case err := <-respErrCh:
+		t.Fatalf("device command error: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for device command response")
+	}
+
+	_ = sessions.clientSession.Close()
+	cancel()
+	_ = <-errCh
+}
+
+func TestServer_FollowLogs(t *testing.T) {
+	stationID := uuid.New()
+	store := &fakeStore{stationID: stationID, authKey: testAuthKey, itemsLatest: map[string]int64{}}
+	srv := &Server{Store: store, InfoSyncer: &fakeInfoSyncer{}, Notifier: &fakeNotifier{}}
+
+	sessions := newStationSessions(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.StreamStation(ctx, sessions.serverMainStream, sessions.openServerCommandStream, "1.2.3.4:5555")
+	}()
+	doHandshake(t, sessions.clientMainStream, nil)
+
+	followCtx, stopFollow := context.WithCancel(context.Background())
+	t.Cleanup(stopFollow)
+	entriesCh := make(chan []*syncpb.LogEntry, 2)
+	followErrCh := make(chan error, 1)
+	go func() {
+		followErrCh <- srv.FollowLogs(followCtx, stationID, &syncpb.LogRequest{MinLevel: 4, Device: "PWD50"}, func(entries []*syncpb.LogEntry) error {
+			entriesCh <- entries
+			return nil
+		})
+	}()
+
+	cmdStream, err := sessions.acceptClientCommandStream(2 * time.Second)
+	require.NoError(t, err)
+	defer func() { _ = cmdStream.Close() }()
+	f, err := cmdStream.Recv()
+	require.NoError(t, err)
+	req, ok := f.Body.(*syncpb.StationMessage_LogRequest)
+	require.True(t, ok)
+	require.True(t, req.LogRequest.Follow)
+	require.Equal(t, int32(4), req.LogRequest.MinLevel)
+	require.Equal(t, "PWD50", req.LogRequest.Device)
+
+	for _, msg := range []string{"buffered", "new"} {
+		require.NoError(t, cmdStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_LogBatch{
+			LogBatch: &syncpb.LogBatch{Entries: []*syncpb.LogEntry{{Message: msg, Level: 4}}},
+		}}))
+		select {
+		case entries := <-entriesCh:
+			require.Len(t, entries, 1)
+			require.Equal(t, msg, entries[0].Message)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for log entries")
+		}
+	}
+
+	// Stopping closes the stream, the station then closes its side too.
+	stopFollow()
+	_, err = cmdStream.Recv()
+	require.ErrorIs(t, err, io.EOF)
+	require.NoError(t, cmdStream.Close())
+	require.ErrorIs(t, <-followErrCh, context.Canceled)
+
+	_ = sessions.clientSession.Close()
+	cancel()
+	_ = <-errCh
+}
+
 func TestServer_RequestSnapshot_EmptyResponseError(t *testing.T) {
 	stationID := uuid.New()
-	store := &fakeStore{stationID: stationID, itemsLatest: map[string]int64{}, latestStatusRowID: 0}
+	store := &fakeStore{stationID: stationID, authKey: testAuthKey, itemsLatest: map[string]int64{}, latestStatusRowID: 0}
 	srv := &Server{
 		Store:      store,
 		InfoSyncer: &fakeInfoSyncer{},
@@ -421,7 +678,7 @@ func TestServer_RequestSnapshot_EmptyResponseError(t *testing.T) {
 
 func TestServer_RequestSnapshot_ConcurrentSubstreams(t *testing.T) {
 	stationID := uuid.New()
-	store := &fakeStore{stationID: stationID, itemsLatest: map[string]int64{}, latestStatusRowID: 0}
+	store := &fakeStore{stationID: stationID, authKey: testAuthKey, itemsLatest: map[string]int64{}, latestStatusRowID: 0}
 	srv := &Server{
 		Store:      store,
 		InfoSyncer: &fakeInfoSyncer{},
@@ -525,7 +782,7 @@ func TestServer_RequestSnapshot_ConcurrentSubstreams(t *testing.T) {
 
 func TestServer_StreamStation_DataBatchReplayVsRealtime(t *testing.T) {
 	stationID := uuid.New()
-	store := &fakeStore{stationID: stationID, itemsLatest: map[string]int64{}, latestStatusRowID: 0}
+	store := &fakeStore{stationID: stationID, authKey: testAuthKey, itemsLatest: map[string]int64{}, latestStatusRowID: 0}
 	infoSyncer := &fakeInfoSyncer{}
 	notifier := &fakeNotifier{
 		missDataCh:     make(chan common.DataTimeStruct, 1),
@@ -569,6 +826,7 @@ func TestServer_StreamStation_DataBatchReplayVsRealtime(t *testing.T) {
 			Points: []*syncpb.DataPoint{
 				{ItemName: "item1", Value: 2, UnixMs: 2000, Kind: syncpb.DataKind_DATA_KIND_GPIO},
 			},
+			BatchId: 7,
 		},
 	}}))
 	select {
@@ -577,6 +835,11 @@ func TestServer_StreamStation_DataBatchReplayVsRealtime(t *testing.T) {
 	case <-time.After(2 * time.Second):
 		t.Fatal("timeout waiting for realtime data notifier")
 	}
+	f, err := sessions.clientMainStream.Recv()
+	require.NoError(t, err)
+	ack, ok := f.Body.(*syncpb.StationMessage_DataAck)
+	require.True(t, ok)
+	require.Equal(t, uint64(7), ack.DataAck.BatchId)
 
 	store.mu.Lock()
 	defer store.mu.Unlock()
@@ -593,3 +856,436 @@ func TestServer_StreamStation_DataBatchReplayVsRealtime(t *testing.T) {
 	cancel()
 	_ = <-errCh
 }
+
+func TestServer_StreamStation_DataCursorHandshake(t *testing.T) {
+	tests := []struct {
+		name        string
+		storedID    string
+		storedSeq   int64
+		wantLastSeq int64
+		wantReset   bool
+	}{
+		{name: "same outbox resumes", storedID: "outbox1", storedSeq: 7, wantLastSeq: 7},
+		{name: "new outbox starts over", storedID: "outbox0", storedSeq: 7, wantLastSeq: 0, wantReset: true},
+		{name: "first connection", wantLastSeq: 0, wantReset: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{
+				stationID:     uuid.New(),
+				authKey:       testAuthKey,
+				dataOutboxID:  tt.storedID,
+				dataSeq:       tt.storedSeq,
+				savedCursorCh: make(chan int64, 1),
+			}
+			srv := &Server{Store: store, InfoSyncer: &fakeInfoSyncer{}, Notifier: &fakeNotifier{}}
+
+			sessions := newStationSessions(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- srv.StreamStation(ctx, sessions.serverMainStream, sessions.openServerCommandStream, "1.2.3.4:5555")
+			}()
+			require.Equal(t, tt.wantLastSeq, doCursorHandshake(t, sessions.clientMainStream, "outbox1"))
+			if tt.wantReset {
+				require.Equal(t, int64(0), <-store.savedCursorCh)
+				require.Equal(t, "outbox1", store.dataOutboxID)
+			} else {
+				require.Empty(t, store.savedCursorCh)
+			}
+
+			_ = sessions.clientSession.Close()
+			cancel()
+			_ = <-errCh
+		})
+	}
+}
+
+func TestServer_StreamStation_DataCursorAdvance(t *testing.T) {
+	store := &fakeStore{
+		stationID:     uuid.New(),
+		authKey:       testAuthKey,
+		dataOutboxID:  "outbox1",
+		dataSeq:       10,
+		savedCursorCh: make(chan int64, 4),
+	}
+	srv := &Server{Store: store, InfoSyncer: &fakeInfoSyncer{}, Notifier: &fakeNotifier{}}
+
+	sessions := newStationSessions(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.StreamStation(ctx, sessions.serverMainStream, sessions.openServerCommandStream, "1.2.3.4:5555")
+	}()
+	require.Equal(t, int64(10), doCursorHandshake(t, sessions.clientMainStream, "outbox1"))
+
+	sendPoints := func(replay bool, seqs ...int64) {
+		points := make([]*syncpb.DataPoint, 0, len(seqs))
+		for _, seq := range seqs {
+			points = append(points, &syncpb.DataPoint{ItemName: "item1", Value: 1, UnixMs: 1000 + seq, Seq: seq})
+		}
+		require.NoError(t, sessions.clientMainStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataBatch{
+			DataBatch: &syncpb.DataBatch{Replay: replay, Points: points},
+		}}))
+	}
+	recvSaved := func() int64 {
+		select {
+		case seq := <-store.savedCursorCh:
+			return seq
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for saved data cursor")
+			return 0
+		}
+	}
+
+	// Replay skips rows deleted on the client, the cursor follows it.
+	sendPoints(true, 11, 13)
+	require.Equal(t, int64(13), recvSaved())
+
+	sendPoints(false, 14)
+	require.Equal(t, int64(14), recvSaved())
+
+	// 15 is missing so 16 does not advance, otherwise the next save would be 16.
+	sendPoints(false, 16)
+	sendPoints(false, 15)
+	require.Equal(t, int64(15), recvSaved())
+
+	_ = sessions.clientSession.Close()
+	cancel()
+	_ = <-errCh
+}
+
+func TestServer_StreamStation_CompressedColumnarData(t *testing.T) {
+	store := &fakeStore{
+		stationID:     uuid.New(),
+		authKey:       testAuthKey,
+		dataOutboxID:  "outbox1",
+		savedCursorCh: make(chan int64, 1),
+	}
+	notifier := &fakeNotifier{missDataCh: make(chan common.DataTimeStruct, 2)}
+	srv := &Server{Store: store, InfoSyncer: &fakeInfoSyncer{}, Notifier: notifier}
+
+	sessions := newStationSessions(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.StreamStation(ctx, sessions.serverMainStream, sessions.openServerCommandStream, "1.2.3.4:5555")
+	}()
+
+	clientStream := sessions.clientMainStream
+	require.NoError(t, clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ClientHello{
+		ClientHello: &syncpb.ClientHello{
+			StationIdentifier: "station1",
+			ProtocolVersion:   internalsyncv2.ProtocolVersion,
+			OutboxId:          "outbox1",
+			Compressions:      []string{"br", pbstream.CompressionZstd},
+		},
+	}}))
+	f, err := clientStream.Recv()
+	require.NoError(t, err)
+	hello, ok := f.Body.(*syncpb.StationMessage_ServerHello)
+	require.True(t, ok)
+	require.Equal(t, pbstream.CompressionZstd, hello.ServerHello.Compression)
+	require.True(t, hello.ServerHello.ColumnarData)
+	require.NoError(t, clientStream.SetCompression(pbstream.CompressionZstd))
+	require.NoError(t, clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ClientAuth{
+		ClientAuth: &syncpb.ClientAuth{Mac: internalsyncv2.AuthMAC(testAuthKey, hello.ServerHello.AuthChallenge, "station1")},
+	}}))
+	require.NoError(t, clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StationInfo{
+		StationInfo: internalsyncv2.StationInfoToPB(common.StationInfoStruct{
+			Identifier: "station1",
+			Devices:    common.StringMapMap{"dev1": {"t1": "item1"}},
+		}),
+	}}))
+	f, err = clientStream.Recv()
+	require.NoError(t, err)
+	_, ok = f.Body.(*syncpb.StationMessage_DataCursor)
+	require.True(t, ok)
+	f, err = clientStream.Recv()
+	require.NoError(t, err)
+	_, ok = f.Body.(*syncpb.StationMessage_StatusLatest)
+	require.True(t, ok)
+
+	require.NoError(t, clientStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataBatch{
+		DataBatch: &syncpb.DataBatch{
+			Replay: true,
+			Columns: internalsyncv2.DataColumns([]*syncpb.DataPoint{
+				{ItemName: "item1", Value: 1.5, UnixMs: 1000, Kind: syncpb.DataKind_DATA_KIND_NORMAL, Seq: 1},
+				{ItemName: "item1", Value: 1.25, UnixMs: 2000, Kind: syncpb.DataKind_DATA_KIND_NORMAL, Seq: 2},
+			}),
+		},
+	}}))
+	for _, want := range []float64{1.5, 1.25} {
+		select {
+		case d := <-notifier.missDataCh:
+			require.Equal(t, want, d.Value)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for miss data notifier")
+		}
+	}
+	select {
+	case seq := <-store.savedCursorCh:
+		require.Equal(t, int64(2), seq)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for saved data cursor")
+	}
+
+	_ = sessions.clientSession.Close()
+	cancel()
+	_ = <-errCh
+}
+
+func TestServer_StreamStation_SavesStationHealth(t *testing.T) {
+	store := &fakeStore{
+		stationID:   uuid.New(),
+		authKey:     testAuthKey,
+		itemsLatest: map[string]int64{},
+		healthCh:    make(chan common.StationHealthTimeStruct, 2),
+	}
+	srv := &Server{Store: store, InfoSyncer: &fakeInfoSyncer{}, Notifier: &fakeNotifier{}}
+	sessions := newStationSessions(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.StreamStation(ctx, sessions.serverMainStream, sessions.openServerCommandStream, "1.2.3.4:5555")
+	}()
+	doHandshake(t, sessions.clientMainStream, nil)
+
+	cpuTemp, oldCpuTemp := 51.2, 40.0
+	require.NoError(t, sessions.clientMainStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StationHealth{
+		StationHealth: &syncpb.StationHealth{
+			UnixMs:        5000,
+			CpuTemp:       &cpuTemp,
+			Disks:         []*syncpb.DiskUsage{{Path: "/data", FreeBytes: 1 << 30, TotalBytes: 1 << 34}},
+			ReplayBacklog: 12,
+			SerialReopens: map[string]uint32{"/dev/ttyUSB0": 3},
+			ClockSynced:   true,
+		},
+	}}))
+	// Older stations only report the CPU temperature.
+	require.NoError(t, sessions.clientMainStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_RpiStatus{
+		RpiStatus: &syncpb.RpiStatus{CpuTemp: oldCpuTemp, UnixMs: 6000},
+	}}))
+
+	for _, want := range []common.StationHealthTimeStruct{
+		{
+			StationHealthStruct: common.StationHealthStruct{
+				CpuTemp:       &cpuTemp,
+				Disks:         []common.DiskUsageStruct{{Path: "/data", FreeBytes: 1 << 30, TotalBytes: 1 << 34}},
+				ReplayBacklog: 12,
+				SerialReopens: map[string]uint32{"/dev/ttyUSB0": 3},
+				ClockSynced:   true,
+			},
+			Millisecond: 5000,
+		},
+		{
+			StationHealthStruct: common.StationHealthStruct{CpuTemp: &oldCpuTemp},
+			Millisecond:         6000,
+		},
+	} {
+		select {
+		case got := <-store.healthCh:
+			require.Equal(t, want, got)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for station health")
+		}
+	}
+
+	_ = sessions.clientSession.Close()
+	cancel()
+	_ = <-errCh
+}
+
+func TestServer_StreamStation_ClockDriftAndQuarantine(t *testing.T) {
+	store := &fakeStore{
+		stationID:   uuid.New(),
+		authKey:     testAuthKey,
+		itemsLatest: map[string]int64{},
+		installedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	srv := &Server{Store: store, InfoSyncer: &fakeInfoSyncer{}, Notifier: &fakeNotifier{}}
+	sessions := newStationSessions(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.StreamStation(ctx, sessions.serverMainStream, sessions.openServerCommandStream, "1.2.3.4:5555")
+	}()
+	stream := sessions.clientMainStream
+	require.NoError(t, stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ClientHello{
+		ClientHello: &syncpb.ClientHello{StationIdentifier: "station1", ProtocolVersion: internalsyncv2.ProtocolVersion},
+	}}))
+	sendClientAuthWithClockOffset(t, stream, testAuthKey, 2*time.Hour)
+	require.NoError(t, stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StationInfo{
+		StationInfo: internalsyncv2.StationInfoToPB(common.StationInfoStruct{
+			Identifier: "station1",
+			Devices:    common.StringMapMap{"dev1": {"t1": "item1"}},
+		}),
+	}}))
+	for range 2 { // ItemsLatest, StatusLatest
+		_, err := stream.Recv()
+		require.NoError(t, err)
+	}
+
+	now := time.Now()
+	require.NoError(t, stream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DataBatch{
+		DataBatch: &syncpb.DataBatch{
+			Points: []*syncpb.DataPoint{
+				{ItemName: "item1", Value: 1, UnixMs: now.UnixMilli()},
+				{ItemName: "item1", Value: 2, UnixMs: now.Add(24 * time.Hour).UnixMilli(), Flag: uint32(common.QCBad)},
+				{ItemName: "item1", Value: 3, UnixMs: store.installedAt.Add(-time.Hour).UnixMilli()},
+				{ItemName: "item1", Value: 4, UnixMs: now.Add(5 * time.Minute).UnixMilli(), Flag: uint32(common.QCProbablyBad)},
+			},
+			BatchId: 1,
+		},
+	}}))
+	f, err := stream.Recv()
+	require.NoError(t, err)
+	_, ok := f.Body.(*syncpb.StationMessage_DataAck)
+	require.True(t, ok)
+
+	store.mu.Lock()
+	require.Equal(t, 2*time.Hour, store.clockOffset)
+	require.True(t, store.clockDrift)
+	require.Equal(t, []float64{1, 4}, store.savedData)
+	require.Equal(t, []common.QCFlag{common.QCNone, common.QCProbablyBad}, store.savedFlags)
+	require.Equal(t, []quarantinedPoint{{2, common.QCBad, QuarantineFuture}, {3, common.QCNone, QuarantineBeforeInstallation}}, store.quarantined)
+	store.mu.Unlock()
+
+	_ = sessions.clientSession.Close()
+	cancel()
+	_ = <-errCh
+}
+
+func TestServer_StreamStation_PushesStaleDeviceConfig(t *testing.T) {
+	stationID := uuid.New()
+	store := &fakeStore{
+		stationID:           stationID,
+		authKey:             testAuthKey,
+		itemsLatest:         map[string]int64{},
+		deviceConfigVersion: 3,
+		deviceConfig:        []byte(`{"uart":[]}`),
+		deviceConfigErrorCh: make(chan string, 1),
+	}
+	srv := &Server{Store: store, InfoSyncer: &fakeInfoSyncer{}, Notifier: &fakeNotifier{}}
+	require.ErrorIs(t, srv.PushDeviceConfig(stationID), ErrStationNotConnected)
+
+	sessions := newStationSessions(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.StreamStation(ctx, sessions.serverMainStream, sessions.openServerCommandStream, "1.2.3.4:5555")
+	}()
+	// The station reports version 0, so version 3 is pushed right after the handshake.
+	doHandshake(t, sessions.clientMainStream, nil)
+
+	cmdStream, err := sessions.acceptClientCommandStream(2 * time.Second)
+	require.NoError(t, err)
+	defer func() { _ = cmdStream.Close() }()
+	f, err := cmdStream.Recv()
+	require.NoError(t, err)
+	push, ok := f.Body.(*syncpb.StationMessage_DeviceConfigPush)
+	require.True(t, ok)
+	require.Equal(t, int64(3), push.DeviceConfigPush.Version)
+	require.JSONEq(t, `{"uart":[]}`, string(push.DeviceConfigPush.Config))
+	require.NoError(t, cmdStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_DeviceConfigResult{
+		DeviceConfigResult: &syncpb.DeviceConfigResult{Version: 3, Error: "unknown conn type"},
+	}}))
+
+	select {
+	case applyErr := <-store.deviceConfigErrorCh:
+		require.Equal(t, "unknown conn type", applyErr)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for device config error")
+	}
+
+	// Once the station reports the version as applied, it is not pushed again.
+	require.NoError(t, sessions.clientMainStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_StationInfo{
+		StationInfo: internalsyncv2.StationInfoToPB(common.StationInfoStruct{
+			Identifier:          "station1",
+			Devices:             common.StringMapMap{"dev1": {"t1": "item1"}},
+			DeviceConfigVersion: 3,
+		}),
+	}}))
+	require.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return store.deviceConfigApplied == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	_ = sessions.clientSession.Close()
+	cancel()
+	_ = <-errCh
+}
+
+func TestServer_StreamStation_RejectsUnauthenticated(t *testing.T) {
+	tests := []struct {
+		name           string
+		stationErr     error
+		storeKey       string
+		clientKey      string
+		certIdentities []string
+	}{
+		{name: "wrong key", storeKey: testAuthKey, clientKey: "other"},
+		{name: "unknown station", stationErr: sql.ErrNoRows, storeKey: testAuthKey, clientKey: testAuthKey},
+		{name: "key not configured", storeKey: "", clientKey: ""},
+		{name: "client certificate mismatch", storeKey: testAuthKey, clientKey: testAuthKey, certIdentities: []string{"station2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{stationID: uuid.New(), stationErr: tt.stationErr, authKey: tt.storeKey}
+			infoSyncer := &fakeInfoSyncer{}
+			srv := &Server{
+				Store:      store,
+				InfoSyncer: infoSyncer,
+				Notifier:   &fakeNotifier{},
+			}
+
+			sessions := newStationSessions(t)
+			ctx := context.Background()
+			if tt.certIdentities != nil {
+				ctx = WithCertIdentities(ctx, tt.certIdentities)
+			}
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- srv.StreamStation(ctx, sessions.serverMainStream, sessions.openServerCommandStream, "1.2.3.4:5555")
+			}()
+
+			require.NoError(t, sessions.clientMainStream.Send(&syncpb.StationMessage{Body: &syncpb.StationMessage_ClientHello{
+				ClientHello: &syncpb.ClientHello{StationIdentifier: "station1", ProtocolVersion: internalsyncv2.ProtocolVersion},
+			}}))
+			// Every station gets a challenge and is only rejected after answering it.
+			sendClientAuth(t, sessions.clientMainStream, tt.clientKey)
+
+			f, err := sessions.clientMainStream.Recv()
+			require.NoError(t, err)
+			errFrame, ok := f.Body.(*syncpb.StationMessage_Error)
+			require.True(t, ok)
+			require.Equal(t, internalsyncv2.ErrCodeUnauthenticated, errFrame.Error.Code)
+			require.False(t, errFrame.Error.Retryable)
+
+			select {
+			case err = <-errCh:
+				require.Error(t, err)
+			case <-time.After(2 * time.Second):
+				t.Fatal("timeout waiting for server to reject station")
+			}
+			infoSyncer.mu.Lock()
+			require.Zero(t, infoSyncer.callCount)
+			infoSyncer.mu.Unlock()
+			_, connected := srv.reg.Load(store.stationID)
+			require.False(t, connected)
+		})
+	}
+}
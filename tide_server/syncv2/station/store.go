@@ -10,17 +10,30 @@ import (
 
 type Store interface {
 	StationIDByIdentifier(identifier string) (uuid.UUID, error)
+	StationAuthKey(stationID uuid.UUID) (string, error)
 	SetStationIP(stationID uuid.UUID, remoteAddr string) error
 
 	ItemsLatest(stationID uuid.UUID, devices common.StringMapMap) (map[string]int64, error)
 	LatestStatusLogRowID(stationID uuid.UUID) (int64, error)
+	DataCursor(stationID uuid.UUID) (outboxID string, seq int64, err error)
+	SaveDataCursor(stationID uuid.UUID, outboxID string, seq int64) error
+
+	// DeviceConfig returns version 0 if the station has no device config on the server.
+	DeviceConfig(stationID uuid.UUID) (version int64, config []byte, err error)
+	SaveDeviceConfigApplied(stationID uuid.UUID, version int64, applyError string) error
+	SaveDeviceConfigError(stationID uuid.UUID, applyError string) error
 
 	UpdateStationStatus(stationID uuid.UUID, status common.Status, at time.Time) (changed bool, err error)
 
-	SaveRpiStatus(stationID uuid.UUID, cpuTemp float64, at time.Time) error
+	// StationInstalledAt returns the zero time if the installation time of the station is not set.
+	StationInstalledAt(stationID uuid.UUID) (time.Time, error)
+	SaveClockOffset(stationID uuid.UUID, offset time.Duration, drift bool) error
+	SaveQuarantinedData(stationID uuid.UUID, itemName string, value float64, flag common.QCFlag, at time.Time, reason string) error
+
+	SaveStationHealth(stationID uuid.UUID, health common.StationHealthStruct, at time.Time) error
 
 	UpdateItemStatus(stationID uuid.UUID, itemName string, status common.Status, at time.Time) (changed bool, err error)
-	SaveDataHistory(stationID uuid.UUID, itemName string, value float64, at time.Time) (inserted bool, err error)
+	SaveDataHistory(stationID uuid.UUID, itemName string, value float64, flag common.QCFlag, at time.Time) (inserted bool, err error)
 
 	SaveItemStatusLog(stationID uuid.UUID, rowID int64, itemName string, status common.Status, at time.Time) (inserted bool, err error)
 	UpdateAndSaveStatusLog(stationID uuid.UUID, rowID int64, itemName string, status common.Status, at time.Time) (inserted bool, err error)
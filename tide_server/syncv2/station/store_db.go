@@ -1,6 +1,8 @@
 package syncv2station
 
 import (
+	"database/sql"
+	"errors"
 	"time"
 
 	"tide/common"
@@ -16,6 +18,10 @@ func (DBStore) StationIDByIdentifier(identifier string) (uuid.UUID, error) {
 	return db.GetLocalStationIdByIdentifier(identifier)
 }
 
+func (DBStore) StationAuthKey(stationID uuid.UUID) (string, error) {
+	return db.GetStationAuthKey(stationID)
+}
+
 func (DBStore) SetStationIP(stationID uuid.UUID, remoteAddr string) error {
 	return db.EditStationNotSync(stationID, remoteAddr)
 }
@@ -41,13 +47,57 @@ func (DBStore) LatestStatusLogRowID(stationID uuid.UUID) (int64, error) {
 	return db.GetLatestStatusLogRowId(stationID)
 }
 
+func (DBStore) DataCursor(stationID uuid.UUID) (string, int64, error) {
+	return db.GetStationDataCursor(stationID)
+}
+
+func (DBStore) SaveDataCursor(stationID uuid.UUID, outboxID string, seq int64) error {
+	_, err := db.SaveStationDataCursor(stationID, outboxID, seq)
+	return err
+}
+
+func (DBStore) DeviceConfig(stationID uuid.UUID) (int64, []byte, error) {
+	c, err := db.GetStationDeviceConfig(stationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, nil
+	}
+	return c.Version, c.Config, err
+}
+
+func (DBStore) SaveDeviceConfigApplied(stationID uuid.UUID, version int64, applyError string) error {
+	_, err := db.UpdateStationDeviceConfigApplied(stationID, version, applyError)
+	return err
+}
+
+func (DBStore) SaveDeviceConfigError(stationID uuid.UUID, applyError string) error {
+	_, err := db.UpdateStationDeviceConfigError(stationID, applyError)
+	return err
+}
+
 func (DBStore) UpdateStationStatus(stationID uuid.UUID, status common.Status, at time.Time) (bool, error) {
 	n, err := db.UpdateStationStatus(stationID, status, at)
 	return n > 0, err
 }
 
-func (DBStore) SaveRpiStatus(stationID uuid.UUID, cpuTemp float64, at time.Time) error {
-	return db.SaveRpiStatus(stationID, cpuTemp, at)
+func (DBStore) StationInstalledAt(stationID uuid.UUID) (time.Time, error) {
+	installedAt, err := db.GetStationInstalledAt(stationID)
+	if err != nil || installedAt == 0 {
+		return time.Time{}, err
+	}
+	return installedAt.ToTime(), nil
+}
+
+func (DBStore) SaveClockOffset(stationID uuid.UUID, offset time.Duration, drift bool) error {
+	_, err := db.SaveStationClockOffset(stationID, offset.Milliseconds(), drift)
+	return err
+}
+
+func (DBStore) SaveQuarantinedData(stationID uuid.UUID, itemName string, value float64, flag common.QCFlag, at time.Time, reason string) error {
+	return db.SaveQuarantinedData(stationID, itemName, value, flag, custype.ToUnixMs(at), reason)
+}
+
+func (DBStore) SaveStationHealth(stationID uuid.UUID, health common.StationHealthStruct, at time.Time) error {
+	return db.SaveStationHealth(stationID, health, at)
 }
 
 func (DBStore) UpdateItemStatus(stationID uuid.UUID, itemName string, status common.Status, at time.Time) (bool, error) {
@@ -55,8 +105,8 @@ func (DBStore) UpdateItemStatus(stationID uuid.UUID, itemName string, status com
 	return n > 0, err
 }
 
-func (DBStore) SaveDataHistory(stationID uuid.UUID, itemName string, value float64, at time.Time) (bool, error) {
-	n, err := db.SaveDataHistory(stationID, itemName, value, at)
+func (DBStore) SaveDataHistory(stationID uuid.UUID, itemName string, value float64, flag common.QCFlag, at time.Time) (bool, error) {
+	n, err := db.SaveDataHistory(stationID, itemName, value, flag, at)
 	return n > 0, err
 }
 